@@ -0,0 +1,269 @@
+// Package analytics records product events, such as a signup or a created
+// todo, and delivers them in batches to a sink: stdout, a file or an HTTP
+// collector.
+//
+// Handlers record events with c.Track once App.Analytics is set. Events are
+// buffered in memory and written in the background, so tracking never blocks
+// a request; when the buffer is full, events are dropped and counted.
+// RunWithShutdown flushes the remaining events after the server stopped.
+//
+// Example:
+//
+//	app.Analytics = analytics.New(analytics.HTTP("https://collector.example.com/events", nil))
+//
+//	app.POST("/todos", func(c *context.Context) error {
+//	    ...
+//	    c.Track("todo_created", map[string]interface{}{"list": todo.ListID})
+//	    return c.JSON(201, todo)
+//	})
+package analytics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JedizLaPulga/kese/logger"
+)
+
+// ErrClosed is returned by Flush and Close after the tracker was closed.
+var ErrClosed = errors.New("analytics: tracker closed")
+
+// Event is a product event.
+type Event struct {
+	// Name identifies the event, e.g. "todo_created"
+	Name string `json:"name"`
+
+	// Time is when the event happened
+	Time time.Time `json:"time"`
+
+	// Properties are the event's details
+	Properties map[string]interface{} `json:"properties,omitempty"`
+
+	// RequestID, Path and Client describe the request that recorded the
+	// event; Client is the parsed User-Agent, e.g. "Chrome 120 (Windows 10)"
+	RequestID string `json:"request_id,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Client    string `json:"client,omitempty"`
+
+	// Bot reports an event recorded for a crawler or script
+	Bot bool `json:"bot,omitempty"`
+}
+
+// Sink receives batches of events. Write is called from a single goroutine.
+// Sinks that implement io.Closer are closed by Tracker.Close.
+type Sink interface {
+	Write(ctx context.Context, events []Event) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(ctx context.Context, events []Event) error
+
+// Write calls f(ctx, events).
+func (f SinkFunc) Write(ctx context.Context, events []Event) error {
+	return f(ctx, events)
+}
+
+// Config holds configuration for a Tracker.
+type Config struct {
+	// Sink receives the batches
+	Sink Sink
+
+	// BatchSize is the largest batch written at once. Default: 100
+	BatchSize int
+
+	// FlushInterval is how often incomplete batches are written. Default: 5 seconds
+	FlushInterval time.Duration
+
+	// BufferSize is how many events wait for the sink before new ones are
+	// dropped. Default: 10000
+	BufferSize int
+
+	// WriteTimeout bounds each Sink.Write. Default: 10 seconds
+	WriteTimeout time.Duration
+
+	// Logger logs failed writes; their events are lost. Default: logger.New()
+	Logger *logger.Logger
+}
+
+// DefaultConfig returns the default configuration writing to sink.
+func DefaultConfig(sink Sink) Config {
+	return Config{
+		Sink:          sink,
+		BatchSize:     100,
+		FlushInterval: 5 * time.Second,
+		BufferSize:    10000,
+		WriteTimeout:  10 * time.Second,
+		Logger:        logger.New(),
+	}
+}
+
+// Tracker buffers events and writes them to its sink in batches. It is safe
+// for concurrent use.
+type Tracker struct {
+	config  Config
+	events  chan Event
+	flush   chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	closed  atomic.Bool
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// New returns a Tracker writing to sink with the default configuration.
+func New(sink Sink) *Tracker {
+	return NewWithConfig(DefaultConfig(sink))
+}
+
+// NewWithConfig returns a Tracker with custom configuration. Zero fields use
+// their defaults. It panics if config.Sink is nil.
+func NewWithConfig(config Config) *Tracker {
+	if config.Sink == nil {
+		panic("analytics: Sink is nil")
+	}
+	defaults := DefaultConfig(config.Sink)
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaults.BufferSize
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaults.WriteTimeout
+	}
+	if config.Logger == nil {
+		config.Logger = defaults.Logger
+	}
+
+	t := &Tracker{
+		config: config,
+		events: make(chan Event, config.BufferSize),
+		flush:  make(chan chan struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Track records event without blocking. A zero Time is set to now. The event
+// is dropped if the tracker is closed or its buffer is full.
+func (t *Tracker) Track(event Event) {
+	if t.closed.Load() {
+		t.dropped.Add(1)
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	select {
+	case t.events <- event:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+// or the tracker was closed.
+func (t *Tracker) Dropped() uint64 {
+	return t.dropped.Load()
+}
+
+// Flush writes the buffered events and waits until they are written or ctx
+// is done.
+func (t *Tracker) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case t.flush <- done:
+	case <-t.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting events, writes the buffered ones and closes the
+// sink if it is an io.Closer. It waits until then or until ctx is done.
+func (t *Tracker) Close(ctx context.Context) error {
+	t.once.Do(func() {
+		t.closed.Store(true)
+		close(t.stop)
+	})
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if closer, ok := t.config.Sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// run batches events until the tracker is closed.
+func (t *Tracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, t.config.BatchSize)
+	write := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), t.config.WriteTimeout)
+		defer cancel()
+		if err := t.config.Sink.Write(ctx, batch); err != nil {
+			t.config.Logger.Error("Failed to write analytics events", "events", len(batch), "error", err.Error())
+		}
+		// Sinks may keep the slice, e.g. in tests
+		batch = make([]Event, 0, t.config.BatchSize)
+	}
+	// drain moves the buffered events into batches
+	drain := func() {
+		for {
+			select {
+			case event := <-t.events:
+				batch = append(batch, event)
+				if len(batch) >= t.config.BatchSize {
+					write()
+				}
+			default:
+				write()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case event := <-t.events:
+			batch = append(batch, event)
+			if len(batch) >= t.config.BatchSize {
+				write()
+			}
+		case <-ticker.C:
+			write()
+		case done := <-t.flush:
+			drain()
+			close(done)
+		case <-t.stop:
+			drain()
+			return
+		}
+	}
+}
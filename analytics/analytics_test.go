@@ -0,0 +1,130 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a Sink keeping the batches it receives.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]Event
+}
+
+func (r *recorder) Write(ctx context.Context, events []Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, events)
+	return nil
+}
+
+func (r *recorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, len(r.batches))
+	for i, batch := range r.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+func TestTrackerBatches(t *testing.T) {
+	sink := &recorder{}
+	tracker := NewWithConfig(Config{Sink: sink, BatchSize: 2, FlushInterval: time.Hour})
+
+	for i := 0; i < 5; i++ {
+		tracker.Track(Event{Name: "todo_created"})
+	}
+	if err := tracker.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got := sink.sizes(); len(got) != 3 || got[0] != 2 || got[1] != 2 || got[2] != 1 {
+		t.Errorf("expected batches of 2, 2 and 1, got %v", got)
+	}
+	if sink.batches[0][0].Time.IsZero() {
+		t.Error("Track should set the event time")
+	}
+
+	// Close writes the remaining events and rejects new ones
+	tracker.Track(Event{Name: "todo_deleted"})
+	if err := tracker.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := sink.sizes(); len(got) != 4 {
+		t.Errorf("Close should flush the buffered event, got batches %v", got)
+	}
+	tracker.Track(Event{Name: "late"})
+	if tracker.Dropped() != 1 {
+		t.Errorf("expected 1 dropped event, got %d", tracker.Dropped())
+	}
+	if err := tracker.Flush(context.Background()); err != ErrClosed {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestTrackerFlushInterval(t *testing.T) {
+	sink := &recorder{}
+	tracker := NewWithConfig(Config{Sink: sink, FlushInterval: 10 * time.Millisecond})
+	defer tracker.Close(context.Background())
+
+	tracker.Track(Event{Name: "signup"})
+	deadline := time.Now().Add(time.Second)
+	for len(sink.sizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(sink.sizes()) != 1 {
+		t.Error("incomplete batches should be written after FlushInterval")
+	}
+}
+
+func TestSinks(t *testing.T) {
+	events := []Event{{Name: "a", Properties: map[string]interface{}{"n": 1.0}}, {Name: "b"}}
+
+	var buf bytes.Buffer
+	if err := Writer(&buf).Write(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[0], `"name":"a"`) {
+		t.Errorf("expected 2 JSON lines, got %q", buf.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	file, err := File(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := New(file)
+	tracker.Track(events[0])
+	if err := tracker.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), `"name":"a"`) {
+		t.Errorf("File sink wrote %q", data)
+	}
+
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/" || json.NewDecoder(r.Body).Decode(&received) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	if err := HTTP(server.URL, nil).Write(context.Background(), events); err != nil {
+		t.Fatalf("HTTP sink failed: %v", err)
+	}
+	if len(received) != 2 || received[1].Name != "b" {
+		t.Errorf("collector received %v", received)
+	}
+	if err := HTTP(server.URL+"/missing", nil).Write(context.Background(), events); err == nil {
+		t.Error("HTTP sink should fail on rejected batches")
+	}
+}
@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Writer returns a Sink writing events to w as JSON lines.
+func Writer(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+// Stdout returns a Sink writing events to standard output as JSON lines, for
+// development and log-based pipelines.
+func Stdout() Sink {
+	return Writer(os.Stdout)
+}
+
+// File returns a Sink appending events to the file at path as JSON lines,
+// creating it if needed. The file is closed by Tracker.Close.
+func File(path string) (Sink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("analytics: %w", err)
+	}
+	return &writerSink{w: file, closer: file}, nil
+}
+
+// writerSink writes JSON lines.
+type writerSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// Write writes events in a single call to the underlying writer.
+func (s *writerSink) Write(ctx context.Context, events []Event) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+// Close closes the file of a File sink.
+func (s *writerSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// HTTP returns a Sink posting each batch to url as a JSON array of events.
+// Responses other than 2xx are errors. client defaults to a client with a 10
+// second timeout.
+func HTTP(url string, client *http.Client) Sink {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return SinkFunc(func(ctx context.Context, events []Event) error {
+		body, err := json.Marshal(events)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("analytics: POST %s: %s", url, resp.Status)
+		}
+		return nil
+	})
+}
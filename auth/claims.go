@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrTokenNotYetValid is returned when a token is used before its "nbf" time
+	ErrTokenNotYetValid = fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+
+	// ErrInvalidIssuer is returned when the "iss" claim is not the expected issuer
+	ErrInvalidIssuer = fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+
+	// ErrInvalidAudience is returned when the "aud" claim does not include the expected audience
+	ErrInvalidAudience = fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+
+	// ErrMissingExpiry is returned when ValidationOptions.RequireExpiry is set
+	// and the token has no "exp" claim
+	ErrMissingExpiry = fmt.Errorf("%w: token has no expiry", ErrInvalidToken)
+
+	// ErrTokenUse is returned when the "token_use" claim does not match
+	// ValidationOptions.TokenUse, e.g. for a refresh token sent as an access token
+	ErrTokenUse = fmt.Errorf("%w: wrong token use", ErrInvalidToken)
+)
+
+// RegisteredClaims holds the registered claims of RFC 7519. Zero values mean
+// the claim is absent.
+type RegisteredClaims struct {
+	// Issuer ("iss") identifies who issued the token
+	Issuer string
+
+	// Subject ("sub") identifies whom the token is about, usually a user ID
+	Subject string
+
+	// Audience ("aud") lists the recipients the token is intended for
+	Audience []string
+
+	// ExpiresAt ("exp") is when the token expires
+	ExpiresAt time.Time
+
+	// NotBefore ("nbf") is when the token becomes valid
+	NotBefore time.Time
+
+	// IssuedAt ("iat") is when the token was issued
+	IssuedAt time.Time
+
+	// ID ("jti") uniquely identifies the token, e.g. for revocation lists
+	ID string
+}
+
+// Registered returns the registered claims of c. Claims of the wrong type are
+// treated as absent.
+//
+// Example:
+//
+//	claims := c.Get("jwt_claims").(auth.Claims)
+//	userID := claims.Registered().Subject
+func (c Claims) Registered() RegisteredClaims {
+	r := RegisteredClaims{
+		Issuer:    stringClaim(c, "iss"),
+		Subject:   stringClaim(c, "sub"),
+		ExpiresAt: timeClaim(c, "exp"),
+		NotBefore: timeClaim(c, "nbf"),
+		IssuedAt:  timeClaim(c, "iat"),
+		ID:        stringClaim(c, "jti"),
+	}
+
+	// "aud" is a single string or an array of strings
+	switch aud := c["aud"].(type) {
+	case string:
+		r.Audience = []string{aud}
+	case []string:
+		r.Audience = aud
+	case []interface{}:
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				r.Audience = append(r.Audience, s)
+			}
+		}
+	}
+	return r
+}
+
+// SetRegistered stores the non-zero fields of r in c. GenerateToken and
+// GenerateTokenWithKey still set "iat" and "exp" from the current time and ttl.
+//
+// Example:
+//
+//	claims := auth.Claims{"role": "admin"}
+//	claims.SetRegistered(auth.RegisteredClaims{
+//	    Issuer:   "https://id.example.com",
+//	    Subject:  user.ID,
+//	    Audience: []string{"orders-api"},
+//	})
+//	token, err := auth.GenerateToken(claims, secret, time.Hour)
+func (c Claims) SetRegistered(r RegisteredClaims) {
+	setString := func(name, value string) {
+		if value != "" {
+			c[name] = value
+		}
+	}
+	setTime := func(name string, value time.Time) {
+		if !value.IsZero() {
+			c[name] = value.Unix()
+		}
+	}
+
+	setString("iss", r.Issuer)
+	setString("sub", r.Subject)
+	setString("jti", r.ID)
+	setTime("exp", r.ExpiresAt)
+	setTime("nbf", r.NotBefore)
+	setTime("iat", r.IssuedAt)
+	switch len(r.Audience) {
+	case 0:
+	case 1:
+		c["aud"] = r.Audience[0]
+	default:
+		c["aud"] = r.Audience
+	}
+}
+
+// stringClaim returns a string claim, or "".
+func stringClaim(c Claims, name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// timeClaim returns a NumericDate claim (seconds since the epoch), or the zero time.
+func timeClaim(c Claims, name string) time.Time {
+	var seconds float64
+	switch v := c[name].(type) {
+	case float64:
+		seconds = v
+	case int64:
+		seconds = float64(v)
+	case int:
+		seconds = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}
+		}
+		seconds = f
+	default:
+		return time.Time{}
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9))
+}
+
+// ValidationOptions configures the checks of registered claims made by
+// ValidateTokenWithOptions. The zero value only rejects expired tokens and
+// tokens used before their "nbf" time.
+type ValidationOptions struct {
+	// Issuer, if set, must equal the "iss" claim
+	Issuer string
+
+	// Audience, if set, must be listed in the "aud" claim
+	Audience string
+
+	// Leeway tolerates clock skew between the issuer and this server when
+	// checking "exp" and "nbf" (e.g. 30 * time.Second)
+	Leeway time.Duration
+
+	// RequireExpiry rejects tokens without an "exp" claim
+	RequireExpiry bool
+
+	// TokenUse, if set, must equal the "token_use" claim. Refresh tokens
+	// (token_use "refresh") are only accepted when TokenUse is RefreshTokenUse,
+	// so they can't be used as access tokens.
+	TokenUse string
+
+	// Now returns the current time (default: time.Now), for tests
+	Now func() time.Time
+}
+
+// validate checks the registered claims of claims against the options.
+func (opts ValidationOptions) validate(claims Claims) error {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	registered := claims.Registered()
+
+	if registered.ExpiresAt.IsZero() {
+		if opts.RequireExpiry {
+			return ErrMissingExpiry
+		}
+	} else if now.After(registered.ExpiresAt.Add(opts.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	if !registered.NotBefore.IsZero() && now.Add(opts.Leeway).Before(registered.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+
+	use, _ := claims["token_use"].(string)
+	if (opts.TokenUse != "" && use != opts.TokenUse) || (opts.TokenUse == "" && use == RefreshTokenUse) {
+		return ErrTokenUse
+	}
+
+	if opts.Issuer != "" && registered.Issuer != opts.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if opts.Audience != "" {
+		for _, audience := range registered.Audience {
+			if audience == opts.Audience {
+				return nil
+			}
+		}
+		return ErrInvalidAudience
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is returned when a token names a signing key that is not in
+// the key set, even after refreshing it.
+var ErrUnknownKey = fmt.Errorf("%w: unknown signing key", ErrInvalidToken)
+
+// JWK is a public JSON Web Key (RFC 7517) as published in a JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// Crv, X and Y are the curve and coordinates of EC and OKP keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	// N and E are the modulus and exponent of RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// PublicKey returns the key and the algorithm it verifies: RS256 for RSA
+// keys, ES256 for P-256 keys and EdDSA for Ed25519 keys.
+func (k JWK) PublicKey() (string, crypto.PublicKey, error) {
+	decode := func(s string) ([]byte, error) {
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	}
+
+	var alg string
+	var key crypto.PublicKey
+	switch {
+	case k.Kty == "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return "", nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return "", nil, fmt.Errorf("invalid RSA key %q", k.Kid)
+		}
+		alg, key = RS256, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+
+	case k.Kty == "EC" && k.Crv == "P-256":
+		x, err := decode(k.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		ecKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !ecKey.Curve.IsOnCurve(ecKey.X, ecKey.Y) {
+			return "", nil, fmt.Errorf("invalid EC key %q", k.Kid)
+		}
+		alg, key = ES256, ecKey
+
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := decode(k.X)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return "", nil, fmt.Errorf("invalid Ed25519 key %q", k.Kid)
+		}
+		alg, key = EdDSA, ed25519.PublicKey(x)
+
+	default:
+		return "", nil, fmt.Errorf("%w: %s key %q", ErrUnsupportedAlgorithm, k.Kty+" "+k.Crv, k.Kid)
+	}
+
+	// A key pinned to another algorithm, e.g. RS384, cannot verify RS256 tokens
+	if k.Alg != "" && k.Alg != alg {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, k.Alg)
+	}
+	return alg, key, nil
+}
+
+// keySetKey is a parsed key of a KeySet.
+type keySetKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+const (
+	// keySetFetchTimeout bounds a fetch, which runs detached from the
+	// request that triggered it.
+	keySetFetchTimeout = 10 * time.Second
+
+	// keySetRetryInterval is how long a failed fetch is remembered before
+	// the issuer is asked again, so an outage doesn't turn every request
+	// into a fetch.
+	keySetRetryInterval = 10 * time.Second
+)
+
+// KeySet is the signing keys of a token issuer, fetched from its JWKS URL
+// and cached. Keys are refetched every hour and when a token names an
+// unknown key, so the issuer can rotate keys without restarts. It is safe
+// for concurrent use: one fetch runs at a time, and requests with a cached
+// key don't wait for it.
+type KeySet struct {
+	url    string
+	client *http.Client
+
+	mu         sync.Mutex
+	keys       map[string]keySetKey
+	fetched    time.Time
+	err        error
+	failedAt   time.Time
+	refreshing chan struct{}
+
+	// MaxAge is how long fetched keys are used before refetching. Default: 1 hour
+	MaxAge time.Duration
+
+	// MinRefreshInterval limits refetches for unknown keys, so tokens with
+	// made-up key IDs cannot flood the issuer. Default: 1 minute
+	MinRefreshInterval time.Duration
+}
+
+// NewKeySet returns the key set published at url. A nil client uses a client
+// with a 10 second timeout.
+//
+// Example:
+//
+//	keys := auth.NewKeySet("https://id.example.com/.well-known/jwks.json", nil)
+//	claims, err := auth.ValidateTokenWithKeySet(ctx, token, keys, auth.ValidationOptions{
+//	    Issuer:   "https://id.example.com",
+//	    Audience: "orders-api",
+//	})
+func NewKeySet(url string, client *http.Client) *KeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &KeySet{
+		url:                url,
+		client:             client,
+		MaxAge:             time.Hour,
+		MinRefreshInterval: time.Minute,
+	}
+}
+
+// Key returns the algorithm and public key with ID kid. An empty kid matches
+// the only key of a set with a single key. Expired keys keep being returned
+// while they are refetched, and while the issuer is unreachable.
+func (s *KeySet) Key(ctx context.Context, kid string) (string, crypto.PublicKey, error) {
+	s.mu.Lock()
+	key, found := s.lookup(kid)
+	stale := s.keys == nil || time.Since(s.fetched) > s.MaxAge
+	if found && !stale {
+		s.mu.Unlock()
+		return key.alg, key.key, nil
+	}
+
+	// Refetch expired keys, or for an unknown key in case the issuer rotated
+	// its keys, unless the issuer was asked recently
+	wanted := stale || time.Since(s.fetched) > s.MinRefreshInterval
+	if !wanted || time.Since(s.failedAt) < keySetRetryInterval {
+		err := s.missingKeyError()
+		s.mu.Unlock()
+		if found {
+			return key.alg, key.key, nil
+		}
+		return "", nil, err
+	}
+	done := s.startRefresh()
+	s.mu.Unlock()
+
+	if found {
+		return key.alg, key.key, nil
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return "", nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.lookup(kid); ok {
+		return key.alg, key.key, nil
+	}
+	if s.err != nil {
+		return "", nil, s.err
+	}
+	return "", nil, ErrUnknownKey
+}
+
+// missingKeyError returns the error for a key that is not in the set: the
+// last fetch error if no keys could be fetched yet. The caller holds s.mu.
+func (s *KeySet) missingKeyError() error {
+	if s.keys == nil && s.err != nil {
+		return s.err
+	}
+	return ErrUnknownKey
+}
+
+// startRefresh starts fetching the keys unless a fetch is running, and
+// returns a channel closed when it completes. The caller holds s.mu.
+func (s *KeySet) startRefresh() <-chan struct{} {
+	if s.refreshing != nil {
+		return s.refreshing
+	}
+	done := make(chan struct{})
+	s.refreshing = done
+
+	go func() {
+		defer close(done)
+		// Detached from the triggering request, so one client
+		// disconnecting doesn't fail the fetch for everyone
+		ctx, cancel := context.WithTimeout(context.Background(), keySetFetchTimeout)
+		defer cancel()
+		keys, err := s.fetch(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.refreshing = nil
+		s.err = err
+		if err != nil {
+			s.failedAt = time.Now()
+			return
+		}
+		s.keys = keys
+		s.fetched = time.Now()
+	}()
+	return done
+}
+
+// lookup returns the key with ID kid. The caller holds s.mu.
+func (s *KeySet) lookup(kid string) (keySetKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// fetch downloads and parses the keys.
+func (s *KeySet) fetch(ctx context.Context) (map[string]keySetKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching keys: GET %s: %s", s.url, resp.Status)
+	}
+
+	var document struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf("fetching keys: %w", err)
+	}
+
+	// Keys of unsupported types or for encryption are skipped
+	keys := make(map[string]keySetKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if alg, key, err := jwk.PublicKey(); err == nil {
+			keys[jwk.Kid] = keySetKey{alg, key}
+		}
+	}
+	return keys, nil
+}
+
+// ValidateTokenWithKeySet validates a token signed with one of the keys in
+// keys, chosen by the token's "kid" header. The token's algorithm must match
+// the key's. Errors fetching the keys are returned as is; every rejected
+// token yields an error wrapping ErrInvalidToken.
+func ValidateTokenWithKeySet(ctx context.Context, token string, keys *KeySet, opts ValidationOptions) (Claims, error) {
+	headerEncoded, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(headerEncoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	alg, key, err := keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateTokenWithOptions(token, alg, key, opts)
+}
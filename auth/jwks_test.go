@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeySetRefresh(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var fetches int32
+	var failing atomic.Bool
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		if failing.Load() {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []JWK{{
+			Kty: "EC",
+			Crv: "P-256",
+			Kid: "k1",
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	}))
+	defer server.Close()
+	keys := NewKeySet(server.URL, nil)
+
+	// Concurrent callers share one fetch
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := keys.Key(context.Background(), "k1")
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected key, got %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Fatalf("expected 1 fetch, got %d", n)
+	}
+
+	// A cancelled caller doesn't fail the fetch, and expired keys are served
+	// while the issuer is down, without asking it on every call
+	failing.Store(true)
+	keys.MaxAge = time.Millisecond
+	time.Sleep(5 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 5; i++ {
+		if _, _, err := keys.Key(ctx, "k1"); err != nil {
+			t.Fatalf("expected expired key during outage, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected 1 refetch during outage, got %d", n)
+	}
+	if _, _, err := keys.Key(context.Background(), "k2"); err != ErrUnknownKey {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected no fetch after a recent failure, got %d", n)
+	}
+}
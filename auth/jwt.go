@@ -1,8 +1,6 @@
 package auth
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -22,7 +20,8 @@ var (
 // Claims represents JWT claims (payload)
 type Claims map[string]interface{}
 
-// GenerateToken creates a new JWT token with the given claims.
+// GenerateToken creates a new JWT token with the given claims, signed with
+// HS256. Use GenerateTokenWithKey for asymmetric algorithms.
 //
 // claims: Custom data to store in the token
 // secret: Secret key for signing the token
@@ -35,6 +34,20 @@ type Claims map[string]interface{}
 //	    "email": "user@example.com",
 //	}, "my-secret-key", 24*time.Hour)
 func GenerateToken(claims Claims, secret string, ttl time.Duration) (string, error) {
+	return GenerateTokenWithKey(claims, HS256, []byte(secret), ttl)
+}
+
+// GenerateTokenWithKey creates a new JWT token with the given claims, signed
+// with alg. The key must match the algorithm: a []byte secret for HS256, an
+// *rsa.PrivateKey for RS256, an *ecdsa.PrivateKey on P-256 for ES256 and an
+// ed25519.PrivateKey for EdDSA. Keys can be loaded with ParsePrivateKeyPEM.
+//
+// Example:
+//
+//	key, err := auth.ParsePrivateKeyPEM(pemBytes)
+//	...
+//	token, err := auth.GenerateTokenWithKey(auth.Claims{"userID": "123"}, auth.RS256, key, time.Hour)
+func GenerateTokenWithKey(claims Claims, alg string, key interface{}, ttl time.Duration) (string, error) {
 	// Add standard claims
 	now := time.Now()
 	claims["iat"] = now.Unix()          // issued at
@@ -42,7 +55,7 @@ func GenerateToken(claims Claims, secret string, ttl time.Duration) (string, err
 
 	// Create header
 	header := map[string]string{
-		"alg": "HS256",
+		"alg": alg,
 		"typ": "JWT",
 	}
 
@@ -62,15 +75,19 @@ func GenerateToken(claims Claims, secret string, ttl time.Duration) (string, err
 
 	// Create signature
 	message := headerEncoded + "." + claimsEncoded
-	signature := createSignature(message, secret)
+	signature, err := sign(alg, message, key)
+	if err != nil {
+		return "", err
+	}
 
 	// Combine parts
-	token := message + "." + signature
+	token := message + "." + base64.RawURLEncoding.EncodeToString(signature)
 
 	return token, nil
 }
 
-// ValidateToken validates a JWT token and returns its claims.
+// ValidateToken validates an HS256 JWT token and returns its claims.
+// Tokens signed with any other algorithm are rejected.
 //
 // Example:
 //
@@ -80,6 +97,40 @@ func GenerateToken(claims Claims, secret string, ttl time.Duration) (string, err
 //	}
 //	userID := claims["userID"].(string)
 func ValidateToken(token, secret string) (Claims, error) {
+	return ValidateTokenWithKey(token, HS256, []byte(secret))
+}
+
+// ValidateTokenWithKey validates a JWT token signed with alg and returns its
+// claims. The algorithm is pinned: tokens whose header names a different
+// algorithm are rejected, so a token signed with HS256 using a public key as
+// the secret cannot pass as RS256 (algorithm confusion). The key is a []byte
+// secret for HS256 and the public key (or its private key) otherwise; public
+// keys can be loaded with ParsePublicKeyPEM.
+//
+// Example:
+//
+//	key, err := auth.ParsePublicKeyPEM(pemBytes)
+//	...
+//	claims, err := auth.ValidateTokenWithKey(token, auth.RS256, key)
+func ValidateTokenWithKey(token, alg string, key interface{}) (Claims, error) {
+	return ValidateTokenWithOptions(token, alg, key, ValidationOptions{})
+}
+
+// ValidateTokenWithOptions is ValidateTokenWithKey with additional checks of
+// the registered claims, such as the expected issuer and audience.
+//
+// Example:
+//
+//	claims, err := auth.ValidateTokenWithOptions(token, auth.RS256, key, auth.ValidationOptions{
+//	    Issuer:   "https://id.example.com",
+//	    Audience: "orders-api",
+//	    Leeway:   30 * time.Second,
+//	})
+//	if err != nil {
+//	    // errors.Is(err, auth.ErrInvalidToken) for any rejected token
+//	}
+//	subject := claims.Registered().Subject
+func ValidateTokenWithOptions(token, alg string, key interface{}, opts ValidationOptions) (Claims, error) {
 	// Split token into parts
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
@@ -90,11 +141,24 @@ func ValidateToken(token, secret string) (Claims, error) {
 	claimsEncoded := parts[1]
 	signatureEncoded := parts[2]
 
-	// Verify signature
-	message := headerEncoded + "." + claimsEncoded
-	expectedSignature := createSignature(message, secret)
+	// Reject tokens signed with any algorithm but the expected one
+	headerJSON, err := base64.RawURLEncoding.DecodeString(headerEncoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != alg {
+		return nil, ErrInvalidToken
+	}
 
-	if signatureEncoded != expectedSignature {
+	// Verify signature
+	signature, err := base64.RawURLEncoding.DecodeString(signatureEncoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !verify(alg, headerEncoded+"."+claimsEncoded, signature, key) {
 		return nil, ErrInvalidToken
 	}
 
@@ -109,26 +173,19 @@ func ValidateToken(token, secret string) (Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	// Check expiration
-	if exp, ok := claims["exp"].(float64); ok {
-		if time.Now().Unix() > int64(exp) {
-			return nil, ErrTokenExpired
-		}
+	// Check expiration, validity start, issuer and audience
+	if err := opts.validate(claims); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
 }
 
-// createSignature creates HMAC-SHA256 signature
-func createSignature(message, secret string) string {
-	h := hmac.New(sha256.New, []byte(secret))
-	h.Write([]byte(message))
-	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
-}
-
 // RefreshToken creates a new token with the same claims but extended expiration.
 // The original token must still be valid (not expired) to be refreshed.
 // This prevents indefinite token refresh after expiration.
+// For long-lived sessions, prefer GenerateTokenPair and RotateRefreshToken,
+// which issue single-use refresh tokens and detect their reuse.
 //
 // Example:
 //
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Signing algorithms supported by GenerateTokenWithKey and ValidateTokenWithKey.
+const (
+	// HS256 is HMAC with SHA-256, using a shared secret
+	HS256 = "HS256"
+
+	// RS256 is RSASSA-PKCS1-v1_5 with SHA-256
+	RS256 = "RS256"
+
+	// ES256 is ECDSA on the P-256 curve with SHA-256
+	ES256 = "ES256"
+
+	// EdDSA is Ed25519
+	EdDSA = "EdDSA"
+)
+
+var (
+	// ErrUnsupportedAlgorithm is returned for algorithms other than HS256, RS256, ES256 and EdDSA
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+
+	// ErrKeyType is returned when a key does not match the signing algorithm
+	ErrKeyType = errors.New("key type does not match signing algorithm")
+)
+
+// es256KeySize is the byte length of each of r and s in an ES256 signature.
+const es256KeySize = 32
+
+// CheckKey reports whether key can be used with alg, returning
+// ErrUnsupportedAlgorithm or ErrKeyType if not. Private keys are accepted
+// wherever public keys are.
+func CheckKey(alg string, key interface{}) error {
+	if signer, ok := key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+
+	var ok bool
+	switch alg {
+	case HS256:
+		_, ok = key.([]byte)
+	case RS256:
+		_, ok = key.(*rsa.PublicKey)
+	case ES256:
+		var ecKey *ecdsa.PublicKey
+		ecKey, ok = key.(*ecdsa.PublicKey)
+		ok = ok && ecKey.Curve == elliptic.P256()
+	case EdDSA:
+		_, ok = key.(ed25519.PublicKey)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s needs %s, got %T", ErrKeyType, alg, keyDescription(alg), key)
+	}
+	return nil
+}
+
+// keyDescription names the key type an algorithm needs, for error messages.
+func keyDescription(alg string) string {
+	switch alg {
+	case HS256:
+		return "a []byte secret"
+	case RS256:
+		return "an RSA key"
+	case ES256:
+		return "an ECDSA P-256 key"
+	default:
+		return "an Ed25519 key"
+	}
+}
+
+// sign signs message with key using alg.
+func sign(alg, message string, key interface{}) ([]byte, error) {
+	if err := CheckKey(alg, key); err != nil {
+		return nil, err
+	}
+
+	if alg == HS256 {
+		h := hmac.New(sha256.New, key.([]byte))
+		h.Write([]byte(message))
+		return h.Sum(nil), nil
+	}
+
+	digest := sha256.Sum256([]byte(message))
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses the fixed-size concatenation of r and s, not ASN.1
+		signature := make([]byte, 2*es256KeySize)
+		r.FillBytes(signature[:es256KeySize])
+		s.FillBytes(signature[es256KeySize:])
+		return signature, nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, []byte(message)), nil
+	}
+	return nil, fmt.Errorf("%w: %s signing needs a private key, got %T", ErrKeyType, alg, key)
+}
+
+// verify reports whether signature is a valid alg signature of message.
+func verify(alg, message string, signature []byte, key interface{}) bool {
+	if CheckKey(alg, key) != nil {
+		return false
+	}
+	if signer, ok := key.(crypto.Signer); ok {
+		key = signer.Public()
+	}
+
+	if alg == HS256 {
+		h := hmac.New(sha256.New, key.([]byte))
+		h.Write([]byte(message))
+		return hmac.Equal(signature, h.Sum(nil))
+	}
+
+	digest := sha256.Sum256([]byte(message))
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		if len(signature) != 2*es256KeySize {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:es256KeySize])
+		s := new(big.Int).SetBytes(signature[es256KeySize:])
+		return ecdsa.Verify(k, digest[:], r, s)
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, []byte(message), signature)
+	}
+	return false
+}
+
+// ParsePrivateKeyPEM parses the first PEM-encoded private key in data, in
+// PKCS #8 ("PRIVATE KEY"), PKCS #1 ("RSA PRIVATE KEY") or SEC 1
+// ("EC PRIVATE KEY") form, for signing tokens with GenerateTokenWithKey.
+//
+// Example:
+//
+//	data, err := os.ReadFile("jwt-private.pem")
+//	...
+//	key, err := auth.ParsePrivateKeyPEM(data)
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("auth: no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("auth: unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("auth: unsupported PEM block type %q", block.Type)
+}
+
+// ParsePublicKeyPEM parses the first PEM-encoded public key in data, in PKIX
+// ("PUBLIC KEY") or PKCS #1 ("RSA PUBLIC KEY") form, or the key of a
+// certificate ("CERTIFICATE"), for validating tokens with ValidateTokenWithKey.
+//
+// Example:
+//
+//	data, err := os.ReadFile("jwt-public.pem")
+//	...
+//	key, err := auth.ParsePublicKeyPEM(data)
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("auth: no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	return nil, fmt.Errorf("auth: unsupported PEM block type %q", block.Type)
+}
@@ -0,0 +1,377 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RefreshTokenUse is the "token_use" claim of refresh tokens issued by
+// GenerateTokenPair.
+const RefreshTokenUse = "refresh"
+
+var (
+	// ErrRefreshTokenReused is returned by RotateRefreshToken when a refresh
+	// token that was already rotated is used again. This means the token was
+	// stolen or replayed, so its whole family is revoked.
+	ErrRefreshTokenReused = fmt.Errorf("%w: refresh token reused", ErrInvalidToken)
+
+	// ErrRefreshTokenRevoked is returned when the family of a refresh token
+	// was revoked or has expired from the RefreshStore
+	ErrRefreshTokenRevoked = fmt.Errorf("%w: refresh token revoked", ErrInvalidToken)
+)
+
+// TokenPair is a short-lived access token with the long-lived refresh token
+// that renews it. It marshals to an OAuth 2.0 token response.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// RefreshStore tracks the latest refresh token of each token family (the
+// chain of refresh tokens rotated from one login), so RotateRefreshToken can
+// detect reuse. Implementations must be safe for concurrent use.
+type RefreshStore interface {
+	// Rotate records next as the latest token ID of family, until expiresAt,
+	// if prev is the latest one; prev is "" when a new family is started.
+	// The check and update must be atomic. It returns ErrRefreshTokenReused
+	// if prev is not the latest ID and ErrRefreshTokenRevoked if the family
+	// is unknown.
+	Rotate(ctx context.Context, family, prev, next string, expiresAt time.Time) error
+
+	// Revoke forgets family, so none of its tokens can be rotated again
+	Revoke(ctx context.Context, family string) error
+}
+
+// TokenPairConfig holds configuration for issuing and rotating token pairs.
+type TokenPairConfig struct {
+	// Algorithm signs both tokens: HS256, RS256, ES256 or EdDSA.
+	// Default: HS256
+	Algorithm string
+
+	// Key signs and validates the tokens: a []byte secret for HS256 or a
+	// private key, e.g. loaded with ParsePrivateKeyPEM
+	Key interface{}
+
+	// AccessTTL is the lifetime of access tokens.
+	// Default: 15 minutes
+	AccessTTL time.Duration
+
+	// RefreshTTL is the lifetime of refresh tokens.
+	// Default: 30 days
+	RefreshTTL time.Duration
+
+	// Store records the latest refresh token of each family
+	Store RefreshStore
+
+	// Validation checks the registered claims of refresh tokens, such as the
+	// issuer. Its TokenUse is always RefreshTokenUse.
+	Validation ValidationOptions
+}
+
+// withDefaults returns config with defaults applied, or an error if it
+// cannot issue tokens.
+func (config TokenPairConfig) withDefaults() (TokenPairConfig, error) {
+	if config.Algorithm == "" {
+		config.Algorithm = HS256
+	}
+	if config.AccessTTL <= 0 {
+		config.AccessTTL = 15 * time.Minute
+	}
+	if config.RefreshTTL <= 0 {
+		config.RefreshTTL = 30 * 24 * time.Hour
+	}
+	if config.Store == nil {
+		return config, errors.New("auth: TokenPairConfig.Store is nil")
+	}
+	if err := CheckKey(config.Algorithm, config.Key); err != nil {
+		return config, err
+	}
+	config.Validation.TokenUse = RefreshTokenUse
+	return config, nil
+}
+
+// GenerateTokenPair issues an access token and a refresh token carrying
+// claims, starting a new refresh token family in config.Store. Use it at
+// login; clients then exchange the refresh token with RotateRefreshToken
+// (or RefreshHandler) when the access token expires.
+//
+// The JWT middleware rejects the refresh token, which carries a
+// "token_use" claim of RefreshTokenUse.
+//
+// Example:
+//
+//	tokens := auth.TokenPairConfig{Key: []byte(secret), Store: auth.NewMemoryRefreshStore()}
+//
+//	app.POST("/login", func(c *context.Context) error {
+//	    user, err := authenticate(c)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    pair, err := auth.GenerateTokenPair(c, auth.Claims{"userID": user.ID}, tokens)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return c.JSON(200, pair)
+//	})
+func GenerateTokenPair(ctx context.Context, claims Claims, config TokenPairConfig) (*TokenPair, error) {
+	config, err := config.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	family, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	return issueTokenPair(ctx, claims, family, "", config)
+}
+
+// RotateRefreshToken validates refreshToken and exchanges it for a new token
+// pair with the same claims. Every refresh token can be used once: using one
+// again returns ErrRefreshTokenReused and revokes its family, logging out both
+// the legitimate client and whoever replayed it.
+//
+// Example:
+//
+//	pair, err := auth.RotateRefreshToken(ctx, refreshToken, tokens)
+//	if errors.Is(err, auth.ErrRefreshTokenReused) {
+//	    log.Warn("Refresh token reuse detected")
+//	}
+func RotateRefreshToken(ctx context.Context, refreshToken string, config TokenPairConfig) (*TokenPair, error) {
+	config, err := config.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := ValidateTokenWithOptions(refreshToken, config.Algorithm, config.Key, config.Validation)
+	if err != nil {
+		return nil, err
+	}
+	family, _ := claims["fam"].(string)
+	id, _ := claims["jti"].(string)
+	if family == "" || id == "" {
+		return nil, ErrInvalidToken
+	}
+
+	pair, err := issueTokenPair(ctx, claims, family, id, config)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if revokeErr := config.Store.Revoke(ctx, family); revokeErr != nil {
+			return nil, revokeErr
+		}
+	}
+	return pair, err
+}
+
+// RevokeRefreshToken revokes the family of refreshToken, e.g. at logout, so
+// neither it nor any token rotated from it can be used again. Access tokens
+// already issued stay valid until they expire.
+func RevokeRefreshToken(ctx context.Context, refreshToken string, config TokenPairConfig) error {
+	config, err := config.withDefaults()
+	if err != nil {
+		return err
+	}
+
+	// An expired refresh token can still end its session
+	config.Validation.Leeway = config.RefreshTTL
+	claims, err := ValidateTokenWithOptions(refreshToken, config.Algorithm, config.Key, config.Validation)
+	if err != nil {
+		return err
+	}
+	family, _ := claims["fam"].(string)
+	if family == "" {
+		return ErrInvalidToken
+	}
+	return config.Store.Revoke(ctx, family)
+}
+
+// issueTokenPair signs a new pair for claims and rotates the family from
+// prev to the new refresh token.
+func issueTokenPair(ctx context.Context, claims Claims, family, prev string, config TokenPairConfig) (*TokenPair, error) {
+	id, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	access := make(Claims, len(claims))
+	for name, value := range claims {
+		switch name {
+		case "iat", "exp", "nbf", "jti", "fam", "token_use":
+		default:
+			access[name] = value
+		}
+	}
+	refresh := make(Claims, len(access)+3)
+	for name, value := range access {
+		refresh[name] = value
+	}
+	refresh["jti"] = id
+	refresh["fam"] = family
+	refresh["token_use"] = RefreshTokenUse
+
+	accessToken, err := GenerateTokenWithKey(access, config.Algorithm, config.Key, config.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := GenerateTokenWithKey(refresh, config.Algorithm, config.Key, config.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Store.Rotate(ctx, family, prev, id, time.Now().Add(config.RefreshTTL)); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(config.AccessTTL / time.Second),
+	}, nil
+}
+
+// newTokenID returns a random token or family ID.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RefreshHandler returns an http.Handler for a token refresh endpoint. It
+// reads the refresh token from a JSON body ({"refresh_token": "..."}) or a
+// form field of the same name, as in the OAuth 2.0 refresh_token grant, and
+// responds with the new TokenPair. Invalid, expired, revoked and reused
+// tokens get 401 {"error": "invalid_grant"}.
+//
+// Example:
+//
+//	refresh := auth.RefreshHandler(tokens)
+//	app.POST("/token/refresh", func(c *context.Context) error {
+//	    refresh.ServeHTTP(c.Writer, c.Request)
+//	    return nil
+//	})
+func RefreshHandler(config TokenPairConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeTokenError(w, http.StatusMethodNotAllowed, "invalid_request")
+			return
+		}
+
+		token := refreshTokenFromRequest(r)
+		if token == "" {
+			writeTokenError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+
+		pair, err := RotateRefreshToken(r.Context(), token, config)
+		switch {
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrTokenExpired):
+			writeTokenError(w, http.StatusUnauthorized, "invalid_grant")
+			return
+		case err != nil:
+			writeTokenError(w, http.StatusInternalServerError, "server_error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pair)
+	})
+}
+
+// refreshTokenFromRequest reads the refresh_token of a JSON or form body.
+func refreshTokenFromRequest(r *http.Request) string {
+	body := http.MaxBytesReader(nil, r.Body, 64<<10)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var input struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		if json.NewDecoder(body).Decode(&input) != nil {
+			return ""
+		}
+		return input.RefreshToken
+	}
+	r.Body = body
+	return r.PostFormValue("refresh_token")
+}
+
+// writeTokenError writes an OAuth 2.0 error response.
+func writeTokenError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+// MemoryRefreshStore is an in-memory RefreshStore for single-instance
+// deployments and tests. Families are lost on restart, which logs every
+// client out; use a shared store when running several instances.
+type MemoryRefreshStore struct {
+	mu       sync.Mutex
+	families map[string]refreshFamily
+}
+
+// refreshFamily is the latest token of a family.
+type refreshFamily struct {
+	latest    string
+	expiresAt time.Time
+}
+
+// NewMemoryRefreshStore creates an empty MemoryRefreshStore.
+func NewMemoryRefreshStore() *MemoryRefreshStore {
+	return &MemoryRefreshStore{families: make(map[string]refreshFamily)}
+}
+
+// Rotate implements RefreshStore.
+func (s *MemoryRefreshStore) Rotate(ctx context.Context, family, prev, next string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	current, exists := s.families[family]
+	if exists && now.After(current.expiresAt) {
+		delete(s.families, family)
+		exists = false
+	}
+
+	switch {
+	case prev == "" && exists:
+		return fmt.Errorf("auth: refresh token family %q already exists", family)
+	case prev == "":
+		// Drop expired families now and then, so abandoned sessions don't accumulate
+		if len(s.families)%1024 == 1023 {
+			for name, f := range s.families {
+				if now.After(f.expiresAt) {
+					delete(s.families, name)
+				}
+			}
+		}
+	case !exists:
+		return ErrRefreshTokenRevoked
+	case current.latest != prev:
+		return ErrRefreshTokenReused
+	}
+
+	s.families[family] = refreshFamily{latest: next, expiresAt: expiresAt}
+	return nil
+}
+
+// Revoke implements RefreshStore.
+func (s *MemoryRefreshStore) Revoke(ctx context.Context, family string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.families, family)
+	return nil
+}
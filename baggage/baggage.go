@@ -0,0 +1,143 @@
+// Package baggage reads and propagates W3C Baggage (https://www.w3.org/TR/baggage/)
+// so correlation values such as customer or order IDs follow a request across services.
+//
+// Incoming values are collected by middleware.Baggage. Outgoing HTTP calls
+// propagate them with Transport, and message publishers can copy
+// FromContext(ctx) into message headers.
+package baggage
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Header is the W3C baggage header name.
+const Header = "baggage"
+
+// Limits from the W3C specification.
+const (
+	maxMembers = 180
+	maxBytes   = 8192
+)
+
+// Baggage is a set of key/value correlation entries.
+// Member properties from the header are not retained.
+type Baggage map[string]string
+
+// Parse parses a baggage header value. Malformed members are skipped.
+func Parse(header string) Baggage {
+	b := make(Baggage)
+	for _, member := range strings.Split(header, ",") {
+		if len(b) >= maxMembers {
+			break
+		}
+
+		// Drop properties ("key=value;prop=x")
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		b[key] = decoded
+	}
+	return b
+}
+
+// String encodes the baggage as a header value with keys in sorted order.
+// Members that would exceed the specification's size limit are dropped.
+func (b Baggage) String() string {
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var members []string
+	size := 0
+	for _, key := range keys {
+		member := key + "=" + url.PathEscape(b[key])
+		if size+len(member)+1 > maxBytes || len(members) == maxMembers {
+			break
+		}
+		size += len(member) + 1
+		members = append(members, member)
+	}
+	return strings.Join(members, ",")
+}
+
+// Merge returns a new Baggage with the entries of other added to b.
+func (b Baggage) Merge(other Baggage) Baggage {
+	merged := make(Baggage, len(b)+len(other))
+	for key, value := range b {
+		merged[key] = value
+	}
+	for key, value := range other {
+		merged[key] = value
+	}
+	return merged
+}
+
+// contextKey is the context key for the request baggage.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying b.
+func NewContext(ctx context.Context, b Baggage) context.Context {
+	return context.WithValue(ctx, contextKey{}, b)
+}
+
+// FromContext returns the baggage carried by ctx, or nil.
+func FromContext(ctx context.Context) Baggage {
+	b, _ := ctx.Value(contextKey{}).(Baggage)
+	return b
+}
+
+// Inject sets the baggage header on h from the baggage carried by ctx,
+// merged with any baggage already present in h.
+func Inject(ctx context.Context, h http.Header) {
+	b := FromContext(ctx)
+	if len(b) == 0 {
+		return
+	}
+	if existing := h.Get(Header); existing != "" {
+		b = Parse(existing).Merge(b)
+	}
+	h.Set(Header, b.String())
+}
+
+// Transport returns an http.RoundTripper that propagates the baggage of each
+// request's context to the outgoing request. If base is nil, http.DefaultTransport is used.
+//
+// Example:
+//
+//	client := &http.Client{Transport: baggage.Transport(nil)}
+//	req, _ := http.NewRequestWithContext(c.Context(), "GET", billingURL, nil)
+//	resp, err := client.Do(req)
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{base: base}
+}
+
+// roundTripper injects baggage into outgoing requests.
+type roundTripper struct {
+	base http.RoundTripper
+}
+
+func (rt roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(FromContext(req.Context())) > 0 {
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		Inject(req.Context(), req.Header)
+	}
+	return rt.base.RoundTrip(req)
+}
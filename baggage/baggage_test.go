@@ -0,0 +1,44 @@
+package baggage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAndString(t *testing.T) {
+	b := Parse("customer_id=42, order=A%2F7;ttl=5, malformed, =x")
+	if len(b) != 2 || b["customer_id"] != "42" || b["order"] != "A/7" {
+		t.Fatalf("Unexpected baggage: %v", b)
+	}
+	if s := b.String(); s != "customer_id=42,order=A%2F7" {
+		t.Errorf("Unexpected encoding: %q", s)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(Header)
+	}))
+	defer server.Close()
+
+	ctx := NewContext(context.Background(), Baggage{"customer_id": "42"})
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	req.Header.Set(Header, "region=eu")
+
+	client := &http.Client{Transport: Transport(nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if received != "customer_id=42,region=eu" {
+		t.Errorf("Expected merged baggage, got %q", received)
+	}
+	if req.Header.Get(Header) != "region=eu" {
+		t.Error("Transport must not modify the caller's request")
+	}
+}
@@ -0,0 +1,210 @@
+package bench
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/middleware"
+)
+
+// discardWriter is a ResponseWriter that drops the body, so long benchmark
+// runs don't accumulate responses the way httptest.ResponseRecorder does.
+type discardWriter struct {
+	header http.Header
+	status int
+}
+
+func newDiscardWriter() *discardWriter {
+	return &discardWriter{header: make(http.Header)}
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardWriter) WriteHeader(status int)      { w.status = status }
+
+// reset clears the headers set by the previous request.
+func (w *discardWriter) reset() {
+	for key := range w.header {
+		delete(w.header, key)
+	}
+	w.status = 0
+}
+
+// serve runs req through app b.N times.
+func serve(b *testing.B, app *kese.App, req *http.Request) {
+	w := newDiscardWriter()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.reset()
+		app.ServeHTTP(w, req)
+	}
+}
+
+func noop(c *context.Context) error {
+	return nil
+}
+
+// BenchmarkRoutingDepth measures route matching as paths get deeper,
+// for static segments and for a parameter at every level.
+func BenchmarkRoutingDepth(b *testing.B) {
+	for _, depth := range []int{1, 2, 4, 8} {
+		static := make([]string, depth)
+		pattern := make([]string, depth)
+		path := make([]string, depth)
+		for i := range static {
+			static[i] = fmt.Sprintf("s%d", i)
+			pattern[i] = fmt.Sprintf(":p%d", i)
+			path[i] = fmt.Sprintf("v%d", i)
+		}
+
+		app := kese.New()
+		app.GET("/"+strings.Join(static, "/"), noop)
+		app.GET("/params/"+strings.Join(pattern, "/"), noop)
+		r := app.Router()
+
+		b.Run(fmt.Sprintf("static/depth=%d", depth), func(b *testing.B) {
+			target := "/" + strings.Join(static, "/")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.Match("GET", target)
+			}
+		})
+		b.Run(fmt.Sprintf("param/depth=%d", depth), func(b *testing.B) {
+			target := "/params/" + strings.Join(path, "/")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.Match("GET", target)
+			}
+		})
+	}
+}
+
+// BenchmarkMiddlewareChain measures a request through chains of
+// pass-through middleware of increasing length.
+func BenchmarkMiddlewareChain(b *testing.B) {
+	passThrough := func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			return next(c)
+		}
+	}
+
+	for _, length := range []int{0, 1, 5, 10, 20} {
+		b.Run(fmt.Sprintf("chain=%d", length), func(b *testing.B) {
+			app := kese.New()
+			for i := 0; i < length; i++ {
+				app.Use(passThrough)
+			}
+			app.GET("/ping", noop)
+			serve(b, app, httptest.NewRequest("GET", "/ping", nil))
+		})
+	}
+}
+
+// item is a typical API resource.
+type item struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Active    bool      `json:"active"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// items returns n resources.
+func items(n int) []item {
+	list := make([]item, n)
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range list {
+		list[i] = item{
+			ID:        i,
+			Name:      fmt.Sprintf("user %d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			Active:    i%2 == 0,
+			Tags:      []string{"alpha", "beta"},
+			CreatedAt: created,
+		}
+	}
+	return list
+}
+
+// BenchmarkJSON measures JSON responses of increasing size.
+func BenchmarkJSON(b *testing.B) {
+	for _, size := range []struct {
+		name  string
+		count int
+	}{
+		{"small", 1},
+		{"medium", 100},
+		{"large", 10000},
+	} {
+		b.Run("size="+size.name, func(b *testing.B) {
+			data := items(size.count)
+			app := kese.New()
+			app.GET("/items", func(c *context.Context) error {
+				return c.JSON(http.StatusOK, data)
+			})
+			serve(b, app, httptest.NewRequest("GET", "/items", nil))
+		})
+	}
+}
+
+// BenchmarkGzip measures a 100-item JSON response compressed at different levels.
+func BenchmarkGzip(b *testing.B) {
+	data := items(100)
+	for _, level := range []struct {
+		name  string
+		level int
+	}{
+		{"speed", gzip.BestSpeed},
+		{"default", gzip.DefaultCompression},
+		{"best", gzip.BestCompression},
+	} {
+		b.Run("level="+level.name, func(b *testing.B) {
+			config := middleware.DefaultGzipConfig()
+			config.Level = level.level
+
+			app := kese.New()
+			app.Use(middleware.GzipWithConfig(config))
+			app.GET("/items", func(c *context.Context) error {
+				return c.JSON(http.StatusOK, data)
+			})
+
+			req := httptest.NewRequest("GET", "/items", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			serve(b, app, req)
+		})
+	}
+}
+
+// BenchmarkCache compares serving a 100-item JSON response from the cache
+// middleware with rendering it on every request.
+func BenchmarkCache(b *testing.B) {
+	data := items(100)
+	for _, cached := range []bool{false, true} {
+		name := "path=render"
+		if cached {
+			name = "path=hit"
+		}
+		b.Run(name, func(b *testing.B) {
+			app := kese.New()
+			if cached {
+				app.Use(middleware.Cache(time.Hour))
+			}
+			app.GET("/items", func(c *context.Context) error {
+				return c.JSON(http.StatusOK, data)
+			})
+
+			req := httptest.NewRequest("GET", "/items", nil)
+			app.ServeHTTP(newDiscardWriter(), req) // warm the cache
+			serve(b, app, req)
+		})
+	}
+}
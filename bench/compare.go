@@ -0,0 +1,191 @@
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Result holds the median measurements of one benchmark across runs.
+type Result struct {
+	// Name is the benchmark name without the GOMAXPROCS suffix
+	Name string
+
+	// NsPerOp, BytesPerOp and AllocsPerOp are medians across Runs.
+	// BytesPerOp and AllocsPerOp are -1 if the run did not use -benchmem.
+	NsPerOp     float64
+	BytesPerOp  float64
+	AllocsPerOp float64
+
+	// Runs is the number of runs measured, e.g. from -count
+	Runs int
+}
+
+// procsSuffix matches the GOMAXPROCS suffix go test appends to benchmark names.
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// Parse reads the output of "go test -bench" and returns the results by
+// benchmark name. Lines that are not benchmark results are ignored.
+func Parse(r io.Reader) (map[string]*Result, error) {
+	samples := make(map[string][][3]float64)
+	var order []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+
+		sample := [3]float64{-1, -1, -1}
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("bench: invalid value %q in %q", fields[i], scanner.Text())
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				sample[0] = value
+			case "B/op":
+				sample[1] = value
+			case "allocs/op":
+				sample[2] = value
+			}
+		}
+		if sample[0] < 0 {
+			continue
+		}
+
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if _, exists := samples[name]; !exists {
+			order = append(order, name)
+		}
+		samples[name] = append(samples[name], sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*Result, len(samples))
+	for _, name := range order {
+		runs := samples[name]
+		results[name] = &Result{
+			Name:        name,
+			NsPerOp:     median(runs, 0),
+			BytesPerOp:  median(runs, 1),
+			AllocsPerOp: median(runs, 2),
+			Runs:        len(runs),
+		}
+	}
+	return results, nil
+}
+
+// median returns the median of column i of samples.
+func median(samples [][3]float64, i int) float64 {
+	values := make([]float64, len(samples))
+	for j, sample := range samples {
+		values[j] = sample[i]
+	}
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// Delta compares a benchmark between two runs.
+type Delta struct {
+	Name string
+
+	// Old and New are the results; one of them is nil if the benchmark
+	// exists in only one run
+	Old, New *Result
+
+	// Time and Bytes are the relative changes of ns/op and B/op
+	// (0.1 = 10% slower or larger)
+	Time, Bytes float64
+
+	// Regressed is set if time or memory grew beyond the threshold or
+	// allocations increased
+	Regressed bool
+}
+
+// Compare compares two sets of results. A benchmark regresses if its ns/op
+// or B/op grew by more than threshold (e.g. 0.1 for 10%) or its allocs/op
+// increased at all. Deltas are sorted by name.
+func Compare(old, new map[string]*Result, threshold float64) []Delta {
+	names := make(map[string]bool, len(old)+len(new))
+	for name := range old {
+		names[name] = true
+	}
+	for name := range new {
+		names[name] = true
+	}
+
+	deltas := make([]Delta, 0, len(names))
+	for name := range names {
+		d := Delta{Name: name, Old: old[name], New: new[name]}
+		if d.Old != nil && d.New != nil {
+			d.Time = change(d.Old.NsPerOp, d.New.NsPerOp)
+			d.Bytes = change(d.Old.BytesPerOp, d.New.BytesPerOp)
+			d.Regressed = d.Time > threshold || d.Bytes > threshold ||
+				(d.Old.AllocsPerOp >= 0 && d.New.AllocsPerOp > d.Old.AllocsPerOp)
+		}
+		deltas = append(deltas, d)
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Name < deltas[j].Name })
+	return deltas
+}
+
+// change returns the relative change from old to new, or 0 if unknown.
+func change(old, new float64) float64 {
+	if old < 0 || new < 0 {
+		return 0
+	}
+	if old == 0 {
+		if new == 0 {
+			return 0
+		}
+		return 1
+	}
+	return (new - old) / old
+}
+
+// WriteTable writes deltas as an aligned table, marking regressions.
+func WriteTable(w io.Writer, deltas []Delta) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "benchmark\told ns/op\tnew ns/op\tdelta\told B/op\tnew B/op\told allocs\tnew allocs\t\t")
+	for _, d := range deltas {
+		mark := ""
+		if d.Regressed {
+			mark = "REGRESSION"
+		}
+		switch {
+		case d.Old == nil:
+			fmt.Fprintf(tw, "%s\t-\t%.1f\tnew\t-\t%s\t-\t%s\t%s\t\n", d.Name, d.New.NsPerOp, memory(d.New.BytesPerOp), memory(d.New.AllocsPerOp), mark)
+		case d.New == nil:
+			fmt.Fprintf(tw, "%s\t%.1f\t-\tremoved\t%s\t-\t%s\t-\t%s\t\n", d.Name, d.Old.NsPerOp, memory(d.Old.BytesPerOp), memory(d.Old.AllocsPerOp), mark)
+		default:
+			fmt.Fprintf(tw, "%s\t%.1f\t%.1f\t%+.1f%%\t%s\t%s\t%s\t%s\t%s\t\n", d.Name, d.Old.NsPerOp, d.New.NsPerOp, d.Time*100,
+				memory(d.Old.BytesPerOp), memory(d.New.BytesPerOp), memory(d.Old.AllocsPerOp), memory(d.New.AllocsPerOp), mark)
+		}
+	}
+	return tw.Flush()
+}
+
+// memory formats a B/op or allocs/op value, which is -1 without -benchmem.
+func memory(value float64) string {
+	if value < 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,81 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+)
+
+const baseRun = `goos: linux
+goarch: amd64
+pkg: github.com/JedizLaPulga/kese/bench
+BenchmarkJSON/size=small-8     	 1000000	      1000 ns/op	     512 B/op	       4 allocs/op
+BenchmarkJSON/size=small-8     	 1000000	      1200 ns/op	     512 B/op	       4 allocs/op
+BenchmarkJSON/size=small-8     	 1000000	      1100 ns/op	     512 B/op	       4 allocs/op
+BenchmarkRoutingDepth/static/depth=4-8  	20000000	        50 ns/op	       0 B/op	       0 allocs/op
+BenchmarkMiddlewareChain/chain=5-8      	 5000000	       300 ns/op
+BenchmarkRemoved-8             	 1000000	        10 ns/op	       0 B/op	       0 allocs/op
+PASS
+ok  	github.com/JedizLaPulga/kese/bench	12.3s
+`
+
+const newRun = `BenchmarkJSON/size=small-8     	 1000000	      1150 ns/op	     512 B/op	       4 allocs/op
+BenchmarkRoutingDepth/static/depth=4-8  	20000000	        51 ns/op	       0 B/op	       1 allocs/op
+BenchmarkMiddlewareChain/chain=5-8      	 5000000	       400 ns/op
+BenchmarkAdded-8               	 1000000	        10 ns/op	       0 B/op	       0 allocs/op
+`
+
+func TestParse(t *testing.T) {
+	results, err := Parse(strings.NewReader(baseRun))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 benchmarks, got %d", len(results))
+	}
+
+	json := results["BenchmarkJSON/size=small"]
+	if json == nil || json.Runs != 3 || json.NsPerOp != 1100 || json.AllocsPerOp != 4 {
+		t.Errorf("Expected median of 3 runs without the procs suffix, got %+v", json)
+	}
+	if depth := results["BenchmarkRoutingDepth/static/depth=4"]; depth == nil || depth.NsPerOp != 50 {
+		t.Errorf("Expected key=value names to be kept, got %+v", depth)
+	}
+	if chain := results["BenchmarkMiddlewareChain/chain=5"]; chain == nil || chain.AllocsPerOp != -1 {
+		t.Errorf("Expected unknown allocs without -benchmem, got %+v", chain)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	old, _ := Parse(strings.NewReader(baseRun))
+	current, _ := Parse(strings.NewReader(newRun))
+	deltas := Compare(old, current, 0.10)
+
+	byName := make(map[string]Delta)
+	for _, d := range deltas {
+		byName[d.Name] = d
+	}
+
+	if d := byName["BenchmarkJSON/size=small"]; d.Regressed {
+		t.Errorf("A 4.5%% slowdown is within the threshold: %+v", d)
+	}
+	if d := byName["BenchmarkRoutingDepth/static/depth=4"]; !d.Regressed {
+		t.Errorf("A new allocation should be a regression: %+v", d)
+	}
+	if d := byName["BenchmarkMiddlewareChain/chain=5"]; !d.Regressed || d.Time < 0.33 || d.Time > 0.34 {
+		t.Errorf("A 33%% slowdown should be a regression: %+v", d)
+	}
+	if d := byName["BenchmarkAdded"]; d.Old != nil || d.Regressed {
+		t.Errorf("New benchmarks are not regressions: %+v", d)
+	}
+	if d := byName["BenchmarkRemoved"]; d.New != nil {
+		t.Errorf("Expected removed benchmark, got %+v", d)
+	}
+
+	var table strings.Builder
+	if err := WriteTable(&table, deltas); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(table.String(), "REGRESSION") != 2 {
+		t.Errorf("Expected 2 regressions in table:\n%s", table.String())
+	}
+}
@@ -0,0 +1,22 @@
+// Package bench holds the framework's benchmark suite and the tools to compare
+// benchmark runs against a committed baseline, so performance-motivated
+// changes (context pooling, router fast paths, encoder changes) can be
+// validated and regressions caught before they ship.
+//
+// The suite covers routing depth, middleware chain length, JSON response
+// sizes, gzip levels and cache hits. Sub-benchmarks are named "key=value"
+// (e.g. "depth=4"), so the GOMAXPROCS suffix go test appends can be stripped
+// unambiguously.
+//
+// Workflow:
+//
+//	# Record a run and compare it with the baseline; exits 1 on regressions
+//	go test -run '^$' -bench . -benchmem -count 6 ./bench > new.txt
+//	go run ./cmd/kese-benchcmp bench/testdata/baseline.txt new.txt
+//
+//	# Accept the new numbers after an intentional change
+//	cp new.txt bench/testdata/baseline.txt
+//
+// Baselines are machine-specific: record the baseline and the new run on the
+// same hardware, e.g. both in the same CI job.
+package bench
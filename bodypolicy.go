@@ -0,0 +1,28 @@
+package kese
+
+import "github.com/JedizLaPulga/kese/context"
+
+// StreamBody disables request body buffering for the route, for streaming
+// proxies and large uploads. The handler reads the body with c.BodyReader,
+// without the app's MaxBodySize limit; c.Body, c.BodyBytes and the JSON and
+// XML binders return context.ErrBodyBufferingDisabled, and middleware that
+// buffers bodies (such as Shadow) leaves the request alone.
+//
+// Example:
+//
+//	app.PUT("/blobs/:id", putBlob).StreamBody()
+func (r *Route) StreamBody() *Route {
+	return r.Set(context.BodyPolicyKey, &context.BodyPolicy{Stream: true})
+}
+
+// PrefetchBody reads the whole request body, up to maxSize bytes, before
+// middleware and the handler run, so oversized or slow uploads fail with 413
+// or a timeout before any work (authentication lookups, transactions) is done.
+// maxSize replaces the app's MaxBodySize for the route; 0 keeps it.
+//
+// Example:
+//
+//	app.POST("/webhooks/github", githubWebhook).PrefetchBody(1 << 20)
+func (r *Route) PrefetchBody(maxSize int64) *Route {
+	return r.Set(context.BodyPolicyKey, &context.BodyPolicy{Prefetch: true, MaxSize: maxSize})
+}
@@ -0,0 +1,55 @@
+package kese
+
+import "time"
+
+// CachePolicyKey is the route metadata key under which Route.Cache stores its *CachePolicy.
+const CachePolicyKey = "kese.cache"
+
+// CachePolicy describes how responses of a route are cached by the Cache middleware.
+// It overrides the middleware's global TTL for that route.
+type CachePolicy struct {
+	// TTL is how long responses are cached. Zero disables caching for the route.
+	TTL time.Duration
+
+	// VaryQuery lists query parameters that produce distinct cache entries.
+	// Other query parameters are ignored when building the cache key.
+	VaryQuery []string
+
+	// VaryHeader lists request headers that produce distinct cache entries
+	VaryHeader []string
+}
+
+// CacheOption configures a CachePolicy.
+type CacheOption func(*CachePolicy)
+
+// VaryQuery caches responses separately for each value of the given query parameters.
+func VaryQuery(params ...string) CacheOption {
+	return func(p *CachePolicy) {
+		p.VaryQuery = append(p.VaryQuery, params...)
+	}
+}
+
+// VaryHeader caches responses separately for each value of the given request headers.
+func VaryHeader(headers ...string) CacheOption {
+	return func(p *CachePolicy) {
+		p.VaryHeader = append(p.VaryHeader, headers...)
+	}
+}
+
+// Cache declares the cache policy of the route, consumed by middleware.Cache.
+//
+// Example:
+//
+//	app.GET("/products", listProducts).Cache(5*time.Minute, kese.VaryQuery("page"))
+func (r *Route) Cache(ttl time.Duration, options ...CacheOption) *Route {
+	policy := &CachePolicy{TTL: ttl}
+	for _, option := range options {
+		option(policy)
+	}
+	return r.Set(CachePolicyKey, policy)
+}
+
+// NoCache excludes the route from response caching.
+func (r *Route) NoCache() *Route {
+	return r.Set(CachePolicyKey, &CachePolicy{})
+}
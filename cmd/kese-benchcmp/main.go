@@ -0,0 +1,76 @@
+// Command kese-benchcmp compares two "go test -bench" outputs, typically a
+// committed baseline and a new run, and exits with status 1 if any benchmark
+// regressed, for gating performance in CI.
+//
+// Usage:
+//
+//	go test -run '^$' -bench . -benchmem -count 6 ./bench > new.txt
+//	kese-benchcmp -threshold 0.05 bench/testdata/baseline.txt new.txt
+//
+// A benchmark regresses if its ns/op or B/op grew by more than the threshold
+// or its allocs/op increased. See package bench for the suite.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/JedizLaPulga/kese/bench"
+)
+
+func main() {
+	threshold := flag.Float64("threshold", 0.10, "relative ns/op and B/op increase treated as a regression")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: kese-benchcmp [-threshold 0.10] old.txt new.txt")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	regressed, err := run(flag.Arg(0), flag.Arg(1), *threshold)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "kese-benchcmp:", err)
+		os.Exit(2)
+	}
+	if regressed > 0 {
+		fmt.Fprintf(os.Stderr, "kese-benchcmp: %d benchmark(s) regressed\n", regressed)
+		os.Exit(1)
+	}
+}
+
+func run(oldPath, newPath string, threshold float64) (int, error) {
+	old, err := parseFile(oldPath)
+	if err != nil {
+		return 0, err
+	}
+	current, err := parseFile(newPath)
+	if err != nil {
+		return 0, err
+	}
+
+	deltas := bench.Compare(old, current, threshold)
+	if err := bench.WriteTable(os.Stdout, deltas); err != nil {
+		return 0, err
+	}
+
+	regressed := 0
+	for _, d := range deltas {
+		if d.Regressed {
+			regressed++
+		}
+	}
+	return regressed, nil
+}
+
+func parseFile(path string) (map[string]*bench.Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return bench.Parse(f)
+}
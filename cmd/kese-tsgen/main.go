@@ -0,0 +1,82 @@
+// Command kese-tsgen generates a typed TypeScript client from a kese API
+// schema, read from a JSON file, standard input or the URL of a running app
+// serving App.SchemaHandler.
+//
+// Usage:
+//
+//	kese-tsgen -schema http://localhost:8080/_schema -out web/src/api.ts
+//	kese-tsgen -schema api.json -client TodoClient > api.ts
+//
+// With go:generate:
+//
+//	//go:generate go run github.com/JedizLaPulga/kese/cmd/kese-tsgen -schema api.json -out web/src/api.ts
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/tsgen"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "-", "schema JSON file, http(s) URL, or - for standard input")
+	out := flag.String("out", "", "output file (default: standard output)")
+	client := flag.String("client", "Client", "name of the generated client class")
+	flag.Parse()
+
+	if err := run(*schemaPath, *out, *client); err != nil {
+		fmt.Fprintln(os.Stderr, "kese-tsgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, out, client string) error {
+	data, err := readSchema(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	var schema kese.APISchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("decoding schema: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tsgen.Generate(&buf, &schema, tsgen.Config{ClientName: client}); err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0o644)
+}
+
+// readSchema reads the schema JSON from a file, URL or standard input.
+func readSchema(path string) ([]byte, error) {
+	switch {
+	case path == "-":
+		return io.ReadAll(os.Stdin)
+	case strings.HasPrefix(path, "http://"), strings.HasPrefix(path, "https://"):
+		resp, err := http.Get(path)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching schema: %s", resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	default:
+		return os.ReadFile(path)
+	}
+}
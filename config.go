@@ -0,0 +1,197 @@
+package kese
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ConfigError describes a single misconfiguration detected at startup.
+// Middleware constructors panic with a *ConfigError when given an invalid config,
+// and App.Validate returns them wrapped in ConfigErrors.
+type ConfigError struct {
+	// Component is the part of the app that is misconfigured (e.g. "router", "cors")
+	Component string
+
+	// Problem describes what is wrong
+	Problem string
+
+	// Fix describes how to resolve the problem
+	Fix string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Fix == "" {
+		return fmt.Sprintf("%s: %s", e.Component, e.Problem)
+	}
+	return fmt.Sprintf("%s: %s (fix: %s)", e.Component, e.Problem, e.Fix)
+}
+
+// ConfigErrors is the list of problems returned by App.Validate.
+type ConfigErrors []*ConfigError
+
+func (e ConfigErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("kese: %d configuration problem(s) found", len(e)))
+	for _, err := range e {
+		lines = append(lines, "  - "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AddStartupCheck registers a check that Validate runs before the server starts.
+// Returning a *ConfigError gives the most actionable message, but any error is accepted.
+//
+// Example:
+//
+//	app.AddStartupCheck(func() error {
+//	    if os.Getenv("DATABASE_URL") == "" {
+//	        return &kese.ConfigError{Component: "database", Problem: "DATABASE_URL is not set"}
+//	    }
+//	    return nil
+//	})
+func (a *App) AddStartupCheck(check func() error) {
+	a.startupChecks = append(a.startupChecks, check)
+}
+
+// reportedConfigErrors are problems reported by middleware constructors with
+// ReportConfigError, until the app the middleware is registered with claims
+// them.
+var (
+	reportedConfigMu     sync.Mutex
+	reportedConfigErrors []*ConfigError
+)
+
+// ReportConfigError records a problem found by a middleware constructor, for
+// configurations that should stop the server from starting rather than panic
+// where the middleware is built. The next App.Use or App.Group call, which
+// normally receives the middleware, registers it as a startup check, so
+// Validate reports it with the app's other problems and Run refuses to start.
+//
+// Example:
+//
+//	func Tenant(config TenantConfig) kese.MiddlewareFunc {
+//	    if err := config.Validate(); err != nil {
+//	        kese.ReportConfigError(err)
+//	    }
+//	    ...
+//	}
+func ReportConfigError(err *ConfigError) {
+	reportedConfigMu.Lock()
+	defer reportedConfigMu.Unlock()
+	reportedConfigErrors = append(reportedConfigErrors, err)
+}
+
+// claimConfigErrors registers the problems reported with ReportConfigError
+// as startup checks of a.
+func (a *App) claimConfigErrors() {
+	reportedConfigMu.Lock()
+	reported := reportedConfigErrors
+	reportedConfigErrors = nil
+	reportedConfigMu.Unlock()
+
+	for _, err := range reported {
+		err := err
+		a.AddStartupCheck(func() error { return err })
+	}
+}
+
+// Validate inspects the application for common misconfigurations and returns
+// ConfigErrors describing every problem found, or nil if the app looks sane.
+// Run, RunTLS and RunWithShutdown call Validate and refuse to start on failure.
+//
+// Detected problems:
+//   - the same method and path registered twice
+//   - routes whose parameters use different names at the same position
+//   - middleware registered with Use after routes (it does not apply to earlier routes)
+//   - failing checks registered with AddStartupCheck
+//   - middleware configuration problems reported with ReportConfigError, such
+//     as CORS credentials allowed for the wildcard origin
+//
+// CSRF protection is not checked for sessions: its double-submit cookie
+// works without them, and CSRFConfig.SessionFunc binds tokens to sessions
+// where there are some.
+func (a *App) Validate() error {
+	a.claimConfigErrors()
+	var problems ConfigErrors
+
+	problems = append(problems, a.validateRoutes()...)
+
+	if a.lateMiddleware > 0 {
+		problems = append(problems, &ConfigError{
+			Component: "middleware",
+			Problem:   fmt.Sprintf("%d middleware registered with Use after routes; they do not apply to routes registered earlier", a.lateMiddleware),
+			Fix:       "call app.Use before registering routes, or attach the middleware to a Group",
+		})
+	}
+
+	if len(a.FlashSecret) > 0 && len(a.FlashSecret) < 32 {
+		problems = append(problems, &ConfigError{
+			Component: "flash",
+			Problem:   fmt.Sprintf("FlashSecret is %d bytes; signatures could be brute-forced", len(a.FlashSecret)),
+			Fix:       "use a random FlashSecret of at least 32 bytes",
+		})
+	}
+
+	for _, check := range a.startupChecks {
+		if err := check(); err != nil {
+			if cfgErr, ok := err.(*ConfigError); ok {
+				problems = append(problems, cfgErr)
+			} else {
+				problems = append(problems, &ConfigError{Component: "startup check", Problem: err.Error()})
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// validateRoutes detects duplicate routes and conflicting parameter names.
+func (a *App) validateRoutes() ConfigErrors {
+	var problems ConfigErrors
+
+	registered := make(map[string]string) // method + shape -> original path
+	paramNames := make(map[string]string) // method + shape prefix -> param name
+
+	for _, route := range a.registeredRoutes() {
+		segments := strings.FieldsFunc(route.Path, func(r rune) bool { return r == '/' })
+		shape := make([]string, 0, len(segments))
+		conflicted := false
+
+		for _, segment := range segments {
+			if !strings.HasPrefix(segment, ":") {
+				shape = append(shape, segment)
+				continue
+			}
+
+			shape = append(shape, ":")
+			prefixKey := route.Method + " /" + strings.Join(shape, "/")
+			if existing, ok := paramNames[prefixKey]; ok && existing != segment {
+				problems = append(problems, &ConfigError{
+					Component: "router",
+					Problem:   fmt.Sprintf("%s %s uses parameter %s where another route uses %s", route.Method, route.Path, segment, existing),
+					Fix:       "use the same parameter name at the same position in every route",
+				})
+				conflicted = true
+				continue
+			}
+			paramNames[prefixKey] = segment
+		}
+
+		key := route.Method + " /" + strings.Join(shape, "/")
+		if existing, ok := registered[key]; ok && !conflicted {
+			problems = append(problems, &ConfigError{
+				Component: "router",
+				Problem:   fmt.Sprintf("%s %s conflicts with previously registered %s %s", route.Method, route.Path, route.Method, existing),
+				Fix:       "remove the duplicate registration; the last one silently wins",
+			})
+			continue
+		}
+		registered[key] = route.Path
+	}
+
+	return problems
+}
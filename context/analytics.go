@@ -0,0 +1,36 @@
+package context
+
+import (
+	"time"
+
+	"github.com/JedizLaPulga/kese/analytics"
+)
+
+// Track records a product event with the request's ID, route and client. It
+// never blocks and does nothing unless App.Analytics is set.
+//
+// Example:
+//
+//	c.Track("todo_created", map[string]interface{}{
+//	    "list":    todo.ListID,
+//	    "has_due": todo.Due != nil,
+//	})
+func (c *Context) Track(name string, props map[string]interface{}) {
+	if c.Analytics == nil {
+		return
+	}
+	path := c.routePath
+	if path == "" {
+		path = c.Path()
+	}
+	ua := c.UserAgent()
+	c.Analytics.Track(analytics.Event{
+		Name:       name,
+		Time:       time.Now(),
+		Properties: props,
+		RequestID:  c.RequestID(),
+		Path:       path,
+		Client:     ua.String(),
+		Bot:        ua.Bot,
+	})
+}
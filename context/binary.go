@@ -0,0 +1,74 @@
+package context
+
+import (
+	"github.com/JedizLaPulga/kese/msgpack"
+)
+
+// Content types of the binary encodings.
+const (
+	MIMEMsgpack  = "application/msgpack"
+	MIMEProtobuf = "application/x-protobuf"
+)
+
+// ProtoMarshaler is implemented by protobuf messages that can encode
+// themselves, such as those generated by gogo/protobuf or vtprotobuf
+// (wrap google.golang.org/protobuf messages with proto.Marshal).
+type ProtoMarshaler interface {
+	Marshal() ([]byte, error)
+}
+
+// ProtoUnmarshaler is implemented by protobuf messages that can decode themselves.
+type ProtoUnmarshaler interface {
+	Unmarshal(data []byte) error
+}
+
+// Msgpack sends a MessagePack response with the specified status code.
+// It is a compact binary alternative to JSON for internal APIs.
+//
+// Example:
+//
+//	return c.Msgpack(200, users)
+func (c *Context) Msgpack(status int, data interface{}) error {
+	body, err := msgpack.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.Bytes(status, MIMEMsgpack, body)
+}
+
+// Protobuf sends a protobuf-encoded response with the specified status code.
+//
+// Example:
+//
+//	return c.Protobuf(200, &pb.User{Id: 1, Name: "Ada"})
+func (c *Context) Protobuf(status int, message ProtoMarshaler) error {
+	body, err := message.Marshal()
+	if err != nil {
+		return err
+	}
+	return c.Bytes(status, MIMEProtobuf, body)
+}
+
+// BindMsgpack decodes a MessagePack request body into v, returning a *BindError on failure.
+func (c *Context) BindMsgpack(v interface{}) error {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return &BindError{Err: err}
+	}
+	if err := msgpack.Unmarshal(body, v); err != nil {
+		return &BindError{Err: err}
+	}
+	return nil
+}
+
+// BindProtobuf decodes a protobuf request body into message, returning a *BindError on failure.
+func (c *Context) BindProtobuf(message ProtoUnmarshaler) error {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return &BindError{Err: err}
+	}
+	if err := message.Unmarshal(body); err != nil {
+		return &BindError{Err: err}
+	}
+	return nil
+}
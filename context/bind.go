@@ -0,0 +1,501 @@
+package context
+
+import (
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JedizLaPulga/kese/validate"
+)
+
+// BindError is returned when request data cannot be bound to a struct.
+// The default error handler maps it to 400 Bad Request.
+type BindError struct {
+	// Field is the request key that failed to bind (empty for whole-body errors)
+	Field string
+
+	// Value is the raw value that failed to convert; for JSON type mismatches
+	// it is the JSON type that was received (e.g. "string")
+	Value string
+
+	// Offset is the byte offset in the body where JSON decoding failed (0 if unknown)
+	Offset int64
+
+	// Expected is the type the field requires, for JSON type mismatches (e.g. "integer")
+	Expected string
+
+	// Err is the underlying error
+	Err error
+}
+
+func (e *BindError) Error() string {
+	switch {
+	case e.Expected != "" && e.Field != "":
+		return fmt.Sprintf("invalid value for %q: expected %s, got %s", e.Field, e.Expected, e.Value)
+	case e.Expected != "":
+		return fmt.Sprintf("invalid request body: expected %s, got %s", e.Expected, e.Value)
+	case errors.Is(e.Err, ErrUnknownField):
+		return fmt.Sprintf("unknown field %q", e.Field)
+	case e.Field == "" && e.Offset > 0:
+		return fmt.Sprintf("invalid request at offset %d: %v", e.Offset, e.Err)
+	case e.Field == "":
+		return fmt.Sprintf("invalid request: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid value %q for %q: %v", e.Value, e.Field, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnsupportedMediaType is wrapped in a BindError when Bind receives a body
+// with a Content-Type it cannot decode. The default error handler maps it to 415.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// timeLayouts are the formats accepted for time.Time fields, tried in order.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType     = reflect.TypeOf([]*multipart.FileHeader(nil))
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind decodes the request into v based on the Content-Type header:
+//
+//	application/json (and +json types)   -> JSON body
+//	application/xml, text/xml            -> XML body
+//	application/x-www-form-urlencoded    -> BindForm
+//	multipart/form-data                  -> BindForm
+//	application/msgpack, x-msgpack       -> BindMsgpack
+//	application/x-protobuf, protobuf     -> BindProtobuf (v must implement ProtoUnmarshaler)
+//	no body (GET, HEAD, DELETE, ...)     -> BindQuery
+//
+// All failures are returned as *BindError, which the default error handler maps
+// to 400 (or 415 for unsupported content types).
+//
+// Example:
+//
+//	var in CreateTodoInput
+//	if err := c.Bind(&in); err != nil {
+//	    return err
+//	}
+func (c *Context) Bind(v interface{}) error {
+	contentType := c.Header("Content-Type")
+	if contentType == "" && (c.Request.ContentLength <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody) {
+		return c.BindQuery(v)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &BindError{Err: ErrUnsupportedMediaType}
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return c.BindJSON(v)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return c.BindXML(v)
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		return c.BindForm(v)
+	case mediaType == MIMEMsgpack || mediaType == "application/x-msgpack":
+		return c.BindMsgpack(v)
+	case mediaType == MIMEProtobuf || mediaType == "application/protobuf":
+		if message, ok := v.(ProtoUnmarshaler); ok {
+			return c.BindProtobuf(message)
+		}
+		return &BindError{Err: fmt.Errorf("%w: %s requires a protobuf message, got %T", ErrUnsupportedMediaType, mediaType, v)}
+	}
+
+	return &BindError{Err: fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)}
+}
+
+// BindAndValidate binds the request into v like Bind, then validates it using
+// its `validate` struct tags. Validation failures are returned as a
+// *validate.ValidationError, which the default error handler maps to a
+// 400 response listing every failing field.
+//
+// Example:
+//
+//	type CreateUserInput struct {
+//	    Name  string `json:"name" validate:"required,min=3,max=50"`
+//	    Email string `json:"email" validate:"required,email"`
+//	}
+//
+//	var in CreateUserInput
+//	if err := c.BindAndValidate(&in); err != nil {
+//	    return err
+//	}
+func (c *Context) BindAndValidate(v interface{}) error {
+	if err := c.Bind(v); err != nil {
+		return err
+	}
+	return validate.Struct(v)
+}
+
+// BindJSON decodes a JSON request body into v, returning a *BindError on
+// failure that names the offending field and expected type where possible.
+// Use BindJSONWithOptions for strict decoding.
+func (c *Context) BindJSON(v interface{}) error {
+	return c.BindJSONWithOptions(v, JSONOptions{})
+}
+
+// BindXML decodes an XML request body into v, returning a *BindError on failure.
+func (c *Context) BindXML(v interface{}) error {
+	body, err := c.bufferedBody()
+	if err != nil {
+		return &BindError{Err: err}
+	}
+	if err := xml.NewDecoder(body).Decode(v); err != nil {
+		return &BindError{Err: err}
+	}
+	return nil
+}
+
+// BindQuery populates the struct pointed to by v from URL query parameters.
+// Fields are matched by their `query:"name"` tag, falling back to the field name;
+// a tag of "-" skips the field. Supported field types are strings, integers,
+// floats, bools, time.Time (RFC 3339 or 2006-01-02), time.Duration, types
+// implementing encoding.TextUnmarshaler, pointers to these, and slices of these
+// (filled from repeated parameters like ?tag=a&tag=b or ?tag[]=a&tag[]=b).
+// Bracketed keys such as ?filter[status]=open bind nested structs, matched
+// by their own tags, and maps with string keys.
+//
+// Example:
+//
+//	var filter struct {
+//	    Page  int       `query:"page"`
+//	    Done  *bool     `query:"done"`
+//	    Since time.Time `query:"since"`
+//	    Tags  []string  `query:"tag"`
+//	    Where struct {
+//	        Status string `query:"status"`
+//	    } `query:"filter"`
+//	    Sort map[string]string `query:"sort"` // ?sort[name]=asc
+//	}
+//	if err := c.BindQuery(&filter); err != nil {
+//	    return err // 400 via the default error handler
+//	}
+func (c *Context) BindQuery(v interface{}) error {
+	return bindValues(v, c.Request.URL.Query(), nil, "query")
+}
+
+// BindForm populates the struct pointed to by v from an application/x-www-form-urlencoded
+// or multipart/form-data body. Fields are matched by their `form:"name"` tag and
+// support the same types as BindQuery. Fields of type *multipart.FileHeader or
+// []*multipart.FileHeader receive uploaded files.
+//
+// Example:
+//
+//	var in struct {
+//	    Name   string                `form:"name"`
+//	    Age    int                   `form:"age"`
+//	    Avatar *multipart.FileHeader `form:"avatar"`
+//	}
+//	if err := c.BindForm(&in); err != nil {
+//	    return err
+//	}
+func (c *Context) BindForm(v interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Header("Content-Type"))
+
+	var files map[string][]*multipart.FileHeader
+	if mediaType == "multipart/form-data" {
+		if err := c.Request.ParseMultipartForm(c.MaxBodySize); err != nil {
+			return &BindError{Err: err}
+		}
+		files = c.Request.MultipartForm.File
+	} else {
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, c.MaxBodySize)
+		}
+		if err := c.Request.ParseForm(); err != nil {
+			return &BindError{Err: err}
+		}
+	}
+
+	return bindValues(v, c.Request.PostForm, files, "form")
+}
+
+// bindValues binds values (and uploaded files, if any) to the struct pointed to by v
+// using the given tag name.
+func bindValues(v interface{}, values map[string][]string, files map[string][]*multipart.FileHeader, tag string) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a non-nil pointer to a struct, got %T", v)
+	}
+	return bindStruct(target.Elem(), normalizeArrayKeys(values), files, tag)
+}
+
+// normalizeArrayKeys merges "name[]" keys, as sent by many JavaScript
+// clients for arrays, into "name".
+func normalizeArrayKeys(values map[string][]string) map[string][]string {
+	found := false
+	for key := range values {
+		if strings.HasSuffix(key, "[]") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return values
+	}
+
+	normalized := make(map[string][]string, len(values))
+	for key, raw := range values {
+		key = strings.TrimSuffix(key, "[]")
+		normalized[key] = append(normalized[key], raw...)
+	}
+	return normalized
+}
+
+// nestedValues returns the values of bracketed keys under name, with the
+// first bracket level removed: "filter[status]" becomes "status" and
+// "filter[a][b]" becomes "a[b]".
+func nestedValues(values map[string][]string, name string) map[string][]string {
+	var nested map[string][]string
+	for key, raw := range values {
+		rest, ok := strings.CutPrefix(key, name+"[")
+		if !ok {
+			continue
+		}
+		end := strings.IndexByte(rest, ']')
+		if end <= 0 {
+			continue
+		}
+		if nested == nil {
+			nested = make(map[string][]string)
+		}
+		nested[rest[:end]+rest[end+1:]] = raw
+	}
+	return normalizeArrayKeys(nested)
+}
+
+// nestedName returns the full bracketed name of a nested field, for errors.
+func nestedName(name, field string) string {
+	if i := strings.IndexByte(field, '['); i >= 0 {
+		return name + "[" + field[:i] + "]" + field[i:]
+	}
+	return name + "[" + field + "]"
+}
+
+// bindNested binds bracketed values to a nested struct or map field.
+// It reports false if the field is neither.
+func bindNested(field reflect.Value, values map[string][]string, name, tag string) (bool, error) {
+	fieldType := field.Type()
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	isStruct := fieldType.Kind() == reflect.Struct && fieldType != timeType &&
+		!reflect.PointerTo(fieldType).Implements(textUnmarshalerType)
+	isMap := fieldType.Kind() == reflect.Map && fieldType.Key().Kind() == reflect.String
+	if !isStruct && !isMap {
+		return false, nil
+	}
+
+	nested := nestedValues(values, name)
+	if len(nested) == 0 {
+		return true, nil
+	}
+
+	target := field
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(fieldType))
+		}
+		target = field.Elem()
+	}
+
+	if isStruct {
+		if err := bindStruct(target, nested, nil, tag); err != nil {
+			var bindErr *BindError
+			if errors.As(err, &bindErr) {
+				bindErr.Field = nestedName(name, bindErr.Field)
+			}
+			return true, err
+		}
+		return true, nil
+	}
+
+	if target.IsNil() {
+		target.Set(reflect.MakeMapWithSize(fieldType, len(nested)))
+	}
+	for key, raw := range nested {
+		if strings.Contains(key, "[") {
+			continue
+		}
+		elem := reflect.New(fieldType.Elem()).Elem()
+		if err := setField(elem, raw); err != nil {
+			return true, &BindError{Field: nestedName(name, key), Value: strings.Join(raw, ","), Err: err}
+		}
+		target.SetMapIndex(reflect.ValueOf(key).Convert(fieldType.Key()), elem)
+	}
+	return true, nil
+}
+
+// bindStruct binds values to each exported field of a struct value.
+func bindStruct(structValue reflect.Value, values map[string][]string, files map[string][]*multipart.FileHeader, tag string) error {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		name := field.Tag.Get(tag)
+		if name == "-" {
+			continue
+		}
+
+		// Recurse into embedded structs without their own tag
+		if field.Anonymous && name == "" && fieldValue.Kind() == reflect.Struct {
+			if err := bindStruct(fieldValue, values, files, tag); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		name = strings.Split(name, ",")[0]
+
+		// Uploaded files are bound from the multipart file map
+		switch field.Type {
+		case fileHeaderType:
+			if uploaded := files[name]; len(uploaded) > 0 {
+				fieldValue.Set(reflect.ValueOf(uploaded[0]))
+			}
+			continue
+		case fileHeadersType:
+			if uploaded := files[name]; len(uploaded) > 0 {
+				fieldValue.Set(reflect.ValueOf(uploaded))
+			}
+			continue
+		}
+
+		// Bracketed keys bind nested structs and maps
+		if nested, err := bindNested(fieldValue, values, name, tag); nested {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, exists := values[name]
+		if !exists || len(raw) == 0 {
+			continue
+		}
+
+		if err := setField(fieldValue, raw); err != nil {
+			return &BindError{Field: name, Value: strings.Join(raw, ","), Err: err}
+		}
+	}
+
+	return nil
+}
+
+// setField converts raw values into the field, handling slices and pointers.
+func setField(field reflect.Value, raw []string) error {
+	if field.Kind() == reflect.Slice && !isScalarSlice(field.Type()) {
+		slice := reflect.MakeSlice(field.Type(), len(raw), len(raw))
+		for i, value := range raw {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, raw[0])
+}
+
+// isScalarSlice reports whether a slice type is bound from a single value ([]byte).
+func isScalarSlice(t reflect.Type) bool {
+	return t.Elem().Kind() == reflect.Uint8
+}
+
+// setValue converts a single raw value into field.
+func setValue(field reflect.Value, raw string) error {
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setValue(ptr.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) && field.Type() != timeType {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	switch field.Type() {
+	case timeType:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, raw); err == nil {
+				field.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("expected RFC 3339 time or YYYY-MM-DD date")
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected a boolean")
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a non-negative integer")
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		field.SetBytes([]byte(raw))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pagination struct {
+	Page    int `query:"page"`
+	PerPage int `query:"per_page"`
+}
+
+type todoFilter struct {
+	pagination
+	Done    *bool         `query:"done"`
+	Since   time.Time     `query:"since"`
+	Tags    []string      `query:"tag"`
+	IDs     []int64       `query:"id"`
+	Timeout time.Duration `query:"timeout"`
+	Score   float64       `query:"score"`
+	Ignored string        `query:"-"`
+	Search  string
+}
+
+func TestBindQuery(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/todos?page=2&per_page=50&done=true&since=2026-01-02&tag=a&tag=b&id=1&id=2&timeout=1500ms&score=0.5&Ignored=x&Search=milk", nil)
+	ctx := New(w, r, defaultTestLimit)
+
+	var filter todoFilter
+	if err := ctx.BindQuery(&filter); err != nil {
+		t.Fatalf("BindQuery error: %v", err)
+	}
+
+	if filter.Page != 2 || filter.PerPage != 50 {
+		t.Errorf("Embedded pagination not bound: %+v", filter.pagination)
+	}
+	if filter.Done == nil || !*filter.Done {
+		t.Error("Expected done=true")
+	}
+	if !filter.Since.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected since: %v", filter.Since)
+	}
+	if len(filter.Tags) != 2 || filter.Tags[1] != "b" {
+		t.Errorf("Unexpected tags: %v", filter.Tags)
+	}
+	if len(filter.IDs) != 2 || filter.IDs[1] != 2 {
+		t.Errorf("Unexpected ids: %v", filter.IDs)
+	}
+	if filter.Timeout != 1500*time.Millisecond || filter.Score != 0.5 {
+		t.Errorf("Unexpected timeout/score: %v %v", filter.Timeout, filter.Score)
+	}
+	if filter.Ignored != "" || filter.Search != "milk" {
+		t.Errorf("Unexpected ignored/search: %q %q", filter.Ignored, filter.Search)
+	}
+}
+
+func TestBindQueryError(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/todos?page=two", nil)
+	ctx := New(w, r, defaultTestLimit)
+
+	var filter todoFilter
+	err := ctx.BindQuery(&filter)
+
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) || bindErr.Field != "page" || bindErr.Value != "two" {
+		t.Fatalf("Expected BindError for page, got %v", err)
+	}
+
+	if err := ctx.BindQuery(filter); err == nil {
+		t.Error("Expected error when binding into a non-pointer")
+	}
+}
+
+func TestBindQueryNested(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/todos?tag[]=a&tag[]=b&filter[status]=open&filter[owner][id]=7&sort[name]=asc&sort[due]=desc", nil)
+	ctx := New(w, r, defaultTestLimit)
+
+	var in struct {
+		Tags   []string `query:"tag"`
+		Filter struct {
+			Status string `query:"status"`
+			Owner  *struct {
+				ID int `query:"id"`
+			} `query:"owner"`
+		} `query:"filter"`
+		Sort map[string]string `query:"sort"`
+	}
+	if err := ctx.BindQuery(&in); err != nil {
+		t.Fatalf("BindQuery error: %v", err)
+	}
+
+	if len(in.Tags) != 2 || in.Tags[0] != "a" || in.Tags[1] != "b" {
+		t.Errorf("Unexpected tags: %v", in.Tags)
+	}
+	if in.Filter.Status != "open" || in.Filter.Owner == nil || in.Filter.Owner.ID != 7 {
+		t.Errorf("Unexpected filter: %+v", in.Filter)
+	}
+	if in.Sort["name"] != "asc" || in.Sort["due"] != "desc" {
+		t.Errorf("Unexpected sort: %v", in.Sort)
+	}
+
+	r = httptest.NewRequest("GET", "/todos?filter[owner][id]=x", nil)
+	ctx = New(w, r, defaultTestLimit)
+	var bindErr *BindError
+	if err := ctx.BindQuery(&in); !errors.As(err, &bindErr) || bindErr.Field != "filter[owner][id]" {
+		t.Errorf("Expected BindError for filter[owner][id], got %v", err)
+	}
+}
+
+func TestBindFormURLEncoded(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/signup?name=ignored", strings.NewReader("name=Ada&age=36&lang=go&lang=rust"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	ctx := New(w, r, defaultTestLimit)
+
+	var in struct {
+		Name  string   `form:"name"`
+		Age   int      `form:"age"`
+		Langs []string `form:"lang"`
+	}
+	if err := ctx.BindForm(&in); err != nil {
+		t.Fatalf("BindForm error: %v", err)
+	}
+
+	if in.Name != "Ada" || in.Age != 36 || len(in.Langs) != 2 {
+		t.Errorf("Unexpected result: %+v", in)
+	}
+}
+
+func TestBindFormMultipart(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "Report")
+	part, _ := mw.CreateFormFile("attachment", "report.txt")
+	part.Write([]byte("file contents"))
+	part, _ = mw.CreateFormFile("images", "a.png")
+	part.Write([]byte("a"))
+	part, _ = mw.CreateFormFile("images", "b.png")
+	part.Write([]byte("b"))
+	mw.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	ctx := New(w, r, defaultTestLimit)
+
+	var in struct {
+		Title      string                  `form:"title"`
+		Attachment *multipart.FileHeader   `form:"attachment"`
+		Images     []*multipart.FileHeader `form:"images"`
+		Missing    *multipart.FileHeader   `form:"missing"`
+	}
+	if err := ctx.BindForm(&in); err != nil {
+		t.Fatalf("BindForm error: %v", err)
+	}
+
+	if in.Title != "Report" {
+		t.Errorf("Expected title Report, got %q", in.Title)
+	}
+	if in.Attachment == nil || in.Attachment.Filename != "report.txt" {
+		t.Errorf("Expected attachment report.txt, got %+v", in.Attachment)
+	}
+	if len(in.Images) != 2 || in.Images[1].Filename != "b.png" {
+		t.Errorf("Expected 2 images, got %d", len(in.Images))
+	}
+	if in.Missing != nil {
+		t.Error("Missing file field should stay nil")
+	}
+}
+
+// fakeProto is a minimal protobuf-style message encoding a single string field.
+type fakeProto struct {
+	Name string
+}
+
+func (m *fakeProto) Marshal() ([]byte, error) {
+	return append([]byte{0x0a, byte(len(m.Name))}, m.Name...), nil
+}
+
+func (m *fakeProto) Unmarshal(data []byte) error {
+	if len(data) < 2 || data[0] != 0x0a || int(data[1]) != len(data)-2 {
+		return errors.New("malformed message")
+	}
+	m.Name = string(data[2:])
+	return nil
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/", nil), defaultTestLimit)
+	if err := ctx.Msgpack(200, map[string]interface{}{"name": "Ada", "age": 36}); err != nil {
+		t.Fatalf("Msgpack error: %v", err)
+	}
+	if w.Header().Get("Content-Type") != MIMEMsgpack {
+		t.Errorf("Unexpected Content-Type %q", w.Header().Get("Content-Type"))
+	}
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(w.Body.Bytes()))
+	r.Header.Set("Content-Type", MIMEMsgpack)
+	ctx = New(httptest.NewRecorder(), r, defaultTestLimit)
+
+	var in struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	if err := ctx.Bind(&in); err != nil {
+		t.Fatalf("Bind error: %v", err)
+	}
+	if in.Name != "Ada" || in.Age != 36 {
+		t.Errorf("Unexpected result: %+v", in)
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/", nil), defaultTestLimit)
+	if err := ctx.Protobuf(200, &fakeProto{Name: "Ada"}); err != nil {
+		t.Fatalf("Protobuf error: %v", err)
+	}
+	if w.Header().Get("Content-Type") != MIMEProtobuf {
+		t.Errorf("Unexpected Content-Type %q", w.Header().Get("Content-Type"))
+	}
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(w.Body.Bytes()))
+	r.Header.Set("Content-Type", MIMEProtobuf)
+	ctx = New(httptest.NewRecorder(), r, defaultTestLimit)
+
+	var in fakeProto
+	if err := ctx.Bind(&in); err != nil || in.Name != "Ada" {
+		t.Fatalf("Bind error: %v (%+v)", err, in)
+	}
+
+	r = httptest.NewRequest("POST", "/", bytes.NewReader(w.Body.Bytes()))
+	r.Header.Set("Content-Type", MIMEProtobuf)
+	ctx = New(httptest.NewRecorder(), r, defaultTestLimit)
+	var plain struct{ Name string }
+	if err := ctx.Bind(&plain); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Expected ErrUnsupportedMediaType for non-proto target, got %v", err)
+	}
+}
+
+func TestBindJSONWithOptions(t *testing.T) {
+	type item struct {
+		Name  string                 `json:"name"`
+		Qty   int                    `json:"qty"`
+		Extra map[string]interface{} `json:"extra"`
+	}
+
+	bind := func(body string, opts JSONOptions) (item, *BindError) {
+		ctx := New(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)), defaultTestLimit)
+		var in item
+		err := ctx.BindJSONWithOptions(&in, opts)
+		var bindErr *BindError
+		if err != nil && !errors.As(err, &bindErr) {
+			t.Fatalf("Expected *BindError, got %T", err)
+		}
+		return in, bindErr
+	}
+
+	_, err := bind(`{"name": "pen", "qty": "two"}`, JSONOptions{})
+	if err == nil || err.Field != "qty" || err.Expected != "integer" || err.Value != "string" || err.Offset == 0 {
+		t.Errorf("Unexpected type error %+v", err)
+	}
+	if err != nil && err.Error() != `invalid value for "qty": expected integer, got string` {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+
+	if _, err := bind(`{"name": "pen", "discount": 5}`, JSONOptions{}); err != nil {
+		t.Errorf("Unknown fields should be ignored by default, got %v", err)
+	}
+	_, err = bind(`{"name": "pen", "discount": 5}`, JSONOptions{DisallowUnknownFields: true})
+	if err == nil || err.Field != "discount" || !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Expected unknown field error, got %+v", err)
+	}
+
+	_, err = bind(`{"name": "pen",}`, JSONOptions{})
+	if err == nil || err.Offset == 0 || err.Field != "" {
+		t.Errorf("Expected syntax error with offset, got %+v", err)
+	}
+
+	in, err := bind(`{"extra": {"id": 9007199254740993}}`, JSONOptions{UseNumber: true})
+	if err != nil || in.Extra["id"] != json.Number("9007199254740993") {
+		t.Errorf("Expected json.Number preserving precision, got %v (%v)", in.Extra["id"], err)
+	}
+
+	_, err = bind(`{"extra": {"a": {"b": {"c": "[[[not nesting]]]"}}}}`, JSONOptions{MaxDepth: 3})
+	if err == nil || !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("Expected depth error, got %v", err)
+	}
+	if _, err := bind(`{"extra": {"a": {"b": "[[[[["}}}`, JSONOptions{MaxDepth: 3}); err != nil {
+		t.Errorf("Brackets in strings must not count toward depth, got %v", err)
+	}
+
+	if _, err := bind(`{"name": "pen"} {"name": "ink"}`, JSONOptions{}); err == nil {
+		t.Error("Expected error for trailing data")
+	}
+}
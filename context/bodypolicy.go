@@ -0,0 +1,67 @@
+package context
+
+import "errors"
+
+// BodyPolicyKey is the route metadata key under which Route.StreamBody and
+// Route.PrefetchBody store the route's *BodyPolicy.
+const BodyPolicyKey = "kese.body"
+
+// ErrBodyBufferingDisabled is returned by Body, BodyBytes and the JSON and
+// XML binders on routes whose BodyPolicy streams the body.
+var ErrBodyBufferingDisabled = errors.New("request body buffering is disabled for this route")
+
+// BodyPolicy controls how the request body of a route is buffered. It is
+// applied by SetRoute when the route is matched.
+type BodyPolicy struct {
+	// Stream disables buffering, for streaming proxies and large uploads: the
+	// body can only be read with BodyReader, without the MaxBodySize limit,
+	// and middleware that buffers bodies (such as Shadow) leaves it alone
+	Stream bool
+
+	// Prefetch reads the whole body before middleware and the handler run,
+	// so oversized or slow uploads fail before any work is done
+	Prefetch bool
+
+	// MaxSize replaces the app's MaxBodySize for the route (0 = unchanged)
+	MaxSize int64
+}
+
+// BodyPolicy returns the body policy of the matched route, or the zero
+// policy (buffer on demand) if it has none.
+func (c *Context) BodyPolicy() BodyPolicy {
+	if c.bodyPolicy == nil {
+		return BodyPolicy{}
+	}
+	return *c.bodyPolicy
+}
+
+// Prefetch reads the request body now if the route's BodyPolicy asks for
+// eager buffering, returning the read error (e.g. *http.MaxBytesError).
+// It does nothing otherwise.
+func (c *Context) Prefetch() error {
+	if c.bodyPolicy == nil || !c.bodyPolicy.Prefetch {
+		return nil
+	}
+	return c.bufferBody()
+}
+
+// BodyTooLarge reports whether the request declares a body larger than the
+// route allows, so it can be rejected before the body is sent.
+func (c *Context) BodyTooLarge() bool {
+	if c.bodyPolicy != nil && c.bodyPolicy.Stream {
+		return false
+	}
+	return c.Request.ContentLength > c.MaxBodySize
+}
+
+// applyBodyPolicy applies the body policy stored in route metadata.
+func (c *Context) applyBodyPolicy(meta map[string]interface{}) {
+	policy, ok := meta[BodyPolicyKey].(*BodyPolicy)
+	if !ok {
+		return
+	}
+	c.bodyPolicy = policy
+	if policy.MaxSize > 0 {
+		c.MaxBodySize = policy.MaxSize
+	}
+}
@@ -0,0 +1,126 @@
+package context
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+)
+
+// bufferBody reads the request body once, up to MaxBodySize. Bodies larger
+// than BodyMemoryLimit are written to a temporary file instead of the heap;
+// Finish removes it. c.Request.Body is then replaced by a reader over the
+// buffer, so form parsing and code reading the request directly still see
+// the body.
+func (c *Context) bufferBody() error {
+	if c.bodyStreamed {
+		return ErrBodyStreamed
+	}
+	if c.bodyRead {
+		return nil
+	}
+	if c.bodyPolicy != nil && c.bodyPolicy.Stream {
+		return ErrBodyBufferingDisabled
+	}
+
+	if err := c.readBody(); err != nil {
+		return err
+	}
+	body, _ := c.bufferedBody()
+	c.Request.Body = io.NopCloser(body)
+	return nil
+}
+
+// readBody reads the request body into memory or a temporary file.
+func (c *Context) readBody() error {
+	defer c.Request.Body.Close()
+	// Limit to MaxBodySize to prevent memory exhaustion
+	// http.MaxBytesReader returns an error if the body exceeds the limit
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, c.MaxBodySize)
+
+	if c.BodyMemoryLimit <= 0 || c.BodyMemoryLimit >= c.MaxBodySize {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		c.bodyBytes = data
+		c.bodyRead = true
+		return nil
+	}
+
+	// Bodies declared larger than the in-memory limit go straight to disk;
+	// the others are read into memory until they prove to be larger
+	var head []byte
+	if c.Request.ContentLength < 0 || c.Request.ContentLength <= c.BodyMemoryLimit {
+		data, err := io.ReadAll(io.LimitReader(body, c.BodyMemoryLimit+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) <= c.BodyMemoryLimit {
+			c.bodyBytes = data
+			c.bodyRead = true
+			return nil
+		}
+		head = data
+	}
+
+	return c.spillBody(head, body)
+}
+
+// spillBody writes head followed by the rest of body to a temporary file.
+func (c *Context) spillBody(head []byte, body io.Reader) error {
+	file, err := os.CreateTemp("", "kese-body-*")
+	if err != nil {
+		return err
+	}
+
+	size, err := io.Copy(file, io.MultiReader(bytes.NewReader(head), body))
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	c.bodyFile = file
+	c.bodySize = size
+	c.bodyRead = true
+	return nil
+}
+
+// bufferedBody returns a reader over the buffered request body, reading it
+// from the temporary file if it was spilled to disk.
+func (c *Context) bufferedBody() (io.Reader, error) {
+	if err := c.bufferBody(); err != nil {
+		return nil, err
+	}
+	if c.onDisk() {
+		return io.NewSectionReader(c.bodyFile, 0, c.bodySize), nil
+	}
+	return bytes.NewReader(c.bodyBytes), nil
+}
+
+// onDisk reports whether the buffered body is only in the temporary file.
+func (c *Context) onDisk() bool {
+	return c.bodyFile != nil && c.bodyBytes == nil
+}
+
+// BodySpilled reports whether the request body was larger than
+// BodyMemoryLimit and buffered in a temporary file, and its size. Middleware
+// such as Metrics can use it to track temporary disk usage, like
+// MultipartTempFiles.
+func (c *Context) BodySpilled() (spilled bool, size int64) {
+	if c.bodyFile == nil {
+		return false, 0
+	}
+	return true, c.bodySize
+}
+
+// removeBodyFile deletes the temporary file of a spilled request body.
+func (c *Context) removeBodyFile() {
+	if c.bodyFile == nil {
+		return
+	}
+	c.bodyFile.Close()
+	os.Remove(c.bodyFile.Name())
+	c.bodyFile = nil
+}
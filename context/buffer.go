@@ -0,0 +1,65 @@
+package context
+
+import "github.com/JedizLaPulga/kese/rw"
+
+// BufferedResponse holds a response captured by BufferResponse so middleware
+// can inspect or rewrite it before it is sent. Headers are shared with the
+// real response writer; the status and body (resp.Body) are held back until Commit.
+type BufferedResponse struct {
+	*rw.Buffer
+
+	ctx *Context
+}
+
+// Commit sends the (possibly rewritten) response to the client, updating
+// Content-Length if the handler had set one. It does nothing if the handler
+// produced no response, and returns ErrClientClosed if the client is gone.
+func (r *BufferedResponse) Commit() error {
+	if !r.Written() {
+		return nil
+	}
+	if err := r.ctx.clientGone(); err != nil {
+		return err
+	}
+
+	r.ctx.statusCode = r.Status()
+	r.ctx.SetWritten()
+	return r.Buffer.Commit()
+}
+
+// BufferResponse runs next with the response captured in memory instead of
+// being sent, so middleware can transform the body (inject snippets, strip
+// fields, rewrite URLs) before calling Commit. Responses are fully buffered,
+// so streaming handlers lose incremental delivery.
+//
+// If the handler produced no response (for example it returned an error),
+// nothing is committed and the framework's error handling applies as usual.
+//
+// Example:
+//
+//	resp, err := c.BufferResponse(next)
+//	if err != nil {
+//	    return err
+//	}
+//	html := strings.Replace(resp.Body.String(), "</body>", snippet+"</body>", 1)
+//	resp.Body.Reset()
+//	resp.Body.WriteString(html)
+//	return resp.Commit()
+func (c *Context) BufferResponse(next func(*Context) error) (*BufferedResponse, error) {
+	original := c.Writer
+	written, statusCode := c.written.Load(), c.statusCode
+
+	resp := &BufferedResponse{
+		Buffer: rw.NewBuffer(original),
+		ctx:    c,
+	}
+
+	c.Writer = resp
+	err := next(c)
+	c.Writer = original
+
+	// Nothing has been sent yet; the response is committed explicitly
+	c.written.Store(written)
+	c.statusCode = statusCode
+	return resp, err
+}
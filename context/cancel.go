@@ -0,0 +1,141 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Context implements context.Context by delegating to the request context,
+// so it can be passed directly to database drivers and HTTP clients:
+//
+//	rows, err := db.QueryContext(c, "SELECT ...")
+//
+// Only pass c to calls that finish before the handler returns. Contexts are
+// pooled and reused by later requests once the handler returns, so a
+// goroutine or queued job still holding c would see another request's
+// values and cancellation. Hand such work c.Request.Context() instead, or
+// context.WithoutCancel(c.Request.Context()) if it must outlive the request:
+//
+//	ctx := context.WithoutCancel(c.Request.Context())
+//	go audit.Record(ctx, event)
+var _ context.Context = (*Context)(nil)
+
+// ErrClientClosed is returned by response helpers when the client disconnected
+// (or the request deadline passed) before the response could be written.
+// The framework does not treat it as a server error.
+var ErrClientClosed = errors.New("client closed request")
+
+// Deadline returns the deadline of the request context, if any.
+func (c *Context) Deadline() (time.Time, bool) {
+	return c.parent().Deadline()
+}
+
+// Done returns a channel that is closed when the client disconnects,
+// the server shuts down, or the request deadline passes.
+func (c *Context) Done() <-chan struct{} {
+	return c.parent().Done()
+}
+
+// Err returns the reason the request context was canceled, or nil.
+func (c *Context) Err() error {
+	return c.parent().Err()
+}
+
+// Value returns the request context value for key. Values stored with Set are
+// also visible under their string key or ValueKey, so values set by
+// middleware reach code that only receives a context.Context.
+func (c *Context) Value(key interface{}) interface{} {
+	var name string
+	switch key := key.(type) {
+	case string:
+		name = key
+	case ValueKey:
+		name = string(key)
+	default:
+		return c.parent().Value(key)
+	}
+
+	if value, exists := c.values[name]; exists {
+		return value
+	}
+	return c.parent().Value(key)
+}
+
+// SetContext replaces the request context. The request is updated too,
+// so c.Request.Context() and later middleware observe the new context.
+func (c *Context) SetContext(ctx context.Context) {
+	c.ctx = ctx
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// WithValue attaches a value to the request context.
+//
+// Example:
+//
+//	c.WithValue(tenantKey{}, tenant)
+//	repo.Find(c.Context(), id) // repo reads ctx.Value(tenantKey{})
+func (c *Context) WithValue(key, value interface{}) {
+	c.SetContext(context.WithValue(c.parent(), key, value))
+}
+
+// WithTimeout shortens the request deadline to at most d from now.
+// Downstream calls using c.Context() are canceled when it passes, and response
+// helpers then return ErrClientClosed. The returned cancel func releases
+// resources and should be deferred.
+//
+// Example:
+//
+//	cancel := c.WithTimeout(2 * time.Second)
+//	defer cancel()
+//	user, err := db.GetUser(c.Context(), id)
+func (c *Context) WithTimeout(d time.Duration) context.CancelFunc {
+	ctx, cancel := context.WithTimeout(c.parent(), d)
+	c.SetContext(ctx)
+	return cancel
+}
+
+// parent returns the request context, or context.Background() for a
+// released Context, so a Context used after its request ended reports no
+// deadline and no cancellation instead of panicking.
+func (c *Context) parent() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// clientGone returns ErrClientClosed if the request context is already done.
+func (c *Context) clientGone() error {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return ErrClientClosed
+	}
+	return nil
+}
+
+// bodyWriter returns a writer for the response body that stops writing once
+// the request context is done.
+func (c *Context) bodyWriter() io.Writer {
+	return &ctxWriter{ctx: c.ctx, w: c.Writer}
+}
+
+// ctxWriter is an io.Writer that aborts when its context is done,
+// so large responses stop encoding after the client goes away.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw *ctxWriter) Write(p []byte) (int, error) {
+	if cw.ctx != nil && cw.ctx.Err() != nil {
+		return 0, ErrClientClosed
+	}
+
+	n, err := cw.w.Write(p)
+	if err != nil && cw.ctx != nil && cw.ctx.Err() != nil {
+		// Write failures after a disconnect are expected, not server errors
+		return n, ErrClientClosed
+	}
+	return n, err
+}
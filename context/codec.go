@@ -0,0 +1,179 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// JSONMarshaler encodes v as JSON. json.Marshal and the Marshal functions of
+// drop-in libraries such as jsoniter and sonic satisfy it.
+type JSONMarshaler func(v interface{}) ([]byte, error)
+
+// JSONUnmarshaler decodes JSON data into v. json.Unmarshal and the Unmarshal
+// functions of drop-in libraries such as jsoniter and sonic satisfy it.
+type JSONUnmarshaler func(data []byte, v interface{}) error
+
+// jsonCodecFuncs is a replacement JSON codec set with SetJSONCodec.
+type jsonCodecFuncs struct {
+	marshal   JSONMarshaler
+	unmarshal JSONUnmarshaler
+}
+
+// maxJSONBuffer is the size up to which JSON responses are encoded in memory
+// before anything is sent. Larger responses are streamed, so an encoding error
+// past this point can only truncate the body.
+const maxJSONBuffer = 4 << 20
+
+// maxPooledJSONBuffer is the largest buffer returned to jsonBufferPool, so a
+// few huge responses don't pin memory.
+const maxPooledJSONBuffer = 64 << 10
+
+// jsonBufferPool recycles the buffers JSON responses are encoded into.
+var jsonBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// jsonCodec is the codec in use, nil for encoding/json.
+var jsonCodec atomic.Pointer[jsonCodecFuncs]
+
+// SetJSONCodec replaces encoding/json for every Context JSON method (JSON,
+// JSONPretty, NDJSON, Body, BindJSON) and for middleware that use JSONMarshal
+// and JSONUnmarshal. Passing nil for both restores encoding/json. The codec is
+// process-wide; set it once at startup. BindJSONWithOptions with non-zero
+// options and JSON Patch always use encoding/json.
+//
+// Example:
+//
+//	context.SetJSONCodec(sonic.Marshal, sonic.Unmarshal)
+func SetJSONCodec(marshal JSONMarshaler, unmarshal JSONUnmarshaler) {
+	if marshal == nil && unmarshal == nil {
+		jsonCodec.Store(nil)
+		return
+	}
+	if marshal == nil {
+		marshal = json.Marshal
+	}
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	jsonCodec.Store(&jsonCodecFuncs{marshal: marshal, unmarshal: unmarshal})
+}
+
+// JSONMarshal encodes v with the configured JSON codec.
+func JSONMarshal(v interface{}) ([]byte, error) {
+	if codec := jsonCodec.Load(); codec != nil {
+		return codec.marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+// JSONUnmarshal decodes data into v with the configured JSON codec.
+func JSONUnmarshal(data []byte, v interface{}) error {
+	if codec := jsonCodec.Load(); codec != nil {
+		return codec.unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// encodeJSON writes v to w as JSON followed by a newline, like json.Encoder,
+// indenting it with indent if it is not empty.
+func encodeJSON(w io.Writer, v interface{}, indent string) error {
+	codec := jsonCodec.Load()
+	if codec == nil {
+		encoder := json.NewEncoder(w)
+		if indent != "" {
+			encoder.SetIndent("", indent)
+		}
+		return encoder.Encode(v)
+	}
+
+	data, err := codec.marshal(v)
+	if err != nil {
+		return err
+	}
+	if indent != "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", indent); err != nil {
+			return err
+		}
+		data = indented.Bytes()
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// writeJSON encodes data and sends it with status. The response is encoded
+// into a pooled buffer and sent in a single write with its Content-Length, so
+// encoding errors are returned before the status and headers are sent.
+// Responses larger than maxJSONBuffer are streamed without a length.
+func (c *Context) writeJSON(status int, data interface{}, indent string) error {
+	buf := jsonBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledJSONBuffer {
+			jsonBufferPool.Put(buf)
+		}
+	}()
+
+	w := &jsonResponseWriter{c: c, status: status, buf: buf}
+	if err := encodeJSON(w, data, indent); err != nil {
+		return err
+	}
+	if !w.sent {
+		c.SetHeader("Content-Length", strconv.Itoa(buf.Len()))
+		return w.send(nil)
+	}
+	return nil
+}
+
+// jsonResponseWriter buffers a JSON response until it is complete or larger
+// than maxJSONBuffer, then sends the status, headers and body.
+type jsonResponseWriter struct {
+	c      *Context
+	status int
+	buf    *bytes.Buffer
+	body   io.Writer
+	sent   bool
+}
+
+func (w *jsonResponseWriter) Write(p []byte) (int, error) {
+	if w.sent {
+		return w.body.Write(p)
+	}
+	if w.buf.Len()+len(p) <= maxJSONBuffer {
+		return w.buf.Write(p)
+	}
+	if err := w.send(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// send writes the status and headers, then the buffered body followed by p.
+func (w *jsonResponseWriter) send(p []byte) error {
+	c := w.c
+	c.SetHeader("Content-Type", "application/json")
+	c.statusCode = w.status
+	c.Writer.WriteHeader(w.status)
+	c.SetWritten()
+
+	w.sent = true
+	w.body = c.bodyWriter()
+	if w.buf.Len() > 0 {
+		if _, err := w.body.Write(w.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if len(p) > 0 {
+		if _, err := w.body.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,12 +3,16 @@ package context
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 
+	"github.com/JedizLaPulga/kese/analytics"
 	"github.com/JedizLaPulga/kese/router"
 	"github.com/JedizLaPulga/kese/sanitize"
 )
@@ -32,8 +36,9 @@ type Context struct {
 	// statusCode tracks the HTTP status code that was set
 	statusCode int
 
-	// written tracks whether the response has been written
-	written bool
+	// written tracks whether the response has been written.
+	// It is atomic so goroutines (e.g. timeouts) can check it safely.
+	written atomic.Bool
 
 	// bodyBytes stores the buffered request body for multiple reads
 	bodyBytes []byte
@@ -41,27 +46,82 @@ type Context struct {
 	// bodyRead tracks whether the body has been read and buffered
 	bodyRead bool
 
-	// values stores arbitrary key-value pairs for passing data between middleware and handlers
+	// bodyFile holds a buffered body larger than BodyMemoryLimit, in which
+	// case bodyBytes is nil until BodyBytes loads it
+	bodyFile *os.File
+
+	// bodySize is the size of bodyFile
+	bodySize int64
+
+	// bodyStreamed tracks whether the body was handed out by BodyReader
+	bodyStreamed bool
+
+	// values stores arbitrary key-value pairs for passing data between middleware and handlers.
+	// It is allocated on first Set.
 	values map[string]interface{}
 
 	// ctx is the request context for cancellation and deadline handling
 	ctx context.Context
 
+	// routePath is the pattern of the matched route (e.g. "/users/:id")
+	routePath string
+
+	// routeMeta is the metadata attached to the matched route
+	routeMeta map[string]interface{}
+
+	// timings are the metrics recorded with ServerTiming
+	timings []Timing
+
+	// finish are the callbacks registered with OnFinish
+	finish []func()
+
+	// requestID is the ID set with SetRequestID
+	requestID string
+
+	// userAgent is the parsed User-Agent header, see UserAgent
+	userAgent *UserAgent
+
+	// propagate lists the keys mirrored into the request context, see Propagate
+	propagate []string
+
+	// bodyPolicy is the matched route's body policy, nil for the default
+	bodyPolicy *BodyPolicy
+
+	// response records the real status and size of the response, so writes
+	// made directly to c.Writer (e.g. by http.ServeFile) are detected. It is set
+	// for contexts created by Acquire and nil for contexts created by New.
+	response *ResponseWriter
+
+	// writer is the pooled storage for response
+	writer ResponseWriter
+
 	// MaxBodySize limits the size of the request body.
 	MaxBodySize int64
+
+	// BodyMemoryLimit is the largest request body buffered in memory. Larger
+	// bodies, up to MaxBodySize, are buffered in a temporary file that Finish
+	// removes. 0 buffers every body in memory.
+	BodyMemoryLimit int64
+
+	// Analytics receives the events recorded with Track; nil discards them.
+	Analytics *analytics.Tracker
+
+	// FlashSecret signs the flash cookie with HMAC-SHA256; flash cookies
+	// without a valid signature are ignored. Nil leaves the cookie unsigned.
+	FlashSecret []byte
 }
 
 // New creates a new Context instance.
+// The framework uses the pooled Acquire instead; New is for tests and
+// standalone use where the Context may outlive the request.
 func New(w http.ResponseWriter, r *http.Request, maxBodySize int64) *Context {
 	return &Context{
 		Request:     r,
 		Writer:      w,
 		params:      nil,
 		statusCode:  http.StatusOK,
-		written:     false,
 		bodyBytes:   nil,
 		bodyRead:    false,
-		values:      make(map[string]interface{}),
 		ctx:         r.Context(),
 		MaxBodySize: maxBodySize,
 	}
@@ -73,6 +133,34 @@ func (c *Context) SetParams(params router.Params) {
 	c.params = params
 }
 
+// Params returns the route parameters of the request. The slice is
+// allocated for this request and never reused, so it may be kept after
+// the request completes.
+func (c *Context) Params() router.Params {
+	return c.params
+}
+
+// SetRoute sets the pattern and metadata of the matched route and applies
+// the route's BodyPolicy, if any.
+// This is called by the framework before middleware and handlers run.
+func (c *Context) SetRoute(path string, meta map[string]interface{}) {
+	c.routePath = path
+	c.routeMeta = meta
+	c.applyBodyPolicy(meta)
+}
+
+// RoutePath returns the pattern of the matched route (e.g. "/users/:id").
+// Unlike Path, it does not contain parameter values, which makes it suitable
+// as a low-cardinality label for logs and metrics.
+func (c *Context) RoutePath() string {
+	return c.routePath
+}
+
+// RouteMeta returns metadata attached to the matched route, or nil if not present.
+func (c *Context) RouteMeta(key string) interface{} {
+	return c.routeMeta[key]
+}
+
 // Param returns the value of a URL path parameter.
 // For example, for the route "/users/:id", Param("id") returns the ID value.
 func (c *Context) Param(key string) string {
@@ -96,6 +184,14 @@ func (c *Context) QueryDefault(key, defaultValue string) string {
 	return values.Get(key)
 }
 
+// ExpectsContinue reports whether the client sent "Expect: 100-continue" and is
+// waiting for approval before transmitting the body. The interim 100 response is
+// sent automatically on the first body read, so returning a response before reading
+// the body (e.g. 401 from auth middleware) spares the client from uploading it.
+func (c *Context) ExpectsContinue() bool {
+	return strings.EqualFold(c.Request.Header.Get("Expect"), "100-continue")
+}
+
 // Header returns the value of a request header.
 func (c *Context) Header(key string) string {
 	return c.Request.Header.Get(key)
@@ -118,100 +214,146 @@ func (c *Context) Status(code int) {
 // Limited to MaxBodySize to prevent memory exhaustion attacks.
 // The body is buffered on first read, so this method can be called multiple times.
 func (c *Context) Body(v interface{}) error {
-	// Read and buffer the body if not already done
-	if !c.bodyRead {
-		defer c.Request.Body.Close()
-		// Limit to MaxBodySize to prevent memory exhaustion
-		// http.MaxBytesReader returns an error if the body exceeds the limit
-		maxBytesReader := http.MaxBytesReader(c.Writer, c.Request.Body, c.MaxBodySize)
-		data, err := io.ReadAll(maxBytesReader)
-		if err != nil {
+	if err := c.bufferBody(); err != nil {
+		return err
+	}
+
+	// Decode bodies spilled to disk from the file rather than loading them
+	if c.onDisk() && jsonCodec.Load() == nil {
+		body, _ := c.bufferedBody()
+		decoder := json.NewDecoder(body)
+		if err := decoder.Decode(v); err != nil {
 			return err
 		}
-		c.bodyBytes = data
-		c.bodyRead = true
+		if decoder.More() {
+			return errors.New("unexpected data after JSON value")
+		}
+		return nil
+	}
+
+	data, err := c.BodyBytes()
+	if err != nil {
+		return err
 	}
 
 	// Parse JSON from buffered bytes
-	return json.Unmarshal(c.bodyBytes, v)
+	return JSONUnmarshal(data, v)
 }
 
 // BodyBytes reads the raw request body as bytes.
 // Limited to MaxBodySize to prevent memory exhaustion attacks.
 // The body is buffered on first read, so this method can be called multiple times.
+// A body spilled to disk (see BodyMemoryLimit) is loaded into memory by the
+// first call; Body, the JSON and XML binders and BodyReader read it from the
+// file instead.
 func (c *Context) BodyBytes() ([]byte, error) {
-	// Read and buffer the body if not already done
-	if !c.bodyRead {
-		defer c.Request.Body.Close()
-		// Limit to MaxBodySize to prevent memory exhaustion
-		// http.MaxBytesReader returns an error if the body exceeds the limit
-		maxBytesReader := http.MaxBytesReader(c.Writer, c.Request.Body, c.MaxBodySize)
-		data, err := io.ReadAll(maxBytesReader)
-		if err != nil {
+	if err := c.bufferBody(); err != nil {
+		return nil, err
+	}
+
+	if c.onDisk() {
+		data := make([]byte, c.bodySize)
+		if _, err := c.bodyFile.ReadAt(data, 0); err != nil {
 			return nil, err
 		}
 		c.bodyBytes = data
-		c.bodyRead = true
 	}
 
 	return c.bodyBytes, nil
 }
 
+// ErrBodyStreamed is returned by Body and BodyBytes after the body was consumed with BodyReader.
+var ErrBodyStreamed = errors.New("request body already streamed")
+
+// BodyReader returns the raw request body as a stream, without buffering it
+// and without the MaxBodySize limit, for endpoints that proxy or store
+// payloads too large to hold in memory. Apply your own limit if needed, e.g.
+// with http.MaxBytesReader. Once the body is streamed, Body and BodyBytes
+// return ErrBodyStreamed; if it was already buffered, the buffer is returned.
+//
+// Example:
+//
+//	f, err := os.Create(path)
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	if _, err := io.Copy(f, c.BodyReader()); err != nil {
+//	    return err
+//	}
+func (c *Context) BodyReader() io.ReadCloser {
+	if c.bodyRead {
+		body, _ := c.bufferedBody()
+		return io.NopCloser(body)
+	}
+	c.bodyStreamed = true
+	return c.Request.Body
+}
+
 // JSON sends a JSON response with the specified status code.
 // The data will be marshaled to JSON automatically.
+// Returns ErrClientClosed without writing if the client has already disconnected.
+// If data cannot be encoded (e.g. it contains a channel or func), nothing is
+// written and the encoding error is returned, so the error handler can send a
+// clean 500 instead of a truncated body.
 func (c *Context) JSON(status int, data interface{}) error {
-	c.SetHeader("Content-Type", "application/json")
-	c.statusCode = status
-	c.Writer.WriteHeader(c.statusCode)
-	c.written = true
-
-	encoder := json.NewEncoder(c.Writer)
-	return encoder.Encode(data)
+	if err := c.clientGone(); err != nil {
+		return err
+	}
+	return c.writeJSON(status, data, "")
 }
 
 // JSONPretty sends a pretty-printed JSON response.
 // Useful for debugging or human-readable APIs.
 func (c *Context) JSONPretty(status int, data interface{}) error {
-	c.SetHeader("Content-Type", "application/json")
-	c.statusCode = status
-	c.Writer.WriteHeader(c.statusCode)
-	c.written = true
-
-	encoder := json.NewEncoder(c.Writer)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	if err := c.clientGone(); err != nil {
+		return err
+	}
+	return c.writeJSON(status, data, "  ")
 }
 
 // String sends a plain text response.
 func (c *Context) String(status int, text string) error {
+	if err := c.clientGone(); err != nil {
+		return err
+	}
+
 	c.SetHeader("Content-Type", "text/plain; charset=utf-8")
 	c.statusCode = status
 	c.Writer.WriteHeader(c.statusCode)
-	c.written = true
+	c.SetWritten()
 
-	_, err := c.Writer.Write([]byte(text))
+	_, err := c.bodyWriter().Write([]byte(text))
 	return err
 }
 
 // HTML sends an HTML response.
 func (c *Context) HTML(status int, html string) error {
+	if err := c.clientGone(); err != nil {
+		return err
+	}
+
 	c.SetHeader("Content-Type", "text/html; charset=utf-8")
 	c.statusCode = status
 	c.Writer.WriteHeader(c.statusCode)
-	c.written = true
+	c.SetWritten()
 
-	_, err := c.Writer.Write([]byte(html))
+	_, err := c.bodyWriter().Write([]byte(html))
 	return err
 }
 
 // Bytes sends a raw byte response with the specified content type.
 func (c *Context) Bytes(status int, contentType string, data []byte) error {
+	if err := c.clientGone(); err != nil {
+		return err
+	}
+
 	c.SetHeader("Content-Type", contentType)
 	c.statusCode = status
 	c.Writer.WriteHeader(c.statusCode)
-	c.written = true
+	c.SetWritten()
 
-	_, err := c.Writer.Write(data)
+	_, err := c.bodyWriter().Write(data)
 	return err
 }
 
@@ -219,7 +361,7 @@ func (c *Context) Bytes(status int, contentType string, data []byte) error {
 func (c *Context) NoContent() error {
 	c.statusCode = http.StatusNoContent
 	c.Writer.WriteHeader(http.StatusNoContent)
-	c.written = true
+	c.SetWritten()
 	return nil
 }
 
@@ -229,7 +371,7 @@ func (c *Context) Redirect(status int, url string) error {
 		return fmt.Errorf("invalid redirect status code: %d (must be 3xx)", status)
 	}
 	http.Redirect(c.Writer, c.Request, url, status)
-	c.written = true
+	c.SetWritten()
 	return nil
 }
 
@@ -255,18 +397,35 @@ func (c *Context) Path() string {
 
 // IsWritten returns true if the response has been written.
 func (c *Context) IsWritten() bool {
-	return c.written
+	return c.written.Load() || (c.response != nil && c.response.Written())
 }
 
-// StatusCode returns the HTTP status code that was set.
+// StatusCode returns the HTTP status code of the response.
+// For requests served by the framework it is the status actually sent,
+// even if the handler wrote to c.Writer directly.
 func (c *Context) StatusCode() int {
+	if c.response != nil && c.response.Written() {
+		return c.response.Status()
+	}
 	return c.statusCode
 }
 
-// SetWritten marks the response as written.
-// This is used internally by static file serving and other methods that write directly.
+// ResponseSize returns the number of response body bytes written so far.
+// It is 0 for contexts not created by the framework.
+func (c *Context) ResponseSize() int64 {
+	if c.response == nil {
+		return 0
+	}
+	return c.response.Size()
+}
+
+// SetWritten marks the response as written. For requests served by the
+// framework, writes through c.Writer (including http.ServeFile and similar
+// helpers) are detected automatically, so it is only needed when the response
+// is produced another way, such as on a hijacked connection, or for contexts
+// created with New. It is safe for concurrent use.
 func (c *Context) SetWritten() {
-	c.written = true
+	c.written.Store(true)
 }
 
 // Context returns the request context for cancellation and deadline handling.
@@ -280,7 +439,7 @@ func (c *Context) SetWritten() {
 //	    return c.JSON(200, result)
 //	}
 func (c *Context) Context() context.Context {
-	return c.ctx
+	return c.parent()
 }
 
 // CSRFToken returns the CSRF token from context.
@@ -292,11 +451,26 @@ func (c *Context) CSRFToken() string {
 	return ""
 }
 
+// CSPNonce returns the Content-Security-Policy nonce generated for this
+// request by middleware.SecureHeaders with CSPNonce enabled, for the nonce
+// attribute of inline scripts and styles.
+func (c *Context) CSPNonce() string {
+	nonce, _ := c.Get("csp_nonce").(string)
+	return nonce
+}
+
 // Set stores a key-value pair in the context.
 // This is useful for passing data between middleware and handlers.
+// Keys selected with Propagate are mirrored into the request context.
 // Example: c.Set("user", authenticatedUser)
 func (c *Context) Set(key string, value interface{}) {
+	if c.values == nil {
+		c.values = make(map[string]interface{})
+	}
 	c.values[key] = value
+	if c.propagates(key) {
+		c.WithValue(ValueKey(key), value)
+	}
 }
 
 // Get retrieves a value from the context by key.
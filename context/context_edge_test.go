@@ -2,8 +2,14 @@ package context
 
 import (
 	"bytes"
+	stdcontext "context"
+	"errors"
+	"io"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 const defaultTestLimit = 10 << 20
@@ -169,3 +175,216 @@ func TestMaxBodySize(t *testing.T) {
 		t.Errorf("Read more bytes than limit: %d > %d", len(data), limit)
 	}
 }
+
+// TestBodyReader verifies BodyReader streams past MaxBodySize and blocks later buffering
+func TestBodyReader(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 1024)
+	ctx := New(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewReader(payload)), 16)
+
+	data, err := io.ReadAll(ctx.BodyReader())
+	if err != nil || len(data) != len(payload) {
+		t.Fatalf("Expected full body streamed past the limit, got %d bytes (%v)", len(data), err)
+	}
+	if _, err := ctx.BodyBytes(); !errors.Is(err, ErrBodyStreamed) {
+		t.Errorf("Expected ErrBodyStreamed, got %v", err)
+	}
+
+	// A body that was already buffered is returned from the buffer
+	ctx = New(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewBufferString("buffered")), defaultTestLimit)
+	ctx.BodyBytes()
+	data, _ = io.ReadAll(ctx.BodyReader())
+	if string(data) != "buffered" {
+		t.Errorf("Expected buffered body, got %q", data)
+	}
+}
+
+// TestBodySpill verifies bodies above BodyMemoryLimit are buffered in a temp file removed by Finish
+func TestBodySpill(t *testing.T) {
+	payload := `{"name":"` + strings.Repeat("x", 1024) + `"}`
+	newCtx := func(body io.Reader) *Context {
+		r := httptest.NewRequest("POST", "/", body)
+		r.ContentLength = -1 // chunked, so the size is only found by reading
+		ctx := New(httptest.NewRecorder(), r, defaultTestLimit)
+		ctx.BodyMemoryLimit = 64
+		return ctx
+	}
+
+	ctx := newCtx(strings.NewReader(payload))
+	var v struct{ Name string }
+	if err := ctx.Body(&v); err != nil || len(v.Name) != 1024 {
+		t.Fatalf("Expected body decoded from disk, got %d bytes (%v)", len(v.Name), err)
+	}
+	spilled, size := ctx.BodySpilled()
+	if !spilled || size != int64(len(payload)) {
+		t.Fatalf("Expected %d bytes spilled, got %v %d", len(payload), spilled, size)
+	}
+	name := ctx.bodyFile.Name()
+
+	// The binders and BodyReader read the same file again
+	v.Name = ""
+	if err := ctx.BindJSONWithOptions(&v, JSONOptions{DisallowUnknownFields: true, MaxDepth: 2}); err != nil || len(v.Name) != 1024 {
+		t.Errorf("Expected strict bind from disk, got %v", err)
+	}
+	if data, _ := io.ReadAll(ctx.BodyReader()); string(data) != payload {
+		t.Errorf("Expected BodyReader to replay the spilled body, got %d bytes", len(data))
+	}
+	if data, err := ctx.BodyBytes(); err != nil || string(data) != payload {
+		t.Errorf("Expected BodyBytes to load the spilled body, got %d bytes (%v)", len(data), err)
+	}
+
+	ctx.Finish()
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("Expected temp file removed on Finish, got %v", err)
+	}
+
+	// Small bodies stay in memory
+	ctx = newCtx(strings.NewReader(`{"name":"a"}`))
+	if err := ctx.Body(&v); err != nil || v.Name != "a" {
+		t.Fatalf("Expected small body, got %v", err)
+	}
+	if spilled, _ := ctx.BodySpilled(); spilled {
+		t.Error("Expected small body buffered in memory")
+	}
+
+	// MaxBodySize still applies to spilled bodies
+	ctx = newCtx(strings.NewReader(payload))
+	ctx.MaxBodySize = 512
+	if _, err := ctx.BodyBytes(); err == nil {
+		t.Error("Expected body larger than MaxBodySize to be rejected")
+	}
+	if spilled, _ := ctx.BodySpilled(); spilled {
+		t.Error("Expected no temp file for a rejected body")
+	}
+}
+
+// TestStandardContext verifies Context implements context.Context and propagates values and deadlines
+func TestStandardContext(t *testing.T) {
+	type key struct{}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	ctx := New(w, r, defaultTestLimit)
+
+	var std stdcontext.Context = ctx
+	if _, ok := std.Deadline(); ok {
+		t.Error("Expected no deadline by default")
+	}
+
+	ctx.Set("user", "alice")
+	if std.Value("user") != "alice" {
+		t.Error("Expected Set values to be visible via Value")
+	}
+
+	ctx.WithValue(key{}, "tenant-1")
+	if ctx.Context().Value(key{}) != "tenant-1" || ctx.Request.Context().Value(key{}) != "tenant-1" {
+		t.Error("Expected WithValue to update the request context")
+	}
+
+	cancel := ctx.WithTimeout(time.Millisecond)
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("Expected deadline after WithTimeout")
+	}
+
+	<-ctx.Done()
+	if ctx.Err() != stdcontext.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", ctx.Err())
+	}
+	if err := ctx.String(200, "late"); err != ErrClientClosed {
+		t.Errorf("Expected ErrClientClosed after deadline, got %v", err)
+	}
+}
+
+// TestAcquireRelease verifies pooled contexts are reset between requests
+func TestAcquireRelease(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/first", bytes.NewBufferString(`{"a":1}`))
+
+	ctx := Acquire(w, r, defaultTestLimit)
+	ctx.Set("user", "alice")
+	ctx.BodyBytes()
+	ctx.String(201, "created")
+	Release(ctx)
+
+	// A Context leaked past its request must not panic as a context.Context
+	if ctx.Err() != nil || ctx.Value("user") != nil || ctx.Context() == nil {
+		t.Error("Expected a released context to behave like context.Background()")
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline on a released context")
+	}
+	select {
+	case <-ctx.Done():
+		t.Error("Expected a released context not to be done")
+	default:
+	}
+
+	r = httptest.NewRequest("GET", "/second", nil)
+	ctx = Acquire(httptest.NewRecorder(), r, defaultTestLimit)
+	defer Release(ctx)
+
+	if ctx.Get("user") != nil {
+		t.Error("Expected values to be cleared")
+	}
+	if ctx.IsWritten() || ctx.StatusCode() != 200 {
+		t.Errorf("Expected fresh response state, got written=%v status=%d", ctx.IsWritten(), ctx.StatusCode())
+	}
+	if ctx.Path() != "/second" || ctx.Context() != r.Context() {
+		t.Error("Expected context bound to the new request")
+	}
+	if body, _ := ctx.BodyBytes(); len(body) != 0 {
+		t.Errorf("Expected empty body, got %q", body)
+	}
+}
+
+// TestBufferResponse verifies captured responses are held back until Commit
+func TestBufferResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := Acquire(w, httptest.NewRequest("GET", "/", nil), defaultTestLimit)
+	defer Release(ctx)
+
+	resp, err := ctx.BufferResponse(func(c *Context) error {
+		c.SetHeader("Content-Length", "5")
+		return c.String(201, "hello")
+	})
+	if err != nil {
+		t.Fatalf("BufferResponse error: %v", err)
+	}
+	if ctx.IsWritten() || w.Body.Len() != 0 {
+		t.Fatal("Expected nothing to be sent before Commit")
+	}
+	if resp.Status() != 201 || resp.Body.String() != "hello" {
+		t.Errorf("Unexpected capture: %d %q", resp.Status(), resp.Body.String())
+	}
+
+	resp.Body.WriteString(", world")
+	if err := resp.Commit(); err != nil {
+		t.Fatalf("Commit error: %v", err)
+	}
+	if w.Code != 201 || w.Body.String() != "hello, world" || w.Header().Get("Content-Length") != "12" {
+		t.Errorf("Unexpected response: %d %q (Content-Length %s)", w.Code, w.Body.String(), w.Header().Get("Content-Length"))
+	}
+	if !ctx.IsWritten() || ctx.StatusCode() != 201 {
+		t.Errorf("Expected committed response to be recorded, got status %d", ctx.StatusCode())
+	}
+}
+
+func TestPropagate(t *testing.T) {
+	ctx := New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), defaultLimit)
+	ctx.Set("tenant", "acme")
+	ctx.Propagate(RequestIDKey, "tenant", "userID")
+	ctx.Set("userID", "42")
+	ctx.Set("other", "x")
+	ctx.SetRequestID("req-1")
+
+	reqCtx := ctx.Request.Context()
+	if ValueFrom(reqCtx, "tenant") != "acme" || ValueFrom(reqCtx, "userID") != "42" {
+		t.Error("Expected propagated values in the request context")
+	}
+	if ValueFrom(reqCtx, RequestIDKey) != "req-1" {
+		t.Error("Expected request ID in the request context")
+	}
+	if ValueFrom(reqCtx, "other") != nil {
+		t.Error("Expected unselected keys to stay out of the request context")
+	}
+}
@@ -2,11 +2,17 @@ package context
 
 import (
 	"bytes"
+	stdcontext "context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/JedizLaPulga/kese/feed"
 	"github.com/JedizLaPulga/kese/router"
 )
 
@@ -416,3 +422,328 @@ func TestIsWritten(t *testing.T) {
 		t.Error("IsWritten should be true after writing response")
 	}
 }
+
+func TestIfMatch(t *testing.T) {
+	etag := ResourceETag(42, "2026-01-01T00:00:00Z")
+	if etag != ResourceETag(42, "2026-01-01T00:00:00Z") {
+		t.Fatal("ResourceETag should be deterministic")
+	}
+	if etag == ResourceETag(43, "2026-01-01T00:00:00Z") {
+		t.Fatal("ResourceETag should change with the version")
+	}
+
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{"", true},
+		{"*", true},
+		{etag, true},
+		{`"other", ` + etag, true},
+		{`"other"`, false},
+		{"W/" + etag, false},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", "/todos/42", nil)
+		if test.header != "" {
+			r.Header.Set("If-Match", test.header)
+		}
+		ctx := New(w, r, defaultLimit)
+
+		if got := ctx.IfMatch(etag); got != test.expected {
+			t.Errorf("If-Match %q: expected %v, got %v", test.header, test.expected, got)
+		}
+	}
+}
+
+func TestTypedParams(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	ctx := New(w, r, defaultLimit)
+	ctx.SetParams(router.Params{
+		{Key: "id", Value: "42"},
+		{Key: "big", Value: "9007199254740993"},
+		{Key: "flag", Value: "true"},
+		{Key: "uuid", Value: "123E4567-E89B-12D3-A456-426614174000"},
+		{Key: "bad", Value: "abc"},
+	})
+
+	if n, err := ctx.ParamInt("id"); err != nil || n != 42 {
+		t.Errorf("ParamInt: expected 42, got %d (err=%v)", n, err)
+	}
+	if n, err := ctx.ParamInt64("big"); err != nil || n != 9007199254740993 {
+		t.Errorf("ParamInt64: unexpected %d (err=%v)", n, err)
+	}
+	if b, err := ctx.ParamBool("flag"); err != nil || !b {
+		t.Errorf("ParamBool: expected true, got %v (err=%v)", b, err)
+	}
+	if u, err := ctx.ParamUUID("uuid"); err != nil || u != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("ParamUUID: unexpected %q (err=%v)", u, err)
+	}
+
+	_, err := ctx.ParamInt("bad")
+	var paramErr *ParamError
+	if !errors.As(err, &paramErr) || paramErr.Name != "bad" || paramErr.Type != "int" {
+		t.Errorf("Expected ParamError for bad int, got %v", err)
+	}
+	if _, err := ctx.ParamUUID("bad"); err == nil {
+		t.Error("Expected error for invalid UUID")
+	}
+}
+
+func TestJSONClientClosed(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	reqCtx, cancel := stdcontext.WithCancel(r.Context())
+	cancel()
+	r = r.WithContext(reqCtx)
+
+	ctx := New(w, r, defaultLimit)
+	err := ctx.JSON(200, map[string]string{"key": "value"})
+
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("Expected ErrClientClosed, got %v", err)
+	}
+	if ctx.IsWritten() || w.Body.Len() != 0 {
+		t.Error("Nothing should be written after the client disconnected")
+	}
+}
+
+func TestSetJSONCodec(t *testing.T) {
+	var marshals, unmarshals int
+	SetJSONCodec(func(v interface{}) ([]byte, error) {
+		marshals++
+		return json.Marshal(v)
+	}, func(data []byte, v interface{}) error {
+		unmarshals++
+		return json.Unmarshal(data, v)
+	})
+	t.Cleanup(func() { SetJSONCodec(nil, nil) })
+
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"name":"pen"}`)), defaultLimit)
+
+	var in struct{ Name string }
+	if err := ctx.BindJSON(&in); err != nil || in.Name != "pen" {
+		t.Fatalf("BindJSON failed: %v", err)
+	}
+	ctx.JSONPretty(200, map[string]int{"a": 1})
+	stream := ctx.NDJSON(200)
+	stream.Encode(1)
+
+	if marshals != 2 || unmarshals != 1 {
+		t.Errorf("Expected codec used for encoding and decoding, got %d marshals and %d unmarshals", marshals, unmarshals)
+	}
+	if w.Body.String() != "{\n  \"a\": 1\n}\n1\n" {
+		t.Errorf("Unexpected output %q", w.Body.String())
+	}
+
+	SetJSONCodec(nil, nil)
+	ctx.JSON(200, "std")
+	if marshals != 2 {
+		t.Error("Expected encoding/json restored")
+	}
+}
+
+func TestJSONUnsupportedType(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/", nil), defaultLimit)
+
+	err := ctx.JSON(200, map[string]interface{}{"ok": true, "callback": func() {}})
+	var unsupported *json.UnsupportedTypeError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Expected UnsupportedTypeError, got %v", err)
+	}
+	if ctx.IsWritten() || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Error("Expected nothing written when encoding fails")
+	}
+
+	// Large responses are streamed past the in-memory limit
+	big := strings.Repeat("x", maxJSONBuffer+10)
+	if err := ctx.JSON(200, big); err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	if w.Body.Len() != len(big)+3 || !ctx.IsWritten() {
+		t.Errorf("Expected full large body, got %d bytes", w.Body.Len())
+	}
+}
+
+// countingWriter counts the body writes made to a ResponseWriter.
+type countingWriter struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.ResponseRecorder.Write(p)
+}
+
+func TestJSONContentLength(t *testing.T) {
+	w := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+	ctx := New(w, httptest.NewRequest("GET", "/", nil), defaultLimit)
+
+	if err := ctx.JSON(200, map[string]interface{}{"items": []int{1, 2, 3}}); err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	if w.writes != 1 {
+		t.Errorf("Expected a single write, got %d", w.writes)
+	}
+	if got := w.Header().Get("Content-Length"); got != strconv.Itoa(w.Body.Len()) {
+		t.Errorf("Expected Content-Length %d, got %q", w.Body.Len(), got)
+	}
+}
+
+func TestJSONWithETag(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/", nil), defaultLimit)
+	if err := ctx.JSONWithETag(200, map[string]int{"count": 3}); err != nil {
+		t.Fatalf("JSONWithETag failed: %v", err)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Body.String() != "{\"count\":3}\n" {
+		t.Fatalf("Unexpected response %q with ETag %q", w.Body.String(), etag)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", "W/"+etag)
+	w = httptest.NewRecorder()
+	ctx = New(w, r, defaultLimit)
+	if err := ctx.JSONWithETag(200, map[string]int{"count": 3}); err != nil {
+		t.Fatalf("JSONWithETag failed: %v", err)
+	}
+	if w.Code != 304 || w.Body.Len() != 0 {
+		t.Errorf("Expected empty 304, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestFeed(t *testing.T) {
+	updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	f := &feed.Feed{
+		Format: feed.Atom,
+		Title:  "Changelog",
+		Link:   "https://example.com",
+		TTL:    time.Hour,
+		Items:  []feed.Item{{Title: "v1.2", Link: "https://example.com/v1.2", Published: updated}},
+	}
+
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/feed", nil), defaultLimit)
+	if err := ctx.Feed(200, f); err != nil {
+		t.Fatalf("Feed failed: %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != feed.MIMEAtom {
+		t.Errorf("Expected Atom content type, got %q", ct)
+	}
+	if lm := w.Header().Get("Last-Modified"); lm != "Fri, 01 Mar 2024 12:00:00 GMT" {
+		t.Errorf("Unexpected Last-Modified %q", lm)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=3600" {
+		t.Errorf("Unexpected Cache-Control %q", cc)
+	}
+	if !strings.Contains(w.Body.String(), "<title>v1.2</title>") {
+		t.Errorf("Expected entry in body: %s", w.Body.String())
+	}
+	etag := w.Header().Get("ETag")
+
+	for _, header := range [][2]string{
+		{"If-None-Match", etag},
+		{"If-Modified-Since", "Sat, 02 Mar 2024 00:00:00 GMT"},
+	} {
+		r := httptest.NewRequest("GET", "/feed", nil)
+		r.Header.Set(header[0], header[1])
+		w = httptest.NewRecorder()
+		if err := New(w, r, defaultLimit).Feed(200, f); err != nil {
+			t.Fatalf("Feed failed: %v", err)
+		}
+		if w.Code != 304 || w.Body.Len() != 0 {
+			t.Errorf("%s: expected empty 304, got %d", header[0], w.Code)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/feed", nil)
+	r.Header.Set("If-Modified-Since", "Thu, 29 Feb 2024 00:00:00 GMT")
+	w = httptest.NewRecorder()
+	New(w, r, defaultLimit).Feed(200, f)
+	if w.Code != 200 {
+		t.Errorf("Expected 200 for stale copy, got %d", w.Code)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/json"},
+		{"text/csv", "text/csv"},
+		{"text/*;q=0.9, application/json;q=0.5", "text/csv"},
+		{"*/*;q=0.1, application/json", "application/json"},
+		{"image/png", ""},
+		{"text/*, text/csv;q=0", ""},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", tt.accept)
+		ctx := New(httptest.NewRecorder(), r, defaultLimit)
+		if got := ctx.Negotiate("application/json", "text/csv"); got != tt.want {
+			t.Errorf("Accept %q: expected %q, got %q", tt.accept, tt.want, got)
+		}
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		header string
+		want   UserAgent
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36",
+			UserAgent{Browser: "Chrome", Version: "120.0.6099.109", OS: "Windows", OSVersion: "10"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			UserAgent{Browser: "Edge", Version: "120.0.2210.91", OS: "Windows", OSVersion: "10"},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			UserAgent{Browser: "Safari", Version: "17.1", OS: "iOS", OSVersion: "17.1.2", Mobile: true},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:121.0) Gecko/20100101 Firefox/121.0",
+			UserAgent{Browser: "Firefox", Version: "121.0", OS: "macOS", OSVersion: "10.15"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			UserAgent{Browser: "Chrome", Version: "120.0.0.0", OS: "Android", OSVersion: "14", Tablet: true},
+		},
+		{
+			"Mozilla/4.0 (compatible; MSIE 8.0; Windows NT 6.1; Trident/4.0)",
+			UserAgent{Browser: "Internet Explorer", Version: "8.0", OS: "Windows", OSVersion: "7"},
+		},
+		{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			UserAgent{Browser: "Googlebot", Version: "2.1", Bot: true},
+		},
+		{"curl/8.4.0", UserAgent{Browser: "curl", Version: "8.4.0", Bot: true}},
+		{"", UserAgent{}},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("User-Agent", tt.header)
+		ctx := New(httptest.NewRecorder(), r, defaultLimit)
+		tt.want.Raw = tt.header
+		if got := ctx.UserAgent(); got != tt.want {
+			t.Errorf("%q:\n got  %+v\n want %+v", tt.header, got, tt.want)
+		}
+	}
+
+	ua := ParseUserAgent(tests[0].header)
+	if ua.MajorVersion() != 120 || ua.String() != "Chrome 120 (Windows 10)" {
+		t.Errorf("unexpected MajorVersion %d or String %q", ua.MajorVersion(), ua.String())
+	}
+}
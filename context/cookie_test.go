@@ -0,0 +1,168 @@
+package context
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/JedizLaPulga/kese/validate"
+)
+
+func setCookie(t *testing.T, https bool, name string, opts CookieOptions) (*http.Cookie, error) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	if https {
+		r.Header.Set("X-Forwarded-Proto", "https")
+	}
+
+	err := New(w, r, defaultTestLimit).SetCookieSecure(name, "v", opts)
+	if cookies := w.Result().Cookies(); len(cookies) == 1 {
+		return cookies[0], err
+	}
+	return nil, err
+}
+
+func TestSetCookieSecureDefaults(t *testing.T) {
+	cookie, _ := setCookie(t, false, "prefs", CookieOptions{})
+	if !cookie.HttpOnly || cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" || cookie.MaxAge != 0 {
+		t.Errorf("Unexpected defaults over HTTP: %+v", cookie)
+	}
+
+	cookie, _ = setCookie(t, true, "prefs", CookieOptions{MaxAge: 90 * time.Minute, AllowScript: true})
+	if !cookie.Secure || cookie.HttpOnly || cookie.MaxAge != 5400 {
+		t.Errorf("Unexpected cookie over HTTPS: %+v", cookie)
+	}
+
+	cookie, _ = setCookie(t, false, "embed", CookieOptions{SameSite: http.SameSiteNoneMode})
+	if !cookie.Secure {
+		t.Error("SameSite=None must imply Secure")
+	}
+}
+
+func TestSetCookieSecurePrefixes(t *testing.T) {
+	cookie, err := setCookie(t, false, HostCookiePrefix+"session", CookieOptions{})
+	if err != nil || !cookie.Secure || cookie.Path != "/" {
+		t.Errorf("Unexpected __Host- cookie: %+v (%v)", cookie, err)
+	}
+
+	cookie, _ = setCookie(t, false, SecureCookiePrefix+"id", CookieOptions{Path: "/app"})
+	if !cookie.Secure {
+		t.Error("__Secure- cookies must be Secure")
+	}
+
+	cookie, err = setCookie(t, true, HostCookiePrefix+"session", CookieOptions{Domain: "example.com"})
+	if !errors.Is(err, ErrInvalidCookie) || cookie != nil {
+		t.Errorf("Expected ErrInvalidCookie for __Host- with Domain, got %v", err)
+	}
+}
+
+func TestClearCookie(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil), defaultTestLimit).ClearCookie("prefs", CookieOptions{})
+
+	cookie := w.Result().Cookies()[0]
+	if cookie.MaxAge != -1 || cookie.Value != "" {
+		t.Errorf("Expected expired cookie, got %+v", cookie)
+	}
+}
+
+func TestFlash(t *testing.T) {
+	form := url.Values{"name": {"Ada"}, "new_password": {"hunter2"}, "csrf_token": {"abc"}}
+	r := httptest.NewRequest("POST", "/profile", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	ctx := New(w, r, defaultTestLimit)
+
+	verr := validate.NewValidationError()
+	verr.Add("name", "is taken")
+	ctx.Flash("error", "first")
+	ctx.FlashInput(verr)
+	ctx.Flash("error", "Could not save")
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected a single flash cookie, got %d", len(cookies))
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/profile", nil)
+	r.AddCookie(cookies[0])
+	ctx = New(w, r, defaultTestLimit)
+	flash := ctx.Flashed()
+	if flash.Message("error") != "Could not save" || flash.Old("name") != "Ada" || flash.Error("name") != "is taken" {
+		t.Errorf("Unexpected flash %+v", flash)
+	}
+	if flash.Old("new_password") != "" || flash.Old("csrf_token") != "" {
+		t.Error("Sensitive fields must not be flashed")
+	}
+	if ctx.Flashed() != flash {
+		t.Error("Expected the flash to be read once per request")
+	}
+	if cleared := w.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("Expected the flash cookie to be deleted, got %v", cleared)
+	}
+
+	// Oversized input is dropped, keeping messages
+	form = url.Values{"essay": {strings.Repeat("x", 5000)}}
+	r = httptest.NewRequest("POST", "/essay", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	ctx = New(w, r, defaultTestLimit)
+	ctx.FlashInput(nil)
+	ctx.Flash("error", "Too long")
+	if value := w.Result().Cookies()[0].Value; len(value) > maxFlashCookie {
+		t.Errorf("Flash cookie of %d bytes exceeds the limit", len(value))
+	}
+
+	// Oversized messages and errors are truncated, then dropped
+	w = httptest.NewRecorder()
+	ctx = New(w, httptest.NewRequest("POST", "/essay", nil), defaultTestLimit)
+	for i := 0; i < 40; i++ {
+		ctx.Flash("note"+strconv.Itoa(i), strings.Repeat("é", 300))
+	}
+	cookies = w.Result().Cookies()
+	if len(cookies[0].Value) > maxFlashCookie {
+		t.Errorf("Flash cookie of %d bytes exceeds the limit", len(cookies[0].Value))
+	}
+	r = httptest.NewRequest("GET", "/essay", nil)
+	r.AddCookie(cookies[0])
+	flash = New(httptest.NewRecorder(), r, defaultTestLimit).Flashed()
+	if note := flash.Message("note0"); !strings.HasSuffix(note, "…") || !utf8.ValidString(note) {
+		t.Errorf("Expected a truncated message, got %q", note)
+	}
+
+	// With a FlashSecret, only cookies signed by the server are read
+	secret := []byte(strings.Repeat("k", 32))
+	w = httptest.NewRecorder()
+	ctx = New(w, httptest.NewRequest("POST", "/profile", nil), defaultTestLimit)
+	ctx.FlashSecret = secret
+	ctx.Flash("success", "Saved")
+	signed := w.Result().Cookies()[0]
+
+	read := func(value string) *Flash {
+		r := httptest.NewRequest("GET", "/profile", nil)
+		r.AddCookie(&http.Cookie{Name: FlashCookieName, Value: value})
+		ctx := New(httptest.NewRecorder(), r, defaultTestLimit)
+		ctx.FlashSecret = secret
+		return ctx.Flashed()
+	}
+	if msg := read(signed.Value).Message("success"); msg != "Saved" {
+		t.Errorf("Expected signed flash to be read, got %q", msg)
+	}
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"m":{"success":"Call +1 555 0100"}}`))
+	if msg := read(forged).Message("success"); msg != "" {
+		t.Errorf("Expected unsigned flash to be ignored, got %q", msg)
+	}
+	payload, _, _ := strings.Cut(signed.Value, ".")
+	if msg := read(forged + "." + strings.TrimPrefix(signed.Value, payload+".")).Message("success"); msg != "" {
+		t.Errorf("Expected flash with another payload's signature to be ignored, got %q", msg)
+	}
+}
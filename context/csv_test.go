@@ -0,0 +1,135 @@
+package context
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/JedizLaPulga/kese/xlsx"
+)
+
+func TestCSV(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/report", nil), defaultTestLimit)
+
+	err := ctx.CSV(200, []string{"id", "name"}, func(cw *csv.Writer) error {
+		cw.Write([]string{"1", "Smith, John"})
+		return cw.Write([]string{"2", `Say "hi"`})
+	})
+	if err != nil {
+		t.Fatalf("CSV error: %v", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+	expected := "id,name\n1,\"Smith, John\"\n2,\"Say \"\"hi\"\"\"\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, w.Body.String())
+	}
+}
+
+func TestCSVWithOptions(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/report", nil), defaultTestLimit)
+
+	err := ctx.CSVWithOptions(200, []string{"a", "b"}, nil, CSVOptions{BOM: true, Comma: ';', Filename: "report.csv"})
+	if err != nil {
+		t.Fatalf("CSVWithOptions error: %v", err)
+	}
+	if w.Body.String() != "\xEF\xBB\xBFa;b\n" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+	if !strings.Contains(w.Header().Get("Content-Disposition"), `filename=report.csv`) {
+		t.Errorf("Unexpected Content-Disposition %q", w.Header().Get("Content-Disposition"))
+	}
+
+	failure := errors.New("query failed")
+	err = New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), defaultTestLimit).CSV(200, nil, func(*csv.Writer) error {
+		return failure
+	})
+	if err != failure {
+		t.Errorf("Expected rows error to be returned, got %v", err)
+	}
+}
+
+func TestCSVFile(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/report", nil), defaultTestLimit)
+
+	if err := ctx.CSVFile("orders.csv", []string{"id"}, [][]string{{"1"}, {"2"}}); err != nil {
+		t.Fatalf("CSVFile error: %v", err)
+	}
+	if w.Body.String() != "\xEF\xBB\xBFid\n1\n2\n" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+	if !strings.Contains(w.Header().Get("Content-Disposition"), "attachment; filename=orders.csv") {
+		t.Errorf("Unexpected Content-Disposition %q", w.Header().Get("Content-Disposition"))
+	}
+}
+
+func TestExcelAndPDF(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/report", nil), defaultTestLimit)
+	if err := ctx.Excel("orders.xlsx", xlsx.Sheet{Rows: [][]interface{}{{"id"}, {1}}}); err != nil {
+		t.Fatalf("Excel error: %v", err)
+	}
+	if w.Header().Get("Content-Type") != xlsx.MIMEType || !strings.HasPrefix(w.Body.String(), "PK") {
+		t.Errorf("Expected xlsx response, got %q", w.Header().Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	ctx = New(w, httptest.NewRequest("GET", "/invoice", nil), defaultTestLimit)
+	err := ctx.PDF("", PDFRendererFunc(func(out io.Writer) error {
+		_, err := io.WriteString(out, "%PDF-1.7")
+		return err
+	}))
+	if err != nil || w.Header().Get("Content-Type") != MIMEPDF || w.Body.String() != "%PDF-1.7" {
+		t.Errorf("Unexpected PDF response %q %q, %v", w.Header().Get("Content-Type"), w.Body.String(), err)
+	}
+	if w.Header().Get("Content-Disposition") != "" {
+		t.Errorf("Expected inline PDF, got %q", w.Header().Get("Content-Disposition"))
+	}
+
+	failure := errors.New("renderer crashed")
+	ctx = New(httptest.NewRecorder(), httptest.NewRequest("GET", "/invoice", nil), defaultTestLimit)
+	if err := ctx.PDF("invoice.pdf", PDFRendererFunc(func(io.Writer) error { return failure })); err != failure || ctx.IsWritten() {
+		t.Errorf("Expected renderer error before writing, got %v", err)
+	}
+}
+
+func TestQRCode(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/2fa/qr", nil), defaultTestLimit)
+
+	if err := ctx.QRCode(200, "otpauth://totp/Example:ada?secret=JBSWY3DPEHPK3PXP", 128); err != nil {
+		t.Fatalf("QRCode error: %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected image/png, got %q", ct)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Expected Cache-Control no-store, got %q", cc)
+	}
+	if !strings.HasPrefix(w.Body.String(), "\x89PNG") {
+		t.Error("Expected a PNG body")
+	}
+
+	// Served from the cache the second time
+	w2 := httptest.NewRecorder()
+	ctx = New(w2, httptest.NewRequest("GET", "/2fa/qr", nil), defaultTestLimit)
+	ctx.QRCode(200, "otpauth://totp/Example:ada?secret=JBSWY3DPEHPK3PXP", 128)
+	if w2.Body.String() != w.Body.String() {
+		t.Error("Expected identical image for identical content")
+	}
+
+	w3 := httptest.NewRecorder()
+	ctx = New(w3, httptest.NewRequest("GET", "/2fa/qr", nil), defaultTestLimit)
+	ctx.QRCode(200, strings.Repeat("x", 3000), 128)
+	if w3.Code != 400 {
+		t.Errorf("Expected 400 for oversized content, got %d", w3.Code)
+	}
+}
@@ -0,0 +1,170 @@
+package context
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResourceETag builds a strong ETag from the values that identify a resource version,
+// typically a version counter or an updated_at timestamp.
+//
+// Example:
+//
+//	etag := context.ResourceETag(todo.ID, todo.UpdatedAt)
+func ResourceETag(parts ...interface{}) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%v|", part)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// ContentETag builds a strong ETag from a response body.
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// FileETag builds a weak ETag from a file's size and modification time,
+// which is cheap to compute without reading the file.
+func FileETag(info fs.FileInfo) string {
+	return `W/"` + strconv.FormatInt(info.Size(), 16) + "-" + strconv.FormatInt(info.ModTime().UnixNano(), 16) + `"`
+}
+
+// SetETag sets the ETag response header, quoting the value if needed.
+func (c *Context) SetETag(etag string) {
+	c.SetHeader("ETag", quoteETag(etag))
+}
+
+// IfMatch reports whether the request's If-Match header allows modifying a
+// resource whose current ETag is currentETag. Requests without If-Match are allowed;
+// use HasIfMatch or middleware.RequireIfMatch to make the header mandatory.
+// Comparison is strong, as required for If-Match: weak validators never match.
+//
+// Example:
+//
+//	if !c.IfMatch(context.ResourceETag(todo.Version)) {
+//	    return c.PreconditionFailed("todo was modified by another request")
+//	}
+func (c *Context) IfMatch(currentETag string) bool {
+	header := c.Header("If-Match")
+	if header == "" {
+		return true
+	}
+
+	current := quoteETag(currentETag)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") || strings.HasPrefix(current, "W/") {
+			continue
+		}
+		if candidate == current {
+			return true
+		}
+	}
+	return false
+}
+
+// IfNoneMatch reports whether the request's If-None-Match header matches
+// currentETag, meaning the client's cached copy is still current and a
+// 304 Not Modified can be sent instead of the body. Comparison is weak, as
+// required for If-None-Match.
+//
+// Example:
+//
+//	etag := context.ResourceETag(todo.Version)
+//	if c.IfNoneMatch(etag) {
+//	    return c.NotModified(etag)
+//	}
+func (c *Context) IfNoneMatch(currentETag string) bool {
+	header := c.Header("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	current := strings.TrimPrefix(quoteETag(currentETag), "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == current {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModified sends a 304 Not Modified response with the given ETag and no body.
+func (c *Context) NotModified(etag string) error {
+	header := c.Writer.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	if etag != "" {
+		c.SetETag(etag)
+	}
+
+	c.statusCode = http.StatusNotModified
+	c.Writer.WriteHeader(http.StatusNotModified)
+	c.SetWritten()
+	return nil
+}
+
+// JSONWithETag sends data as JSON with an ETag computed from the encoded
+// body. GET and HEAD requests whose If-None-Match matches get an empty
+// 304 Not Modified instead, so clients polling unchanged data skip the download.
+//
+// Example:
+//
+//	return c.JSONWithETag(200, todos)
+func (c *Context) JSONWithETag(status int, data interface{}) error {
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, data, ""); err != nil {
+		return err
+	}
+
+	etag := ContentETag(buf.Bytes())
+	if c.conditionalGET(status) && c.IfNoneMatch(etag) {
+		return c.NotModified(etag)
+	}
+
+	c.SetETag(etag)
+	c.SetHeader("Content-Length", strconv.Itoa(buf.Len()))
+	return c.Bytes(status, "application/json", buf.Bytes())
+}
+
+// conditionalGET reports whether a response with status may be replaced by
+// 304 Not Modified for this request.
+func (c *Context) conditionalGET(status int) bool {
+	method := c.Request.Method
+	return (method == http.MethodGet || method == http.MethodHead) && status == http.StatusOK
+}
+
+// HasIfMatch reports whether the request carries an If-Match header.
+func (c *Context) HasIfMatch() bool {
+	return c.Header("If-Match") != ""
+}
+
+// PreconditionFailed sends a 412 Precondition Failed JSON response.
+func (c *Context) PreconditionFailed(message string) error {
+	return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": message})
+}
+
+// PreconditionRequired sends a 428 Precondition Required JSON response.
+func (c *Context) PreconditionRequired(message string) error {
+	return c.JSON(http.StatusPreconditionRequired, map[string]string{"error": message})
+}
+
+// quoteETag wraps an ETag in double quotes unless it is already quoted.
+func quoteETag(etag string) string {
+	if strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
@@ -0,0 +1,80 @@
+package context
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+
+	"github.com/JedizLaPulga/kese/xlsx"
+)
+
+// MIMEPDF is the content type of PDF documents.
+const MIMEPDF = "application/pdf"
+
+// PDFRenderer renders a PDF document. Implement it to plug a PDF library or
+// an HTML-to-PDF service into c.PDF.
+type PDFRenderer interface {
+	RenderPDF(w io.Writer) error
+}
+
+// PDFRendererFunc adapts a function to the PDFRenderer interface.
+type PDFRendererFunc func(w io.Writer) error
+
+// RenderPDF calls f(w).
+func (f PDFRendererFunc) RenderPDF(w io.Writer) error {
+	return f(w)
+}
+
+// CSVFile sends records as a CSV download named filename, with a header row
+// and a byte order mark so Excel opens it with the right encoding. Use
+// CSVWithOptions to stream large reports instead.
+//
+// Example:
+//
+//	return c.CSVFile("orders.csv", []string{"id", "total"}, records)
+func (c *Context) CSVFile(filename string, headers []string, records [][]string) error {
+	return c.CSVWithOptions(http.StatusOK, headers, func(w *csv.Writer) error {
+		return w.WriteAll(records)
+	}, CSVOptions{BOM: true, Filename: filename})
+}
+
+// Excel sends an Excel workbook holding sheets as a download named filename.
+// The workbook is built in memory, so a failure still results in an error
+// response rather than a truncated file.
+//
+// Example:
+//
+//	rows := [][]interface{}{{"ID", "Customer", "Total", "Placed"}}
+//	for _, o := range orders {
+//	    rows = append(rows, []interface{}{o.ID, o.Customer, o.Total, o.PlacedAt})
+//	}
+//	return c.Excel("orders.xlsx", xlsx.Sheet{Name: "Orders", Rows: rows})
+func (c *Context) Excel(filename string, sheets ...xlsx.Sheet) error {
+	var buf bytes.Buffer
+	if err := xlsx.Write(&buf, sheets...); err != nil {
+		return err
+	}
+	c.setContentDisposition("attachment", filename)
+	return c.Bytes(http.StatusOK, xlsx.MIMEType, buf.Bytes())
+}
+
+// PDF sends the document rendered by renderer, as a download named filename,
+// or shown in the browser if filename is empty. The document is rendered in
+// memory first, so a renderer failure still results in an error response.
+//
+// Example:
+//
+//	return c.PDF("invoice-"+id+".pdf", context.PDFRendererFunc(func(w io.Writer) error {
+//	    return invoicePDF(w, invoice)
+//	}))
+func (c *Context) PDF(filename string, renderer PDFRenderer) error {
+	var buf bytes.Buffer
+	if err := renderer.RenderPDF(&buf); err != nil {
+		return err
+	}
+	if filename != "" {
+		c.setContentDisposition("attachment", filename)
+	}
+	return c.Bytes(http.StatusOK, MIMEPDF, buf.Bytes())
+}
@@ -0,0 +1,53 @@
+package context
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JedizLaPulga/kese/feed"
+)
+
+// Feed sends f as RSS or Atom, according to its Format, with an ETag and a
+// Last-Modified date. Feed readers poll often; GET and HEAD requests whose
+// If-None-Match or If-Modified-Since show their copy is current get an
+// empty 304 Not Modified instead. A positive f.TTL is sent as
+// Cache-Control max-age unless the handler set its own.
+//
+// Example:
+//
+//	return c.Feed(200, &feed.Feed{Title: "Changelog", Link: "https://example.com", Items: items})
+func (c *Context) Feed(status int, f *feed.Feed) error {
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return err
+	}
+
+	etag := ContentETag(buf.Bytes())
+	modified := f.LastModified().UTC().Truncate(time.Second)
+	if !modified.IsZero() {
+		c.SetHeader("Last-Modified", modified.Format(http.TimeFormat))
+	}
+	if f.TTL > 0 && c.Writer.Header().Get("Cache-Control") == "" {
+		c.SetHeader("Cache-Control", "public, max-age="+strconv.Itoa(int(f.TTL.Seconds())))
+	}
+
+	if c.conditionalGET(status) && c.feedNotModified(etag, modified) {
+		return c.NotModified(etag)
+	}
+
+	c.SetETag(etag)
+	c.SetHeader("Content-Length", strconv.Itoa(buf.Len()))
+	return c.Bytes(status, f.ContentType(), buf.Bytes())
+}
+
+// feedNotModified reports whether the client's copy is current.
+// If-Modified-Since is only consulted without If-None-Match, per RFC 9110.
+func (c *Context) feedNotModified(etag string, modified time.Time) bool {
+	if c.Header("If-None-Match") != "" {
+		return c.IfNoneMatch(etag)
+	}
+	since, err := http.ParseTime(c.Header("If-Modified-Since"))
+	return err == nil && !modified.IsZero() && !modified.After(since)
+}
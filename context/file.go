@@ -0,0 +1,119 @@
+package context
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// File sends the file at filePath. The Content-Type is derived from the file
+// extension, and Range, If-Modified-Since, If-None-Match and HEAD requests are handled.
+// A missing file or a directory results in a 404 JSON response.
+//
+// Example:
+//
+//	return c.File("./reports/" + id + ".pdf")
+func (c *Context) File(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c.NotFoundError("File not found")
+		}
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return c.NotFoundError("File not found")
+	}
+
+	return c.serveContent(filepath.Base(filePath), info, file)
+}
+
+// Attachment sends the file at filePath as a download, using downloadName as
+// the suggested file name (defaults to the base name of filePath).
+//
+// Example:
+//
+//	return c.Attachment("./exports/2024.csv", "sales-2024.csv")
+func (c *Context) Attachment(filePath, downloadName string) error {
+	if downloadName == "" {
+		downloadName = filepath.Base(filePath)
+	}
+	c.setContentDisposition("attachment", downloadName)
+	return c.File(filePath)
+}
+
+// FileFromFS sends the named file from fsys, such as an embed.FS.
+// It behaves like File; name uses forward slashes as required by fs.FS.
+//
+// Example:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	return c.FileFromFS("assets/logo.png", assets)
+func (c *Context) FileFromFS(name string, fsys fs.FS) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c.NotFoundError("File not found")
+		}
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return c.NotFoundError("File not found")
+	}
+
+	// Range support requires seeking; buffer files that cannot seek
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(data)
+	}
+
+	return c.serveContent(path.Base(name), info, content)
+}
+
+// serveContent writes content with http.ServeContent and marks the response
+// written. Unless the handler set one, a weak ETag derived from the file's
+// size and modification time is sent, so If-None-Match is honored as well.
+func (c *Context) serveContent(name string, info fs.FileInfo, content io.ReadSeeker) error {
+	if err := c.clientGone(); err != nil {
+		return err
+	}
+
+	if c.Writer.Header().Get("ETag") == "" {
+		c.SetHeader("ETag", FileETag(info))
+	}
+
+	http.ServeContent(c.Writer, c.Request, name, info.ModTime(), content)
+	c.SetWritten()
+	return nil
+}
+
+// setContentDisposition sets the Content-Disposition header, encoding
+// non-ASCII file names as required by RFC 6266.
+func (c *Context) setContentDisposition(disposition, filename string) {
+	c.SetHeader("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{
+		"filename": filename,
+	}))
+}
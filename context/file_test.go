@@ -0,0 +1,86 @@
+package context
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "report.json")
+	os.WriteFile(filePath, []byte(`{"total":42}`), 0o644)
+
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/report", nil), defaultTestLimit)
+	if err := ctx.File(filePath); err != nil {
+		t.Fatalf("File error: %v", err)
+	}
+	if w.Code != 200 || w.Body.String() != `{"total":42}` {
+		t.Errorf("Unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected application/json, got %q", ct)
+	}
+	if !ctx.IsWritten() {
+		t.Error("Expected response to be marked written")
+	}
+
+	// Range request
+	r := httptest.NewRequest("GET", "/report", nil)
+	r.Header.Set("Range", "bytes=1-7")
+	w = httptest.NewRecorder()
+	New(w, r, defaultTestLimit).File(filePath)
+	if w.Code != 206 || w.Body.String() != `"total"` {
+		t.Errorf("Expected partial content, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Missing file and directory
+	for _, missing := range []string{filepath.Join(dir, "nope.txt"), dir} {
+		w = httptest.NewRecorder()
+		New(w, httptest.NewRequest("GET", "/", nil), defaultTestLimit).File(missing)
+		if w.Code != 404 {
+			t.Errorf("Expected 404 for %s, got %d", missing, w.Code)
+		}
+	}
+}
+
+func TestAttachment(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "data.csv")
+	os.WriteFile(filePath, []byte("a,b\n1,2\n"), 0o644)
+
+	w := httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/export", nil), defaultTestLimit).Attachment(filePath, "ventes-été.csv")
+
+	disposition := w.Header().Get("Content-Disposition")
+	if !strings.HasPrefix(disposition, "attachment;") || !strings.Contains(disposition, "filename*=utf-8''ventes-%C3%A9t%C3%A9.csv") {
+		t.Errorf("Unexpected Content-Disposition %q", disposition)
+	}
+	if w.Body.String() != "a,b\n1,2\n" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+}
+
+func TestFileFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/logo.svg": {Data: []byte("<svg/>")},
+	}
+
+	w := httptest.NewRecorder()
+	if err := New(w, httptest.NewRequest("GET", "/logo", nil), defaultTestLimit).FileFromFS("assets/logo.svg", fsys); err != nil {
+		t.Fatalf("FileFromFS error: %v", err)
+	}
+	if w.Body.String() != "<svg/>" || w.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Errorf("Unexpected response %q %q", w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/missing", nil), defaultTestLimit).FileFromFS("assets/missing.svg", fsys)
+	if w.Code != 404 {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,279 @@
+package context
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/JedizLaPulga/kese/validate"
+)
+
+// FlashCookieName is the cookie carrying flash data to the next request.
+const FlashCookieName = "kese_flash"
+
+// maxFlashCookie keeps the flash cookie under the 4KB browsers accept,
+// leaving room for its attributes.
+const maxFlashCookie = 3800
+
+// maxFlashText is the length messages and validation errors are truncated
+// to when the flash data would not fit in its cookie.
+const maxFlashText = 200
+
+// Flash is data kept for the next request only, typically across the
+// redirect of a POST-redirect-GET cycle: status messages, the input of a
+// rejected form and its validation errors. Its methods are safe on a nil
+// *Flash.
+//
+// Flash data travels in a cookie. Unless the Context has a FlashSecret (see
+// App.FlashSecret), the cookie is unsigned: a client, or a sibling subdomain
+// able to set cookies, can forge the messages and errors shown to the user.
+type Flash struct {
+	Messages map[string]string   `json:"m,omitempty"`
+	Input    map[string][]string `json:"i,omitempty"`
+	Errors   map[string]string   `json:"e,omitempty"`
+}
+
+// Message returns the message flashed under key, e.g. "success".
+func (f *Flash) Message(key string) string {
+	if f == nil {
+		return ""
+	}
+	return f.Messages[key]
+}
+
+// Old returns the first value previously submitted for the form field name.
+func (f *Flash) Old(name string) string {
+	if f == nil || len(f.Input[name]) == 0 {
+		return ""
+	}
+	return f.Input[name][0]
+}
+
+// Error returns the validation error of the form field name.
+func (f *Flash) Error(name string) string {
+	if f == nil {
+		return ""
+	}
+	return f.Errors[name]
+}
+
+// Flash stores a message for the next request, e.g. a confirmation shown
+// after a redirect. Messages are stored in a cookie, so they are visible to
+// the client; never flash secrets. Flash data that would not fit in the
+// cookie is shrunk: form input is dropped first, then long messages and
+// errors are truncated.
+//
+// Example:
+//
+//	c.Flash("success", "Profile saved")
+//	return c.Redirect(303, "/profile")
+func (c *Context) Flash(key, message string) {
+	out := c.flashOut()
+	if out.Messages == nil {
+		out.Messages = make(map[string]string)
+	}
+	out.Messages[key] = message
+	c.writeFlash(out)
+}
+
+// FlashInput stores the submitted form for the next request so a rejected
+// form can be shown again with the user's input, and the messages of err if
+// it is a *validate.ValidationError. Fields whose name contains "password",
+// "secret" or "token" (including the CSRF token) are never stored.
+//
+// Validation errors are keyed by json tag and form values by form tag; give
+// fields matching tags so templates find both under one name.
+//
+// Example:
+//
+//	type SignupInput struct {
+//	    Email string `json:"email" form:"email" validate:"required,email"`
+//	}
+//
+//	app.POST("/signup", func(c *kese.Context) error {
+//	    var in SignupInput
+//	    if err := c.BindAndValidate(&in); err != nil {
+//	        c.FlashInput(err)
+//	        return c.Redirect(303, "/signup")
+//	    }
+//	    ...
+//	})
+func (c *Context) FlashInput(err error) {
+	out := c.flashOut()
+	if c.Request.PostForm == nil {
+		c.Request.ParseForm()
+	}
+	for name, values := range c.Request.PostForm {
+		if sensitiveField(name) {
+			continue
+		}
+		if out.Input == nil {
+			out.Input = make(map[string][]string)
+		}
+		out.Input[name] = values
+	}
+
+	var verr *validate.ValidationError
+	if errors.As(err, &verr) {
+		if out.Errors == nil {
+			out.Errors = make(map[string]string)
+		}
+		for field, message := range verr.Errors {
+			out.Errors[field] = message
+		}
+	}
+	c.writeFlash(out)
+}
+
+// Flashed returns the flash data stored by the previous request, and
+// deletes it so it is shown once. Templates read it through the old,
+// fieldError and flash functions.
+func (c *Context) Flashed() *Flash {
+	if in, ok := c.Get("flash_in").(*Flash); ok {
+		return in
+	}
+
+	in := &Flash{}
+	if cookie, err := c.Cookie(FlashCookieName); err == nil {
+		if data, ok := c.decodeFlash(cookie.Value); ok {
+			json.Unmarshal(data, in)
+		}
+		if _, pending := c.Get("flash_out").(*Flash); !pending {
+			c.writeFlash(nil)
+		}
+	}
+	c.Set("flash_in", in)
+	return in
+}
+
+// flashOut returns the flash data being stored for the next request.
+func (c *Context) flashOut() *Flash {
+	if out, ok := c.Get("flash_out").(*Flash); ok {
+		return out
+	}
+	out := &Flash{}
+	c.Set("flash_out", out)
+	return out
+}
+
+// writeFlash replaces the flash cookie of the response with f, or deletes
+// it if f is nil.
+func (c *Context) writeFlash(f *Flash) {
+	header := c.Writer.Header()
+	cookies := header["Set-Cookie"][:0]
+	for _, cookie := range header["Set-Cookie"] {
+		if !strings.HasPrefix(cookie, FlashCookieName+"=") {
+			cookies = append(cookies, cookie)
+		}
+	}
+	if len(cookies) == 0 {
+		header.Del("Set-Cookie")
+	} else {
+		header["Set-Cookie"] = cookies
+	}
+
+	if f == nil {
+		c.ClearCookie(FlashCookieName, CookieOptions{})
+		return
+	}
+	c.SetCookieSecure(FlashCookieName, c.encodeFlash(f), CookieOptions{})
+}
+
+// encodeFlash returns the cookie value of f, signed with FlashSecret if set,
+// shrinking f to fit: Input is dropped first, then long messages and errors
+// are truncated, then messages and errors are dropped in key order.
+func (c *Context) encodeFlash(f *Flash) string {
+	value := c.signFlash(f)
+	if len(value) <= maxFlashCookie {
+		return value
+	}
+
+	trimmed := Flash{
+		Messages: truncateFlashText(f.Messages),
+		Errors:   truncateFlashText(f.Errors),
+	}
+	value = c.signFlash(&trimmed)
+	for len(value) > maxFlashCookie {
+		if !dropLastFlashText(trimmed.Errors) && !dropLastFlashText(trimmed.Messages) {
+			break
+		}
+		value = c.signFlash(&trimmed)
+	}
+	return value
+}
+
+// signFlash encodes f, followed by "." and its signature with FlashSecret.
+func (c *Context) signFlash(f *Flash) string {
+	data, _ := json.Marshal(f)
+	value := base64.RawURLEncoding.EncodeToString(data)
+	if len(c.FlashSecret) == 0 {
+		return value
+	}
+	return value + "." + flashSignature(c.FlashSecret, value)
+}
+
+// decodeFlash returns the JSON of a flash cookie value. With a FlashSecret,
+// values without a valid signature are rejected.
+func (c *Context) decodeFlash(value string) ([]byte, bool) {
+	if len(c.FlashSecret) > 0 {
+		payload, signature, ok := strings.Cut(value, ".")
+		if !ok || !hmac.Equal([]byte(signature), []byte(flashSignature(c.FlashSecret, payload))) {
+			return nil, false
+		}
+		value = payload
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	return data, err == nil
+}
+
+func flashSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(FlashCookieName + "=" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// truncateFlashText returns a copy of texts with values longer than
+// maxFlashText cut at a rune boundary and ended with an ellipsis.
+func truncateFlashText(texts map[string]string) map[string]string {
+	if texts == nil {
+		return nil
+	}
+	out := make(map[string]string, len(texts))
+	for key, text := range texts {
+		if len(text) > maxFlashText {
+			cut := maxFlashText
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut] + "…"
+		}
+		out[key] = text
+	}
+	return out
+}
+
+// dropLastFlashText deletes the entry of texts with the greatest key, and
+// reports whether there was one.
+func dropLastFlashText(texts map[string]string) bool {
+	if len(texts) == 0 {
+		return false
+	}
+	keys := make([]string, 0, len(texts))
+	for key := range texts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	delete(texts, keys[len(keys)-1])
+	return true
+}
+
+// sensitiveField reports whether a form field must not be echoed back.
+func sensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "password") || strings.Contains(name, "secret") || strings.Contains(name, "token")
+}
@@ -0,0 +1,183 @@
+package context
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrUnknownField is wrapped in a BindError when strict decoding meets a JSON
+// field that does not exist in the target struct.
+var ErrUnknownField = errors.New("unknown field")
+
+// ErrMaxDepth is wrapped in a BindError when a JSON body is nested deeper than
+// JSONOptions.MaxDepth.
+var ErrMaxDepth = errors.New("nesting exceeds maximum depth")
+
+// JSONOptions configures strict JSON decoding for BindJSONWithOptions.
+type JSONOptions struct {
+	// DisallowUnknownFields rejects fields that don't exist in the target struct
+	DisallowUnknownFields bool
+
+	// UseNumber decodes numbers into interface{} values as json.Number instead
+	// of float64, so large integers keep their precision
+	UseNumber bool
+
+	// MaxDepth rejects bodies with objects or arrays nested deeper than this
+	// (0 = unlimited)
+	MaxDepth int
+}
+
+// BindJSONWithOptions decodes a JSON request body into v like BindJSON, with
+// strict decoding options. Decode failures are returned as a *BindError
+// describing the offending field, byte offset and expected type, so they can
+// be returned to the client as a 400 response without exposing Go types.
+//
+// Example:
+//
+//	var in CreateOrderInput
+//	err := c.BindJSONWithOptions(&in, context.JSONOptions{
+//	    DisallowUnknownFields: true,
+//	    MaxDepth:              10,
+//	})
+//	if err != nil {
+//	    return err // 400 {"error": "unknown field \"discount\"", "field": "discount"}
+//	}
+func (c *Context) BindJSONWithOptions(v interface{}, opts JSONOptions) error {
+	if err := c.bufferBody(); err != nil {
+		return &BindError{Err: err}
+	}
+
+	// Strict options rely on encoding/json; otherwise honor a custom codec
+	if opts == (JSONOptions{}) && jsonCodec.Load() != nil {
+		data, err := c.BodyBytes()
+		if err != nil {
+			return &BindError{Err: err}
+		}
+		if err := JSONUnmarshal(data, v); err != nil {
+			return jsonBindError(err, 0)
+		}
+		return nil
+	}
+
+	if opts.MaxDepth > 0 {
+		body, err := c.bufferedBody()
+		if err != nil {
+			return &BindError{Err: err}
+		}
+		if offset, exceeded := exceedsDepth(body, opts.MaxDepth); exceeded {
+			return &BindError{Offset: offset, Err: fmt.Errorf("%w of %d", ErrMaxDepth, opts.MaxDepth)}
+		}
+	}
+
+	body, err := c.bufferedBody()
+	if err != nil {
+		return &BindError{Err: err}
+	}
+	decoder := json.NewDecoder(body)
+	if opts.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if opts.UseNumber {
+		decoder.UseNumber()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return jsonBindError(err, decoder.InputOffset())
+	}
+	if decoder.More() {
+		return &BindError{Offset: decoder.InputOffset(), Err: errors.New("unexpected data after JSON value")}
+	}
+	return nil
+}
+
+// jsonBindError converts an encoding/json error into a BindError. offset is
+// used for errors that don't report their own position.
+func jsonBindError(err error, offset int64) *BindError {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &BindError{Offset: syntaxErr.Offset, Err: err}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &BindError{
+			Field:    typeErr.Field,
+			Value:    typeErr.Value,
+			Offset:   typeErr.Offset,
+			Expected: jsonTypeName(typeErr.Type.Kind().String()),
+			Err:      err,
+		}
+	}
+
+	// encoding/json reports unknown fields only as `json: unknown field "name"`
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &BindError{Field: strings.Trim(name, `"`), Offset: offset, Err: ErrUnknownField}
+	}
+
+	return &BindError{Offset: offset, Err: err}
+}
+
+// jsonTypeName names a Go kind the way a JSON client would understand it.
+func jsonTypeName(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"):
+		return "integer"
+	case strings.HasPrefix(kind, "float"):
+		return "number"
+	case kind == "bool":
+		return "boolean"
+	case kind == "slice", kind == "array":
+		return "array"
+	case kind == "struct", kind == "map":
+		return "object"
+	}
+	return kind
+}
+
+// exceedsDepth reports whether the JSON read from r nests objects or arrays
+// deeper than max, and the offset where the limit is first exceeded.
+func exceedsDepth(r io.Reader, max int) (int64, bool) {
+	reader, ok := r.(io.ByteReader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := int64(0); ; i++ {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, false
+		}
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > max {
+				return i, true
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+}
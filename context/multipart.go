@@ -0,0 +1,59 @@
+package context
+
+import (
+	"os"
+)
+
+// OnFinish registers fn to run once the request is done, after the response
+// has been written and all middleware has returned. Callbacks run in reverse
+// order of registration, like deferred calls.
+//
+// Example:
+//
+//	tmp, _ := os.CreateTemp("", "export-*.zip")
+//	c.OnFinish(func() { os.Remove(tmp.Name()) })
+func (c *Context) OnFinish(fn func()) {
+	c.finish = append(c.finish, fn)
+}
+
+// Finish runs the OnFinish callbacks and removes the temporary files of a
+// parsed multipart form and of a request body spilled to disk. The framework calls it at the end of every request;
+// call it yourself only for contexts created with New.
+func (c *Context) Finish() {
+	for i := len(c.finish) - 1; i >= 0; i-- {
+		c.finish[i]()
+	}
+	c.finish = nil
+	c.removeBodyFile()
+
+	// net/http only cleans up forms parsed on the original request, which
+	// misses requests replaced by SetContext
+	if c.Request != nil && c.Request.MultipartForm != nil {
+		c.Request.MultipartForm.RemoveAll()
+	}
+}
+
+// MultipartTempFiles reports how many uploaded files of the parsed multipart
+// form were spilled to disk and their total size. Files smaller than the
+// in-memory limit are not counted. Middleware such as Metrics use it to track
+// temporary disk usage.
+func (c *Context) MultipartTempFiles() (files int, size int64) {
+	if c.Request == nil || c.Request.MultipartForm == nil {
+		return 0, 0
+	}
+
+	for _, headers := range c.Request.MultipartForm.File {
+		for _, header := range headers {
+			file, err := header.Open()
+			if err != nil {
+				continue
+			}
+			if _, onDisk := file.(*os.File); onDisk {
+				files++
+				size += header.Size
+			}
+			file.Close()
+		}
+	}
+	return files, size
+}
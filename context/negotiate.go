@@ -0,0 +1,105 @@
+package context
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ProducesKey is the route metadata key under which Route.Produces stores the
+// media types a route can respond with.
+const ProducesKey = "kese.produces"
+
+// Negotiate picks the offered media type that best matches the request's
+// Accept header, honoring quality values and wildcards ("text/*", "*/*").
+// Without an Accept header the first offer is returned; if no offer is
+// acceptable it returns "". Called without offers, it negotiates between the
+// types the route declared with Route.Produces.
+//
+// Example:
+//
+//	switch c.Negotiate("application/json", "text/csv") {
+//	case "text/csv":
+//	    return c.Bytes(200, "text/csv", report.CSV())
+//	default:
+//	    return c.JSON(200, report)
+//	}
+func (c *Context) Negotiate(offers ...string) string {
+	if len(offers) == 0 {
+		offers, _ = c.RouteMeta(ProducesKey).([]string)
+		if len(offers) == 0 {
+			return ""
+		}
+	}
+
+	accept := c.Header("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(ranges, strings.ToLower(offer)); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses an Accept header into media ranges.
+func parseAccept(header string) []mediaRange {
+	parts := strings.Split(header, ",")
+	ranges := make([]mediaRange, 0, len(parts))
+	for _, part := range parts {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of offer under the most specific
+// matching media range, or 0 if no range matches.
+func acceptQuality(ranges []mediaRange, offer string) float64 {
+	offer, _, _ = strings.Cut(offer, ";")
+	offer = strings.TrimSpace(offer)
+	offerType, _, _ := strings.Cut(offer, "/")
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.mediaType == offer:
+			s = 2
+		case r.mediaType == offerType+"/*":
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
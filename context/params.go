@@ -0,0 +1,84 @@
+package context
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParamError is returned by the typed parameter accessors when a path parameter
+// is missing or cannot be converted. The default error handler maps it to 400.
+type ParamError struct {
+	// Name is the parameter name
+	Name string
+
+	// Value is the raw parameter value
+	Value string
+
+	// Type is the expected type (e.g. "int", "uuid")
+	Type string
+
+	// Err is the underlying conversion error, if any
+	Err error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid path parameter %q: %q is not a valid %s", e.Name, e.Value, e.Type)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// uuidPattern matches the canonical 8-4-4-4-12 hexadecimal UUID format.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ParamInt returns a path parameter converted to int.
+// Returning the error from a handler produces a 400 response.
+//
+// Example:
+//
+//	id, err := c.ParamInt("id")
+//	if err != nil {
+//	    return err
+//	}
+func (c *Context) ParamInt(key string) (int, error) {
+	value := c.Param(key)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ParamError{Name: key, Value: value, Type: "int", Err: err}
+	}
+	return n, nil
+}
+
+// ParamInt64 returns a path parameter converted to int64.
+func (c *Context) ParamInt64(key string) (int64, error) {
+	value := c.Param(key)
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &ParamError{Name: key, Value: value, Type: "int64", Err: err}
+	}
+	return n, nil
+}
+
+// ParamBool returns a path parameter converted to bool.
+// Accepts the values understood by strconv.ParseBool ("true", "false", "1", "0", ...).
+func (c *Context) ParamBool(key string) (bool, error) {
+	value := c.Param(key)
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &ParamError{Name: key, Value: value, Type: "bool", Err: err}
+	}
+	return b, nil
+}
+
+// ParamUUID returns a path parameter validated as a UUID in canonical form.
+// The result is lowercased so it can be compared and stored consistently.
+func (c *Context) ParamUUID(key string) (string, error) {
+	value := c.Param(key)
+	if !uuidPattern.MatchString(value) {
+		return "", &ParamError{Name: key, Value: value, Type: "uuid"}
+	}
+	return strings.ToLower(value), nil
+}
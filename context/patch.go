@@ -0,0 +1,404 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Content types for partial updates.
+const (
+	MIMEMergePatch = "application/merge-patch+json"
+	MIMEJSONPatch  = "application/json-patch+json"
+)
+
+// PatchError is returned when a patch document is malformed or cannot be applied.
+// The default error handler maps it to 422 Unprocessable Entity.
+type PatchError struct {
+	// Op is the JSON Patch operation that failed (empty for merge patches)
+	Op string
+
+	// Path is the JSON Pointer the operation targeted
+	Path string
+
+	// Reason describes the failure
+	Reason string
+}
+
+func (e *PatchError) Error() string {
+	if e.Op == "" {
+		return "invalid patch: " + e.Reason
+	}
+	return fmt.Sprintf("patch operation %q on %q failed: %s", e.Op, e.Path, e.Reason)
+}
+
+// ApplyPatch applies the request body to resource as a JSON Patch when the
+// Content-Type is application/json-patch+json, and as a JSON Merge Patch otherwise.
+// resource must be a pointer; it is only modified if the whole patch succeeds.
+//
+// Example:
+//
+//	todo := load(id)
+//	if err := c.ApplyPatch(todo); err != nil {
+//	    return err
+//	}
+func (c *Context) ApplyPatch(resource interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Header("Content-Type"))
+	if mediaType == MIMEJSONPatch {
+		return c.ApplyJSONPatch(resource)
+	}
+	return c.ApplyJSONMergePatch(resource)
+}
+
+// ApplyJSONMergePatch applies the request body to resource as an RFC 7396 JSON Merge Patch.
+// Fields present in the body replace those of resource, null removes them,
+// and absent fields are left untouched.
+func (c *Context) ApplyJSONMergePatch(resource interface{}) error {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return err
+	}
+
+	patch, err := decodeJSON(body)
+	if err != nil {
+		return &PatchError{Reason: "body is not valid JSON"}
+	}
+
+	return patchResource(resource, func(doc interface{}) (interface{}, error) {
+		return mergePatch(doc, patch), nil
+	})
+}
+
+// ApplyJSONPatch applies the request body to resource as an RFC 6902 JSON Patch.
+// The operations are applied atomically: if any fails, resource is unchanged.
+func (c *Context) ApplyJSONPatch(resource interface{}) error {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return err
+	}
+
+	var ops []patchOperation
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&ops); err != nil {
+		return &PatchError{Reason: "body must be a JSON array of operations"}
+	}
+
+	return patchResource(resource, func(doc interface{}) (interface{}, error) {
+		for _, op := range ops {
+			var err error
+			if doc, err = op.apply(doc); err != nil {
+				return nil, err
+			}
+		}
+		return doc, nil
+	})
+}
+
+// patchResource converts resource to a generic JSON document, transforms it,
+// and decodes the result into a fresh value that replaces resource on success.
+func patchResource(resource interface{}, transform func(interface{}) (interface{}, error)) error {
+	target := reflect.ValueOf(resource)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("patch target must be a non-nil pointer, got %T", resource)
+	}
+
+	original, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeJSON(original)
+	if err != nil {
+		return err
+	}
+
+	patched, err := transform(doc)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(patched)
+	if err != nil {
+		return err
+	}
+
+	fresh := reflect.New(target.Elem().Type())
+	if err := json.Unmarshal(data, fresh.Interface()); err != nil {
+		return &PatchError{Reason: err.Error()}
+	}
+	target.Elem().Set(fresh.Elem())
+	return nil
+}
+
+// decodeJSON decodes data into a generic document, keeping numbers exact.
+func decodeJSON(data []byte) (interface{}, error) {
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// mergePatch implements the RFC 7396 MergePatch algorithm.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatch(targetObj[key], value)
+		}
+	}
+	return targetObj
+}
+
+// patchOperation is a single RFC 6902 operation.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// apply applies the operation to doc and returns the new document.
+func (op patchOperation) apply(doc interface{}) (interface{}, error) {
+	fail := func(reason string) error {
+		return &PatchError{Op: op.Op, Path: op.Path, Reason: reason}
+	}
+
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, fail(err.Error())
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fail("missing value")
+		}
+		value, err := decodeJSON(op.Value)
+		if err != nil {
+			return nil, fail("invalid value")
+		}
+
+		switch op.Op {
+		case "add":
+			doc, err = addValue(doc, path, value)
+		case "replace":
+			if doc, _, err = removeValue(doc, path); err == nil {
+				doc, err = addValue(doc, path, value)
+			}
+		case "test":
+			var current interface{}
+			if current, err = getValue(doc, path); err == nil && !reflect.DeepEqual(current, value) {
+				err = fmt.Errorf("value does not match")
+			}
+		}
+		if err != nil {
+			return nil, fail(err.Error())
+		}
+		return doc, nil
+
+	case "remove":
+		doc, _, err = removeValue(doc, path)
+		if err != nil {
+			return nil, fail(err.Error())
+		}
+		return doc, nil
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fail(err.Error())
+		}
+
+		var value interface{}
+		if op.Op == "move" {
+			doc, value, err = removeValue(doc, from)
+		} else {
+			value, err = getValue(doc, from)
+			if err == nil {
+				value, err = deepCopy(value)
+			}
+		}
+		if err == nil {
+			doc, err = addValue(doc, path, value)
+		}
+		if err != nil {
+			return nil, fail(err.Error())
+		}
+		return doc, nil
+	}
+
+	return nil, fail("unknown operation")
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON pointer must start with \"/\"")
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array reference token; "-" refers to the end when allowEnd is set.
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	max := length - 1
+	if allowEnd {
+		max = length
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+// getValue returns the value at path.
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	current := doc
+	for _, token := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, exists := node[token]
+			if !exists {
+				return nil, fmt.Errorf("path not found")
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	}
+	return current, nil
+}
+
+// addValue sets value at path, inserting into arrays, and returns the new document.
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return doc, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node, nil)
+		copy(node[index+1:], node[index:])
+		node[index] = value
+		return replaceParent(doc, path[:len(path)-1], node)
+	}
+	return nil, fmt.Errorf("parent is not a container")
+}
+
+// removeValue removes the value at path and returns the new document and removed value.
+func removeValue(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, exists := node[last]
+		if !exists {
+			return nil, nil, fmt.Errorf("path not found")
+		}
+		delete(node, last)
+		return doc, value, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[index]
+		node = append(node[:index:index], node[index+1:]...)
+		doc, err = replaceParent(doc, path[:len(path)-1], node)
+		return doc, value, err
+	}
+	return nil, nil, fmt.Errorf("parent is not a container")
+}
+
+// replaceParent stores a resized array back at path, since slices may be reallocated.
+func replaceParent(doc interface{}, path []string, array []interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return array, nil
+	}
+
+	grandparent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch node := grandparent.(type) {
+	case map[string]interface{}:
+		node[last] = array
+	case []interface{}:
+		index, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = array
+	}
+	return doc, nil
+}
+
+// deepCopy duplicates a generic JSON value so copies do not share containers.
+func deepCopy(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(data)
+}
@@ -0,0 +1,75 @@
+package context
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+type patchTodo struct {
+	Title string   `json:"title"`
+	Done  bool     `json:"done"`
+	Tags  []string `json:"tags,omitempty"`
+	Note  string   `json:"note,omitempty"`
+}
+
+func newPatchContext(contentType, body string) *Context {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("PATCH", "/todos/1", bytes.NewBufferString(body))
+	r.Header.Set("Content-Type", contentType)
+	return New(w, r, defaultTestLimit)
+}
+
+func TestApplyJSONMergePatch(t *testing.T) {
+	todo := patchTodo{Title: "write tests", Tags: []string{"dev"}, Note: "soon"}
+
+	ctx := newPatchContext(MIMEMergePatch, `{"done":true,"note":null}`)
+	if err := ctx.ApplyPatch(&todo); err != nil {
+		t.Fatalf("ApplyPatch error: %v", err)
+	}
+
+	if !todo.Done || todo.Note != "" || todo.Title != "write tests" || len(todo.Tags) != 1 {
+		t.Errorf("Unexpected result: %+v", todo)
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	todo := patchTodo{Title: "write tests", Tags: []string{"dev"}}
+
+	ctx := newPatchContext(MIMEJSONPatch, `[
+		{"op":"test","path":"/title","value":"write tests"},
+		{"op":"replace","path":"/done","value":true},
+		{"op":"add","path":"/tags/0","value":"urgent"},
+		{"op":"add","path":"/tags/-","value":"qa"},
+		{"op":"copy","from":"/title","path":"/note"}
+	]`)
+	if err := ctx.ApplyPatch(&todo); err != nil {
+		t.Fatalf("ApplyPatch error: %v", err)
+	}
+
+	if !todo.Done || todo.Note != "write tests" {
+		t.Errorf("Unexpected result: %+v", todo)
+	}
+	if len(todo.Tags) != 3 || todo.Tags[0] != "urgent" || todo.Tags[2] != "qa" {
+		t.Errorf("Unexpected tags: %v", todo.Tags)
+	}
+}
+
+func TestApplyJSONPatchAtomic(t *testing.T) {
+	todo := patchTodo{Title: "original"}
+
+	ctx := newPatchContext(MIMEJSONPatch, `[
+		{"op":"replace","path":"/title","value":"changed"},
+		{"op":"remove","path":"/missing"}
+	]`)
+	err := ctx.ApplyJSONPatch(&todo)
+
+	var patchErr *PatchError
+	if !errors.As(err, &patchErr) || patchErr.Op != "remove" {
+		t.Fatalf("Expected PatchError for remove, got %v", err)
+	}
+	if todo.Title != "original" {
+		t.Errorf("Resource should be unchanged after a failed patch, got %+v", todo)
+	}
+}
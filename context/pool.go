@@ -0,0 +1,46 @@
+package context
+
+import (
+	"net/http"
+	"sync"
+)
+
+// pool recycles Context objects between requests to avoid per-request allocations.
+var pool = sync.Pool{
+	New: func() interface{} {
+		return &Context{}
+	},
+}
+
+// Acquire returns a Context from the pool, initialized for the given request.
+// The writer is wrapped in a ResponseWriter so StatusCode and ResponseSize
+// reflect what was actually sent.
+// Contexts obtained with Acquire must be returned with Release once the request
+// is done. The framework does this automatically, so handlers MUST NOT keep a
+// reference to the Context after they return: not in a goroutine, not in a
+// queued job, not as the context.Context of a background call. The next
+// request reuses it. Pass c.Request.Context() to such work instead, or
+// context.WithoutCancel(c.Request.Context()) if it must outlive the request.
+func Acquire(w http.ResponseWriter, r *http.Request, maxBodySize int64) *Context {
+	c := pool.Get().(*Context)
+	c.Request = r
+	c.writer.Reset(w)
+	c.response = &c.writer
+	c.Writer = c.response
+	c.statusCode = http.StatusOK
+	c.ctx = r.Context()
+	c.MaxBodySize = maxBodySize
+	return c
+}
+
+// Release resets the Context and returns it to the pool.
+// The Context must not be used afterwards. As a context.Context, a released
+// Context behaves like context.Background() rather than panicking, but it
+// may already belong to another request.
+func Release(c *Context) {
+	values := c.values
+	clear(values)
+
+	*c = Context{values: values}
+	pool.Put(c)
+}
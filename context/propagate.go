@@ -0,0 +1,50 @@
+package context
+
+import (
+	"context"
+	"slices"
+)
+
+// ValueKey is the type of the request context keys under which propagated
+// values are stored, see Propagate.
+type ValueKey string
+
+// RequestIDKey propagates the request ID set with SetRequestID.
+const RequestIDKey = "request_id"
+
+// Propagate mirrors the values of the given keys, now and whenever they are
+// set later with Set, into the request context under ValueKey(key). Libraries
+// that only receive c.Request.Context(), such as SQL drivers, HTTP clients
+// or loggers, can then read them with ValueFrom. Use RequestIDKey for the
+// request ID. App.PropagateValues enables it for every request.
+//
+// Example:
+//
+//	c.Propagate(context.RequestIDKey, "userID")
+//
+//	// in a database hook that only sees the stdlib context
+//	userID, _ := context.ValueFrom(ctx, "userID").(string)
+func (c *Context) Propagate(keys ...string) {
+	for _, key := range keys {
+		if c.propagates(key) {
+			continue
+		}
+		c.propagate = append(c.propagate, key)
+
+		if key == RequestIDKey && c.requestID != "" {
+			c.WithValue(ValueKey(key), c.requestID)
+		} else if value, exists := c.values[key]; exists {
+			c.WithValue(ValueKey(key), value)
+		}
+	}
+}
+
+// propagates reports whether key is mirrored into the request context.
+func (c *Context) propagates(key string) bool {
+	return len(c.propagate) > 0 && slices.Contains(c.propagate, key)
+}
+
+// ValueFrom returns the value propagated under key in ctx, or nil.
+func ValueFrom(ctx context.Context, key string) interface{} {
+	return ctx.Value(ValueKey(key))
+}
@@ -0,0 +1,74 @@
+package context
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JedizLaPulga/kese/cache"
+	"github.com/JedizLaPulga/kese/qrcode"
+)
+
+const (
+	// qrMaxSize bounds the image size so a query parameter cannot make the
+	// server render huge images.
+	qrMaxSize = 2048
+
+	qrCacheSize = 256
+	qrCacheTTL  = 10 * time.Minute
+)
+
+var (
+	qrCacheOnce sync.Once
+	qrCache     *cache.MemoryStore
+)
+
+// qrCodeCache returns the cache of rendered QR codes, created on first use
+// so applications not using c.QRCode don't run its cleanup goroutine.
+func qrCodeCache() *cache.MemoryStore {
+	qrCacheOnce.Do(func() {
+		qrCache = cache.NewMemoryStoreWithSize(qrCacheSize)
+	})
+	return qrCache
+}
+
+// QRCode sends content encoded as a QR code PNG of about size pixels square,
+// at medium error correction. Rendered images are cached in memory for a few
+// minutes, so endpoints serving the same link or ticket repeatedly don't
+// re-encode it. Content too long for a QR code gets 400 Bad Request.
+//
+// The response is marked Cache-Control: no-store unless the handler set
+// its own, since codes often carry secrets such as 2FA seeds.
+//
+// Example:
+//
+//	app.GET("/2fa/qr", func(c *kese.Context) error {
+//	    uri := "otpauth://totp/Example:" + user.Email + "?secret=" + secret + "&issuer=Example"
+//	    return c.QRCode(200, uri, 256)
+//	})
+func (c *Context) QRCode(status int, content string, size int) error {
+	if size <= 0 {
+		size = 256
+	} else if size > qrMaxSize {
+		size = qrMaxSize
+	}
+
+	key := strconv.Itoa(size) + ":" + content
+	png, ok := qrCodeCache().Get(key)
+	if !ok {
+		var err error
+		png, err = qrcode.PNG(content, qrcode.Medium, size)
+		if err == qrcode.ErrTooLong {
+			return c.BadRequest("content too long for a QR code")
+		}
+		if err != nil {
+			return err
+		}
+		qrCodeCache().Set(key, png, qrCacheTTL)
+	}
+
+	if c.Writer.Header().Get("Cache-Control") == "" {
+		c.SetHeader("Cache-Control", "no-store")
+	}
+	return c.Bytes(status, "image/png", png)
+}
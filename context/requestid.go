@@ -0,0 +1,68 @@
+package context
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// HeaderRequestID is the header carrying the request ID between services.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs accepted from clients and upstream proxies.
+const maxRequestIDLength = 128
+
+// RequestID returns the ID of the request, as set by the RequestID middleware
+// or SetRequestID. Without one, it returns a valid X-Request-ID header sent by
+// the client or an upstream proxy, or "" if there is none. Include it in
+// errors shown to users and forward it to downstream services so reports can
+// be correlated with logs.
+//
+// Example:
+//
+//	req, _ := http.NewRequestWithContext(c, "GET", inventoryURL, nil)
+//	req.Header.Set(context.HeaderRequestID, c.RequestID())
+func (c *Context) RequestID() string {
+	if c.requestID != "" {
+		return c.requestID
+	}
+	if id := c.Request.Header.Get(HeaderRequestID); ValidRequestID(id) {
+		return id
+	}
+	return ""
+}
+
+// SetRequestID sets the ID of the request and echoes it in the X-Request-ID
+// response header. If RequestIDKey is propagated, the ID is also stored in the
+// request context.
+func (c *Context) SetRequestID(id string) {
+	c.requestID = id
+	c.SetHeader(HeaderRequestID, id)
+	if c.propagates(RequestIDKey) {
+		c.WithValue(ValueKey(RequestIDKey), id)
+	}
+}
+
+// requestIDCounter numbers the IDs generated by NewRequestID.
+var requestIDCounter atomic.Uint64
+
+// NewRequestID generates a request ID unique within the process, made of the
+// current Unix time and a counter.
+func NewRequestID() string {
+	count := requestIDCounter.Add(1)
+	return strconv.FormatInt(time.Now().Unix(), 10) + "-" + strconv.FormatUint(count, 10)
+}
+
+// ValidRequestID reports whether id is safe to accept from a client: non-empty,
+// at most 128 characters, and made only of printable ASCII without spaces.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package context
+
+import (
+	"errors"
+	"net/http"
+)
+
+// MIMENDJSON is the content type of newline-delimited JSON streams.
+const MIMENDJSON = "application/x-ndjson"
+
+// StreamEncoder writes newline-delimited JSON records, flushing each one to the client.
+type StreamEncoder struct {
+	c *Context
+}
+
+// NDJSON starts a newline-delimited JSON response and returns an encoder for its records.
+// Each Encode call writes one line and flushes it, so large datasets can be exported
+// without holding them in memory. Encode returns ErrClientClosed once the client is gone.
+//
+// Example:
+//
+//	stream := c.NDJSON(http.StatusOK)
+//	for rows.Next() {
+//	    var order Order
+//	    rows.Scan(&order.ID, &order.Total)
+//	    if err := stream.Encode(order); err != nil {
+//	        return err
+//	    }
+//	}
+//	return rows.Err()
+func (c *Context) NDJSON(status int) *StreamEncoder {
+	c.SetHeader("Content-Type", MIMENDJSON)
+	c.SetHeader("X-Content-Type-Options", "nosniff")
+	c.statusCode = status
+	c.Writer.WriteHeader(status)
+	c.SetWritten()
+
+	return &StreamEncoder{c: c}
+}
+
+// Encode writes v as one JSON line and flushes it.
+func (s *StreamEncoder) Encode(v interface{}) error {
+	if err := s.c.clientGone(); err != nil {
+		return err
+	}
+	if err := encodeJSON(s.c.bodyWriter(), v, ""); err != nil {
+		return err
+	}
+	return s.c.flush()
+}
+
+// JSONStream writes every value received from ch as a line of newline-delimited
+// JSON until ch is closed. It returns ErrClientClosed if the client disconnects
+// first; producers should also watch c.Done() so they stop sending.
+//
+// Example:
+//
+//	ch := make(chan interface{})
+//	go func() {
+//	    defer close(ch)
+//	    for _, e := range events {
+//	        select {
+//	        case ch <- e:
+//	        case <-c.Done():
+//	            return
+//	        }
+//	    }
+//	}()
+//	return c.JSONStream(http.StatusOK, ch)
+func (c *Context) JSONStream(status int, ch <-chan interface{}) error {
+	if err := c.clientGone(); err != nil {
+		return err
+	}
+
+	stream := c.NDJSON(status)
+	for {
+		select {
+		case <-c.ctx.Done():
+			return ErrClientClosed
+		case v, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Encode(v); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// flush sends buffered response data to the client. Writers that cannot flush
+// are ignored; the data is sent when the response completes.
+func (c *Context) flush() error {
+	err := http.NewResponseController(c.Writer).Flush()
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		if c.ctx.Err() != nil {
+			return ErrClientClosed
+		}
+		return err
+	}
+	return nil
+}
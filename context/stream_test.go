@@ -0,0 +1,63 @@
+package context
+
+import (
+	stdcontext "context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNDJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/export", nil), defaultTestLimit)
+
+	stream := ctx.NDJSON(200)
+	for i := 1; i <= 3; i++ {
+		if err := stream.Encode(map[string]int{"id": i}); err != nil {
+			t.Fatalf("Encode error: %v", err)
+		}
+	}
+
+	if w.Header().Get("Content-Type") != MIMENDJSON {
+		t.Errorf("Expected %s, got %q", MIMENDJSON, w.Header().Get("Content-Type"))
+	}
+	if !w.Flushed {
+		t.Error("Expected records to be flushed")
+	}
+	expected := "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, w.Body.String())
+	}
+}
+
+func TestJSONStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := New(w, httptest.NewRequest("GET", "/events", nil), defaultTestLimit)
+
+	ch := make(chan interface{}, 2)
+	ch <- "a"
+	ch <- "b"
+	close(ch)
+
+	if err := ctx.JSONStream(200, ch); err != nil {
+		t.Fatalf("JSONStream error: %v", err)
+	}
+	if w.Body.String() != "\"a\"\n\"b\"\n" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+}
+
+func TestJSONStreamClientClosed(t *testing.T) {
+	reqCtx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	r := httptest.NewRequest("GET", "/events", nil).WithContext(reqCtx)
+	ctx := New(httptest.NewRecorder(), r, defaultTestLimit)
+
+	ch := make(chan interface{})
+	go func() {
+		ch <- "first"
+		cancel()
+	}()
+
+	if err := ctx.JSONStream(200, ch); err != ErrClientClosed {
+		t.Errorf("Expected ErrClientClosed, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package context
+
+import "time"
+
+// Timing is a backend metric reported in the Server-Timing response header.
+type Timing struct {
+	Name        string
+	Duration    time.Duration
+	Description string
+}
+
+// ServerTiming records a metric for the Server-Timing response header, which
+// browser devtools show in the network panel. Metrics are only emitted when
+// App.ServerTiming is enabled, and must be recorded before the response is written.
+//
+// Example:
+//
+//	start := time.Now()
+//	users, err := db.ListUsers(c)
+//	c.ServerTiming("db", time.Since(start), "List users")
+func (c *Context) ServerTiming(name string, dur time.Duration, desc string) {
+	c.timings = append(c.timings, Timing{Name: name, Duration: dur, Description: desc})
+}
+
+// Timings returns the metrics recorded with ServerTiming.
+func (c *Context) Timings() []Timing {
+	return c.timings
+}
@@ -0,0 +1,240 @@
+package context
+
+import (
+	"strconv"
+	"strings"
+)
+
+// UserAgent is the client software of a request, parsed from its User-Agent
+// header. Parsing is heuristic and covers the common browsers, operating
+// systems, crawlers and HTTP libraries; fields it cannot determine are empty.
+// Never rely on it for security: clients can send any User-Agent.
+type UserAgent struct {
+	// Raw is the User-Agent header
+	Raw string
+
+	// Browser is the client's name, e.g. "Chrome", "Firefox", "Safari",
+	// "Edge", "Googlebot" or "curl"
+	Browser string
+
+	// Version is the client's version, e.g. "120.0.6099.109"
+	Version string
+
+	// OS is the operating system, e.g. "Windows", "macOS", "iOS", "Android",
+	// "Linux" or "ChromeOS"
+	OS string
+
+	// OSVersion is the operating system's version, e.g. "14" or "10.15.7"
+	OSVersion string
+
+	// Mobile reports a phone, Tablet a tablet
+	Mobile bool
+	Tablet bool
+
+	// Bot reports a crawler, monitoring service or HTTP library rather than
+	// a person's browser
+	Bot bool
+}
+
+// MajorVersion returns the major version of the client, e.g. 120 for Chrome
+// 120.0.6099.109, or 0 if it is unknown.
+func (ua UserAgent) MajorVersion() int {
+	major, _, _ := strings.Cut(ua.Version, ".")
+	n, _ := strconv.Atoi(major)
+	return n
+}
+
+// String returns a short description for logs, e.g. "Chrome 120 (Windows 10)".
+func (ua UserAgent) String() string {
+	if ua.Browser == "" {
+		return ua.OS
+	}
+	s := ua.Browser
+	if major := ua.MajorVersion(); major > 0 {
+		s += " " + strconv.Itoa(major)
+	}
+	if ua.OS != "" {
+		s += " (" + strings.TrimSpace(ua.OS+" "+ua.OSVersion) + ")"
+	}
+	return s
+}
+
+// UserAgent returns the parsed User-Agent header of the request. It is parsed
+// once per request.
+//
+// Example:
+//
+//	// Point clients too old for TLS 1.2 at an upgrade instead of failing obscurely
+//	if ua := c.UserAgent(); ua.Browser == "Internet Explorer" && ua.MajorVersion() < 11 {
+//	    return c.String(426, "Please update your browser to use this site.")
+//	}
+func (c *Context) UserAgent() UserAgent {
+	if c.userAgent == nil {
+		ua := ParseUserAgent(c.Request.Header.Get("User-Agent"))
+		c.userAgent = &ua
+	}
+	return *c.userAgent
+}
+
+// userAgentClient maps a product token of the User-Agent header to a client name.
+type userAgentClient struct {
+	token string
+	name  string
+}
+
+// userAgentBrowsers are checked in order: most browsers also name the engines
+// they are compatible with, e.g. Edge claims to be Chrome and Safari.
+var userAgentBrowsers = []userAgentClient{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"Edge/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+	{"MSIE ", "Internet Explorer"},
+}
+
+// userAgentLibraries are HTTP clients used by scripts and services.
+var userAgentLibraries = []string{
+	"curl/", "Wget/", "python-requests/", "Python-urllib/", "Go-http-client/",
+	"okhttp/", "axios/", "node-fetch/", "Java/", "Apache-HttpClient/",
+	"libwww-perl/", "PostmanRuntime/", "insomnia/",
+}
+
+// userAgentBotWords mark crawlers and monitoring services.
+var userAgentBotWords = []string{"bot", "crawl", "spider", "slurp", "monitor", "preview", "facebookexternalhit", "headless"}
+
+// ParseUserAgent parses a User-Agent header, see Context.UserAgent.
+func ParseUserAgent(header string) UserAgent {
+	ua := UserAgent{Raw: header}
+	if header == "" {
+		return ua
+	}
+	lower := strings.ToLower(header)
+
+	for _, word := range userAgentBotWords {
+		if strings.Contains(lower, word) {
+			ua.Bot = true
+			ua.Browser, ua.Version = botProduct(header)
+			break
+		}
+	}
+	if ua.Browser == "" {
+		for _, library := range userAgentLibraries {
+			if strings.HasPrefix(header, library) {
+				ua.Bot = true
+				ua.Browser, ua.Version = library[:len(library)-1], productVersion(header, library)
+				break
+			}
+		}
+	}
+	if ua.Browser == "" {
+		for _, browser := range userAgentBrowsers {
+			if browser.name == "Safari" && !strings.Contains(header, "Safari/") {
+				continue
+			}
+			if version := productVersion(header, browser.token); version != "" {
+				ua.Browser, ua.Version = browser.name, version
+				break
+			}
+		}
+		if ua.Browser == "" && strings.Contains(header, "Trident/") {
+			ua.Browser, ua.Version = "Internet Explorer", productVersion(header, "rv:")
+		}
+	}
+
+	switch {
+	case strings.Contains(header, "iPad"):
+		ua.OS, ua.OSVersion, ua.Tablet = "iOS", dotted(productVersion(header, "CPU OS ")), true
+	case strings.Contains(header, "iPhone"):
+		ua.OS, ua.OSVersion, ua.Mobile = "iOS", dotted(productVersion(header, "iPhone OS ")), true
+	case strings.Contains(header, "Android"):
+		ua.OS, ua.OSVersion = "Android", productVersion(header, "Android ")
+		ua.Mobile = strings.Contains(header, "Mobile")
+		ua.Tablet = !ua.Mobile
+	case strings.Contains(header, "Windows"):
+		ua.OS, ua.OSVersion = "Windows", windowsVersion(productVersion(header, "Windows NT "))
+	case strings.Contains(header, "Mac OS X"):
+		ua.OS, ua.OSVersion = "macOS", dotted(productVersion(header, "Mac OS X "))
+	case strings.Contains(header, "CrOS"):
+		ua.OS = "ChromeOS"
+	case strings.Contains(header, "Linux"):
+		ua.OS = "Linux"
+	}
+	if !ua.Tablet && !ua.Mobile && strings.Contains(header, "Mobi") {
+		ua.Mobile = true
+	}
+	return ua
+}
+
+// productVersion returns the version following token in header, e.g.
+// "120.0" for token "Chrome/", or "" if header does not contain token.
+func productVersion(header, token string) string {
+	i := strings.Index(header, token)
+	if i < 0 {
+		return ""
+	}
+	version := header[i+len(token):]
+	if end := strings.IndexAny(version, " ;)("); end >= 0 {
+		version = version[:end]
+	}
+	return version
+}
+
+// botProduct returns the name and version of the product token that marks
+// header as a bot, e.g. "Googlebot" and "2.1" in
+// "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)".
+func botProduct(header string) (string, string) {
+	fields := strings.FieldsFunc(header, func(r rune) bool {
+		return r == ' ' || r == ';' || r == '(' || r == ')'
+	})
+	for _, field := range fields {
+		name, version, ok := strings.Cut(field, "/")
+		if !ok || name == "" || strings.HasPrefix(field, "+http") || strings.Contains(name, ".") {
+			continue
+		}
+		lower := strings.ToLower(name)
+		for _, word := range userAgentBotWords {
+			if strings.Contains(lower, word) {
+				return name, version
+			}
+		}
+	}
+	// Unnamed bots are reported by their first product, e.g. "Mozilla"
+	if len(fields) == 0 {
+		return "", ""
+	}
+	name, version, _ := strings.Cut(fields[0], "/")
+	return name, version
+}
+
+// dotted converts Apple's "17_1_2" versions to "17.1.2".
+func dotted(version string) string {
+	return strings.ReplaceAll(version, "_", ".")
+}
+
+// windowsVersion maps a Windows NT version to its marketing version.
+// Windows 11 reports NT 10.0 like Windows 10.
+func windowsVersion(nt string) string {
+	switch nt {
+	case "10.0":
+		return "10"
+	case "6.3":
+		return "8.1"
+	case "6.2":
+		return "8"
+	case "6.1":
+		return "7"
+	case "6.0":
+		return "Vista"
+	case "5.1", "5.2":
+		return "XP"
+	}
+	return nt
+}
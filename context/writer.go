@@ -0,0 +1,8 @@
+package context
+
+import "github.com/JedizLaPulga/kese/rw"
+
+// ResponseWriter wraps an http.ResponseWriter and records the status code and
+// number of body bytes written, including writes made directly to c.Writer or
+// by helpers such as http.ServeFile.
+type ResponseWriter = rw.Recorder
@@ -0,0 +1,90 @@
+package kese
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// DeprecationKey is the route metadata key under which Route.Deprecated stores its *Deprecation.
+const DeprecationKey = "kese.deprecation"
+
+// Deprecation describes a route scheduled for removal.
+type Deprecation struct {
+	// Since is when the route was deprecated (default: when Deprecated was called)
+	Since time.Time
+
+	// Sunset is when the route is expected to stop responding. Zero means no date yet.
+	Sunset time.Time
+
+	// Link points to documentation about the replacement (optional)
+	Link string
+}
+
+// Deprecated marks the route as deprecated. Every response then carries the
+// Deprecation (RFC 9745), Sunset (RFC 8594) and, if link is set, a
+// Link rel="deprecation" header, and each call is logged with the caller's
+// identity so remaining clients can be contacted. middleware.Metrics counts
+// calls to deprecated routes as kese_deprecated_requests_total.
+//
+// Example:
+//
+//	app.GET("/v1/users", listUsersV1).
+//	    Deprecated(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), "https://example.com/docs/v2-migration")
+func (r *Route) Deprecated(sunset time.Time, link string) *Route {
+	return r.Set(DeprecationKey, &Deprecation{
+		Since:  time.Now(),
+		Sunset: sunset,
+		Link:   link,
+	})
+}
+
+// serveDeprecated runs next with the deprecation headers of the route set and
+// logs the call afterwards, once authentication middleware has identified the caller.
+// Routes that are not deprecated are served unchanged.
+func (a *App) serveDeprecated(c *context.Context, next HandlerFunc) error {
+	deprecation, ok := c.RouteMeta(DeprecationKey).(*Deprecation)
+	if !ok {
+		return next(c)
+	}
+
+	header := c.Writer.Header()
+	header.Set("Deprecation", fmt.Sprintf("@%d", deprecation.Since.Unix()))
+	if !deprecation.Sunset.IsZero() {
+		header.Set("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecation.Link != "" {
+		header.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", deprecation.Link))
+	}
+
+	err := next(c)
+
+	fields := []interface{}{
+		"method", c.Method(),
+		"route", c.RoutePath(),
+		"caller", callerIdentity(c),
+		"user_agent", c.Header("User-Agent"),
+	}
+	if !deprecation.Sunset.IsZero() {
+		fields = append(fields, "sunset", deprecation.Sunset.Format("2006-01-02"))
+	}
+	a.Logger.Warn("Deprecated route called", fields...)
+	return err
+}
+
+// callerIdentity identifies the client of a request: the authenticated user ID
+// set by the JWT middleware if present, otherwise the remote IP address.
+func callerIdentity(c *context.Context) string {
+	if userID, ok := c.Get("userID").(string); ok && userID != "" {
+		return "user:" + userID
+	}
+
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		return "ip:" + c.Request.RemoteAddr
+	}
+	return "ip:" + host
+}
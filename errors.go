@@ -2,7 +2,10 @@ package kese
 
 import (
 	"errors"
-	"fmt"
+	"net/http"
+
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/validate"
 )
 
 // ErrorHandler is a function that handles errors returned by handlers.
@@ -13,6 +16,20 @@ type ErrorHandler func(err error) (int, interface{})
 // It does not expose internal error details to clients for security reasons.
 // The actual error is logged by the framework in kese.go ServeHTTP.
 func DefaultErrorHandler(err error) (int, interface{}) {
+	// Problems returned by handlers are rendered as application/problem+json
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return problemStatus(problem), problem
+	}
+
+	// HTTP errors carry their own status and client-facing message
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code, map[string]string{
+			"error": httpErr.Message,
+		}
+	}
+
 	// Check for common error types
 	var validationErr *ValidationError
 	if errors.As(err, &validationErr) {
@@ -22,6 +39,51 @@ func DefaultErrorHandler(err error) (int, interface{}) {
 		}
 	}
 
+	var paramErr *context.ParamError
+	if errors.As(err, &paramErr) {
+		return 400, map[string]interface{}{
+			"error": paramErr.Error(),
+			"param": paramErr.Name,
+		}
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return 413, map[string]string{
+			"error": "Request body too large",
+		}
+	}
+
+	if errors.Is(err, context.ErrUnsupportedMediaType) {
+		return 415, map[string]string{
+			"error": err.Error(),
+		}
+	}
+
+	var bindErr *context.BindError
+	if errors.As(err, &bindErr) {
+		response := map[string]interface{}{
+			"error": bindErr.Error(),
+		}
+		if bindErr.Field != "" {
+			response["field"] = bindErr.Field
+		}
+		if bindErr.Expected != "" {
+			response["expected"] = bindErr.Expected
+		}
+		if bindErr.Offset > 0 {
+			response["offset"] = bindErr.Offset
+		}
+		return 400, response
+	}
+
+	var patchErr *context.PatchError
+	if errors.As(err, &patchErr) {
+		return 422, map[string]string{
+			"error": patchErr.Error(),
+		}
+	}
+
 	// Default to 500 Internal Server Error
 	// Don't expose internal error details to clients in production
 	return 500, map[string]string{
@@ -30,27 +92,11 @@ func DefaultErrorHandler(err error) (int, interface{}) {
 }
 
 // ValidationError represents validation errors for struct fields.
-type ValidationError struct {
-	Errors map[string]string
-}
-
-func (v *ValidationError) Error() string {
-	return fmt.Sprintf("validation failed: %d errors", len(v.Errors))
-}
+// It is an alias of validate.ValidationError so errors produced by the
+// validate package and by c.BindAndValidate are handled here directly.
+type ValidationError = validate.ValidationError
 
 // NewValidationError creates a new validation error.
 func NewValidationError() *ValidationError {
-	return &ValidationError{
-		Errors: make(map[string]string),
-	}
-}
-
-// Add adds a field error to the validation error.
-func (v *ValidationError) Add(field, message string) {
-	v.Errors[field] = message
-}
-
-// HasErrors returns true if there are any validation errors.
-func (v *ValidationError) HasErrors() bool {
-	return len(v.Errors) > 0
+	return validate.NewValidationError()
 }
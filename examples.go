@@ -0,0 +1,139 @@
+package kese
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+)
+
+// Example is a documented request/response pair attached to a route with Route.Example.
+type Example struct {
+	// Name identifies the example in listings and verification errors
+	Name string
+
+	// Path is the concrete request path, including query string.
+	// Default: the route path (required for routes with parameters).
+	Path string
+
+	// Headers are added to the request
+	Headers map[string]string
+
+	// Request is the request body. Strings and byte slices are sent as-is;
+	// other values are encoded as JSON with Content-Type application/json.
+	Request interface{}
+
+	// Status is the expected response status. Default: 200
+	Status int
+
+	// Response is the expected JSON response body. If nil, the body is not checked.
+	Response interface{}
+}
+
+// VerifyExamples replays every documented route example against the app and
+// returns one error per example whose status or JSON body does not match.
+// It gives documented routes happy-path tests for free.
+//
+// Example:
+//
+//	func TestExamples(t *testing.T) {
+//	    for _, err := range newApp().VerifyExamples() {
+//	        t.Error(err)
+//	    }
+//	}
+func (a *App) VerifyExamples() []error {
+	var errs []error
+	for _, route := range a.Routes() {
+		for _, example := range route.Examples {
+			if err := a.verifyExample(route, example); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s example %q: %w", route.Method, route.Path, example.Name, err))
+			}
+		}
+	}
+	return errs
+}
+
+// verifyExample runs a single example through ServeHTTP and checks the response.
+func (a *App) verifyExample(route *Route, example Example) error {
+	path := example.Path
+	if path == "" {
+		if strings.ContainsAny(route.Path, ":*") {
+			return fmt.Errorf("route has parameters; set Example.Path")
+		}
+		path = route.Path
+	}
+
+	body, contentType, err := exampleBody(example.Request)
+	if err != nil {
+		return err
+	}
+
+	req := httptest.NewRequest(route.Method, path, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, value := range example.Headers {
+		req.Header.Set(key, value)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	status := example.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if w.Code != status {
+		return fmt.Errorf("expected status %d, got %d: %s", status, w.Code, w.Body.String())
+	}
+
+	if example.Response == nil {
+		return nil
+	}
+
+	expected, err := normalizeJSON(example.Response)
+	if err != nil {
+		return err
+	}
+	var actual interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &actual); err != nil {
+		return fmt.Errorf("response is not JSON: %s", w.Body.String())
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		return fmt.Errorf("unexpected response body: %s", w.Body.String())
+	}
+	return nil
+}
+
+// exampleBody encodes an example request body and returns its content type.
+func exampleBody(body interface{}) (io.Reader, string, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		return strings.NewReader(b), "", nil
+	case []byte:
+		return bytes.NewReader(b), "", nil
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(data), "application/json", nil
+}
+
+// normalizeJSON round-trips a value through JSON so it compares equal to a decoded body.
+func normalizeJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
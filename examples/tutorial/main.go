@@ -1,15 +1,13 @@
 package main
 
 import (
-	"strconv"
-
 	"github.com/JedizLaPulga/kese"
 	"github.com/JedizLaPulga/kese/context"
 	"github.com/JedizLaPulga/kese/middleware"
 )
 
 // Global store
-var store = NewTodoStore()
+var todoStore = NewTodoStore()
 
 func main() {
 	app := kese.New()
@@ -48,7 +46,7 @@ func handleHome(c *context.Context) error {
 
 // GET /todos - List all todos
 func handleGetTodos(c *context.Context) error {
-	todos := store.GetAll()
+	todos := todoStore.GetAll()
 
 	// Filter by completed status if provided
 	if completedStr := c.Query("completed"); completedStr != "" {
@@ -70,14 +68,12 @@ func handleGetTodos(c *context.Context) error {
 
 // GET /todos/:id - Get a specific todo
 func handleGetTodo(c *context.Context) error {
-	id, err := strconv.Atoi(c.Param("id"))
+	id, err := c.ParamInt("id")
 	if err != nil {
-		return c.JSON(400, map[string]string{
-			"error": "Invalid todo ID",
-		})
+		return err // 400 via the default error handler
 	}
 
-	todo, exists := store.Get(id)
+	todo, exists := todoStore.Get(id)
 	if !exists {
 		return c.JSON(404, map[string]string{
 			"error": "Todo not found",
@@ -105,7 +101,7 @@ func handleCreateTodo(c *context.Context) error {
 		})
 	}
 
-	todo := store.Create(input.Title)
+	todo := todoStore.Create(input.Title)
 
 	return c.JSON(201, map[string]interface{}{
 		"message": "Todo created successfully",
@@ -115,11 +111,9 @@ func handleCreateTodo(c *context.Context) error {
 
 // PUT /todos/:id - Update a todo
 func handleUpdateTodo(c *context.Context) error {
-	id, err := strconv.Atoi(c.Param("id"))
+	id, err := c.ParamInt("id")
 	if err != nil {
-		return c.JSON(400, map[string]string{
-			"error": "Invalid todo ID",
-		})
+		return err // 400 via the default error handler
 	}
 
 	var input struct {
@@ -139,7 +133,7 @@ func handleUpdateTodo(c *context.Context) error {
 		})
 	}
 
-	todo, exists := store.Update(id, input.Title, input.Completed)
+	todo, exists := todoStore.Update(id, input.Title, input.Completed)
 	if !exists {
 		return c.JSON(404, map[string]string{
 			"error": "Todo not found",
@@ -154,14 +148,12 @@ func handleUpdateTodo(c *context.Context) error {
 
 // DELETE /todos/:id - Delete a todo
 func handleDeleteTodo(c *context.Context) error {
-	id, err := strconv.Atoi(c.Param("id"))
+	id, err := c.ParamInt("id")
 	if err != nil {
-		return c.JSON(400, map[string]string{
-			"error": "Invalid todo ID",
-		})
+		return err // 400 via the default error handler
 	}
 
-	if !store.Delete(id) {
+	if !todoStore.Delete(id) {
 		return c.JSON(404, map[string]string{
 			"error": "Todo not found",
 		})
@@ -1,8 +1,10 @@
 package main
 
 import (
-	"sync"
+	"context"
 	"time"
+
+	"github.com/JedizLaPulga/kese/store"
 )
 
 // Todo represents a todo item
@@ -13,87 +15,60 @@ type Todo struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// TodoStore is our in-memory database
+// TodoStore is our in-memory database, backed by the shared store package
 type TodoStore struct {
-	mu     sync.RWMutex
-	todos  map[int]*Todo
-	nextID int
+	todos *store.Memory[int, *Todo]
+	ids   store.Sequence
 }
 
 // NewTodoStore creates a new todo store
 func NewTodoStore() *TodoStore {
 	return &TodoStore{
-		todos:  make(map[int]*Todo),
-		nextID: 1,
+		todos: store.NewMemory[int, *Todo](),
 	}
 }
 
 // Create adds a new todo
 func (s *TodoStore) Create(title string) *Todo {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	todo := &Todo{
-		ID:        s.nextID,
+		ID:        int(s.ids.Next()),
 		Title:     title,
 		Completed: false,
 		CreatedAt: time.Now(),
 	}
 
-	s.todos[s.nextID] = todo
-	s.nextID++
-
+	s.todos.Put(context.Background(), todo.ID, todo)
 	return todo
 }
 
 // GetAll returns all todos
 func (s *TodoStore) GetAll() []*Todo {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	todos := make([]*Todo, 0, len(s.todos))
-	for _, todo := range s.todos {
-		todos = append(todos, todo)
-	}
-
+	todos, _, _ := s.todos.List(context.Background(), 0, 0)
 	return todos
 }
 
 // Get returns a todo by ID
 func (s *TodoStore) Get(id int) (*Todo, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	todo, exists := s.todos[id]
-	return todo, exists
+	todo, err := s.todos.Get(context.Background(), id)
+	return todo, err == nil
 }
 
 // Update updates a todo
 func (s *TodoStore) Update(id int, title string, completed bool) (*Todo, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	todo, exists := s.todos[id]
+	todo, exists := s.Get(id)
 	if !exists {
 		return nil, false
 	}
 
-	todo.Title = title
-	todo.Completed = completed
+	updated := *todo
+	updated.Title = title
+	updated.Completed = completed
+	s.todos.Put(context.Background(), id, &updated)
 
-	return todo, true
+	return &updated, true
 }
 
 // Delete removes a todo
 func (s *TodoStore) Delete(id int) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	_, exists := s.todos[id]
-	if !exists {
-		return false
-	}
-
-	delete(s.todos, id)
-	return true
+	return s.todos.Delete(context.Background(), id) == nil
 }
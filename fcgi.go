@@ -0,0 +1,57 @@
+package kese
+
+import (
+	"fmt"
+	"net"
+	"net/http/cgi"
+	"net/http/fcgi"
+	"os"
+)
+
+// RunFCGI serves the app over FastCGI, for hosting behind Apache (mod_fcgid,
+// mod_proxy_fcgi) or nginx (fastcgi_pass) instead of reverse-proxying HTTP.
+// If listener is nil, requests are accepted on standard input, as when the
+// web server spawns the process itself.
+// The app is checked with Validate first and RunFCGI returns its error if misconfigured.
+//
+// Example:
+//
+//	l, err := net.Listen("unix", "/run/myapp.sock")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app.RunFCGI(l)
+func (a *App) RunFCGI(listener net.Listener) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.logWarnings()
+	if listener != nil {
+		a.Logger.Info(fmt.Sprintf("🚀 Kese FastCGI server starting on %s", listener.Addr()))
+	}
+	return fcgi.Serve(listener, a)
+}
+
+// RunCGI serves a single request as a CGI program, reading it from the
+// environment and standard input as set up by the web server. Each request
+// starts a new process, so keep startup work small. Standard output carries
+// the response, so app.Logger is switched to standard error.
+// The app is checked with Validate first and RunCGI returns its error if misconfigured.
+//
+// Example:
+//
+//	func main() {
+//	    app := kese.New()
+//	    app.GET("/cgi-bin/hello", hello)
+//	    if err := app.RunCGI(); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+func (a *App) RunCGI() error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.Logger.SetOutput(os.Stderr)
+	a.logWarnings()
+	return cgi.Serve(a)
+}
@@ -0,0 +1,77 @@
+package kese
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/logger"
+)
+
+// fcgiRecord writes a FastCGI record for request 1.
+func fcgiRecord(w io.Writer, recType byte, content []byte) {
+	header := []byte{1, recType, 0, 1, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(header[4:], uint16(len(content)))
+	w.Write(header)
+	w.Write(content)
+}
+
+func TestRunFCGI(t *testing.T) {
+	app := New()
+	app.Logger = logger.NewWithConfig(logger.ErrorLevel, &bytes.Buffer{})
+	app.GET("/hello/:name", func(c *context.Context) error {
+		return c.String(200, "hello "+c.Param("name"))
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go app.RunFCGI(listener)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// BEGIN_REQUEST (responder role), PARAMS, empty PARAMS, empty STDIN
+	fcgiRecord(conn, 1, []byte{0, 1, 0, 0, 0, 0, 0, 0})
+	var params bytes.Buffer
+	for _, kv := range [][2]string{{"REQUEST_METHOD", "GET"}, {"REQUEST_URI", "/hello/kese"}, {"SERVER_PROTOCOL", "HTTP/1.1"}} {
+		params.WriteByte(byte(len(kv[0])))
+		params.WriteByte(byte(len(kv[1])))
+		params.WriteString(kv[0] + kv[1])
+	}
+	fcgiRecord(conn, 4, params.Bytes())
+	fcgiRecord(conn, 4, nil)
+	fcgiRecord(conn, 5, nil)
+
+	// Collect STDOUT records until END_REQUEST
+	var stdout bytes.Buffer
+	reader := bufio.NewReader(conn)
+	for {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			t.Fatalf("Reading FastCGI response failed: %v", err)
+		}
+		content := make([]byte, int(binary.BigEndian.Uint16(header[4:]))+int(header[6]))
+		io.ReadFull(reader, content)
+		if header[1] == 3 {
+			break
+		}
+		if header[1] == 6 {
+			stdout.Write(content[:binary.BigEndian.Uint16(header[4:])])
+		}
+	}
+
+	if !strings.HasPrefix(stdout.String(), "Status: 200") || !strings.HasSuffix(stdout.String(), "hello kese") {
+		t.Errorf("Unexpected FastCGI response %q", stdout.String())
+	}
+}
@@ -0,0 +1,127 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const atomNS = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	NS       string      `xml:"xmlns,attr"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomPerson `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+	URI   string `xml:"uri,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author"`
+	Summary    *atomText      `xml:"summary"`
+	Content    *atomText      `xml:"content"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func (f *Feed) atom() *atomFeed {
+	updated := f.LastModified()
+	out := &atomFeed{
+		NS:       atomNS,
+		Lang:     f.Language,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       f.FeedURL,
+		Updated:  atomDate(updated),
+		Author:   newAtomPerson(f.Author),
+	}
+	if out.ID == "" {
+		out.ID = f.Link
+	}
+	if f.Link != "" {
+		out.Links = append(out.Links, atomLink{Href: f.Link, Rel: "alternate"})
+	}
+	if f.FeedURL != "" {
+		out.Links = append(out.Links, atomLink{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+
+	for i := range f.Items {
+		item := &f.Items[i]
+		entry := atomEntry{
+			Title: item.Title,
+			ID:    item.id(),
+		}
+
+		// Atom requires an updated date on every entry
+		entryUpdated := item.updated()
+		if entryUpdated.IsZero() {
+			entryUpdated = updated
+		}
+		entry.Updated = atomDate(entryUpdated)
+		if !item.Published.IsZero() {
+			entry.Published = atomDate(item.Published)
+		}
+		if item.Link != "" {
+			entry.Links = []atomLink{{Href: item.Link, Rel: "alternate"}}
+		}
+		if item.Author != nil {
+			entry.Author = newAtomPerson(item.Author)
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "html", Body: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Body: item.Content}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+	return out
+}
+
+func newAtomPerson(p *Person) *atomPerson {
+	if p == nil {
+		return nil
+	}
+	return &atomPerson{Name: p.Name, Email: p.Email, URI: p.URI}
+}
+
+// atomDate formats t as RFC 3339, or the current time if t is zero, since
+// Atom dates are mandatory.
+func atomDate(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,186 @@
+// Package feed builds RSS 2.0 and Atom feeds, for blogs, changelogs and
+// status pages. Fill a Feed and send it with c.Feed, which sets the content
+// type and caching headers.
+//
+// Example:
+//
+//	app.GET("/blog/feed", func(c *kese.Context) error {
+//	    f := &feed.Feed{
+//	        Format:  feed.Atom,
+//	        Title:   "Example Blog",
+//	        Link:    "https://example.com/blog",
+//	        FeedURL: "https://example.com/blog/feed",
+//	        Author:  &feed.Person{Name: "Ada"},
+//	        TTL:     time.Hour,
+//	    }
+//	    for _, post := range posts {
+//	        f.Items = append(f.Items, feed.Item{
+//	            Title:     post.Title,
+//	            Link:      "https://example.com/blog/" + post.Slug,
+//	            Summary:   post.Summary,
+//	            Published: post.CreatedAt,
+//	            Updated:   post.UpdatedAt,
+//	        })
+//	    }
+//	    return c.Feed(200, f)
+//	})
+package feed
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+)
+
+// Content types of the feed formats.
+const (
+	MIMERSS  = "application/rss+xml; charset=utf-8"
+	MIMEAtom = "application/atom+xml; charset=utf-8"
+)
+
+// Format is the syndication format a Feed is rendered in.
+type Format int
+
+const (
+	// RSS is RSS 2.0, the most widely supported format
+	RSS Format = iota
+
+	// Atom is RFC 4287 Atom, with stricter semantics for IDs and dates
+	Atom
+)
+
+// ErrNoTitle is returned when rendering a feed without a title.
+var ErrNoTitle = errors.New("feed: title is required")
+
+// Person is the author of a feed or item.
+type Person struct {
+	Name  string
+	Email string
+	URI   string
+}
+
+// Feed is a syndication feed.
+type Feed struct {
+	// Format is the format the feed is rendered in. Default: RSS
+	Format Format
+
+	// Title is the name of the feed (required)
+	Title string
+
+	// Link is the URL of the website the feed belongs to
+	Link string
+
+	// FeedURL is the URL the feed is served at, advertised as its self link.
+	// Atom feeds use it, or Link, as their ID.
+	FeedURL string
+
+	// Description describes the feed
+	Description string
+
+	// Language is the language of the feed, e.g. "en-us" (optional)
+	Language string
+
+	// Author is the default author of items
+	Author *Person
+
+	// Updated is when the feed last changed. Default: the latest item date
+	Updated time.Time
+
+	// TTL is how long readers may cache the feed, sent as the RSS ttl and as
+	// Cache-Control max-age by c.Feed (optional)
+	TTL time.Duration
+
+	Items []Item
+}
+
+// Item is an entry of a feed.
+type Item struct {
+	Title string
+
+	// Link is the URL of the item's page
+	Link string
+
+	// ID identifies the item permanently, even if Link changes.
+	// Default: Link
+	ID string
+
+	// Summary is a short HTML description of the item
+	Summary string
+
+	// Content is the full HTML content of the item (optional)
+	Content string
+
+	// Author overrides the feed's Author for this item
+	Author *Person
+
+	// Published is when the item was first published
+	Published time.Time
+
+	// Updated is when the item last changed. Default: Published
+	Updated time.Time
+
+	Categories []string
+}
+
+// ContentType returns the content type of the feed's format.
+func (f *Feed) ContentType() string {
+	if f.Format == Atom {
+		return MIMEAtom
+	}
+	return MIMERSS
+}
+
+// LastModified returns Updated, or the latest date of the items if it is
+// zero.
+func (f *Feed) LastModified() time.Time {
+	if !f.Updated.IsZero() {
+		return f.Updated
+	}
+	var latest time.Time
+	for i := range f.Items {
+		if updated := f.Items[i].updated(); updated.After(latest) {
+			latest = updated
+		}
+	}
+	return latest
+}
+
+// Write renders the feed to w in its Format.
+func (f *Feed) Write(w io.Writer) error {
+	if f.Title == "" {
+		return ErrNoTitle
+	}
+
+	var doc interface{}
+	if f.Format == Atom {
+		doc = f.atom()
+	} else {
+		doc = f.rss()
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (item *Item) id() string {
+	if item.ID != "" {
+		return item.ID
+	}
+	return item.Link
+}
+
+func (item *Item) updated() time.Time {
+	if !item.Updated.IsZero() {
+		return item.Updated
+	}
+	return item.Published
+}
@@ -0,0 +1,117 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed(format Format) *Feed {
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &Feed{
+		Format:      format,
+		Title:       "Example Blog",
+		Link:        "https://example.com/blog",
+		FeedURL:     "https://example.com/blog/feed",
+		Description: "News & notes",
+		Language:    "en-us",
+		Author:      &Person{Name: "Ada", Email: "ada@example.com"},
+		TTL:         time.Hour,
+		Items: []Item{
+			{
+				Title:      "Hello",
+				Link:       "https://example.com/blog/hello",
+				Summary:    "<p>First post</p>",
+				Content:    "<p>First post, in full</p>",
+				Published:  published,
+				Categories: []string{"news"},
+			},
+			{
+				Title:     "Update",
+				Link:      "https://example.com/blog/update",
+				ID:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
+				Published: published.Add(24 * time.Hour),
+				Updated:   published.Add(48 * time.Hour),
+			},
+		},
+	}
+}
+
+func render(t *testing.T, f *Feed) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// Must be well-formed XML
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("Invalid XML: %v\n%s", err, buf.String())
+			}
+			break
+		}
+	}
+	return buf.String()
+}
+
+func TestRSS(t *testing.T) {
+	f := testFeed(RSS)
+	out := render(t, f)
+
+	for _, want := range []string{
+		`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/">`,
+		`<description>News &amp; notes</description>`,
+		`<lastBuildDate>Sun, 03 Mar 2024 12:00:00 +0000</lastBuildDate>`,
+		`<ttl>60</ttl>`,
+		`<atom:link href="https://example.com/blog/feed" rel="self" type="application/rss+xml"></atom:link>`,
+		`<guid isPermaLink="true">https://example.com/blog/hello</guid>`,
+		`<guid isPermaLink="false">urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</guid>`,
+		`<content:encoded><![CDATA[<p>First post, in full</p>]]></content:encoded>`,
+		`<author>ada@example.com (Ada)</author>`,
+		`<category>news</category>`,
+		`<pubDate>Fri, 01 Mar 2024 12:00:00 +0000</pubDate>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %s in:\n%s", want, out)
+		}
+	}
+	if f.ContentType() != MIMERSS {
+		t.Errorf("Unexpected content type %q", f.ContentType())
+	}
+}
+
+func TestAtom(t *testing.T) {
+	f := testFeed(Atom)
+	out := render(t, f)
+
+	for _, want := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en-us">`,
+		`<id>https://example.com/blog/feed</id>`,
+		`<updated>2024-03-03T12:00:00Z</updated>`,
+		`<link href="https://example.com/blog" rel="alternate"></link>`,
+		`<link href="https://example.com/blog/feed" rel="self" type="application/atom+xml"></link>`,
+		`<name>Ada</name>`,
+		`<id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a</id>`,
+		`<summary type="html">&lt;p&gt;First post&lt;/p&gt;</summary>`,
+		`<published>2024-03-01T12:00:00Z</published>`,
+		`<category term="news"></category>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %s in:\n%s", want, out)
+		}
+	}
+	if f.ContentType() != MIMEAtom {
+		t.Errorf("Unexpected content type %q", f.ContentType())
+	}
+}
+
+func TestFeedRequiresTitle(t *testing.T) {
+	if err := (&Feed{}).Write(&bytes.Buffer{}); err != ErrNoTitle {
+		t.Errorf("Expected ErrNoTitle, got %v", err)
+	}
+}
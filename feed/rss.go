@@ -0,0 +1,110 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	TTL           string    `xml:"ttl,omitempty"`
+	Self          *atomLink `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string      `xml:"title,omitempty"`
+	Link        string      `xml:"link,omitempty"`
+	GUID        *rssGUID    `xml:"guid"`
+	Description string      `xml:"description,omitempty"`
+	Content     *rssContent `xml:"content:encoded"`
+	Author      string      `xml:"author,omitempty"`
+	Categories  []string    `xml:"category"`
+	PubDate     string      `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	ID          string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssContent struct {
+	HTML string `xml:",cdata"`
+}
+
+func (f *Feed) rss() *rssFeed {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		Language:    f.Language,
+	}
+	if updated := f.LastModified(); !updated.IsZero() {
+		channel.LastBuildDate = rssDate(updated)
+	}
+	if f.TTL > 0 {
+		channel.TTL = strconv.Itoa(int(f.TTL.Minutes()))
+	}
+	if f.FeedURL != "" {
+		channel.Self = &atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"}
+	}
+
+	for i := range f.Items {
+		item := &f.Items[i]
+		out := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Summary,
+			Categories:  item.Categories,
+		}
+		if id := item.id(); id != "" {
+			out.GUID = &rssGUID{ID: id, IsPermaLink: id == item.Link}
+		}
+		if item.Content != "" {
+			out.Content = &rssContent{HTML: item.Content}
+		}
+		if author := item.author(f); author != nil && author.Email != "" {
+			// RSS authors are email addresses, optionally followed by a name
+			out.Author = author.Email
+			if author.Name != "" {
+				out.Author += " (" + author.Name + ")"
+			}
+		}
+		if !item.Published.IsZero() {
+			out.PubDate = rssDate(item.Published)
+		}
+		channel.Items = append(channel.Items, out)
+	}
+
+	return &rssFeed{
+		Version:   "2.0",
+		AtomNS:    atomNS,
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel:   channel,
+	}
+}
+
+func (item *Item) author(f *Feed) *Person {
+	if item.Author != nil {
+		return item.Author
+	}
+	return f.Author
+}
+
+// rssDate formats t as RFC 822, as RSS requires.
+func rssDate(t time.Time) string {
+	return t.Format(time.RFC1123Z)
+}
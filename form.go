@@ -0,0 +1,69 @@
+package kese
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// formFuncs returns the template functions rendering forms from the flash
+// data of c: previous input and validation errors after a redirect.
+func (te *TemplateEngine) formFuncs(c *context.Context, csrfField func() template.HTML) template.FuncMap {
+	flashed := func() *context.Flash {
+		if c == nil {
+			return nil
+		}
+		return c.Flashed()
+	}
+
+	return template.FuncMap{
+		"flash": func(key string) string {
+			return flashed().Message(key)
+		},
+		"old": func(name string) string {
+			return flashed().Old(name)
+		},
+		"fieldError": func(name string) string {
+			return flashed().Error(name)
+		},
+		"formStart": func(action string) template.HTML {
+			return template.HTML(`<form method="post" action="`+template.HTMLEscapeString(action)+`">`) + csrfField()
+		},
+		"field": func(name, inputType, label string) template.HTML {
+			value := ""
+			if inputType != "password" {
+				value = flashed().Old(name)
+			}
+			input := `<input type="` + template.HTMLEscapeString(inputType) + `" value="` + template.HTMLEscapeString(value) + `"`
+			return formField(name, label, input, ">", flashed().Error(name))
+		},
+		"textarea": func(name, label string) template.HTML {
+			return formField(name, label, "<textarea", ">"+template.HTMLEscapeString(flashed().Old(name))+"</textarea>", flashed().Error(name))
+		},
+	}
+}
+
+// formField renders a labelled control with its validation error. The
+// control is open up to its id, name and ARIA attributes, followed by end.
+func formField(name, label, control, end, errMessage string) template.HTML {
+	id := template.HTMLEscapeString(name)
+
+	var b strings.Builder
+	if errMessage != "" {
+		b.WriteString(`<div class="field field-invalid">`)
+	} else {
+		b.WriteString(`<div class="field">`)
+	}
+	b.WriteString(`<label for="` + id + `">` + template.HTMLEscapeString(label) + `</label>`)
+	b.WriteString(control + ` id="` + id + `" name="` + id + `"`)
+	if errMessage != "" {
+		b.WriteString(` aria-invalid="true" aria-describedby="` + id + `-error"`)
+	}
+	b.WriteString(end)
+	if errMessage != "" {
+		b.WriteString(`<p class="field-error" id="` + id + `-error">` + template.HTMLEscapeString(errMessage) + `</p>`)
+	}
+	b.WriteString(`</div>`)
+	return template.HTML(b.String())
+}
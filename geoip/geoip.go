@@ -0,0 +1,141 @@
+// Package geoip resolves client IPs to their country and autonomous system
+// (ASN), for access control and traffic analysis by origin.
+//
+// The framework ships no database. Wrap a MaxMind GeoLite2/GeoIP2 reader
+// (such as github.com/oschwald/maxminddb-golang) with MaxMind, or implement
+// Resolver for another provider. middleware.GeoIP stores the Location of each
+// request in its context.
+package geoip
+
+import (
+	"errors"
+	"net"
+)
+
+// ErrNotFound is returned by resolvers when an IP is not in the database,
+// e.g. for private addresses.
+var ErrNotFound = errors.New("geoip: address not found")
+
+// Location is what is known about an IP. Empty fields are unknown.
+type Location struct {
+	// Country is the ISO 3166-1 alpha-2 code, e.g. "DE"
+	Country string
+
+	// ASN is the autonomous system number of the network, e.g. 15169
+	ASN uint
+
+	// Organization is the organization of the autonomous system, e.g. "GOOGLE"
+	Organization string
+}
+
+// Resolver looks up the location of an IP.
+type Resolver interface {
+	// Lookup returns the location of ip, or ErrNotFound if it is unknown
+	Lookup(ip net.IP) (*Location, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface.
+type ResolverFunc func(ip net.IP) (*Location, error)
+
+// Lookup calls f(ip).
+func (f ResolverFunc) Lookup(ip net.IP) (*Location, error) {
+	return f(ip)
+}
+
+// MaxMindReader is the lookup method of a MaxMind DB reader, such as
+// *maxminddb.Reader from github.com/oschwald/maxminddb-golang. It decodes the
+// record of ip into result using `maxminddb` struct tags.
+type MaxMindReader interface {
+	Lookup(ip net.IP, result interface{}) error
+}
+
+// maxMindRecord holds the fields of the GeoLite2/GeoIP2 Country, City and
+// ASN databases that make up a Location.
+type maxMindRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	ASN          uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// MaxMind returns a Resolver reading MaxMind databases. Country and ASN data
+// come in separate databases; pass a reader for each and their records are
+// merged.
+//
+// Example:
+//
+//	countries, err := maxminddb.Open("GeoLite2-Country.mmdb")
+//	...
+//	asns, err := maxminddb.Open("GeoLite2-ASN.mmdb")
+//	...
+//	app.Use(middleware.GeoIP(geoip.MaxMind(countries, asns)))
+func MaxMind(readers ...MaxMindReader) Resolver {
+	return ResolverFunc(func(ip net.IP) (*Location, error) {
+		var location Location
+		for _, reader := range readers {
+			var record maxMindRecord
+			if err := reader.Lookup(ip, &record); err != nil {
+				return nil, err
+			}
+			if record.Country.ISOCode != "" {
+				location.Country = record.Country.ISOCode
+			}
+			if record.ASN != 0 {
+				location.ASN = record.ASN
+				location.Organization = record.Organization
+			}
+		}
+		if location == (Location{}) {
+			return nil, ErrNotFound
+		}
+		return &location, nil
+	})
+}
+
+// Static returns a Resolver for fixed CIDR ranges, for tests and private
+// networks. When ranges overlap, the most specific one wins. It panics if a
+// range does not parse.
+//
+// Example:
+//
+//	resolver := geoip.Static(map[string]geoip.Location{
+//	    "10.0.0.0/8": {Country: "DE", Organization: "office"},
+//	})
+func Static(ranges map[string]Location) Resolver {
+	type entry struct {
+		network  *net.IPNet
+		location Location
+	}
+	entries := make([]entry, 0, len(ranges))
+	for cidr, location := range ranges {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		entries = append(entries, entry{network, location})
+	}
+
+	return ResolverFunc(func(ip net.IP) (*Location, error) {
+		// Prefer the most specific range when ranges overlap
+		var best *entry
+		for i := range entries {
+			if entries[i].network.Contains(ip) {
+				if best == nil || prefixLength(entries[i].network) > prefixLength(best.network) {
+					best = &entries[i]
+				}
+			}
+		}
+		if best == nil {
+			return nil, ErrNotFound
+		}
+		location := best.location
+		return &location, nil
+	})
+}
+
+// prefixLength returns the number of ones in the mask of network.
+func prefixLength(network *net.IPNet) int {
+	ones, _ := network.Mask.Size()
+	return ones
+}
@@ -0,0 +1,141 @@
+package kese
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// HTTPError is an error with an HTTP status and a client-facing message.
+// Return it from handlers instead of writing error bodies by hand; the default
+// error handler responds with its status and {"error": message}. The optional
+// Internal error is never sent to the client.
+//
+// Example:
+//
+//	user, err := repo.Find(id)
+//	if errors.Is(err, sql.ErrNoRows) {
+//	    return kese.NewHTTPError(404, "user not found").WithInternal(err)
+//	}
+//	if !user.Active {
+//	    return kese.ErrForbidden
+//	}
+type HTTPError struct {
+	// Code is the HTTP status code
+	Code int
+
+	// Message is sent to the client
+	Message string
+
+	// Internal is the underlying cause, available via errors.Unwrap (optional)
+	Internal error
+}
+
+// Common HTTP errors. Use WithMessage or WithInternal to add details
+// without modifying them; errors.Is matches the derived errors by status.
+var (
+	ErrBadRequest          = NewHTTPError(http.StatusBadRequest, "")
+	ErrUnauthorized        = NewHTTPError(http.StatusUnauthorized, "")
+	ErrForbidden           = NewHTTPError(http.StatusForbidden, "")
+	ErrNotFound            = NewHTTPError(http.StatusNotFound, "")
+	ErrMethodNotAllowed    = NewHTTPError(http.StatusMethodNotAllowed, "")
+	ErrConflict            = NewHTTPError(http.StatusConflict, "")
+	ErrGone                = NewHTTPError(http.StatusGone, "")
+	ErrUnprocessableEntity = NewHTTPError(http.StatusUnprocessableEntity, "")
+	ErrTooManyRequests     = NewHTTPError(http.StatusTooManyRequests, "")
+	ErrInternalServerError = NewHTTPError(http.StatusInternalServerError, "")
+	ErrServiceUnavailable  = NewHTTPError(http.StatusServiceUnavailable, "")
+)
+
+// NewHTTPError creates an HTTPError. An empty message defaults to the
+// standard status text (e.g. "Not Found").
+func NewHTTPError(code int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &HTTPError{Code: code, Message: message}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Internal != nil {
+		return fmt.Sprintf("%d %s: %v", e.Code, e.Message, e.Internal)
+	}
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Unwrap returns the internal error.
+func (e *HTTPError) Unwrap() error {
+	return e.Internal
+}
+
+// Is reports whether target is an HTTPError with the same status, so
+// errors.Is(err, kese.ErrNotFound) matches any 404 HTTPError.
+func (e *HTTPError) Is(target error) bool {
+	t, ok := target.(*HTTPError)
+	return ok && t.Code == e.Code
+}
+
+// WithMessage returns a copy of the error with a different client-facing message.
+func (e *HTTPError) WithMessage(message string) *HTTPError {
+	copied := *e
+	copied.Message = message
+	return &copied
+}
+
+// WithInternal returns a copy of the error wrapping err, which is kept for
+// logging and errors.Is/As but never sent to the client.
+func (e *HTTPError) WithInternal(err error) *HTTPError {
+	copied := *e
+	copied.Internal = err
+	return &copied
+}
+
+// ErrorMapper translates an error to an HTTP status. It returns false if it
+// does not recognize the error.
+type ErrorMapper func(err error) (status int, ok bool)
+
+// MapError maps errors matching target (via errors.Is) to status, so domain
+// errors are translated centrally instead of in every handler. The error is
+// wrapped in an HTTPError with the standard status text as its message and
+// passed to the error handler; the original error is never sent to the client.
+// Mappings are tried in registration order; HTTPErrors and Problems already
+// carry a status and are never remapped.
+//
+// Example:
+//
+//	app.MapError(sql.ErrNoRows, 404)
+//	app.MapError(ErrInsufficientFunds, 402)
+func (a *App) MapError(target error, status int) {
+	a.MapErrorFunc(func(err error) (int, bool) {
+		return status, errors.Is(err, target)
+	})
+}
+
+// MapErrorFunc registers a function that maps errors to HTTP statuses, for
+// errors that cannot be matched with errors.Is, such as error types.
+//
+// Example:
+//
+//	app.MapErrorFunc(func(err error) (int, bool) {
+//	    var lockErr *db.LockError
+//	    return 409, errors.As(err, &lockErr)
+//	})
+func (a *App) MapErrorFunc(fn ErrorMapper) {
+	a.errorMappers = append(a.errorMappers, fn)
+}
+
+// mapError wraps err in an HTTPError if a registered mapping matches it.
+func (a *App) mapError(err error) error {
+	var httpErr *HTTPError
+	var problem *Problem
+	if len(a.errorMappers) == 0 || errors.As(err, &httpErr) || errors.As(err, &problem) {
+		return err
+	}
+
+	for _, mapper := range a.errorMappers {
+		if status, ok := mapper(err); ok {
+			return NewHTTPError(status, "").WithInternal(err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// currencies maps ISO 4217 codes to their symbol and number of minor digits.
+var currencies = map[string]struct {
+	symbol string
+	digits int
+}{
+	"USD": {"$", 2},
+	"EUR": {"€", 2},
+	"GBP": {"£", 2},
+	"JPY": {"¥", 0},
+	"CHF": {"CHF", 2},
+	"NGN": {"₦", 2},
+	"INR": {"₹", 2},
+}
+
+// Number formats n with the given number of decimals using the locale's separators.
+func (l *Locale) Number(n float64, decimals int) string {
+	formatted := strconv.FormatFloat(math.Abs(n), 'f', decimals, 64)
+	integer, fraction, _ := strings.Cut(formatted, ".")
+
+	var b strings.Builder
+	if n < 0 && strings.Trim(formatted, "0.") != "" {
+		b.WriteString("-")
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(l.Group)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(l.Decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// Currency formats an amount in the given ISO 4217 currency code.
+// A symbol placed after the amount is separated by a no-break space.
+// Unknown codes are shown as the code itself with two decimals.
+func (l *Locale) Currency(amount float64, code string) string {
+	code = strings.ToUpper(code)
+	currency, ok := currencies[code]
+	if !ok {
+		currency.symbol, currency.digits = code, 2
+	}
+
+	number := l.Number(amount, currency.digits)
+	if l.CurrencyAfter {
+		return number + "\u00a0" + currency.symbol
+	}
+	if strings.HasPrefix(number, "-") {
+		return "-" + currency.symbol + number[1:]
+	}
+	return currency.symbol + number
+}
+
+// Date formats t as a short date, e.g. "01/02/2006" for en.
+func (l *Locale) Date(t time.Time) string {
+	return t.Format(l.DateFormat)
+}
+
+// DateTime formats t as a short date and time.
+func (l *Locale) DateTime(t time.Time) string {
+	return t.Format(l.DateTimeFormat)
+}
+
+// LongDate formats t with the month name spelled out, e.g. "15 octobre 2026".
+func (l *Locale) LongDate(t time.Time) string {
+	// Quote the month name so letters in it are not read as layout elements
+	layout := strings.Replace(l.LongDateFormat, "{month}", "\x00", 1)
+	formatted := t.Format(layout)
+	return strings.Replace(formatted, "\x00", l.Months[t.Month()-1], 1)
+}
+
+// RelativeTime describes t relative to now, e.g. "3 hours ago" or "in 2 days".
+// Durations under a minute are reported as the locale's "now" phrase.
+func (l *Locale) RelativeTime(t, now time.Time) string {
+	diff := t.Sub(now)
+	pattern := l.Future
+	if diff < 0 {
+		diff = -diff
+		pattern = l.Past
+	}
+
+	var count int
+	var unit string
+	switch {
+	case diff < time.Minute:
+		return l.Now
+	case diff < time.Hour:
+		count, unit = int(diff/time.Minute), "minute"
+	case diff < 24*time.Hour:
+		count, unit = int(diff/time.Hour), "hour"
+	case diff < 30*24*time.Hour:
+		count, unit = int(diff/(24*time.Hour)), "day"
+	case diff < 365*24*time.Hour:
+		count, unit = int(diff/(30*24*time.Hour)), "month"
+	default:
+		count, unit = int(diff/(365*24*time.Hour)), "year"
+	}
+
+	forms := l.Units[unit]
+	word := forms[1]
+	if count == 1 {
+		word = forms[0]
+	}
+	return fmt.Sprintf(pattern, strconv.Itoa(count)+" "+word)
+}
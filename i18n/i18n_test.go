@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header    string
+		supported []string
+		expected  string
+	}{
+		{"fr-CA,fr;q=0.9,en;q=0.8", []string{"en", "fr"}, "fr"},
+		{"de;q=0.5, es", []string{"en", "de", "es"}, "es"},
+		{"en-GB", []string{"en", "en-GB"}, "en-GB"},
+		{"ja", []string{"en", "fr"}, "en"},
+		{"", []string{"de", "en"}, "de"},
+		{"fr;q=0", []string{"en", "fr"}, "en"},
+	}
+
+	for _, test := range tests {
+		if got := Negotiate(test.header, test.supported...); got != test.expected {
+			t.Errorf("Negotiate(%q) = %q, expected %q", test.header, got, test.expected)
+		}
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	date := time.Date(2026, time.October, 5, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		tag      string
+		number   string
+		currency string
+		date     string
+		longDate string
+	}{
+		{"en", "-1,234,567.89", "€1,234.50", "10/05/2026", "October 5, 2026"},
+		{"fr", "-1\u202f234\u202f567,89", "1\u202f234,50\u00a0€", "05/10/2026", "5 octobre 2026"},
+		{"de-AT", "-1.234.567,89", "1.234,50\u00a0€", "05.10.2026", "5. Oktober 2026"},
+		{"es", "-1.234.567,89", "1.234,50\u00a0€", "05/10/2026", "5 de octubre de 2026"},
+	}
+
+	for _, test := range tests {
+		l := Lookup(test.tag)
+		if got := l.Number(-1234567.891, 2); got != test.number {
+			t.Errorf("%s Number = %q, expected %q", test.tag, got, test.number)
+		}
+		if got := l.Currency(1234.5, "eur"); got != test.currency {
+			t.Errorf("%s Currency = %q, expected %q", test.tag, got, test.currency)
+		}
+		if got := l.Date(date); got != test.date {
+			t.Errorf("%s Date = %q, expected %q", test.tag, got, test.date)
+		}
+		if got := l.LongDate(date); got != test.longDate {
+			t.Errorf("%s LongDate = %q, expected %q", test.tag, got, test.longDate)
+		}
+	}
+
+	if got := Lookup("en").Currency(-5, "JPY"); got != "-¥5" {
+		t.Errorf("Expected -¥5, got %q", got)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		tag      string
+		offset   time.Duration
+		expected string
+	}{
+		{"en", -10 * time.Second, "just now"},
+		{"en", -1 * time.Minute, "1 minute ago"},
+		{"en", -3 * time.Hour, "3 hours ago"},
+		{"en", 48 * time.Hour, "in 2 days"},
+		{"fr", -3 * time.Hour, "il y a 3 heures"},
+		{"de", -2 * 24 * time.Hour, "vor 2 Tagen"},
+		{"es", 400 * 24 * time.Hour, "dentro de 1 año"},
+	}
+
+	for _, test := range tests {
+		if got := Lookup(test.tag).RelativeTime(now.Add(test.offset), now); got != test.expected {
+			t.Errorf("%s RelativeTime(%v) = %q, expected %q", test.tag, test.offset, got, test.expected)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	if FromContext(context.Background()).Tag != DefaultTag {
+		t.Error("Expected default locale without negotiation")
+	}
+	ctx := NewContext(context.Background(), "fr")
+	if FromContext(ctx).Tag != "fr" {
+		t.Error("Expected locale from context")
+	}
+}
+
+func TestInZone(t *testing.T) {
+	instant := time.Date(2026, time.October, 15, 12, 0, 0, 0, time.UTC)
+	if got := InZone(context.Background(), instant); !got.Equal(instant) || got.Location() != time.UTC {
+		t.Error("Expected time unchanged without a zone")
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	ctx := WithLocation(context.Background(), tokyo)
+	if got := InZone(ctx, instant); got.Hour() != 21 {
+		t.Errorf("Expected 21:00 in Tokyo, got %v", got)
+	}
+}
@@ -0,0 +1,224 @@
+// Package i18n provides locale negotiation and locale-aware formatting of
+// numbers, currencies, dates and relative times.
+//
+// The negotiated locale is stored in the request context by middleware.Locale;
+// handlers, JSON serializers and templates format values with FromContext:
+//
+//	f := i18n.FromContext(c)
+//	f.Currency(1234.5, "EUR")      // "1.234,50 €" for de
+//	f.Date(order.CreatedAt)        // "15.10.2026"
+//	f.RelativeTime(order.CreatedAt, time.Now()) // "vor 3 Stunden"
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale holds the formatting conventions of a language or region.
+type Locale struct {
+	// Tag is the BCP 47 language tag, e.g. "en-US" or "fr"
+	Tag string
+
+	// Decimal and Group are the decimal and thousands separators.
+	// French uses a narrow no-break space (U+202F) for grouping.
+	Decimal string
+	Group   string
+
+	// CurrencyAfter places the currency symbol after the amount ("12,50 €")
+	CurrencyAfter bool
+
+	// DateFormat and DateTimeFormat are Go time layouts for short dates and timestamps
+	DateFormat     string
+	DateTimeFormat string
+
+	// LongDateFormat is a layout with "{month}" standing for the localized month name
+	LongDateFormat string
+
+	// Months are the localized month names, January first
+	Months [12]string
+
+	// Now, Past and Future are the relative time phrases; Past and Future
+	// contain "%s" for the quantity and unit (e.g. "%s ago")
+	Now    string
+	Past   string
+	Future string
+
+	// Units maps "second", "minute", "hour", "day", "month" and "year" to
+	// their singular and plural forms
+	Units map[string][2]string
+}
+
+// DefaultTag is the locale used when negotiation finds no match.
+const DefaultTag = "en"
+
+var englishMonths = [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+
+var englishUnits = map[string][2]string{
+	"second": {"second", "seconds"},
+	"minute": {"minute", "minutes"},
+	"hour":   {"hour", "hours"},
+	"day":    {"day", "days"},
+	"month":  {"month", "months"},
+	"year":   {"year", "years"},
+}
+
+// locales holds the built-in locales keyed by lowercase tag.
+var locales = map[string]*Locale{
+	"en": {
+		Tag: "en", Decimal: ".", Group: ",",
+		DateFormat: "01/02/2006", DateTimeFormat: "01/02/2006 3:04 PM", LongDateFormat: "{month} 2, 2006",
+		Months: englishMonths,
+		Now:    "just now", Past: "%s ago", Future: "in %s",
+		Units: englishUnits,
+	},
+	"en-gb": {
+		Tag: "en-GB", Decimal: ".", Group: ",",
+		DateFormat: "02/01/2006", DateTimeFormat: "02/01/2006 15:04", LongDateFormat: "2 {month} 2006",
+		Months: englishMonths,
+		Now:    "just now", Past: "%s ago", Future: "in %s",
+		Units: englishUnits,
+	},
+	"fr": {
+		Tag: "fr", Decimal: ",", Group: "\u202f", CurrencyAfter: true,
+		DateFormat: "02/01/2006", DateTimeFormat: "02/01/2006 15:04", LongDateFormat: "2 {month} 2006",
+		Months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		Now:    "à l'instant", Past: "il y a %s", Future: "dans %s",
+		Units: map[string][2]string{
+			"second": {"seconde", "secondes"},
+			"minute": {"minute", "minutes"},
+			"hour":   {"heure", "heures"},
+			"day":    {"jour", "jours"},
+			"month":  {"mois", "mois"},
+			"year":   {"an", "ans"},
+		},
+	},
+	"de": {
+		Tag: "de", Decimal: ",", Group: ".", CurrencyAfter: true,
+		DateFormat: "02.01.2006", DateTimeFormat: "02.01.2006 15:04", LongDateFormat: "2. {month} 2006",
+		Months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		Now:    "gerade eben", Past: "vor %s", Future: "in %s",
+		Units: map[string][2]string{
+			"second": {"Sekunde", "Sekunden"},
+			"minute": {"Minute", "Minuten"},
+			"hour":   {"Stunde", "Stunden"},
+			"day":    {"Tag", "Tagen"},
+			"month":  {"Monat", "Monaten"},
+			"year":   {"Jahr", "Jahren"},
+		},
+	},
+	"es": {
+		Tag: "es", Decimal: ",", Group: ".", CurrencyAfter: true,
+		DateFormat: "02/01/2006", DateTimeFormat: "02/01/2006 15:04", LongDateFormat: "2 de {month} de 2006",
+		Months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		Now:    "ahora mismo", Past: "hace %s", Future: "dentro de %s",
+		Units: map[string][2]string{
+			"second": {"segundo", "segundos"},
+			"minute": {"minuto", "minutos"},
+			"hour":   {"hora", "horas"},
+			"day":    {"día", "días"},
+			"month":  {"mes", "meses"},
+			"year":   {"año", "años"},
+		},
+	},
+}
+
+// Register adds or replaces a locale. It is typically called from init.
+func Register(locale *Locale) {
+	locales[strings.ToLower(locale.Tag)] = locale
+}
+
+// Lookup returns the locale for tag, falling back to its base language
+// ("fr-CA" -> "fr") and then to DefaultTag.
+func Lookup(tag string) *Locale {
+	tag = strings.ToLower(tag)
+	if locale, ok := locales[tag]; ok {
+		return locale
+	}
+	if base, _, found := strings.Cut(tag, "-"); found {
+		if locale, ok := locales[base]; ok {
+			return locale
+		}
+	}
+	return locales[DefaultTag]
+}
+
+// Negotiate picks the best of the supported tags for an Accept-Language header.
+// Exact matches win over base-language matches; with no match the first
+// supported tag is returned.
+//
+// Example:
+//
+//	i18n.Negotiate("fr-CA,fr;q=0.9,en;q=0.8", "en", "fr") // "fr"
+func Negotiate(acceptLanguage string, supported ...string) string {
+	if len(supported) == 0 {
+		return DefaultTag
+	}
+
+	type preference struct {
+		tag     string
+		quality float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			preferences = append(preferences, preference{strings.ToLower(tag), quality})
+		}
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	for _, pref := range preferences {
+		if pref.tag == "*" {
+			return supported[0]
+		}
+		for _, tag := range supported {
+			if strings.EqualFold(tag, pref.tag) {
+				return tag
+			}
+		}
+		base, _, _ := strings.Cut(pref.tag, "-")
+		for _, tag := range supported {
+			supportedBase, _, _ := strings.Cut(strings.ToLower(tag), "-")
+			if supportedBase == base {
+				return tag
+			}
+		}
+	}
+	return supported[0]
+}
+
+// contextKey is the context key for the negotiated locale.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the locale tag.
+func NewContext(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tag)
+}
+
+// TagFromContext returns the locale tag carried by ctx, or DefaultTag.
+func TagFromContext(ctx context.Context) string {
+	if tag, ok := ctx.Value(contextKey{}).(string); ok {
+		return tag
+	}
+	return DefaultTag
+}
+
+// FromContext returns the locale negotiated for the request carried by ctx.
+func FromContext(ctx context.Context) *Locale {
+	return Lookup(TagFromContext(ctx))
+}
@@ -0,0 +1,38 @@
+package i18n
+
+import (
+	"context"
+	"time"
+)
+
+// locationKey is the context key for the client time zone.
+type locationKey struct{}
+
+// WithLocation returns a copy of ctx carrying the client time zone.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// LocationFromContext returns the client time zone carried by ctx, or UTC.
+func LocationFromContext(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// InZone converts t to the client time zone carried by ctx, for rendering
+// or serializing times as the user expects to see them. If ctx carries no
+// zone, t is returned unchanged.
+//
+// Example:
+//
+//	return c.JSON(200, map[string]interface{}{
+//	    "created_at": i18n.InZone(c, order.CreatedAt), // "2026-10-15T14:30:00+02:00"
+//	})
+func InZone(ctx context.Context, t time.Time) time.Time {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+		return t.In(loc)
+	}
+	return t
+}
@@ -0,0 +1,179 @@
+package kese
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/health"
+)
+
+// K8sConfig configures the Kubernetes helpers installed by K8sDefaultsWithConfig.
+type K8sConfig struct {
+	// LivenessPath serves the liveness probe (default: "/livez").
+	// It only reports that the process is responsive and never runs health
+	// checks, so a failing dependency does not get the pod restarted.
+	LivenessPath string
+
+	// ReadinessPath serves the readiness probe (default: "/readyz").
+	// It runs the app's health checks and fails as soon as shutdown starts.
+	ReadinessPath string
+
+	// StartupPath serves the startup probe (default: "/startupz").
+	StartupPath string
+
+	// StartupCheck reports whether the app has finished starting, e.g. warmed
+	// its caches (optional). Once it succeeds the startup probe keeps passing.
+	StartupCheck func() error
+
+	// PreStopDelay keeps serving after SIGTERM, with readiness failing, so
+	// endpoints and load balancers stop routing to the pod before the listener
+	// closes (default: 5s). It replaces a "sleep" preStop hook.
+	PreStopDelay time.Duration
+
+	// RequestTimeout is the hard deadline for in-flight requests once shutdown
+	// starts, see App.ShutdownRequestTimeout (default: 20s, 0 = none).
+	// Keep PreStopDelay + RequestTimeout below terminationGracePeriodSeconds.
+	RequestTimeout time.Duration
+
+	// PodFields adds the pod name and namespace to every log entry, read from
+	// the POD_NAME and POD_NAMESPACE environment variables (set them with the
+	// downward API), falling back to HOSTNAME for the pod name (default: true).
+	PodFields bool
+}
+
+// DefaultK8sConfig returns the default Kubernetes configuration.
+func DefaultK8sConfig() K8sConfig {
+	return K8sConfig{
+		LivenessPath:   "/livez",
+		ReadinessPath:  "/readyz",
+		StartupPath:    "/startupz",
+		PreStopDelay:   5 * time.Second,
+		RequestTimeout: 20 * time.Second,
+		PodFields:      true,
+	}
+}
+
+// Validate checks the configuration and returns a *ConfigError describing the first problem found.
+func (config K8sConfig) Validate() error {
+	for _, path := range []string{config.LivenessPath, config.ReadinessPath, config.StartupPath} {
+		if !strings.HasPrefix(path, "/") {
+			return &ConfigError{
+				Component: "k8s",
+				Problem:   fmt.Sprintf("probe path %q must start with /", path),
+				Fix:       "use paths such as \"/livez\", \"/readyz\" and \"/startupz\"",
+			}
+		}
+	}
+	if config.PreStopDelay < 0 || config.RequestTimeout < 0 {
+		return &ConfigError{
+			Component: "k8s",
+			Problem:   "PreStopDelay and RequestTimeout must not be negative",
+		}
+	}
+	return nil
+}
+
+// K8sDefaults prepares the app for running on Kubernetes in one call:
+//   - liveness (/livez), readiness (/readyz) and startup (/startupz) probes
+//   - readiness fails as soon as SIGTERM arrives, then the app keeps serving
+//     for a pre-stop delay before it stops accepting connections
+//   - in-flight requests get a hard deadline while draining
+//   - JSON logs tagged with the pod name and namespace
+//
+// The probes are served directly by the router: they bypass middleware, are
+// not subject to maintenance mode and don't appear in Routes. Run the app with
+// RunWithShutdown so SIGTERM triggers the shutdown sequence.
+//
+// Example:
+//
+//	app := kese.New()
+//	app.AddHealthCheck("database", db.Ping)
+//	kese.K8sDefaults(app)
+//	app.RunWithShutdown(":8080", 30*time.Second)
+func K8sDefaults(app *App) {
+	K8sDefaultsWithConfig(app, DefaultK8sConfig())
+}
+
+// K8sDefaultsWithConfig is K8sDefaults with custom configuration.
+// It panics with a *ConfigError if the configuration is invalid.
+func K8sDefaultsWithConfig(app *App, config K8sConfig) {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	app.ShutdownDelay = config.PreStopDelay
+	app.ShutdownRequestTimeout = config.RequestTimeout
+
+	if config.PodFields {
+		pod := os.Getenv("POD_NAME")
+		if pod == "" {
+			pod = os.Getenv("HOSTNAME")
+		}
+		fields := []interface{}{}
+		if pod != "" {
+			fields = append(fields, "pod", pod)
+		}
+		if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+			fields = append(fields, "namespace", namespace)
+		}
+		if len(fields) > 0 {
+			app.Logger = app.Logger.With(fields...)
+		}
+	}
+
+	router := app.Router()
+	router.Add(http.MethodGet, config.LivenessPath, app.LivenessHandler())
+	router.Add(http.MethodGet, config.ReadinessPath, app.ReadinessHandler())
+	router.Add(http.MethodGet, config.StartupPath, startupHandler(config.StartupCheck))
+}
+
+// LivenessHandler returns a liveness probe handler. It responds 200 while the
+// process can serve requests and does not run health checks.
+func (a *App) LivenessHandler() HandlerFunc {
+	return func(c *context.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": "alive"})
+	}
+}
+
+// ReadinessHandler returns a readiness probe handler. It runs the health
+// checks added with AddHealthCheck and responds 503 if one fails or once
+// graceful shutdown has started.
+func (a *App) ReadinessHandler() HandlerFunc {
+	return func(c *context.Context) error {
+		if a.Draining() {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+		}
+
+		status, checks := a.healthCheck.Check()
+		code := http.StatusOK
+		if status == health.StatusUnhealthy {
+			code = http.StatusServiceUnavailable
+		}
+		return c.JSON(code, map[string]interface{}{
+			"status": status,
+			"checks": checks,
+		})
+	}
+}
+
+// startupHandler returns a startup probe handler that passes once check succeeds.
+func startupHandler(check func() error) HandlerFunc {
+	var started atomic.Bool
+	return func(c *context.Context) error {
+		if !started.Load() && check != nil {
+			if err := check(); err != nil {
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{
+					"status": "starting",
+					"error":  err.Error(),
+				})
+			}
+		}
+		started.Store(true)
+		return c.JSON(http.StatusOK, map[string]string{"status": "started"})
+	}
+}
@@ -1,18 +1,30 @@
 package kese
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/JedizLaPulga/kese/analytics"
 	"github.com/JedizLaPulga/kese/context"
 	"github.com/JedizLaPulga/kese/health"
 	"github.com/JedizLaPulga/kese/logger"
 	"github.com/JedizLaPulga/kese/router"
 )
 
+// errRouteNotFound is handled when no route matches the request.
+var errRouteNotFound = ErrNotFound.WithMessage("404 Not Found")
+
 // DefaultMaxBodySize is the default maximum size for request bodies (10MB)
 const DefaultMaxBodySize = 10 << 20 // 10MB
 
+// DefaultBodyMemoryLimit is the default size above which request bodies are
+// buffered in a temporary file instead of memory (1MB)
+const DefaultBodyMemoryLimit = 1 << 20 // 1MB
+
 // HandlerFunc defines the function signature for route handlers.
 // It receives a Context and returns an error for centralized error handling.
 type HandlerFunc func(*context.Context) error
@@ -23,12 +35,93 @@ type App struct {
 	router         *router.Router[HandlerFunc]
 	middleware     []MiddlewareFunc
 	errorHandler   ErrorHandler
+	errorMappers   []ErrorMapper
+	onError        func(c *context.Context, err error)
+	onShutdown     []func()
 	healthCheck    *health.HealthChecker
 	Logger         *logger.Logger
 	templateEngine *TemplateEngine
 
+	// routes records every registered route for validation and introspection
+	routes   []*Route
+	routesMu sync.Mutex
+
+	// lateMiddleware counts middleware added after the first route was registered
+	lateMiddleware int
+
+	// startupChecks are additional checks run by Validate
+	startupChecks []func() error
+
+	// propagate lists the context keys mirrored into every request context
+	propagate []string
+
+	// warnings are logged once the app starts serving, see Warning
+	warnings           []Warning
+	suppressedWarnings map[string]bool
+	warningsLogged     atomic.Bool
+	warningsMu         sync.Mutex
+
+	// inFlight counts requests currently being served
+	inFlight atomic.Int64
+
+	// draining is set once graceful shutdown has started
+	draining atomic.Bool
+
+	// runtimeConfig is the reloadable configuration, nil until one is applied
+	runtimeConfig atomic.Pointer[RuntimeConfig]
+	configLoader  func() (RuntimeConfig, error)
+	reloadMu      sync.Mutex
+
 	// MaxBodySize limits the size of the request body (default: 10MB)
 	MaxBodySize int64
+
+	// BodyMemoryLimit is the largest request body buffered in memory by
+	// c.Body, c.BodyBytes and the binders (default: 1MB). Larger bodies, up to
+	// MaxBodySize, are buffered in a temporary file that is removed once the
+	// request is done, so large but legal payloads don't grow the heap.
+	// 0 buffers every body in memory.
+	BodyMemoryLimit int64
+
+	// Analytics receives the events recorded with c.Track. RunWithShutdown
+	// flushes and closes it once the server stopped. Nil discards events.
+	Analytics *analytics.Tracker
+
+	// PrimeHost is the Host of the requests made by PrimeCache, e.g.
+	// "shop.example.com", so they pass middleware.AllowedHosts and match
+	// cache keys that include the host. Default: "localhost"
+	PrimeHost string
+
+	// FlashSecret signs the cookie carrying c.Flash and c.FlashInput data to
+	// the next request. Use a random key of at least 32 bytes, shared by all
+	// instances. Without it the cookie is unsigned, and a client or a sibling
+	// subdomain can forge the messages and errors shown after a redirect.
+	FlashSecret []byte
+
+	// RequestIDs assigns every request an ID before routing, available via
+	// c.RequestID and echoed in the X-Request-ID response header, so
+	// responses no middleware sees, like 404s for unmatched paths, carry one
+	// too. A valid X-Request-ID sent by the client or an upstream proxy is
+	// kept; otherwise one is generated.
+	RequestIDs bool
+
+	// TraceMiddleware records the time spent in each middleware and the handler,
+	// available via Segments. It applies to routes registered after it is set.
+	TraceMiddleware bool
+
+	// ServerTiming emits a Server-Timing response header with the metrics recorded
+	// by c.ServerTiming, plus the middleware segments when TraceMiddleware is set,
+	// so browser devtools can show the backend breakdown.
+	ServerTiming bool
+
+	// ShutdownDelay keeps serving for this long once graceful shutdown starts,
+	// before the server stops accepting connections, while Draining reports
+	// true so readiness checks fail and load balancers stop routing traffic.
+	ShutdownDelay time.Duration
+
+	// ShutdownRequestTimeout is the hard deadline for requests still in flight
+	// once graceful shutdown starts: their request context is cancelled when it
+	// expires. Zero lets them run until the shutdown timeout of RunWithShutdown.
+	ShutdownRequestTimeout time.Duration
 }
 
 // MiddlewareFunc defines the function signature for middleware.
@@ -39,19 +132,30 @@ type MiddlewareFunc func(HandlerFunc) HandlerFunc
 // This is the starting point for building your web application.
 func New() *App {
 	return &App{
-		router:       router.New[HandlerFunc](),
-		middleware:   make([]MiddlewareFunc, 0),
-		errorHandler: DefaultErrorHandler,
-		healthCheck:  health.New(),
-		Logger:       logger.New(),
-		MaxBodySize:  DefaultMaxBodySize,
+		router:          router.New[HandlerFunc](),
+		middleware:      make([]MiddlewareFunc, 0),
+		errorHandler:    DefaultErrorHandler,
+		healthCheck:     health.New(),
+		Logger:          logger.New(),
+		MaxBodySize:     DefaultMaxBodySize,
+		BodyMemoryLimit: DefaultBodyMemoryLimit,
 	}
 }
 
 // Use adds middleware to the application.
 // Middleware is executed in the order it is registered.
 func (a *App) Use(middleware ...MiddlewareFunc) {
+	if len(a.routes) > 0 {
+		a.lateMiddleware += len(middleware)
+		a.warn(Warning{
+			Code:      "late-middleware",
+			Component: "middleware",
+			Message:   "middleware registered with Use after routes does not apply to routes registered earlier",
+			Fix:       "call app.Use before registering routes, or attach the middleware to a Group",
+		})
+	}
 	a.middleware = append(a.middleware, middleware...)
+	a.claimConfigErrors()
 }
 
 // SetErrorHandler sets a custom error handler for the application.
@@ -60,6 +164,42 @@ func (a *App) SetErrorHandler(handler ErrorHandler) {
 	a.errorHandler = handler
 }
 
+// OnError sets a function called when a handler returns an error after the
+// response was already written, so the error can no longer be sent to the
+// client (e.g. a stream that failed halfway). It replaces the default, which
+// logs the error through app.Logger.
+//
+// Example:
+//
+//	app.OnError(func(c *context.Context, err error) {
+//	    sentry.CaptureException(err)
+//	})
+func (a *App) OnError(fn func(c *context.Context, err error)) {
+	a.onError = fn
+}
+
+// SetJSONCodec replaces encoding/json for all Context JSON methods, problem
+// responses and the cache middleware, e.g. with jsoniter or sonic on
+// high-traffic services. The codec is process-wide, see context.SetJSONCodec.
+//
+// Example:
+//
+//	app.SetJSONCodec(sonic.Marshal, sonic.Unmarshal)
+func (a *App) SetJSONCodec(marshal context.JSONMarshaler, unmarshal context.JSONUnmarshaler) {
+	context.SetJSONCodec(marshal, unmarshal)
+}
+
+// PropagateValues mirrors the given c.Set keys into the request context of
+// every request, so libraries that only receive c.Request.Context() can read
+// them with context.ValueFrom. See context.Context.Propagate.
+//
+// Example:
+//
+//	app.PropagateValues(context.RequestIDKey, "userID")
+func (a *App) PropagateValues(keys ...string) {
+	a.propagate = append(a.propagate, keys...)
+}
+
 // SetTemplateEngine sets the template engine for rendering HTML templates.
 // After calling this, use app.RenderTemplate() in handlers to render templates.
 //
@@ -88,6 +228,19 @@ func (a *App) RenderTemplate(c *context.Context, status int, name string, data i
 	return a.templateEngine.Render(c, status, name, data)
 }
 
+// Router returns the underlying router so routing options can be configured.
+// Routes may still be registered while the app is serving, e.g. by plugins
+// that start late; call Router().Freeze() once startup is complete to reject
+// later registrations.
+//
+// Example:
+//
+//	app.Router().RedirectTrailingSlash = true
+//	app.Router().RedirectFixedPath = true
+func (a *App) Router() *router.Router[HandlerFunc] {
+	return a.router
+}
+
 func (a *App) AddHealthCheck(name string, check health.CheckFunc) {
 	a.healthCheck.AddCheck(name, check)
 }
@@ -96,60 +249,102 @@ func (a *App) AddHealthCheck(name string, check health.CheckFunc) {
 func (a *App) HealthHandler() HandlerFunc {
 	return func(c *context.Context) error {
 		a.healthCheck.ServeHTTP(c.Writer, c.Request)
-		c.SetWritten()
 		return nil
 	}
 }
 
 // GET registers a route that responds to GET requests.
-func (a *App) GET(path string, handler HandlerFunc) {
-	a.addRoute(http.MethodGet, path, handler)
+func (a *App) GET(path string, handler HandlerFunc) *Route {
+	return a.addRoute(http.MethodGet, path, handler)
 }
 
 // POST registers a route that responds to POST requests.
-func (a *App) POST(path string, handler HandlerFunc) {
-	a.addRoute(http.MethodPost, path, handler)
+func (a *App) POST(path string, handler HandlerFunc) *Route {
+	return a.addRoute(http.MethodPost, path, handler)
 }
 
 // PUT registers a route that responds to PUT requests.
-func (a *App) PUT(path string, handler HandlerFunc) {
-	a.addRoute(http.MethodPut, path, handler)
+func (a *App) PUT(path string, handler HandlerFunc) *Route {
+	return a.addRoute(http.MethodPut, path, handler)
 }
 
 // DELETE registers a route that responds to DELETE requests.
-func (a *App) DELETE(path string, handler HandlerFunc) {
-	a.addRoute(http.MethodDelete, path, handler)
+func (a *App) DELETE(path string, handler HandlerFunc) *Route {
+	return a.addRoute(http.MethodDelete, path, handler)
 }
 
 // PATCH registers a route that responds to PATCH requests.
-func (a *App) PATCH(path string, handler HandlerFunc) {
-	a.addRoute(http.MethodPatch, path, handler)
+func (a *App) PATCH(path string, handler HandlerFunc) *Route {
+	return a.addRoute(http.MethodPatch, path, handler)
 }
 
 // OPTIONS registers a route that responds to OPTIONS requests.
-func (a *App) OPTIONS(path string, handler HandlerFunc) {
-	a.addRoute(http.MethodOptions, path, handler)
+func (a *App) OPTIONS(path string, handler HandlerFunc) *Route {
+	return a.addRoute(http.MethodOptions, path, handler)
 }
 
 // HEAD registers a route that responds to HEAD requests.
-func (a *App) HEAD(path string, handler HandlerFunc) {
-	a.addRoute(http.MethodHead, path, handler)
+func (a *App) HEAD(path string, handler HandlerFunc) *Route {
+	return a.addRoute(http.MethodHead, path, handler)
 }
 
 // addRoute is the internal method for registering routes with the router.
-func (a *App) addRoute(method, path string, handler HandlerFunc) {
-	// Wrap the handler with all registered middleware
-	wrappedHandler := a.wrapMiddleware(handler)
-	a.router.Add(method, path, wrappedHandler)
+// It returns the Route so callers can attach metadata such as documentation.
+func (a *App) addRoute(method, path string, handler HandlerFunc, groupMiddleware ...MiddlewareFunc) *Route {
+	route := newRoute(method, path)
+
+	// Wrap the handler with all registered middleware, then the group's
+	chain := make([]MiddlewareFunc, 0, len(a.middleware)+len(groupMiddleware))
+	chain = append(chain, a.middleware...)
+	chain = append(chain, groupMiddleware...)
+	wrappedHandler := a.wrapMiddleware(handler, chain)
+
+	serve := func(c *context.Context) error {
+		if err := checkAccept(c); err != nil {
+			return err
+		}
+		if err := c.Prefetch(); err != nil {
+			return err
+		}
+		return a.serveDeprecated(c, wrappedHandler)
+	}
+
+	// Expose the route's metadata to middleware and handlers before the chain runs
+	a.router.Add(method, path, func(c *context.Context) error {
+		c.SetRoute(route.Path, route.meta)
+
+		// Reject oversized uploads before the client sends the body.
+		// For "Expect: 100-continue" requests net/http only sends the interim 100 response
+		// once the body is first read, so middleware (auth, validation) can still reject
+		// the request without the client transmitting it.
+		if c.ExpectsContinue() && c.BodyTooLarge() {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+				"error": "Request body too large",
+			})
+		}
+		return a.serveMaintenance(c, serve)
+	})
+	a.routesMu.Lock()
+	a.routes = append(a.routes, route)
+	a.routesMu.Unlock()
+	return route
 }
 
 // wrapMiddleware wraps a handler with all registered middleware.
 // Middleware is applied in reverse order so that the first registered
 // middleware is the outermost layer.
-func (a *App) wrapMiddleware(handler HandlerFunc) HandlerFunc {
+// When TraceMiddleware is set, every layer is timed.
+func (a *App) wrapMiddleware(handler HandlerFunc, middleware []MiddlewareFunc) HandlerFunc {
+	if a.TraceMiddleware {
+		handler = traceLayer(len(middleware), "handler", handler)
+	}
+
 	// Apply middleware in reverse order
-	for i := len(a.middleware) - 1; i >= 0; i-- {
-		handler = a.middleware[i](handler)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+		if a.TraceMiddleware {
+			handler = traceLayer(i, middlewareName(middleware[i]), handler)
+		}
 	}
 	return handler
 }
@@ -164,6 +359,7 @@ type RouterGroup struct {
 // Group creates a new router group with the given prefix and optional middleware.
 // Example: api := app.Group("/api/v1", authMiddleware())
 func (a *App) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup {
+	a.claimConfigErrors()
 	return &RouterGroup{
 		app:        a,
 		prefix:     prefix,
@@ -172,64 +368,96 @@ func (a *App) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup {
 }
 
 // GET registers a GET route within the group.
-func (rg *RouterGroup) GET(path string, handler HandlerFunc) {
-	rg.addRoute(http.MethodGet, path, handler)
+func (rg *RouterGroup) GET(path string, handler HandlerFunc) *Route {
+	return rg.addRoute(http.MethodGet, path, handler)
 }
 
 // POST registers a POST route within the group.
-func (rg *RouterGroup) POST(path string, handler HandlerFunc) {
-	rg.addRoute(http.MethodPost, path, handler)
+func (rg *RouterGroup) POST(path string, handler HandlerFunc) *Route {
+	return rg.addRoute(http.MethodPost, path, handler)
 }
 
 // PUT registers a PUT route within the group.
-func (rg *RouterGroup) PUT(path string, handler HandlerFunc) {
-	rg.addRoute(http.MethodPut, path, handler)
+func (rg *RouterGroup) PUT(path string, handler HandlerFunc) *Route {
+	return rg.addRoute(http.MethodPut, path, handler)
 }
 
 // DELETE registers a DELETE route within the group.
-func (rg *RouterGroup) DELETE(path string, handler HandlerFunc) {
-	rg.addRoute(http.MethodDelete, path, handler)
+func (rg *RouterGroup) DELETE(path string, handler HandlerFunc) *Route {
+	return rg.addRoute(http.MethodDelete, path, handler)
 }
 
 // PATCH registers a PATCH route within the group.
-func (rg *RouterGroup) PATCH(path string, handler HandlerFunc) {
-	rg.addRoute(http.MethodPatch, path, handler)
+func (rg *RouterGroup) PATCH(path string, handler HandlerFunc) *Route {
+	return rg.addRoute(http.MethodPatch, path, handler)
 }
 
 // OPTIONS registers an OPTIONS route within the group.
-func (rg *RouterGroup) OPTIONS(path string, handler HandlerFunc) {
-	rg.addRoute(http.MethodOptions, path, handler)
+func (rg *RouterGroup) OPTIONS(path string, handler HandlerFunc) *Route {
+	return rg.addRoute(http.MethodOptions, path, handler)
 }
 
 // HEAD registers a HEAD route within the group.
-func (rg *RouterGroup) HEAD(path string, handler HandlerFunc) {
-	rg.addRoute(http.MethodHead, path, handler)
+func (rg *RouterGroup) HEAD(path string, handler HandlerFunc) *Route {
+	return rg.addRoute(http.MethodHead, path, handler)
 }
 
 // addRoute adds a route to the app with the group's prefix and middleware.
-func (rg *RouterGroup) addRoute(method, path string, handler HandlerFunc) {
-	// Apply group's middleware to the handler
-	for i := len(rg.middleware) - 1; i >= 0; i-- {
-		handler = rg.middleware[i](handler)
-	}
-
-	// Add the route to the main app with the prefixed path
+func (rg *RouterGroup) addRoute(method, path string, handler HandlerFunc) *Route {
+	// Add the route to the main app with the prefixed path; the group's
+	// middleware runs after the app's
 	fullPath := rg.prefix + path
-	rg.app.addRoute(method, fullPath, handler)
+	return rg.app.addRoute(method, fullPath, handler, rg.middleware...)
 }
 
 // ServeHTTP implements http.Handler interface.
 // This allows the App to be used directly with http.Server.
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Create a new context for this request
+	// Take a context from the pool for this request
 	// Use configured MaxBodySize
-	ctx := context.New(w, r, a.MaxBodySize)
+	a.inFlight.Add(1)
+	defer a.inFlight.Add(-1)
+
+	// Routes added from now on, e.g. by late plugins, are copy-on-write
+	a.router.Share()
+	a.logWarnings()
+
+	ctx := context.Acquire(w, r, a.MaxBodySize)
+	ctx.BodyMemoryLimit = a.BodyMemoryLimit
+	ctx.Analytics = a.Analytics
+	ctx.FlashSecret = a.FlashSecret
+	defer context.Release(ctx)
+	defer ctx.Finish()
+	if len(a.propagate) > 0 {
+		ctx.Propagate(a.propagate...)
+	}
+	if a.RequestIDs {
+		requestID := ctx.RequestID()
+		if requestID == "" {
+			requestID = context.NewRequestID()
+		}
+		ctx.SetRequestID(requestID)
+	}
+	if a.TraceMiddleware {
+		ctx.Set(traceKey, newRequestTrace(len(a.middleware)+1))
+	}
+	if a.ServerTiming {
+		ctx.Writer = serverTimingWriter(ctx)
+	}
+
+	// Redirect to the canonical path if routing options request it
+	if location, ok := a.router.RedirectPath(r.Method, r.URL.Path); ok {
+		a.redirectToPath(ctx, location)
+		return
+	}
 
 	// Find the matching route
 	handler, params, found := a.router.Match(r.Method, r.URL.Path)
 	if !found {
-		// No route matched - return 404
-		ctx.String(http.StatusNotFound, "404 Not Found")
+		// No route matched - return 404 through the error handler, so it
+		// carries the same format and request ID as other errors
+		statusCode, response := a.errorHandler(errRouteNotFound)
+		writeError(ctx, statusCode, response)
 		return
 	}
 
@@ -238,29 +466,75 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Execute the handler
 	if err := handler(ctx); err != nil {
+		// The client went away; there is nobody to send an error response to
+		if errors.Is(err, context.ErrClientClosed) {
+			a.Logger.Debug("Client closed request", "method", r.Method, "path", r.URL.Path)
+			return
+		}
+
 		// Handle errors returned by handlers using the custom error handler
 		// Only write error response if no response has been written yet
 		if !ctx.IsWritten() {
-			statusCode, response := a.errorHandler(err)
-			ctx.JSON(statusCode, response)
+			statusCode, response := a.errorHandler(a.mapError(err))
+			writeError(ctx, statusCode, response)
 		} else {
 			// If response was already written, we can't send error info to client
-			// But we should log it
-			a.Logger.Error(fmt.Sprintf("Handler error after write: %v", err))
+			// But we should report it
+			a.reportWrittenError(ctx, err)
 		}
 	}
 
 }
 
+// reportWrittenError reports an error returned after the response was written,
+// through the OnError hook or the app logger.
+func (a *App) reportWrittenError(c *context.Context, err error) {
+	if a.onError != nil {
+		a.onError(c, err)
+		return
+	}
+	a.Logger.Error("Handler error after response was written",
+		"method", c.Method(),
+		"path", c.Path(),
+		"status", c.StatusCode(),
+		"request_id", c.RequestID(),
+		"error", err.Error(),
+	)
+}
+
+// redirectToPath redirects the request to the canonical location, keeping the query string.
+// GET and HEAD use 301; other methods use 308 so clients replay the method and body.
+func (a *App) redirectToPath(c *context.Context, location string) {
+	status := http.StatusMovedPermanently
+	if c.Method() != http.MethodGet && c.Method() != http.MethodHead {
+		status = http.StatusPermanentRedirect
+	}
+
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+
+	c.Redirect(status, location)
+}
+
 // Run starts the HTTP server on the specified address.
 // address should be in the format ":8080" or "localhost:8080"
+// The app is checked with Validate first and Run returns its error if misconfigured.
 func (a *App) Run(address string) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.logWarnings()
 	a.Logger.Info(fmt.Sprintf("🚀 Kese server starting on %s", address))
 	return http.ListenAndServe(address, a)
 }
 
 // RunTLS starts the HTTPS server on the specified address with TLS config.
 func (a *App) RunTLS(address, certFile, keyFile string) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.logWarnings()
 	a.Logger.Info(fmt.Sprintf("🔒 Kese server starting on %s (TLS)", address))
 	return http.ListenAndServeTLS(address, certFile, keyFile, a)
 }
@@ -0,0 +1,102 @@
+package kese
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// benchApp registers a realistic mix of static and parameterized routes.
+func benchApp() *App {
+	app := New()
+	handler := func(c *context.Context) error {
+		return nil
+	}
+
+	app.GET("/", handler)
+	app.GET("/healthz", handler)
+	app.GET("/metrics", handler)
+	app.GET("/api/v1/users", handler)
+	app.GET("/api/v1/users/:id", handler)
+	app.GET("/api/v1/users/:id/posts", handler)
+	app.GET("/api/v1/users/:id/posts/:postId", handler)
+	app.POST("/api/v1/users", handler)
+	return app
+}
+
+// BenchmarkRouterMatch compares the static fast path with a full tree walk.
+// The "tree" cases use a trailing slash, which misses the static map and
+// resolves through the radix tree.
+func BenchmarkRouterMatch(b *testing.B) {
+	r := benchApp().Router()
+
+	cases := []struct {
+		name string
+		path string
+	}{
+		{"static-fast-path", "/api/v1/users"},
+		{"static-tree", "/api/v1/users/"},
+		{"health-fast-path", "/healthz"},
+		{"health-tree", "/healthz/"},
+		{"param-1", "/api/v1/users/42"},
+		{"param-2", "/api/v1/users/42/posts/7"},
+	}
+
+	for _, bc := range cases {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.Match("GET", bc.path)
+			}
+		})
+	}
+}
+
+// BenchmarkRouteMatching measures a full request through ServeHTTP.
+// Contexts are pooled, so static routes should report 0 allocs/op.
+func BenchmarkRouteMatching(b *testing.B) {
+	app := benchApp()
+
+	cases := []struct {
+		name string
+		path string
+	}{
+		{"static", "/healthz"},
+		{"param", "/api/v1/users/42/posts/7"},
+	}
+
+	for _, bc := range cases {
+		b.Run(bc.name, func(b *testing.B) {
+			req := httptest.NewRequest("GET", bc.path, nil)
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				app.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+// BenchmarkRenderTemplate renders a template with the request-aware
+// functions bound, as every page render does.
+func BenchmarkRenderTemplate(b *testing.B) {
+	engine := NewTemplateEngine("testdata/templates")
+	if err := engine.LoadTemplates("*.html"); err != nil {
+		b.Fatal(err)
+	}
+	app := New()
+	app.SetTemplateEngine(engine)
+	app.GET("/form", func(c *context.Context) error {
+		c.Set("csrf_token", "abc123")
+		return app.RenderTemplate(c, 200, "form.html", nil)
+	})
+	req := httptest.NewRequest("GET", "/form", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
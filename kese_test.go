@@ -1,13 +1,28 @@
 package kese
 
 import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/JedizLaPulga/kese/analytics"
 	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/logger"
 )
 
 func TestNew(t *testing.T) {
@@ -349,3 +364,1430 @@ func TestMethodNotAllowed(t *testing.T) {
 		t.Errorf("Expected status 404, got %d", w.Code)
 	}
 }
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	app := New()
+	app.Router().RedirectTrailingSlash = true
+
+	app.GET("/users", func(c *context.Context) error {
+		return c.String(200, "users")
+	})
+	app.POST("/users", func(c *context.Context) error {
+		return c.String(201, "created")
+	})
+
+	req := httptest.NewRequest("GET", "/users/?page=2", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Errorf("Expected status 301, got %d", w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/users?page=2" {
+		t.Errorf("Expected Location /users?page=2, got %q", location)
+	}
+
+	req = httptest.NewRequest("POST", "/users/", nil)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPermanentRedirect {
+		t.Errorf("Expected status 308 for POST, got %d", w.Code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	handler := func(c *context.Context) error {
+		return c.String(200, "OK")
+	}
+
+	app := New()
+	app.GET("/users/:id", handler)
+	app.GET("/users/:id/posts", handler)
+	if err := app.Validate(); err != nil {
+		t.Fatalf("Expected valid app, got %v", err)
+	}
+
+	app.GET("/users/:id", handler)
+	app.GET("/users/:name/comments", handler)
+	app.Use(func(next HandlerFunc) HandlerFunc { return next })
+
+	err := app.Validate()
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expected ConfigErrors, got %v", err)
+	}
+	if len(problems) != 3 {
+		t.Fatalf("Expected 3 problems, got %d: %v", len(problems), err)
+	}
+
+	if err := app.Run(":0"); err == nil {
+		t.Error("Run should refuse to start a misconfigured app")
+	}
+
+	// Problems reported by middleware constructors belong to the app the
+	// middleware is registered with
+	ReportConfigError(&ConfigError{Component: "tenant", Problem: "no tenants"})
+	other := New()
+	other.Group("/t", func(next HandlerFunc) HandlerFunc { return next })
+	if err := other.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Component != "tenant" {
+		t.Errorf("Expected the reported problem, got %v", err)
+	}
+	if err := New().Validate(); err != nil {
+		t.Errorf("Expected reported problems to be claimed once, got %v", err)
+	}
+}
+
+func TestRouteMetadata(t *testing.T) {
+	app := New()
+
+	var seenPath string
+	var seenMeta interface{}
+	app.GET("/users/:id", func(c *context.Context) error {
+		seenPath = c.RoutePath()
+		seenMeta = c.RouteMeta("team")
+		return c.String(200, "OK")
+	}).Doc("Returns a user").Auth("JWT").Set("team", "accounts")
+
+	app.Group("/admin").POST("/flush", func(c *context.Context) error {
+		return c.NoContent()
+	}).Doc("Flushes <caches>")
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if seenPath != "/users/:id" {
+		t.Errorf("Expected route path /users/:id, got %q", seenPath)
+	}
+	if seenMeta != "accounts" {
+		t.Errorf("Expected route metadata 'accounts', got %v", seenMeta)
+	}
+
+	routes := app.Routes()
+	if len(routes) != 2 || routes[0].Path != "/admin/flush" {
+		t.Fatalf("Expected 2 sorted routes, got %+v", routes)
+	}
+
+	app.GET("/routes", app.RoutesHandler())
+	req = httptest.NewRequest("GET", "/routes", nil)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, "Returns a user") || !strings.Contains(body, "JWT") {
+		t.Errorf("Expected route listing to contain docs and auth, got %q", body)
+	}
+	if !strings.Contains(body, "Flushes &lt;caches&gt;") {
+		t.Error("Route documentation should be HTML-escaped")
+	}
+}
+
+func TestExpectContinue(t *testing.T) {
+	app := New()
+	app.MaxBodySize = 1024
+
+	bodyRead := false
+	app.POST("/upload", func(c *context.Context) error {
+		bodyRead = true
+		c.BodyBytes()
+		return c.NoContent()
+	})
+
+	req := httptest.NewRequest("POST", "/upload", strings.NewReader("small"))
+	req.Header.Set("Expect", "100-continue")
+	req.ContentLength = 4096
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+	if bodyRead {
+		t.Error("Handler should not run for an oversized upload")
+	}
+
+	req = httptest.NewRequest("POST", "/upload", strings.NewReader("small"))
+	req.Header.Set("Expect", "100-continue")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 for an acceptable upload, got %d", w.Code)
+	}
+}
+
+func TestBindContentNegotiation(t *testing.T) {
+	type input struct {
+		Name string `json:"name" xml:"name" form:"name" query:"name"`
+	}
+
+	app := New()
+	app.MaxBodySize = 64
+	handler := func(c *context.Context) error {
+		var in input
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		return c.String(200, in.Name)
+	}
+	app.POST("/bind", handler)
+	app.GET("/bind", handler)
+
+	tests := []struct {
+		method      string
+		contentType string
+		body        string
+		status      int
+		expected    string
+	}{
+		{"POST", "application/json", `{"name":"json"}`, 200, "json"},
+		{"POST", "application/xml", `<input><name>xml</name></input>`, 200, "xml"},
+		{"POST", "application/x-www-form-urlencoded", "name=form", 200, "form"},
+		{"GET", "", "", 200, "query"},
+		{"POST", "application/json", `{"name":`, 400, ""},
+		{"POST", "text/csv", "name\nx", 415, ""},
+		{"POST", "application/json", `{"name":"` + strings.Repeat("x", 100) + `"}`, 413, ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "/bind?name=query", strings.NewReader(test.body))
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d (%s)", test.method, test.contentType, test.status, w.Code, w.Body.String())
+			continue
+		}
+		if test.expected != "" && w.Body.String() != test.expected {
+			t.Errorf("%s %s: expected body %q, got %q", test.method, test.contentType, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestBindAndValidate(t *testing.T) {
+	type input struct {
+		Name  string `json:"name" validate:"required,min=3"`
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	app := New()
+	app.POST("/users", func(c *context.Context) error {
+		var in input
+		if err := c.BindAndValidate(&in); err != nil {
+			return err
+		}
+		return c.String(201, in.Name)
+	})
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"al","email":"bad"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"name"`) || !strings.Contains(w.Body.String(), `"email"`) {
+		t.Errorf("Expected field errors for name and email, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"alice","email":"alice@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != 201 || w.Body.String() != "alice" {
+		t.Errorf("Expected 201 alice, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestVerifyExamples(t *testing.T) {
+	app := New()
+	app.POST("/echo", func(c *context.Context) error {
+		var in map[string]interface{}
+		if err := c.Body(&in); err != nil {
+			return err
+		}
+		return c.JSON(201, in)
+	}).Example(Example{
+		Name:     "echo",
+		Request:  map[string]interface{}{"title": "Buy milk"},
+		Status:   201,
+		Response: map[string]interface{}{"title": "Buy milk"},
+	})
+	app.GET("/users/:id", func(c *context.Context) error {
+		return c.JSON(200, map[string]string{"id": c.Param("id")})
+	}).Example(Example{
+		Name:     "wrong body",
+		Path:     "/users/7",
+		Response: map[string]string{"id": "8"},
+	}).Example(Example{
+		Name: "missing path",
+	})
+
+	errs := app.VerifyExamples()
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 failing examples, got %d: %v", len(errs), errs)
+	}
+	for _, err := range errs {
+		if !strings.Contains(err.Error(), "/users/:id") {
+			t.Errorf("Unexpected failure: %v", err)
+		}
+	}
+}
+
+func slowMiddleware(next HandlerFunc) HandlerFunc {
+	return func(c *context.Context) error {
+		time.Sleep(5 * time.Millisecond)
+		return next(c)
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	app := New()
+	app.TraceMiddleware = true
+	app.ServerTiming = true
+	app.Use(slowMiddleware)
+
+	var segments []Segment
+	api := app.Group("/api", func(next HandlerFunc) HandlerFunc {
+		return func(c *context.Context) error {
+			err := next(c)
+			segments = Segments(c)
+			return err
+		}
+	})
+	api.GET("/slow", func(c *context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return c.String(200, "done")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/slow", nil))
+
+	if len(segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %+v", segments)
+	}
+	if segments[0].Name != "slowMiddleware" || segments[2].Name != "handler" {
+		t.Errorf("Unexpected segment names: %+v", segments)
+	}
+	if segments[0].Duration < 5*time.Millisecond {
+		t.Errorf("Expected slowMiddleware to take ~5ms, got %v", segments[0].Duration)
+	}
+	if segments[2].Duration < 10*time.Millisecond {
+		t.Errorf("Expected handler to take >= 10ms, got %v", segments[2].Duration)
+	}
+
+	header := w.Header().Get("Server-Timing")
+	if !strings.Contains(header, "slowMiddleware;dur=") || !strings.Contains(header, "handler;dur=") {
+		t.Errorf("Unexpected Server-Timing header: %q", header)
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	handler := func(c *context.Context) error {
+		c.ServerTiming("db", 12*time.Millisecond, `List "users"`)
+		c.ServerTiming("cache", 0, "")
+		return c.String(200, "ok")
+	}
+
+	app := New()
+	app.ServerTiming = true
+	app.GET("/users", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+
+	expected := `db;dur=12.000;desc="List \"users\"", cache;dur=0.000`
+	if header := w.Header().Get("Server-Timing"); header != expected {
+		t.Errorf("Expected Server-Timing %q, got %q", expected, header)
+	}
+
+	disabled := New()
+	disabled.GET("/users", handler)
+	w = httptest.NewRecorder()
+	disabled.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+	if header := w.Header().Get("Server-Timing"); header != "" {
+		t.Errorf("Expected no Server-Timing header when disabled, got %q", header)
+	}
+}
+
+func TestTemplateCSRFField(t *testing.T) {
+	engine := NewTemplateEngine("testdata/templates")
+	if err := engine.LoadTemplates("*.html"); err != nil {
+		t.Fatalf("LoadTemplates error: %v", err)
+	}
+
+	app := New()
+	app.SetTemplateEngine(engine)
+	app.GET("/form", func(c *context.Context) error {
+		c.Set("csrf_token", "abc<123>")
+		return app.RenderTemplate(c, 200, "form.html", nil)
+	})
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/form", nil))
+
+		expected := `<input type="hidden" name="csrf_token" value="abc&lt;123&gt;">`
+		if !strings.Contains(w.Body.String(), expected) {
+			t.Fatalf("Expected CSRF field in %q", w.Body.String())
+		}
+	}
+
+	// Concurrent renders each see their own request's token
+	app.GET("/form/:token", func(c *context.Context) error {
+		c.Set("csrf_token", c.Param("token"))
+		return app.RenderTemplate(c, 200, "form.html", nil)
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest("GET", "/form/"+token, nil))
+			if !strings.Contains(w.Body.String(), `value="`+token+`"`) {
+				t.Errorf("Expected token %s in %q", token, w.Body.String())
+			}
+		}("t" + strconv.Itoa(i))
+	}
+	wg.Wait()
+}
+
+func TestTemplateFormRepopulation(t *testing.T) {
+	engine := NewTemplateEngine("testdata/templates")
+	if err := engine.LoadTemplates("*.html"); err != nil {
+		t.Fatalf("LoadTemplates error: %v", err)
+	}
+
+	type signupInput struct {
+		Email    string `json:"email" form:"email" validate:"required,email"`
+		Password string `json:"password" form:"password" validate:"required,min=8"`
+		Bio      string `json:"bio" form:"bio"`
+	}
+
+	app := New()
+	app.SetTemplateEngine(engine)
+	app.GET("/signup", func(c *context.Context) error {
+		return app.RenderTemplate(c, 200, "signup.html", nil)
+	})
+	app.POST("/signup", func(c *context.Context) error {
+		var in signupInput
+		if err := c.BindAndValidate(&in); err != nil {
+			c.FlashInput(err)
+			c.Flash("error", "Please fix the errors below")
+			return c.Redirect(303, "/signup")
+		}
+		return c.String(200, "ok")
+	})
+
+	form := url.Values{"email": {"ada<at>example"}, "password": {"hunter2"}, "bio": {"Hi & bye"}}
+	r := httptest.NewRequest("POST", "/signup", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != 303 {
+		t.Fatalf("Expected redirect, got %d: %s", w.Code, w.Body.String())
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != context.FlashCookieName {
+		t.Fatalf("Expected one flash cookie, got %v", cookies)
+	}
+
+	r = httptest.NewRequest("GET", "/signup", nil)
+	r.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`<p class="alert">Please fix the errors below</p>`,
+		`<form method="post" action="/signup"><input type="hidden" name="csrf_token" value="">`,
+		`<div class="field field-invalid"><label for="email">Email</label><input type="email" value="ada&lt;at&gt;example" id="email" name="email" aria-invalid="true" aria-describedby="email-error"><p class="field-error" id="email-error">`,
+		`<input type="password" value="" id="password" name="password" aria-invalid="true"`,
+		`<div class="field"><label for="bio">About you</label><textarea id="bio" name="bio">Hi &amp; bye</textarea></div>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %s in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "hunter2") {
+		t.Error("Password must not be repopulated")
+	}
+
+	// The flash is shown once
+	cleared := w.Result().Cookies()
+	if len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("Expected the flash cookie to be deleted, got %v", cleared)
+	}
+}
+
+func TestDeprecatedRoute(t *testing.T) {
+	var logs bytes.Buffer
+	app := New()
+	app.Logger = logger.NewWithConfig(logger.WarnLevel, &logs)
+
+	sunset := time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)
+	app.GET("/v1/users/:id", func(c *context.Context) error {
+		c.Set("userID", "42")
+		return c.String(200, "ok")
+	}).Deprecated(sunset, "https://example.com/migrate")
+	app.GET("/v2/users/:id", func(c *context.Context) error {
+		return c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/v1/users/7", nil))
+
+	if !strings.HasPrefix(w.Header().Get("Deprecation"), "@") {
+		t.Errorf("Expected Deprecation date header, got %q", w.Header().Get("Deprecation"))
+	}
+	if got := w.Header().Get("Sunset"); got != "Sun, 30 Jun 2030 00:00:00 GMT" {
+		t.Errorf("Unexpected Sunset header %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `<https://example.com/migrate>; rel="deprecation"` {
+		t.Errorf("Unexpected Link header %q", got)
+	}
+	if !strings.Contains(logs.String(), "Deprecated route called") || !strings.Contains(logs.String(), "user:42") {
+		t.Errorf("Expected deprecation log with caller, got %q", logs.String())
+	}
+
+	logs.Reset()
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/v2/users/7", nil))
+	if w.Header().Get("Deprecation") != "" || logs.Len() != 0 {
+		t.Error("Expected no deprecation signals on a current route")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	handlerA := func(c *context.Context) error { return c.String(200, "a") }
+	handlerB := func(c *context.Context) error { return c.String(200, "b") }
+	byUser := func(c *context.Context) string { return c.Query("user") }
+
+	app := New()
+	app.GET("/search", Split(80, handlerA, 20, handlerB, byUser))
+	app.GET("/all-b", Split(0, handlerA, 1, handlerB, byUser))
+
+	serve := func(url string) string {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w.Body.String()
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		url := "/search?user=" + strconv.Itoa(i)
+		variant := serve(url)
+		if serve(url) != variant {
+			t.Fatalf("Expected user %d to be routed consistently", i)
+		}
+		counts[variant]++
+	}
+	if counts["b"] < 150 || counts["b"] > 250 {
+		t.Errorf("Expected ~20%% of users on b, got %d/1000", counts["b"])
+	}
+
+	if serve("/all-b?user=x") != "b" {
+		t.Error("Expected zero weight to route all traffic to b")
+	}
+
+	defer func() {
+		if _, ok := recover().(*ConfigError); !ok {
+			t.Error("Expected ConfigError panic for zero weights")
+		}
+	}()
+	Split(0, handlerA, 0, handlerB, nil)
+}
+
+func TestProblemErrorHandler(t *testing.T) {
+	app := New()
+	app.SetErrorHandler(ProblemErrorHandler)
+	app.GET("/credit", func(c *context.Context) error {
+		return &Problem{
+			Type:       "https://example.com/probs/out-of-credit",
+			Title:      "You do not have enough credit.",
+			Status:     403,
+			Extensions: map[string]interface{}{"balance": 30, "status": 999},
+		}
+	})
+	app.GET("/invalid", func(c *context.Context) error {
+		verr := NewValidationError()
+		verr.Add("email", "is required")
+		return verr
+	})
+	app.GET("/boom", func(c *context.Context) error {
+		return errors.New("database password leaked")
+	})
+
+	tests := []struct {
+		path     string
+		status   int
+		expected string
+	}{
+		{"/credit", 403, `{"balance":30,"instance":"/credit","status":403,"title":"You do not have enough credit.","type":"https://example.com/probs/out-of-credit"}`},
+		{"/invalid", 400, `{"detail":"Validation failed","fields":{"email":"is required"},"instance":"/invalid","status":400,"title":"Bad Request","type":"about:blank"}`},
+		{"/boom", 500, `{"instance":"/boom","status":500,"title":"Internal Server Error","type":"about:blank"}`},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.path, test.status, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != MIMEProblemJSON {
+			t.Errorf("%s: expected problem+json, got %q", test.path, ct)
+		}
+		if w.Body.String() != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.path, test.expected, w.Body.String())
+		}
+	}
+
+	// Problems are honored by the default handler too
+	plain := New()
+	plain.GET("/gone", func(c *context.Context) error {
+		return NewProblem(410, "This resource was removed")
+	})
+	w := httptest.NewRecorder()
+	plain.ServeHTTP(w, httptest.NewRequest("GET", "/gone", nil))
+	if w.Code != 410 || w.Header().Get("Content-Type") != MIMEProblemJSON {
+		t.Errorf("Expected 410 problem, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	dbErr := errors.New("sql: no rows in result set")
+
+	app := New()
+	app.GET("/users/:id", func(c *context.Context) error {
+		return NewHTTPError(404, "user not found").WithInternal(dbErr)
+	})
+	app.GET("/admin", func(c *context.Context) error {
+		return ErrForbidden
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/users/1", nil))
+	if w.Code != 404 || !strings.Contains(w.Body.String(), `"error":"user not found"`) {
+		t.Errorf("Unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "sql") {
+		t.Error("Internal error must not be sent to the client")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/admin", nil))
+	if w.Code != 403 || !strings.Contains(w.Body.String(), `"error":"Forbidden"`) {
+		t.Errorf("Unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	err := ErrNotFound.WithMessage("todo not found").WithInternal(dbErr)
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) || !errors.Is(err, dbErr) {
+		t.Error("Expected errors.Is to match by status and reach the internal error")
+	}
+	if ErrNotFound.Message != "Not Found" || ErrNotFound.Internal != nil {
+		t.Error("Expected sentinel errors to stay unmodified")
+	}
+}
+
+func TestMultipartTempFileCleanup(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+
+	app := New()
+	if err := app.SetUploadTempDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+	if err := app.SetUploadTempDir(dir); err != nil {
+		t.Fatalf("SetUploadTempDir failed: %v", err)
+	}
+
+	var tempPath string
+	var order []int
+	app.POST("/upload", func(c *context.Context) error {
+		c.OnFinish(func() { order = append(order, 1) })
+		c.OnFinish(func() { order = append(order, 2) })
+
+		if err := c.Request.ParseMultipartForm(1024); err != nil {
+			return err
+		}
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		tempPath = file.(*os.File).Name()
+
+		files, size := c.MultipartTempFiles()
+		return c.JSON(200, map[string]int64{"files": int64(files), "size": size})
+	})
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	part, _ := mw.CreateFormFile("file", "big.bin")
+	part.Write(bytes.Repeat([]byte("x"), 64*1024))
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/upload", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"files":1`) || !strings.Contains(w.Body.String(), `"size":65536`) {
+		t.Fatalf("Unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	if filepath.Dir(tempPath) != dir {
+		t.Errorf("Expected upload spilled to %s, got %s", dir, tempPath)
+	}
+	if _, err := os.Stat(tempPath); !os.IsNotExist(err) {
+		t.Error("Expected temp file to be removed after the request")
+	}
+	if len(order) != 2 || order[0] != 2 || order[1] != 1 {
+		t.Errorf("Expected OnFinish callbacks in reverse order, got %v", order)
+	}
+}
+
+func TestMapError(t *testing.T) {
+	errNoRows := errors.New("sql: no rows in result set")
+	type lockError struct{ error }
+
+	app := New()
+	app.MapError(errNoRows, 404)
+	app.MapErrorFunc(func(err error) (int, bool) {
+		var lockErr lockError
+		return 409, errors.As(err, &lockErr)
+	})
+
+	app.GET("/users/:id", func(c *context.Context) error {
+		return fmt.Errorf("find user: %w", errNoRows)
+	})
+	app.GET("/locked", func(c *context.Context) error {
+		return lockError{errors.New("row locked")}
+	})
+	app.GET("/explicit", func(c *context.Context) error {
+		return ErrGone.WithInternal(errNoRows)
+	})
+	app.GET("/other", func(c *context.Context) error {
+		return errors.New("boom")
+	})
+
+	tests := []struct {
+		url      string
+		status   int
+		contains string
+	}{
+		{"/users/1", 404, `"error":"Not Found"`},
+		{"/locked", 409, `"error":"Conflict"`},
+		{"/explicit", 410, `"error":"Gone"`},
+		{"/other", 500, `"error":"Internal Server Error"`},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", test.url, nil))
+		if w.Code != test.status || !strings.Contains(w.Body.String(), test.contains) {
+			t.Errorf("%s: unexpected response %d %s", test.url, w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "sql") {
+			t.Errorf("%s: mapped error leaked to the client", test.url)
+		}
+	}
+}
+
+func TestOnError(t *testing.T) {
+	streamErr := errors.New("upstream closed")
+
+	var logs bytes.Buffer
+	app := New()
+	app.Logger = logger.NewWithConfig(logger.ErrorLevel, &logs)
+	app.GET("/stream", func(c *context.Context) error {
+		c.String(200, "partial")
+		return streamErr
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/stream", nil))
+	if w.Body.String() != "partial" {
+		t.Errorf("Response must not change after write, got %q", w.Body.String())
+	}
+	if !strings.Contains(logs.String(), "upstream closed") || !strings.Contains(logs.String(), "/stream") {
+		t.Errorf("Expected error logged by default, got %q", logs.String())
+	}
+
+	var reported error
+	var status int
+	app.OnError(func(c *context.Context, err error) {
+		reported, status = err, c.StatusCode()
+	})
+	logs.Reset()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/stream", nil))
+	if reported != streamErr || status != 200 {
+		t.Errorf("Expected hook to receive the error, got %v (status %d)", reported, status)
+	}
+	if logs.Len() != 0 {
+		t.Error("Expected hook to replace default logging")
+	}
+}
+
+func TestShutdownDrain(t *testing.T) {
+	app := New()
+	app.Logger = logger.NewWithConfig(logger.ErrorLevel, &bytes.Buffer{})
+	app.ShutdownRequestTimeout = 50 * time.Millisecond
+
+	shutdownStarted := false
+	app.OnShutdown(func() { shutdownStarted = true })
+
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	app.GET("/slow", func(c *context.Context) error {
+		close(started)
+		<-c.Request.Context().Done()
+		cancelled <- c.Request.Context().Err()
+		return nil
+	})
+
+	requestCtx, cancelRequests := stdcontext.WithCancel(stdcontext.Background())
+	defer cancelRequests()
+	server := httptest.NewUnstartedServer(app)
+	server.Config.BaseContext = func(net.Listener) stdcontext.Context { return requestCtx }
+	server.Start()
+	defer server.Close()
+
+	go http.Get(server.URL + "/slow")
+	<-started
+	if app.InFlight() != 1 {
+		t.Errorf("Expected 1 request in flight, got %d", app.InFlight())
+	}
+
+	if err := app.shutdown(server.Config, 2*time.Second, cancelRequests); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if !shutdownStarted || !app.Draining() {
+		t.Error("Expected OnShutdown hooks to run and the app to be draining")
+	}
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, stdcontext.Canceled) {
+			t.Errorf("Expected request context cancelled, got %v", err)
+		}
+	default:
+		t.Error("Expected in-flight request cancelled by the request deadline")
+	}
+	if app.InFlight() != 0 {
+		t.Errorf("Expected no requests in flight, got %d", app.InFlight())
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	var logs bytes.Buffer
+	app := New()
+	app.Logger = logger.NewWithConfig(logger.InfoLevel, &logs)
+
+	if err := app.ReloadConfig("test"); !errors.Is(err, ErrNoConfigLoader) {
+		t.Errorf("Expected ErrNoConfigLoader, got %v", err)
+	}
+
+	next := RuntimeConfig{
+		LogLevel:    logger.DebugLevel,
+		Features:    map[string]bool{"beta": true},
+		Maintenance: true,
+	}
+	app.SetConfigLoader(func() (RuntimeConfig, error) { return next, nil })
+
+	app.GET("/health", func(c *context.Context) error {
+		return c.String(200, "ok")
+	}).AllowInMaintenance()
+	app.GET("/orders", func(c *context.Context) error {
+		return c.String(200, "orders")
+	})
+	app.POST("/admin/reload", app.ReloadHandler())
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/admin/reload", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"log_level":"DEBUG"`) {
+		t.Fatalf("Unexpected reload response: %d %s", w.Code, w.Body.String())
+	}
+
+	if app.Logger.Level() != logger.DebugLevel || !app.Feature("beta") || app.Feature("other") {
+		t.Error("Expected reloaded log level and feature flags to apply")
+	}
+	for _, change := range []string{"log_level=DEBUG (was INFO)", "maintenance=true (was false)", "feature.beta=true (was false)", "admin:ip:"} {
+		if !strings.Contains(logs.String(), change) {
+			t.Errorf("Expected audit log to contain %q, got %s", change, logs.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/orders", nil))
+	if w.Code != 503 {
+		t.Errorf("Expected 503 in maintenance mode, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 200 {
+		t.Errorf("Expected exempt route to stay available, got %d", w.Code)
+	}
+
+	app.SetConfigLoader(func() (RuntimeConfig, error) { return RuntimeConfig{}, errors.New("bad file") })
+	if err := app.ReloadConfig("SIGHUP"); err == nil || !app.Maintenance() {
+		t.Error("Expected failed reload to keep the current configuration")
+	}
+}
+
+func TestK8sDefaults(t *testing.T) {
+	t.Setenv("POD_NAME", "api-7d4f")
+	t.Setenv("POD_NAMESPACE", "shop")
+
+	var logs bytes.Buffer
+	app := New()
+	app.Logger = logger.NewWithConfig(logger.InfoLevel, &logs)
+
+	dbErr := errors.New("connection refused")
+	app.AddHealthCheck("database", func() error { return dbErr })
+
+	warm := errors.New("cache warming")
+	config := DefaultK8sConfig()
+	config.StartupCheck = func() error { return warm }
+	K8sDefaultsWithConfig(app, config)
+
+	probe := func(path string) int {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Code
+	}
+
+	if probe("/livez") != 200 {
+		t.Error("Expected liveness to pass regardless of health checks")
+	}
+	if probe("/readyz") != 503 {
+		t.Error("Expected readiness to fail with a failing health check")
+	}
+	dbErr = nil
+	if probe("/readyz") != 200 {
+		t.Error("Expected readiness to pass once health checks pass")
+	}
+
+	if probe("/startupz") != 503 {
+		t.Error("Expected startup probe to fail until the startup check passes")
+	}
+	warm = nil
+	if probe("/startupz") != 200 {
+		t.Error("Expected startup probe to pass")
+	}
+	warm = errors.New("flapping")
+	if probe("/startupz") != 200 {
+		t.Error("Expected startup probe to keep passing once started")
+	}
+
+	if app.ShutdownDelay != 5*time.Second || app.ShutdownRequestTimeout != 20*time.Second {
+		t.Errorf("Unexpected shutdown settings %v %v", app.ShutdownDelay, app.ShutdownRequestTimeout)
+	}
+	if len(app.Routes()) != 0 {
+		t.Error("Expected probes to stay out of the route table")
+	}
+
+	app.draining.Store(true)
+	if probe("/readyz") != 503 || probe("/livez") != 200 {
+		t.Error("Expected readiness to fail and liveness to pass while draining")
+	}
+
+	app.Logger.Info("hello")
+	if !strings.Contains(logs.String(), `"pod":"api-7d4f"`) || !strings.Contains(logs.String(), `"namespace":"shop"`) {
+		t.Errorf("Expected pod fields in logs, got %s", logs.String())
+	}
+
+	defer func() {
+		if _, ok := recover().(*ConfigError); !ok {
+			t.Error("Expected invalid config to panic with *ConfigError")
+		}
+	}()
+	K8sDefaultsWithConfig(New(), K8sConfig{LivenessPath: "livez"})
+}
+
+func TestRequestIDInErrors(t *testing.T) {
+	app := New()
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *context.Context) error {
+			c.SetRequestID("req-42")
+			return next(c)
+		}
+	})
+	app.GET("/fail", func(c *context.Context) error {
+		return ErrConflict
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+	if w.Code != 409 || !strings.Contains(w.Body.String(), `"request_id":"req-42"`) || w.Header().Get("X-Request-ID") != "req-42" {
+		t.Errorf("Expected request ID in error response, got %s", w.Body.String())
+	}
+
+	app.SetErrorHandler(ProblemErrorHandler)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+	if !strings.Contains(w.Body.String(), `"request_id":"req-42"`) {
+		t.Errorf("Expected request ID in problem response, got %s", w.Body.String())
+	}
+
+	// Unmatched routes carry a request ID propagated by an upstream proxy
+	req := httptest.NewRequest("GET", "/missing", nil)
+	req.Header.Set("X-Request-ID", "edge-7")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != 404 || !strings.Contains(w.Body.String(), `"request_id":"edge-7"`) {
+		t.Errorf("Expected propagated request ID in 404, got %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/missing", nil)
+	req.Header.Set("X-Request-ID", "bad id\n")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if strings.Contains(w.Body.String(), "request_id") {
+		t.Error("Expected invalid request ID to be ignored")
+	}
+
+	// With RequestIDs, unmatched routes get a generated ID
+	app.RequestIDs = true
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	id := w.Header().Get("X-Request-ID")
+	if w.Code != 404 || id == "" || !strings.Contains(w.Body.String(), `"request_id":"`+id+`"`) {
+		t.Errorf("Expected generated request ID in 404, got %q %s", id, w.Body.String())
+	}
+	req.Header.Set("X-Request-ID", "edge-8")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Header().Get("X-Request-ID") != "edge-8" {
+		t.Errorf("Expected propagated request ID to be kept, got %q", w.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestJSONEncodingErrorResponse(t *testing.T) {
+	app := New()
+	app.GET("/bad", func(c *context.Context) error {
+		return c.JSON(200, map[string]interface{}{"updates": make(chan int)})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/bad", nil))
+	if w.Code != 500 || w.Body.String() != "{\"error\":\"Internal Server Error\"}\n" {
+		t.Errorf("Expected clean 500, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRouteProduces(t *testing.T) {
+	app := New()
+	app.GET("/report", func(c *context.Context) error {
+		if c.Negotiate() == "text/csv" {
+			return c.Bytes(200, "text/csv", []byte("a,b\n"))
+		}
+		return c.JSON(200, map[string]string{"a": "b"})
+	}).Produces("application/json", "text/csv")
+
+	tests := []struct {
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"", 200, "application/json"},
+		{"text/csv", 200, "text/csv"},
+		{"application/xml", 406, MIMEProblemJSON},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/report", nil)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != tt.status || w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("Accept %q: got %d %q", tt.accept, w.Code, w.Header().Get("Content-Type"))
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: expected Vary: Accept", tt.accept)
+		}
+	}
+}
+
+func TestConcurrentRouteRegistration(t *testing.T) {
+	app := New()
+	app.GET("/ping", func(c *context.Context) error {
+		return c.String(200, "pong")
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ping", nil))
+
+	// Serving has started; a plugin registers its routes late
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			app.GET(fmt.Sprintf("/plugin/%d", i), func(c *context.Context) error {
+				return c.String(200, "plugin")
+			})
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+		if w.Code != 200 {
+			t.Fatalf("Expected 200 during registration, got %d", w.Code)
+		}
+	}
+	<-done
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/plugin/49", nil))
+	if w.Code != 200 || len(app.Routes()) != 51 {
+		t.Errorf("Expected late routes to be served, got %d with %d routes", w.Code, len(app.Routes()))
+	}
+}
+
+func TestRouteBodyPolicy(t *testing.T) {
+	app := New()
+	app.MaxBodySize = 16
+
+	app.PUT("/blobs", func(c *context.Context) error {
+		if _, err := c.BodyBytes(); !errors.Is(err, context.ErrBodyBufferingDisabled) {
+			t.Errorf("Expected ErrBodyBufferingDisabled, got %v", err)
+		}
+		data, err := io.ReadAll(c.BodyReader())
+		if err != nil {
+			return err
+		}
+		return c.String(200, strconv.Itoa(len(data)))
+	}).StreamBody()
+
+	handlerRan := false
+	app.POST("/hooks", func(c *context.Context) error {
+		handlerRan = true
+		return c.NoContent()
+	}).PrefetchBody(8)
+
+	body := strings.Repeat("x", 100)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("PUT", "/blobs", strings.NewReader(body)))
+	if w.Code != 200 || w.Body.String() != "100" {
+		t.Errorf("Expected streamed body past MaxBodySize, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/hooks", strings.NewReader("0123456789")))
+	if w.Code != http.StatusRequestEntityTooLarge || handlerRan {
+		t.Errorf("Expected 413 before the handler, got %d (handler ran: %v)", w.Code, handlerRan)
+	}
+
+	// Prefetched bodies still reach form parsing, in memory or spilled to disk
+	type signup struct {
+		Name string `form:"name"`
+	}
+	app.MaxBodySize = 1 << 20
+	app.BodyMemoryLimit = 4
+	app.POST("/signup", func(c *context.Context) error {
+		var in signup
+		if err := c.BindForm(&in); err != nil {
+			return err
+		}
+		return c.String(200, in.Name+" "+c.FormValue("name"))
+	}).PrefetchBody(1 << 10)
+	for _, name := range []string{"bo", "robert"} {
+		req := httptest.NewRequest("POST", "/signup", strings.NewReader("name="+name))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != 200 || w.Body.String() != name+" "+name {
+			t.Errorf("Expected prefetched form %q, got %d %q", name, w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest("PUT", "/blobs", strings.NewReader(body))
+	req.Header.Set("Expect", "100-continue")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Errorf("Expected streaming route to accept large Expect: 100-continue uploads, got %d", w.Code)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	type Owner struct {
+		Name string `json:"name" validate:"required,min=2"`
+	}
+	type Todo struct {
+		ID      int64     `json:"id"`
+		Title   string    `json:"title" validate:"required,max=100"`
+		Tags    []string  `json:"tags,omitempty" validate:"max=5"`
+		Owner   *Owner    `json:"owner"`
+		Due     time.Time `json:"due"`
+		Status  string    `json:"status" validate:"oneof=open done"`
+		Secret  string    `json:"-"`
+		Created time.Time `json:"created_at"`
+	}
+	type Filter struct {
+		Page int `query:"page" validate:"gte=1"`
+	}
+
+	app := New()
+	app.POST("/todos", func(c *context.Context) error { return nil }).
+		Request(Todo{}).
+		Response(201, Todo{}).
+		Response(400, nil)
+	app.GET("/todos/:id", func(c *context.Context) error { return nil }).
+		Query(Filter{}).
+		Response(200, []Todo{})
+	app.SchemaHandler()
+
+	schema := app.Schema()
+	if len(schema.Routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %d", len(schema.Routes))
+	}
+
+	get := schema.Routes[1]
+	if get.Path != "/todos/:id" || len(get.Params) != 1 || get.Params[0] != "id" {
+		t.Errorf("Unexpected route: %+v", get)
+	}
+	if get.Responses["200"].Items.Ref != "#/types/Todo" || get.Query.Ref != "#/types/FilterQuery" {
+		t.Errorf("Expected references to named types, got %+v", get)
+	}
+	if page := schema.Types["FilterQuery"].Properties["page"]; page.Type != "integer" || *page.Minimum != 1 {
+		t.Errorf("Unexpected query schema: %+v", page)
+	}
+
+	todo := schema.Types["Todo"]
+	if todo == nil || len(todo.Required) != 1 || todo.Required[0] != "title" {
+		t.Fatalf("Unexpected Todo schema: %+v", todo)
+	}
+	if title := todo.Properties["title"]; title.Type != "string" || *title.MaxLength != 100 {
+		t.Errorf("Unexpected title schema: %+v", title)
+	}
+	if tags := todo.Properties["tags"]; tags.Type != "array" || *tags.MaxItems != 5 {
+		t.Errorf("Unexpected tags schema: %+v", tags)
+	}
+	if owner := todo.Properties["owner"]; owner.Ref != "#/types/Owner" || !owner.Nullable {
+		t.Errorf("Unexpected owner schema: %+v", owner)
+	}
+	if todo.Properties["due"].Format != "date-time" || len(todo.Properties["status"].Enum) != 2 {
+		t.Error("Expected date-time format and enum")
+	}
+	if _, exists := todo.Properties["Secret"]; exists {
+		t.Error("Fields tagged json:\"-\" should be skipped")
+	}
+
+	post := schema.Routes[0]
+	if post.Request.Ref != "#/types/Todo" || post.Responses["400"] != nil {
+		t.Errorf("Unexpected POST schema: %+v", post)
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Errorf("Schema should encode as JSON: %v", err)
+	}
+}
+
+func TestPostmanCollection(t *testing.T) {
+	type Todo struct {
+		Title string `json:"title"`
+	}
+	type Filter struct {
+		Page int `query:"page"`
+	}
+
+	app := New()
+	app.GET("/health", func(c *context.Context) error { return c.NoContent() })
+	app.GET("/todos", func(c *context.Context) error { return c.JSON(200, []Todo{}) }).
+		Query(Filter{}).
+		Auth("JWT")
+	app.POST("/todos", func(c *context.Context) error { return c.JSON(201, Todo{}) }).
+		Doc("Creates a todo").
+		Auth("API key").
+		Example(Example{
+			Name:     "create",
+			Request:  map[string]string{"title": "Buy milk"},
+			Status:   201,
+			Response: map[string]string{"title": "Buy milk"},
+		})
+	app.PUT("/todos/:id", func(c *context.Context) error { return c.NoContent() }).
+		Request(Todo{})
+
+	collection := app.PostmanCollection(PostmanConfig{Name: "Todo API"})
+	if collection.Info.Name != "Todo API" || collection.Info.Schema != postmanSchema {
+		t.Errorf("Unexpected info: %+v", collection.Info)
+	}
+	if len(collection.Item) != 2 || collection.Item[0].Request == nil || collection.Item[1].Name != "todos" {
+		t.Fatalf("Expected /health at top level and a todos folder, got %+v", collection.Item)
+	}
+
+	todos := collection.Item[1].Item
+	if len(todos) != 3 {
+		t.Fatalf("Expected 3 todo requests, got %d", len(todos))
+	}
+	list, create, update := todos[0].Request, todos[1], todos[2].Request
+
+	if list.Auth == nil || list.Auth.Type != "bearer" || list.Auth.Bearer[0].Value != "{{token}}" {
+		t.Errorf("Expected bearer auth, got %+v", list.Auth)
+	}
+	if len(list.URL.Query) != 1 || list.URL.Query[0].Key != "page" || !list.URL.Query[0].Disabled {
+		t.Errorf("Expected disabled page query parameter, got %+v", list.URL.Query)
+	}
+
+	if create.Request.Auth == nil || create.Request.Auth.Type != "apikey" {
+		t.Errorf("Expected API key auth, got %+v", create.Request.Auth)
+	}
+	if create.Request.Body == nil || !strings.Contains(create.Request.Body.Raw, "Buy milk") {
+		t.Errorf("Expected example body, got %+v", create.Request.Body)
+	}
+	if len(create.Response) != 1 || create.Response[0].Code != 201 || !strings.Contains(create.Response[0].Body, "Buy milk") {
+		t.Errorf("Expected saved example response, got %+v", create.Response)
+	}
+
+	if update.URL.Raw != "{{baseUrl}}/todos/:id" || len(update.URL.Variable) != 1 || update.URL.Variable[0].Key != "id" {
+		t.Errorf("Unexpected URL: %+v", update.URL)
+	}
+	if update.Body == nil || !strings.Contains(update.Body.Raw, `"title": ""`) {
+		t.Errorf("Expected zero-value body from the request type, got %+v", update.Body)
+	}
+
+	variables := map[string]bool{}
+	for _, v := range collection.Variable {
+		variables[v.Key] = true
+	}
+	if !variables["baseUrl"] || !variables["token"] || !variables["apiKey"] || variables["username"] {
+		t.Errorf("Unexpected variables: %+v", collection.Variable)
+	}
+
+	w := httptest.NewRecorder()
+	app.GET("/postman", app.PostmanHandler(PostmanConfig{Name: "Todo API"}))
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/postman", nil))
+	if w.Code != 200 || !strings.Contains(w.Header().Get("Content-Disposition"), "Todo API.postman_collection.json") {
+		t.Errorf("Unexpected handler response: %d %v", w.Code, w.Header())
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "app.css"), []byte("body{}"), 0o644)
+	os.Mkdir(filepath.Join(dir, "img"), 0o755)
+
+	var logs bytes.Buffer
+	app := New()
+	app.Logger = logger.NewWithConfig(logger.WarnLevel, &logs)
+	app.Static("/assets", dir)
+	app.GET("/", func(c *context.Context) error { return c.NoContent() })
+	app.Use(func(next HandlerFunc) HandlerFunc { return next })
+	app.Use(func(next HandlerFunc) HandlerFunc { return next })
+
+	warnings := app.Warnings()
+	if len(warnings) != 2 || warnings[0].Code != "static-nested-paths" || warnings[1].Code != "late-middleware" {
+		t.Fatalf("Expected one warning per code, got %+v", warnings)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Warnings should not be logged before the app starts serving, got %s", logs.String())
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if n := strings.Count(logs.String(), "\n"); n != 2 {
+		t.Errorf("Expected each warning logged once, got %d lines: %s", n, logs.String())
+	}
+	if !strings.Contains(logs.String(), `"code":"static-nested-paths"`) || !strings.Contains(logs.String(), "img/") {
+		t.Errorf("Expected structured static warning, got %s", logs.String())
+	}
+
+	// Warnings recorded after startup are logged right away
+	logs.Reset()
+	app.Static("/more", dir)
+	app.warn(Warning{Code: "custom", Component: "test", Message: "late"})
+	if strings.Count(logs.String(), "\n") != 1 || !strings.Contains(logs.String(), `"code":"custom"`) {
+		t.Errorf("Expected only the new warning to be logged, got %s", logs.String())
+	}
+
+	quiet := New()
+	quiet.SuppressWarnings("static-nested-paths")
+	quiet.Static("/assets", dir)
+	if len(quiet.Warnings()) != 0 {
+		t.Errorf("Expected suppressed warning, got %+v", quiet.Warnings())
+	}
+}
+
+func TestTrack(t *testing.T) {
+	var events []analytics.Event
+	app := New()
+	app.Analytics = analytics.New(analytics.SinkFunc(func(_ stdcontext.Context, batch []analytics.Event) error {
+		events = append(events, batch...)
+		return nil
+	}))
+	app.POST("/todos/:list", func(c *context.Context) error {
+		c.SetRequestID("req-1")
+		c.Track("todo_created", map[string]interface{}{"title": "milk"})
+		return c.NoContent()
+	})
+
+	req := httptest.NewRequest("POST", "/todos/groceries", nil)
+	req.Header.Set("User-Agent", "curl/8.4.0")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := app.Analytics.Close(stdcontext.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Name != "todo_created" || event.Properties["title"] != "milk" || event.RequestID != "req-1" ||
+		event.Path != "/todos/:list" || event.Client != "curl 8" || !event.Bot {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestSignURL(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	link := SignURL("/files/report.pdf?inline=1", time.Minute, key)
+
+	u, _ := url.Parse(link)
+	if u.Path != "/files/report.pdf" || u.Query().Get("inline") != "1" || u.Query().Get("expires") == "" {
+		t.Fatalf("unexpected signed URL %s", link)
+	}
+	if err := VerifySignedURL("GET", u, key); err != nil {
+		t.Errorf("valid link: %v", err)
+	}
+
+	tampered := *u
+	tampered.Path = "/files/secret.pdf"
+	if err := VerifySignedURL("GET", &tampered, key); err != ErrURLSignature {
+		t.Errorf("tampered path: expected ErrURLSignature, got %v", err)
+	}
+	query := u.Query()
+	query.Set("expires", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	tampered.Path, tampered.RawQuery = u.Path, query.Encode()
+	if err := VerifySignedURL("GET", &tampered, key); err != ErrURLSignature {
+		t.Errorf("extended expiry: expected ErrURLSignature, got %v", err)
+	}
+	if err := VerifySignedURL("GET", u, []byte("another key of thirty two bytes!")); err != ErrURLSignature {
+		t.Errorf("wrong key: expected ErrURLSignature, got %v", err)
+	}
+
+	expired, _ := url.Parse(SignURL("/files/report.pdf", -time.Minute, key))
+	if err := VerifySignedURL("GET", expired, key); err != ErrURLExpired {
+		t.Errorf("expired link: expected ErrURLExpired, got %v", err)
+	}
+
+	// Signatures are bound to the method; HEAD shares GET links
+	if err := VerifySignedURL("HEAD", u, key); err != nil {
+		t.Errorf("HEAD on a GET link: %v", err)
+	}
+	if err := VerifySignedURL("DELETE", u, key); err != ErrURLSignature {
+		t.Errorf("DELETE on a GET link: expected ErrURLSignature, got %v", err)
+	}
+	upload, _ := url.Parse(SignURLForMethod("PUT", "/uploads/a.png", time.Minute, key))
+	if err := VerifySignedURL("PUT", upload, key); err != nil {
+		t.Errorf("PUT link: %v", err)
+	}
+	if err := VerifySignedURL("GET", upload, key); err != ErrURLSignature {
+		t.Errorf("GET on a PUT link: expected ErrURLSignature, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package lambda
+
+// ProxyRequest is an API Gateway REST API proxy event (payload format 1.0).
+type ProxyRequest struct {
+	Resource                        string              `json:"resource"`
+	Path                            string              `json:"path"`
+	HTTPMethod                      string              `json:"httpMethod"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	PathParameters                  map[string]string   `json:"pathParameters"`
+	StageVariables                  map[string]string   `json:"stageVariables"`
+	RequestContext                  ProxyRequestContext `json:"requestContext"`
+	Body                            string              `json:"body"`
+	IsBase64Encoded                 bool                `json:"isBase64Encoded"`
+}
+
+// ProxyRequestContext is the request context of a ProxyRequest.
+type ProxyRequestContext struct {
+	AccountID  string `json:"accountId"`
+	RequestID  string `json:"requestId"`
+	Stage      string `json:"stage"`
+	DomainName string `json:"domainName"`
+	Identity   struct {
+		SourceIP  string `json:"sourceIp"`
+		UserAgent string `json:"userAgent"`
+	} `json:"identity"`
+}
+
+// ProxyResponse is the response to a ProxyRequest.
+type ProxyResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// HTTPAPIRequest is an API Gateway HTTP API event (payload format 2.0).
+type HTTPAPIRequest struct {
+	Version               string                `json:"version"`
+	RouteKey              string                `json:"routeKey"`
+	RawPath               string                `json:"rawPath"`
+	RawQueryString        string                `json:"rawQueryString"`
+	Cookies               []string              `json:"cookies"`
+	Headers               map[string]string     `json:"headers"`
+	QueryStringParameters map[string]string     `json:"queryStringParameters"`
+	PathParameters        map[string]string     `json:"pathParameters"`
+	StageVariables        map[string]string     `json:"stageVariables"`
+	RequestContext        HTTPAPIRequestContext `json:"requestContext"`
+	Body                  string                `json:"body"`
+	IsBase64Encoded       bool                  `json:"isBase64Encoded"`
+}
+
+// HTTPAPIRequestContext is the request context of an HTTPAPIRequest.
+type HTTPAPIRequestContext struct {
+	AccountID  string `json:"accountId"`
+	RequestID  string `json:"requestId"`
+	Stage      string `json:"stage"`
+	DomainName string `json:"domainName"`
+	HTTP       struct {
+		Method    string `json:"method"`
+		Path      string `json:"path"`
+		Protocol  string `json:"protocol"`
+		SourceIP  string `json:"sourceIp"`
+		UserAgent string `json:"userAgent"`
+	} `json:"http"`
+}
+
+// HTTPAPIResponse is the response to an HTTPAPIRequest.
+type HTTPAPIResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Cookies         []string          `json:"cookies,omitempty"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
+
+// ALBRequest is an Application Load Balancer target group event.
+type ALBRequest struct {
+	HTTPMethod                      string              `json:"httpMethod"`
+	Path                            string              `json:"path"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	RequestContext                  struct {
+		ELB struct {
+			TargetGroupArn string `json:"targetGroupArn"`
+		} `json:"elb"`
+	} `json:"requestContext"`
+	Body            string `json:"body"`
+	IsBase64Encoded bool   `json:"isBase64Encoded"`
+}
+
+// ALBResponse is the response to an ALBRequest.
+type ALBResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
@@ -0,0 +1,320 @@
+// Package lambda runs an http.Handler, such as a Kese app, on AWS Lambda
+// behind API Gateway (REST and HTTP APIs) or an Application Load Balancer.
+//
+// Events are converted to *http.Request and the response is mapped back to
+// the format of the triggering service, including base64 bodies for binary
+// content. The package has no dependencies: pass Adapter.Handle to
+// lambda.Start from github.com/aws/aws-lambda-go, which accepts handlers
+// taking and returning raw JSON.
+//
+// Example:
+//
+//	import awslambda "github.com/aws/aws-lambda-go/lambda"
+//
+//	func main() {
+//	    app := kese.New()
+//	    app.GET("/hello", hello)
+//
+//	    if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+//	        awslambda.Start(lambda.New(app).Handle)
+//	        return
+//	    }
+//	    app.Run(":8080")
+//	}
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Adapter converts Lambda events to HTTP requests served by Handler.
+type Adapter struct {
+	// Handler serves the converted requests
+	Handler http.Handler
+
+	// IsBinary reports whether a response with the given Content-Type must be
+	// base64-encoded. Default: anything but text, JSON, XML, JavaScript and
+	// form data, and any response with a Content-Encoding.
+	IsBinary func(contentType string) bool
+}
+
+// New returns an Adapter serving events with handler.
+func New(handler http.Handler) *Adapter {
+	return &Adapter{Handler: handler, IsBinary: isBinary}
+}
+
+// Handle converts an API Gateway (payload v1 or v2) or ALB event to an HTTP
+// request, serves it and returns the response in the matching format.
+func (a *Adapter) Handle(ctx context.Context, event json.RawMessage) (json.RawMessage, error) {
+	var probe struct {
+		Version        string `json:"version"`
+		RequestContext struct {
+			ELB json.RawMessage `json:"elb"`
+		} `json:"requestContext"`
+	}
+	if err := json.Unmarshal(event, &probe); err != nil {
+		return nil, fmt.Errorf("lambda: decode event: %w", err)
+	}
+
+	switch {
+	case probe.Version == "2.0":
+		var req HTTPAPIRequest
+		if err := json.Unmarshal(event, &req); err != nil {
+			return nil, fmt.Errorf("lambda: decode HTTP API event: %w", err)
+		}
+		resp, err := a.ServeHTTPAPI(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(resp)
+
+	case probe.RequestContext.ELB != nil:
+		var req ALBRequest
+		if err := json.Unmarshal(event, &req); err != nil {
+			return nil, fmt.Errorf("lambda: decode ALB event: %w", err)
+		}
+		resp, err := a.ServeALB(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(resp)
+
+	default:
+		var req ProxyRequest
+		if err := json.Unmarshal(event, &req); err != nil {
+			return nil, fmt.Errorf("lambda: decode API Gateway event: %w", err)
+		}
+		resp, err := a.ServeProxy(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(resp)
+	}
+}
+
+// ServeProxy serves an API Gateway REST API (payload v1) event.
+func (a *Adapter) ServeProxy(ctx context.Context, event ProxyRequest) (ProxyResponse, error) {
+	query := url.Values{}
+	for key, values := range event.MultiValueQueryStringParameters {
+		query[key] = values
+	}
+	for key, value := range event.QueryStringParameters {
+		if _, ok := query[key]; !ok {
+			query.Set(key, value)
+		}
+	}
+
+	header := mergeHeaders(event.Headers, event.MultiValueHeaders)
+	r, err := newRequest(ctx, event.HTTPMethod, event.Path, query.Encode(), header, event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return ProxyResponse{}, err
+	}
+	r.RemoteAddr = event.RequestContext.Identity.SourceIP + ":0"
+
+	w := a.serve(r)
+	body, encoded := a.encodeBody(w)
+	return ProxyResponse{
+		StatusCode:        w.status,
+		MultiValueHeaders: w.header,
+		Body:              body,
+		IsBase64Encoded:   encoded,
+	}, nil
+}
+
+// ServeHTTPAPI serves an API Gateway HTTP API (payload v2) event.
+func (a *Adapter) ServeHTTPAPI(ctx context.Context, event HTTPAPIRequest) (HTTPAPIResponse, error) {
+	header := make(http.Header, len(event.Headers))
+	for key, value := range event.Headers {
+		header.Set(key, value)
+	}
+	if len(event.Cookies) > 0 {
+		header.Set("Cookie", strings.Join(event.Cookies, "; "))
+	}
+
+	httpCtx := event.RequestContext.HTTP
+	r, err := newRequest(ctx, httpCtx.Method, event.RawPath, event.RawQueryString, header, event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return HTTPAPIResponse{}, err
+	}
+	r.RemoteAddr = httpCtx.SourceIP + ":0"
+
+	w := a.serve(r)
+	body, encoded := a.encodeBody(w)
+
+	// HTTP APIs take cookies separately; other repeated headers are comma-joined
+	cookies := w.header.Values("Set-Cookie")
+	w.header.Del("Set-Cookie")
+	headers := make(map[string]string, len(w.header))
+	for key, values := range w.header {
+		headers[key] = strings.Join(values, ",")
+	}
+
+	return HTTPAPIResponse{
+		StatusCode:      w.status,
+		Headers:         headers,
+		Cookies:         cookies,
+		Body:            body,
+		IsBase64Encoded: encoded,
+	}, nil
+}
+
+// ServeALB serves an Application Load Balancer event. The response uses
+// multi-value headers if the target group has them enabled.
+func (a *Adapter) ServeALB(ctx context.Context, event ALBRequest) (ALBResponse, error) {
+	// ALB passes query parameters exactly as sent by the client, still escaped
+	raw := make([]string, 0, len(event.QueryStringParameters))
+	for key, values := range event.MultiValueQueryStringParameters {
+		for _, value := range values {
+			raw = append(raw, key+"="+value)
+		}
+	}
+	if len(event.MultiValueQueryStringParameters) == 0 {
+		for key, value := range event.QueryStringParameters {
+			raw = append(raw, key+"="+value)
+		}
+	}
+
+	header := mergeHeaders(event.Headers, event.MultiValueHeaders)
+	r, err := newRequest(ctx, event.HTTPMethod, event.Path, strings.Join(raw, "&"), header, event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return ALBResponse{}, err
+	}
+	if forwarded := header.Get("X-Forwarded-For"); forwarded != "" {
+		r.RemoteAddr = strings.TrimSpace(strings.Split(forwarded, ",")[0]) + ":0"
+	}
+
+	w := a.serve(r)
+	body, encoded := a.encodeBody(w)
+	resp := ALBResponse{
+		StatusCode:        w.status,
+		StatusDescription: fmt.Sprintf("%d %s", w.status, http.StatusText(w.status)),
+		Body:              body,
+		IsBase64Encoded:   encoded,
+	}
+	if event.MultiValueHeaders != nil {
+		resp.MultiValueHeaders = w.header
+	} else {
+		resp.Headers = singleHeaders(w.header)
+	}
+	return resp, nil
+}
+
+// serve runs the handler and captures its response.
+func (a *Adapter) serve(r *http.Request) *responseWriter {
+	w := &responseWriter{header: http.Header{}}
+	a.Handler.ServeHTTP(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w
+}
+
+// encodeBody returns the response body, base64-encoded if it is binary.
+func (a *Adapter) encodeBody(w *responseWriter) (string, bool) {
+	binary := a.IsBinary
+	if binary == nil {
+		binary = isBinary
+	}
+
+	if w.body.Len() > 0 && (w.header.Get("Content-Encoding") != "" || binary(w.header.Get("Content-Type"))) {
+		return base64.StdEncoding.EncodeToString(w.body.Bytes()), true
+	}
+	return w.body.String(), false
+}
+
+// newRequest builds the HTTP request for an event.
+func newRequest(ctx context.Context, method, path, rawQuery string, header http.Header, body string, base64Encoded bool) (*http.Request, error) {
+	payload := []byte(body)
+	if base64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, fmt.Errorf("lambda: decode base64 body: %w", err)
+		}
+		payload = decoded
+	}
+
+	target := path
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+	r, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("lambda: build request: %w", err)
+	}
+
+	r.Header = header
+	r.Host = header.Get("Host")
+	r.RequestURI = target
+	return r, nil
+}
+
+// mergeHeaders combines single and multi-value event headers.
+func mergeHeaders(single map[string]string, multi map[string][]string) http.Header {
+	header := make(http.Header, len(single)+len(multi))
+	for key, values := range multi {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	for key, value := range single {
+		if _, ok := multi[key]; !ok {
+			header.Set(key, value)
+		}
+	}
+	return header
+}
+
+// singleHeaders keeps the last value of each header.
+func singleHeaders(header http.Header) map[string]string {
+	single := make(map[string]string, len(header))
+	for key, values := range header {
+		single[key] = values[len(values)-1]
+	}
+	return single
+}
+
+// isBinary reports whether a content type is not text.
+func isBinary(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case mediaType == "",
+		strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"),
+		strings.HasSuffix(mediaType, "xml"),
+		strings.HasSuffix(mediaType, "javascript"),
+		mediaType == "application/x-www-form-urlencoded":
+		return false
+	}
+	return true
+}
+
+// responseWriter captures a response in memory.
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 && statusCode >= 200 {
+		w.status = statusCode
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
@@ -0,0 +1,111 @@
+package lambda
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/JedizLaPulga/kese"
+	kesecontext "github.com/JedizLaPulga/kese/context"
+)
+
+func testApp() *kese.App {
+	app := kese.New()
+	app.POST("/items/:id", func(c *kesecontext.Context) error {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.SetCookie(&http.Cookie{Name: "a", Value: "1"})
+		c.SetCookie(&http.Cookie{Name: "b", Value: "2"})
+		return c.JSON(201, map[string]string{
+			"id":     c.Param("id"),
+			"tag":    c.Query("tag"),
+			"body":   string(body),
+			"client": c.Request.RemoteAddr,
+			"auth":   c.Header("Authorization"),
+		})
+	})
+	app.GET("/logo.png", func(c *kesecontext.Context) error {
+		return c.Bytes(200, "image/png", []byte{0x89, 'P', 'N', 'G', 0x00})
+	})
+	return app
+}
+
+func TestHandleHTTPAPI(t *testing.T) {
+	event := `{
+		"version": "2.0",
+		"rawPath": "/items/7",
+		"rawQueryString": "tag=new%20arrival",
+		"cookies": ["session=abc"],
+		"headers": {"authorization": "Bearer t", "content-type": "text/plain"},
+		"requestContext": {"http": {"method": "POST", "sourceIp": "203.0.113.9"}},
+		"body": "` + base64.StdEncoding.EncodeToString([]byte("payload")) + `",
+		"isBase64Encoded": true
+	}`
+
+	out, err := New(testApp()).Handle(context.Background(), json.RawMessage(event))
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	var resp HTTPAPIResponse
+	json.Unmarshal(out, &resp)
+	if resp.StatusCode != 201 || resp.IsBase64Encoded {
+		t.Fatalf("Unexpected response: %+v", resp)
+	}
+	for _, want := range []string{`"id":"7"`, `"tag":"new arrival"`, `"body":"payload"`, `"client":"203.0.113.9:0"`, `"auth":"Bearer t"`} {
+		if !strings.Contains(resp.Body, want) {
+			t.Errorf("Expected body to contain %s, got %s", want, resp.Body)
+		}
+	}
+	if len(resp.Cookies) != 2 || resp.Headers["Set-Cookie"] != "" {
+		t.Errorf("Expected cookies returned separately, got %v / %v", resp.Cookies, resp.Headers)
+	}
+}
+
+func TestHandleProxyBinary(t *testing.T) {
+	event := `{"httpMethod": "GET", "path": "/logo.png", "headers": {"Accept": "image/png"}, "requestContext": {"identity": {"sourceIp": "198.51.100.1"}}}`
+
+	out, err := New(testApp()).Handle(context.Background(), json.RawMessage(event))
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	var resp ProxyResponse
+	json.Unmarshal(out, &resp)
+	decoded, _ := base64.StdEncoding.DecodeString(resp.Body)
+	if resp.StatusCode != 200 || !resp.IsBase64Encoded || string(decoded) != "\x89PNG\x00" {
+		t.Errorf("Expected base64-encoded binary body, got %+v", resp)
+	}
+	if resp.MultiValueHeaders["Content-Type"][0] != "image/png" {
+		t.Errorf("Unexpected headers %v", resp.MultiValueHeaders)
+	}
+}
+
+func TestHandleALB(t *testing.T) {
+	event := `{
+		"httpMethod": "POST",
+		"path": "/items/9",
+		"queryStringParameters": {"tag": "a%2Bb"},
+		"headers": {"x-forwarded-for": "192.0.2.7, 10.0.0.1"},
+		"requestContext": {"elb": {"targetGroupArn": "arn:aws:elasticloadbalancing:eu-west-1:1:targetgroup/t/1"}},
+		"body": "hi",
+		"isBase64Encoded": false
+	}`
+
+	out, err := New(testApp()).Handle(context.Background(), json.RawMessage(event))
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	var resp ALBResponse
+	json.Unmarshal(out, &resp)
+	if resp.StatusCode != 201 || resp.StatusDescription != "201 Created" || resp.Headers == nil {
+		t.Fatalf("Unexpected response: %+v", resp)
+	}
+	if !strings.Contains(resp.Body, `"tag":"a+b"`) || !strings.Contains(resp.Body, `"client":"192.0.2.7:0"`) {
+		t.Errorf("Unexpected body %s", resp.Body)
+	}
+}
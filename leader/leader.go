@@ -0,0 +1,228 @@
+// Package leader elects one instance of a multi-replica deployment to run
+// background tasks, such as scheduled jobs that must not run once per
+// replica.
+//
+// Instances compete for a lease in a LockStore shared by all of them. The
+// leader renews its lease while it runs; if it stops or loses the store, the
+// lease expires and another instance takes over.
+//
+// Example:
+//
+//	elector := leader.NewWithConfig(leader.Config{
+//	    Store: redisLocks,
+//	    Key:   "jobs",
+//	    OnElected: func(ctx context.Context) {
+//	        // ctx is cancelled when leadership is lost
+//	        go runNightlyReports(ctx)
+//	    },
+//	})
+//	app.OnShutdown(elector.Stop)
+//
+//	// or check before each run
+//	if elector.IsLeader() {
+//	    cleanupExpiredSessions()
+//	}
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JedizLaPulga/kese/logger"
+)
+
+// Config holds configuration for an Elector.
+type Config struct {
+	// Store holds the lease shared by all instances
+	Store LockStore
+
+	// Key names the election; electors with the same key compete for one
+	// lease. Use different keys to spread tasks over instances.
+	Key string
+
+	// ID identifies this instance in the store. Default: hostname and a
+	// random suffix
+	ID string
+
+	// LeaseDuration is how long a lease is valid without renewal, and so how
+	// long tasks may pause after a leader crashed. Default: 15 seconds
+	LeaseDuration time.Duration
+
+	// RenewInterval is how often the leader renews its lease and followers
+	// try to take it. Default: a third of LeaseDuration
+	RenewInterval time.Duration
+
+	// OnElected is called in a new goroutine when this instance becomes the
+	// leader. ctx is cancelled when leadership is lost or the elector stops.
+	OnElected func(ctx context.Context)
+
+	// OnDemoted is called when this instance stops being the leader.
+	OnDemoted func()
+
+	// Logger logs store failures. Default: logger.New()
+	Logger *logger.Logger
+}
+
+// DefaultConfig returns the default configuration for election key in store.
+func DefaultConfig(store LockStore, key string) Config {
+	return Config{
+		Store:         store,
+		Key:           key,
+		ID:            instanceID(),
+		LeaseDuration: 15 * time.Second,
+		RenewInterval: 5 * time.Second,
+		Logger:        logger.New(),
+	}
+}
+
+// Elector takes part in an election for as long as it runs. It is safe for
+// concurrent use.
+type Elector struct {
+	config Config
+	leader atomic.Bool
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// New starts an Elector for election key in store with the default
+// configuration.
+func New(store LockStore, key string) *Elector {
+	return NewWithConfig(DefaultConfig(store, key))
+}
+
+// NewWithConfig starts an Elector with custom configuration. Zero fields use
+// their defaults. It panics if config.Store is nil or config.Key is empty.
+func NewWithConfig(config Config) *Elector {
+	if config.Store == nil {
+		panic("leader: Store is nil")
+	}
+	if config.Key == "" {
+		panic("leader: Key is empty")
+	}
+	defaults := DefaultConfig(config.Store, config.Key)
+	if config.ID == "" {
+		config.ID = defaults.ID
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = defaults.LeaseDuration
+	}
+	if config.RenewInterval <= 0 || config.RenewInterval >= config.LeaseDuration {
+		config.RenewInterval = config.LeaseDuration / 3
+	}
+	if config.Logger == nil {
+		config.Logger = defaults.Logger
+	}
+
+	e := &Elector{
+		config: config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// IsLeader reports whether this instance currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// ID returns the ID of this instance in the store.
+func (e *Elector) ID() string {
+	return e.config.ID
+}
+
+// Stop leaves the election and waits until it did. A leader releases its
+// lease, so another instance takes over without waiting for it to expire.
+func (e *Elector) Stop() {
+	e.once.Do(func() {
+		close(e.stop)
+	})
+	<-e.done
+}
+
+// run competes for the lease until the elector stops.
+func (e *Elector) run() {
+	defer close(e.done)
+
+	var (
+		cancel    context.CancelFunc
+		renewedAt time.Time
+	)
+	demote := func() {
+		e.leader.Store(false)
+		cancel()
+		if e.config.OnDemoted != nil {
+			e.config.OnDemoted()
+		}
+	}
+
+	ticker := time.NewTicker(e.config.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancelAcquire := context.WithTimeout(context.Background(), e.config.RenewInterval)
+		acquired, err := e.config.Store.Acquire(ctx, e.config.Key, e.config.ID, e.config.LeaseDuration)
+		cancelAcquire()
+
+		switch {
+		case err != nil:
+			e.config.Logger.Warn("Failed to acquire leader lease", "key", e.config.Key, "error", err.Error())
+			// Step down before the lease expires and another instance may
+			// be elected
+			if e.leader.Load() && time.Since(renewedAt)+e.config.RenewInterval >= e.config.LeaseDuration {
+				demote()
+			}
+		case acquired:
+			renewedAt = time.Now()
+			if !e.leader.Load() {
+				cancel = e.elect()
+			}
+		case e.leader.Load():
+			demote()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-e.stop:
+			if e.leader.Load() {
+				demote()
+				ctx, cancelRelease := context.WithTimeout(context.Background(), e.config.RenewInterval)
+				if err := e.config.Store.Release(ctx, e.config.Key, e.config.ID); err != nil {
+					e.config.Logger.Warn("Failed to release leader lease", "key", e.config.Key, "error", err.Error())
+				}
+				cancelRelease()
+			}
+			return
+		}
+	}
+}
+
+// elect makes this instance the leader and returns the function that
+// cancels the context passed to OnElected.
+func (e *Elector) elect() context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.leader.Store(true)
+	if e.config.OnElected != nil {
+		go e.config.OnElected(ctx)
+	}
+	return cancel
+}
+
+// instanceID returns the hostname with a random suffix, unique even for
+// several electors in one process.
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "instance"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,134 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds or a second passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met within 1s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if ok, _ := store.Acquire(ctx, "jobs", "a", time.Minute); !ok {
+		t.Fatal("Expected a to acquire the free lock")
+	}
+	if ok, _ := store.Acquire(ctx, "jobs", "b", time.Minute); ok {
+		t.Error("Expected b to be refused while a holds the lock")
+	}
+	if ok, _ := store.Acquire(ctx, "jobs", "a", time.Minute); !ok {
+		t.Error("Expected a to renew its lock")
+	}
+
+	store.Release(ctx, "jobs", "b")
+	if ok, _ := store.Acquire(ctx, "jobs", "b", time.Minute); ok {
+		t.Error("Expected release by a non-owner to be ignored")
+	}
+	store.Release(ctx, "jobs", "a")
+	if ok, _ := store.Acquire(ctx, "jobs", "b", 10*time.Millisecond); !ok {
+		t.Error("Expected b to acquire the released lock")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if ok, _ := store.Acquire(ctx, "jobs", "a", time.Minute); !ok {
+		t.Error("Expected a to acquire the expired lock")
+	}
+}
+
+func TestElectorFailover(t *testing.T) {
+	store := NewMemoryStore()
+	elected := make(chan string, 2)
+	demoted := make(chan string, 2)
+	newElector := func(id string) *Elector {
+		return NewWithConfig(Config{
+			Store:         store,
+			Key:           "jobs",
+			ID:            id,
+			LeaseDuration: 200 * time.Millisecond,
+			RenewInterval: 20 * time.Millisecond,
+			OnElected: func(ctx context.Context) {
+				elected <- id
+				<-ctx.Done()
+				demoted <- id
+			},
+		})
+	}
+
+	first := newElector("first")
+	waitFor(t, first.IsLeader)
+	second := newElector("second")
+	defer second.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	if second.IsLeader() {
+		t.Fatal("Expected only one leader")
+	}
+
+	first.Stop()
+	if first.IsLeader() {
+		t.Error("Expected stopped elector not to be leader")
+	}
+	waitFor(t, second.IsLeader)
+
+	if id := <-elected; id != "first" {
+		t.Errorf("Expected first elected first, got %s", id)
+	}
+	if id := <-demoted; id != "first" {
+		t.Errorf("Expected first's context cancelled, got %s", id)
+	}
+	if id := <-elected; id != "second" {
+		t.Errorf("Expected second elected after failover, got %s", id)
+	}
+}
+
+// failingStore is a LockStore that fails once broken.
+type failingStore struct {
+	*MemoryStore
+	broken chan struct{}
+}
+
+func (s *failingStore) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	select {
+	case <-s.broken:
+		return false, errors.New("connection refused")
+	default:
+		return s.MemoryStore.Acquire(ctx, key, owner, ttl)
+	}
+}
+
+func TestElectorStepsDownWhenStoreFails(t *testing.T) {
+	store := &failingStore{MemoryStore: NewMemoryStore(), broken: make(chan struct{})}
+	demoted := make(chan struct{})
+	elector := NewWithConfig(Config{
+		Store:         store,
+		Key:           "jobs",
+		LeaseDuration: 100 * time.Millisecond,
+		RenewInterval: 20 * time.Millisecond,
+		OnDemoted:     func() { close(demoted) },
+	})
+	defer elector.Stop()
+	waitFor(t, elector.IsLeader)
+
+	close(store.broken)
+	select {
+	case <-demoted:
+	case <-time.After(time.Second):
+		t.Fatal("Expected leader to step down while the store fails")
+	}
+	if elector.IsLeader() {
+		t.Error("Expected IsLeader false after stepping down")
+	}
+}
@@ -0,0 +1,66 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LockStore holds the leases of elections. Implement it on storage shared by
+// all instances, e.g. Redis with SET NX PX or a database row, so that only
+// one instance holds a lease at a time.
+type LockStore interface {
+	// Acquire takes the lock key for owner for ttl, or extends the lease if
+	// owner already holds it. It reports whether owner holds the lock.
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Release gives up the lock key if owner holds it.
+	Release(ctx context.Context, key, owner string) error
+}
+
+// MemoryStore is an in-memory implementation of LockStore, for a single
+// process and tests.
+type MemoryStore struct {
+	mu     sync.Mutex
+	leases map[string]*lease
+}
+
+type lease struct {
+	owner  string
+	expiry time.Time
+}
+
+// NewMemoryStore creates a new in-memory lock store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		leases: make(map[string]*lease),
+	}
+}
+
+// Acquire takes or extends the lock key for owner.
+func (s *MemoryStore) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if l, exists := s.leases[key]; exists && l.owner != owner && now.Before(l.expiry) {
+		return false, nil
+	}
+
+	s.leases[key] = &lease{
+		owner:  owner,
+		expiry: now.Add(ttl),
+	}
+	return true, nil
+}
+
+// Release gives up the lock key if owner holds it.
+func (s *MemoryStore) Release(ctx context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, exists := s.leases[key]; exists && l.owner == owner {
+		delete(s.leases, key)
+	}
+	return nil
+}
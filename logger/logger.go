@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -38,31 +40,89 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel parses a level name such as "debug" or "WARN", case-insensitively.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return DebugLevel, nil
+	case "INFO":
+		return InfoLevel, nil
+	case "WARN", "WARNING":
+		return WarnLevel, nil
+	case "ERROR":
+		return ErrorLevel, nil
+	default:
+		return 0, fmt.Errorf("logger: unknown level %q", name)
+	}
+}
+
+// MarshalText encodes the level as its name, so it appears as "INFO" in JSON.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText decodes a level name, see ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
 // Logger provides structured logging functionality.
 type Logger struct {
-	level  Level
+	level  atomic.Int32
 	output io.Writer
+
+	// fields are added to every entry (see With)
+	fields []interface{}
 }
 
 // New creates a new logger that writes to stdout.
 func New() *Logger {
-	return &Logger{
-		level:  InfoLevel,
-		output: os.Stdout,
-	}
+	return NewWithConfig(InfoLevel, os.Stdout)
 }
 
 // NewWithConfig creates a logger with custom configuration.
 func NewWithConfig(level Level, output io.Writer) *Logger {
-	return &Logger{
-		level:  level,
-		output: output,
-	}
+	l := &Logger{output: output}
+	l.level.Store(int32(level))
+	return l
 }
 
 // SetLevel sets the minimum log level.
+// It is safe to call while other goroutines are logging.
 func (l *Logger) SetLevel(level Level) {
-	l.level = level
+	l.level.Store(int32(level))
+}
+
+// SetOutput sets the destination of log entries.
+// It must not be called while other goroutines are logging.
+func (l *Logger) SetOutput(output io.Writer) {
+	l.output = output
+}
+
+// Level returns the minimum log level.
+func (l *Logger) Level() Level {
+	return Level(l.level.Load())
+}
+
+// With returns a child logger that adds the given key-value fields to every entry.
+// The child shares the output and starts with the parent's level.
+// Example: reqLog := log.With("request_id", id, "customer_id", customer)
+func (l *Logger) With(fields ...interface{}) *Logger {
+	combined := make([]interface{}, 0, len(l.fields)+len(fields))
+	combined = append(combined, l.fields...)
+	combined = append(combined, fields...)
+
+	child := &Logger{
+		output: l.output,
+		fields: combined,
+	}
+	child.level.Store(l.level.Load())
+	return child
 }
 
 // Debug logs a debug message with optional fields.
@@ -87,7 +147,7 @@ func (l *Logger) Error(msg string, fields ...interface{}) {
 
 // log is the internal logging method.
 func (l *Logger) log(level Level, msg string, fields ...interface{}) {
-	if level < l.level {
+	if level < l.Level() {
 		return
 	}
 
@@ -98,7 +158,10 @@ func (l *Logger) log(level Level, msg string, fields ...interface{}) {
 		"message":   msg,
 	}
 
-	// Add fields as key-value pairs
+	// Add fields as key-value pairs, after the logger's own fields
+	if len(l.fields) > 0 {
+		fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	}
 	for i := 0; i < len(fields); i += 2 {
 		if i+1 < len(fields) {
 			key := fmt.Sprintf("%v", fields[i])
@@ -3,6 +3,7 @@ package metrics
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,6 +16,14 @@ type Metrics struct {
 	activeRequests     int
 	totalRequests      int
 	totalErrors        int
+	clientClosed       int
+	deprecatedCount    map[string]int
+	countryCount       map[string]int
+	uploadTempFiles    int
+	uploadTempBytes    int64
+	bodyTempFiles      int
+	bodyTempBytes      int64
+	draining           bool
 }
 
 // New creates a new metrics collector.
@@ -22,6 +31,8 @@ func New() *Metrics {
 	return &Metrics{
 		requestCount:       make(map[string]int),
 		requestDurationSum: make(map[string]time.Duration),
+		deprecatedCount:    make(map[string]int),
+		countryCount:       make(map[string]int),
 	}
 }
 
@@ -40,6 +51,56 @@ func (m *Metrics) RecordRequest(method, path string, duration time.Duration, sta
 	}
 }
 
+// RecordClientClosed records a request abandoned by the client before the response was written.
+func (m *Metrics) RecordClientClosed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clientClosed++
+}
+
+// RecordDeprecated records a call to a deprecated route, identified by its pattern.
+func (m *Metrics) RecordDeprecated(method, route string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deprecatedCount[method+" "+route]++
+}
+
+// RecordCountry records a request from country, an ISO 3166-1 alpha-2 code
+// resolved by GeoIP, or "" if unknown.
+func (m *Metrics) RecordCountry(country string) {
+	if country == "" {
+		country = "unknown"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.countryCount[country]++
+}
+
+// RecordUploadTempFiles records multipart upload files spilled to disk by a request.
+func (m *Metrics) RecordUploadTempFiles(files int, bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploadTempFiles += files
+	m.uploadTempBytes += bytes
+}
+
+// RecordBodyTempFile records a request body spilled to disk because it was
+// larger than the app's BodyMemoryLimit.
+func (m *Metrics) RecordBodyTempFile(bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bodyTempFiles++
+	m.bodyTempBytes += bytes
+}
+
+// SetDraining marks whether the server is draining requests during graceful
+// shutdown. Together with kese_active_requests it shows why shutdown is slow.
+func (m *Metrics) SetDraining(draining bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.draining = draining
+}
+
 // IncrementActive increments active request count.
 func (m *Metrics) IncrementActive() {
 	m.mu.Lock()
@@ -66,6 +127,15 @@ func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "# TYPE kese_active_requests gauge\n")
 	fmt.Fprintf(w, "kese_active_requests %d\n\n", m.activeRequests)
 
+	// Draining during graceful shutdown
+	draining := 0
+	if m.draining {
+		draining = 1
+	}
+	fmt.Fprintf(w, "# HELP kese_draining Whether the server is draining requests during shutdown\n")
+	fmt.Fprintf(w, "# TYPE kese_draining gauge\n")
+	fmt.Fprintf(w, "kese_draining %d\n\n", draining)
+
 	// Total requests
 	fmt.Fprintf(w, "# HELP kese_requests_total Total number of requests\n")
 	fmt.Fprintf(w, "# TYPE kese_requests_total counter\n")
@@ -76,6 +146,45 @@ func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "# TYPE kese_errors_total counter\n")
 	fmt.Fprintf(w, "kese_errors_total %d\n\n", m.totalErrors)
 
+	// Client-closed requests
+	fmt.Fprintf(w, "# HELP kese_client_closed_total Requests abandoned by the client before the response was written\n")
+	fmt.Fprintf(w, "# TYPE kese_client_closed_total counter\n")
+	fmt.Fprintf(w, "kese_client_closed_total %d\n\n", m.clientClosed)
+
+	// Upload temp files
+	fmt.Fprintf(w, "# HELP kese_upload_temp_files_total Multipart upload files spilled to disk\n")
+	fmt.Fprintf(w, "# TYPE kese_upload_temp_files_total counter\n")
+	fmt.Fprintf(w, "kese_upload_temp_files_total %d\n\n", m.uploadTempFiles)
+
+	fmt.Fprintf(w, "# HELP kese_upload_temp_bytes_total Bytes of multipart upload files spilled to disk\n")
+	fmt.Fprintf(w, "# TYPE kese_upload_temp_bytes_total counter\n")
+	fmt.Fprintf(w, "kese_upload_temp_bytes_total %d\n\n", m.uploadTempBytes)
+
+	// Request body temp files
+	fmt.Fprintf(w, "# HELP kese_body_temp_files_total Request bodies spilled to disk\n")
+	fmt.Fprintf(w, "# TYPE kese_body_temp_files_total counter\n")
+	fmt.Fprintf(w, "kese_body_temp_files_total %d\n\n", m.bodyTempFiles)
+
+	fmt.Fprintf(w, "# HELP kese_body_temp_bytes_total Bytes of request bodies spilled to disk\n")
+	fmt.Fprintf(w, "# TYPE kese_body_temp_bytes_total counter\n")
+	fmt.Fprintf(w, "kese_body_temp_bytes_total %d\n\n", m.bodyTempBytes)
+
+	// Deprecated route usage
+	fmt.Fprintf(w, "# HELP kese_deprecated_requests_total Requests to routes marked deprecated\n")
+	fmt.Fprintf(w, "# TYPE kese_deprecated_requests_total counter\n")
+	for route, count := range m.deprecatedCount {
+		fmt.Fprintf(w, "kese_deprecated_requests_total{route=\"%s\"} %d\n", route, count)
+	}
+	fmt.Fprintln(w)
+
+	// Request count by country
+	fmt.Fprintf(w, "# HELP kese_requests_by_country_total Requests by client country\n")
+	fmt.Fprintf(w, "# TYPE kese_requests_by_country_total counter\n")
+	for country, count := range m.countryCount {
+		fmt.Fprintf(w, "kese_requests_by_country_total{country=\"%s\"} %d\n", labelValue(country), count)
+	}
+	fmt.Fprintln(w)
+
 	// Request count by route
 	fmt.Fprintf(w, "# HELP kese_requests_by_route_total Requests by route\n")
 	fmt.Fprintf(w, "# TYPE kese_requests_by_route_total counter\n")
@@ -108,3 +217,13 @@ func RecordRequest(method, path string, duration time.Duration, statusCode int)
 func Handler() http.Handler {
 	return defaultMetrics
 }
+
+// labelEscaper escapes the characters the Prometheus text format requires
+// escaping in label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelValue escapes v for use as a label value. Country codes come from
+// resolvers and databases outside the application, so they are not trusted.
+func labelValue(v string) string {
+	return labelEscaper.Replace(v)
+}
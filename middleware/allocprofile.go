@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"math/rand"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// AllocSample is the allocation profile of a single sampled request.
+// Memory statistics are process-wide, so concurrent requests contribute to the
+// deltas; treat them as an upper bound and look for routes that stay on top.
+type AllocSample struct {
+	Method   string        `json:"method"`
+	Route    string        `json:"route"`
+	Bytes    uint64        `json:"bytes"`
+	Allocs   uint64        `json:"allocs"`
+	GCs      uint32        `json:"gcs"`
+	Duration time.Duration `json:"duration_ns"`
+	Time     time.Time     `json:"time"`
+}
+
+// AllocProfiler keeps the sampled requests with the largest allocation deltas.
+type AllocProfiler struct {
+	mu      sync.Mutex
+	samples []AllocSample
+	size    int
+}
+
+// NewAllocProfiler creates a profiler keeping the worst size requests (default 50).
+func NewAllocProfiler(size int) *AllocProfiler {
+	if size <= 0 {
+		size = 50
+	}
+	return &AllocProfiler{size: size}
+}
+
+// Record adds a sample, keeping only the largest by allocated bytes.
+func (p *AllocProfiler) Record(sample AllocSample) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.samples) == p.size && sample.Bytes <= p.samples[len(p.samples)-1].Bytes {
+		return
+	}
+
+	index := sort.Search(len(p.samples), func(i int) bool {
+		return p.samples[i].Bytes < sample.Bytes
+	})
+	p.samples = append(p.samples, AllocSample{})
+	copy(p.samples[index+1:], p.samples[index:])
+	p.samples[index] = sample
+
+	if len(p.samples) > p.size {
+		p.samples = p.samples[:p.size]
+	}
+}
+
+// Top returns the recorded samples, largest allocations first.
+func (p *AllocProfiler) Top() []AllocSample {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	top := make([]AllocSample, len(p.samples))
+	copy(top, p.samples)
+	return top
+}
+
+// Reset discards all samples.
+func (p *AllocProfiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.samples = nil
+}
+
+// Handler returns a handler serving the worst offenders as JSON.
+// Mount it on an internal path, ideally behind authentication.
+//
+// Example:
+//
+//	admin.GET("/debug/allocs", profiler.Handler())
+func (p *AllocProfiler) Handler() kese.HandlerFunc {
+	return func(c *context.Context) error {
+		return c.JSON(http.StatusOK, p.Top())
+	}
+}
+
+// AllocProfileConfig holds configuration for allocation profiling middleware.
+type AllocProfileConfig struct {
+	// Profiler receives the samples
+	Profiler *AllocProfiler
+
+	// SampleRate is the fraction of requests profiled, between 0 and 1.
+	// Reading memory statistics briefly stops the world, so keep it low in production.
+	// Default: 0.01
+	SampleRate float64
+
+	// SkipFunc allows skipping profiling for certain requests
+	SkipFunc func(*context.Context) bool
+}
+
+// DefaultAllocProfileConfig returns default allocation profiling configuration.
+func DefaultAllocProfileConfig(profiler *AllocProfiler) AllocProfileConfig {
+	return AllocProfileConfig{
+		Profiler:   profiler,
+		SampleRate: 0.01,
+	}
+}
+
+// AllocProfile returns a middleware that records allocation and GC deltas for
+// a sample of requests.
+//
+// Example:
+//
+//	profiler := middleware.NewAllocProfiler(50)
+//	app.Use(middleware.AllocProfile(profiler))
+//	admin.GET("/debug/allocs", profiler.Handler())
+func AllocProfile(profiler *AllocProfiler) kese.MiddlewareFunc {
+	return AllocProfileWithConfig(DefaultAllocProfileConfig(profiler))
+}
+
+// AllocProfileWithConfig returns allocation profiling middleware with custom configuration.
+func AllocProfileWithConfig(config AllocProfileConfig) kese.MiddlewareFunc {
+	if config.Profiler == nil {
+		config.Profiler = NewAllocProfiler(0)
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SampleRate <= 0 || rand.Float64() >= config.SampleRate {
+				return next(c)
+			}
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			start := time.Now()
+
+			err := next(c)
+
+			duration := time.Since(start)
+			runtime.ReadMemStats(&after)
+
+			route := c.RoutePath()
+			if route == "" {
+				route = c.Path()
+			}
+			config.Profiler.Record(AllocSample{
+				Method:   c.Method(),
+				Route:    route,
+				Bytes:    after.TotalAlloc - before.TotalAlloc,
+				Allocs:   after.Mallocs - before.Mallocs,
+				GCs:      after.NumGC - before.NumGC,
+				Duration: duration,
+				Time:     start,
+			})
+
+			return err
+		}
+	}
+}
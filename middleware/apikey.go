@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// apiKeyContextKey is the context key of the authenticated API key.
+const apiKeyContextKey = "api_key"
+
+// ErrAPIKeyNotFound may be returned by an APIKeyConfig.Lookup func to report
+// an unknown key. Returning a nil key and nil error has the same effect.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKey describes the owner and permissions of an API key.
+type APIKey struct {
+	// Owner identifies who the key was issued to (user, service or tenant ID)
+	Owner string
+
+	// Scopes lists the permissions granted to the key, e.g. "orders:read"
+	Scopes []string
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// missingScopes returns the scopes of required the key was not granted.
+func (k *APIKey) missingScopes(required []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if !k.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// APIKeyConfig holds configuration for API key authentication middleware.
+type APIKeyConfig struct {
+	// Lookup resolves a key to its owner and scopes, typically from a database
+	// or cache. c can be passed to drivers as a context.Context. It returns
+	// ErrAPIKeyNotFound (or a nil key) for unknown keys; any other error
+	// is treated as a server error rather than a rejected key.
+	Lookup func(c *context.Context, key string) (*APIKey, error)
+
+	// KeyLookup is a comma-separated list of places to look for the key,
+	// tried in order. Format: "<source>:<name>"
+	// Possible sources: "header", "query", "cookie"
+	// Default: "header:X-API-Key"
+	KeyLookup string
+
+	// Scopes are required on every request. Use RequireScopes for
+	// route-specific scopes.
+	Scopes []string
+
+	// SkipFunc allows skipping authentication for certain requests.
+	SkipFunc func(*context.Context) bool
+}
+
+// DefaultAPIKeyConfig returns the default API key configuration using lookup.
+func DefaultAPIKeyConfig(lookup func(c *context.Context, key string) (*APIKey, error)) APIKeyConfig {
+	return APIKeyConfig{
+		Lookup:    lookup,
+		KeyLookup: "header:X-API-Key",
+	}
+}
+
+// APIKeyAuth returns a middleware that authenticates requests by the API key
+// in the X-API-Key header. Requests without a key or with an unknown key are
+// rejected with 401 Unauthorized; the key's owner and scopes are available
+// to handlers via RequestAPIKey.
+//
+// Example:
+//
+//	api := app.Group("/api", middleware.APIKeyAuth(func(c *context.Context, key string) (*middleware.APIKey, error) {
+//	    return store.FindAPIKey(c, key)
+//	}))
+//
+//	// In handler
+//	owner := middleware.RequestAPIKey(c).Owner
+func APIKeyAuth(lookup func(c *context.Context, key string) (*APIKey, error)) kese.MiddlewareFunc {
+	return APIKeyAuthWithConfig(DefaultAPIKeyConfig(lookup))
+}
+
+// Validate reports configuration mistakes that would reject every request.
+func (config APIKeyConfig) Validate() error {
+	if config.Lookup == nil {
+		return &kese.ConfigError{
+			Component: "api-key",
+			Problem:   "Lookup is nil; keys cannot be resolved",
+			Fix:       "set Lookup to a func that finds keys in your store",
+		}
+	}
+	if _, err := parseKeyLookup(config.KeyLookup); err != nil {
+		return &kese.ConfigError{
+			Component: "api-key",
+			Problem:   err.Error(),
+			Fix:       "use \"header:<name>\", \"query:<name>\" or \"cookie:<name>\", separated by commas",
+		}
+	}
+	return nil
+}
+
+// APIKeyAuthWithConfig returns an API key middleware with custom configuration.
+// Requests whose key lacks one of config.Scopes are rejected with 403 Forbidden.
+// Panics with a *kese.ConfigError if the configuration is invalid.
+//
+// Keys sent in the query string end up in access logs and browser history;
+// prefer headers where clients allow it.
+//
+// Example:
+//
+//	app.Use(middleware.APIKeyAuthWithConfig(middleware.APIKeyConfig{
+//	    Lookup:    store.FindAPIKey,
+//	    KeyLookup: "header:X-API-Key,query:api_key",
+//	    Scopes:    []string{"api"},
+//	    SkipFunc: func(c *context.Context) bool {
+//	        return c.Path() == "/health"
+//	    },
+//	}))
+func APIKeyAuthWithConfig(config APIKeyConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	sources, _ := parseKeyLookup(config.KeyLookup)
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+
+			raw := extractAPIKey(c, sources)
+			if raw == "" {
+				return kese.NewProblem(http.StatusUnauthorized, "missing API key")
+			}
+
+			key, err := config.Lookup(c, raw)
+			if errors.Is(err, ErrAPIKeyNotFound) || (err == nil && key == nil) {
+				return kese.NewProblem(http.StatusUnauthorized, "invalid API key")
+			}
+			if err != nil {
+				return fmt.Errorf("api key lookup: %w", err)
+			}
+
+			c.Set(apiKeyContextKey, key)
+			if err := checkScopes(key, config.Scopes); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireScopes returns a middleware that rejects requests whose API key
+// lacks any of scopes with 403 Forbidden. It must run after APIKeyAuth;
+// requests that were not authenticated are rejected with 401 Unauthorized.
+//
+// Example:
+//
+//	api.DELETE("/orders/:id", middleware.RequireScopes("orders:write")(deleteOrder))
+func RequireScopes(scopes ...string) kese.MiddlewareFunc {
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			key := RequestAPIKey(c)
+			if key == nil {
+				return kese.NewProblem(http.StatusUnauthorized, "missing API key")
+			}
+			if err := checkScopes(key, scopes); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequestAPIKey returns the API key authenticated by APIKeyAuth, or nil.
+func RequestAPIKey(c *context.Context) *APIKey {
+	key, _ := c.Get(apiKeyContextKey).(*APIKey)
+	return key
+}
+
+// checkScopes returns a 403 problem listing the scopes key is missing.
+func checkScopes(key *APIKey, required []string) error {
+	missing := key.missingScopes(required)
+	if len(missing) == 0 {
+		return nil
+	}
+	problem := kese.NewProblem(http.StatusForbidden, "API key lacks required scopes")
+	problem.Extensions = map[string]interface{}{"missing_scopes": missing}
+	return problem
+}
+
+// keySource is one entry of APIKeyConfig.KeyLookup.
+type keySource struct {
+	source, name string
+}
+
+// parseKeyLookup parses a comma-separated KeyLookup. An empty lookup
+// means the X-API-Key header.
+func parseKeyLookup(lookup string) ([]keySource, error) {
+	if lookup == "" {
+		return []keySource{{source: "header", name: "X-API-Key"}}, nil
+	}
+	var sources []keySource
+	for _, entry := range strings.Split(lookup, ",") {
+		source, name, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("KeyLookup entry %q is not of the form <source>:<name>", entry)
+		}
+		switch source {
+		case "header", "query", "cookie":
+		default:
+			return nil, fmt.Errorf("KeyLookup entry %q has an unsupported source", entry)
+		}
+		sources = append(sources, keySource{source: source, name: name})
+	}
+	return sources, nil
+}
+
+// extractAPIKey returns the first key found in sources, or "".
+func extractAPIKey(c *context.Context, sources []keySource) string {
+	for _, s := range sources {
+		var key string
+		switch s.source {
+		case "header":
+			key = c.Header(s.name)
+		case "query":
+			key = c.Query(s.name)
+		case "cookie":
+			if cookie, err := c.Cookie(s.name); err == nil {
+				key = cookie.Value
+			}
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			return key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/baggage"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/logger"
+)
+
+// baggageLoggerKey is the context key of the request-scoped logger.
+const baggageLoggerKey = "logger"
+
+// BaggageConfig holds configuration for baggage propagation middleware.
+type BaggageConfig struct {
+	// Headers maps custom correlation request headers to baggage keys,
+	// e.g. {"X-Correlation-ID": "correlation_id"}. Header values take
+	// precedence over the same key in the baggage header.
+	Headers map[string]string
+
+	// Logger, if set, is used to create a request-scoped logger carrying the
+	// baggage entries as fields, available via RequestLogger.
+	Logger *logger.Logger
+}
+
+// DefaultBaggageConfig returns default baggage configuration.
+func DefaultBaggageConfig() BaggageConfig {
+	return BaggageConfig{
+		Headers: map[string]string{
+			"X-Correlation-ID": "correlation_id",
+		},
+	}
+}
+
+// Baggage returns a middleware that reads W3C baggage and correlation headers
+// into the request context, so baggage.Transport propagates them to outgoing calls.
+//
+// Example:
+//
+//	app.Use(middleware.Baggage())
+//
+//	client := &http.Client{Transport: baggage.Transport(nil)}
+func Baggage() kese.MiddlewareFunc {
+	return BaggageWithConfig(DefaultBaggageConfig())
+}
+
+// BaggageWithConfig returns baggage middleware with custom configuration.
+//
+// Example:
+//
+//	app.Use(middleware.BaggageWithConfig(middleware.BaggageConfig{
+//	    Headers: map[string]string{"X-Customer-ID": "customer_id"},
+//	    Logger:  app.Logger,
+//	}))
+//
+//	func handler(c *context.Context) error {
+//	    middleware.RequestLogger(c, app.Logger).Info("Order placed") // includes customer_id
+//	    ...
+//	}
+func BaggageWithConfig(config BaggageConfig) kese.MiddlewareFunc {
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			b := baggage.Parse(c.Header(baggage.Header))
+			for header, key := range config.Headers {
+				if value := c.Header(header); value != "" {
+					b[key] = value
+				}
+			}
+
+			if len(b) > 0 {
+				c.SetContext(baggage.NewContext(c.Context(), b))
+			}
+
+			if config.Logger != nil {
+				fields := make([]interface{}, 0, len(b)*2)
+				for key, value := range b {
+					fields = append(fields, key, value)
+				}
+				c.Set(baggageLoggerKey, config.Logger.With(fields...))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequestLogger returns the request-scoped logger created by the Baggage
+// middleware, or fallback if none was created.
+func RequestLogger(c *context.Context, fallback *logger.Logger) *logger.Logger {
+	if scoped, ok := c.Get(baggageLoggerKey).(*logger.Logger); ok {
+		return scoped
+	}
+	return fallback
+}
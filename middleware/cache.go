@@ -1,9 +1,8 @@
 package middleware
 
 import (
-	"bytes"
-	"encoding/json"
-	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/JedizLaPulga/kese"
@@ -29,6 +28,10 @@ type CacheConfig struct {
 	// KeyFunc generates cache keys from context
 	// Default: uses method + path
 	KeyFunc func(*context.Context) string
+
+	// RouteOnly caches only routes that declare a policy with Route.Cache.
+	// Default: false (other GET routes use TTL)
+	RouteOnly bool
 }
 
 // DefaultCacheConfig returns default cache configuration.
@@ -43,6 +46,9 @@ func DefaultCacheConfig(ttl time.Duration) CacheConfig {
 }
 
 // Cache returns a middleware that caches GET responses.
+// Routes can override the TTL and vary the cache key with Route.Cache,
+// or opt out with Route.NoCache. App.PrimeCache fills the cache ahead of
+// traffic.
 //
 // Example:
 //
@@ -60,14 +66,24 @@ func CacheWithConfig(config CacheConfig) kese.MiddlewareFunc {
 				return next(c)
 			}
 
-			// Generate cache key
+			// A route-level policy overrides the global TTL
+			ttl := config.TTL
 			key := config.KeyFunc(c)
+			if policy, ok := c.RouteMeta(kese.CachePolicyKey).(*kese.CachePolicy); ok {
+				ttl = policy.TTL
+				key += policyKey(c, policy)
+			} else if config.RouteOnly {
+				return next(c)
+			}
+			if ttl <= 0 {
+				return next(c)
+			}
 
-			// Try to get from cache
-			if cached, found := config.Store.Get(key); found {
+			// Try to get from cache, unless refreshing it for app.PrimeCache
+			if cached, found := config.Store.Get(key); found && !kese.IsPriming(c) {
 				// Unmarshal cached response
 				var resp cachedResponse
-				if err := json.Unmarshal(cached, &resp); err == nil {
+				if err := context.JSONUnmarshal(cached, &resp); err == nil {
 					// Restore headers
 					for k, values := range resp.Headers {
 						for _, v := range values {
@@ -80,71 +96,63 @@ func CacheWithConfig(config CacheConfig) kese.MiddlewareFunc {
 					// Write status and body
 					c.Writer.WriteHeader(resp.StatusCode)
 					c.Writer.Write(resp.Body)
-					c.SetWritten()
 					return nil
 				}
 				// If unmarshal fails, continue to generate fresh response
 			}
 
 			// Capture response
-			recorder := &responseRecorder{
-				ResponseWriter: c.Writer,
-				body:           &bytes.Buffer{},
-			}
-
-			c.Writer = recorder
-
-			// Call next handler
-			err := next(c)
+			resp, err := c.BufferResponse(next)
 
 			// Cache the response if successful
-			if err == nil && recorder.statusCode >= 200 && recorder.statusCode < 300 {
+			if err == nil && resp.Status() >= 200 && resp.Status() < 300 {
 				// Create cached response with full metadata
-				resp := cachedResponse{
-					StatusCode: recorder.statusCode,
+				cached := cachedResponse{
+					StatusCode: resp.Status(),
 					Headers:    make(map[string][]string),
-					Body:       recorder.body.Bytes(),
+					Body:       resp.Body.Bytes(),
 				}
 
 				// Copy headers
-				for k, v := range recorder.Header() {
-					resp.Headers[k] = v
+				for k, v := range resp.Header() {
+					cached.Headers[k] = v
 				}
 
 				// Marshal and store
-				if data, err := json.Marshal(resp); err == nil {
-					config.Store.Set(key, data, config.TTL)
+				if data, err := context.JSONMarshal(cached); err == nil {
+					config.Store.Set(key, data, ttl)
 				}
 			}
 
 			// Set cache miss header
-			recorder.Header().Set("X-Cache", "MISS")
+			resp.Header().Set("X-Cache", "MISS")
 
 			// Write the captured response
-			for k, v := range recorder.Header() {
-				c.Writer.Header()[k] = v
-			}
-			if recorder.statusCode > 0 {
-				c.Writer.WriteHeader(recorder.statusCode)
+			if commitErr := resp.Commit(); err == nil {
+				err = commitErr
 			}
-			c.Writer.Write(recorder.body.Bytes())
 
 			return err
 		}
 	}
 }
 
-// responseRecorder captures the response for caching.
-type responseRecorder struct {
-	http.ResponseWriter
-	statusCode int
-	body       *bytes.Buffer
-}
-
-func (r *responseRecorder) WriteHeader(statusCode int) {
-	r.statusCode = statusCode
-}
-
-func (r *responseRecorder) Write(b []byte) (int, error) {
-	return r.body.Write(b)
+// policyKey returns the cache key suffix for the query parameters and headers
+// a route policy varies on.
+func policyKey(c *context.Context, policy *kese.CachePolicy) string {
+	var b strings.Builder
+	if len(policy.VaryQuery) > 0 {
+		query := c.Request.URL.Query()
+		varied := url.Values{}
+		for _, param := range policy.VaryQuery {
+			if values, exists := query[param]; exists {
+				varied[param] = values
+			}
+		}
+		b.WriteString("?" + varied.Encode())
+	}
+	for _, header := range policy.VaryHeader {
+		b.WriteString("|" + header + "=" + c.Header(header))
+	}
+	return b.String()
 }
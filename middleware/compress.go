@@ -2,12 +2,11 @@ package middleware
 
 import (
 	"compress/gzip"
-	"io"
-	"net/http"
 	"strings"
 
 	"github.com/JedizLaPulga/kese"
 	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/rw"
 )
 
 // GzipConfig holds configuration for gzip compression middleware.
@@ -43,29 +42,6 @@ func DefaultGzipConfig() GzipConfig {
 	}
 }
 
-// gzipResponseWriter wraps http.ResponseWriter to enable gzip compression.
-type gzipResponseWriter struct {
-	io.Writer
-	http.ResponseWriter
-	wroteHeader bool
-}
-
-func (w *gzipResponseWriter) Write(b []byte) (int, error) {
-	if !w.wroteHeader {
-		w.WriteHeader(http.StatusOK)
-	}
-	return w.Writer.Write(b)
-}
-
-func (w *gzipResponseWriter) WriteHeader(code int) {
-	if w.wroteHeader {
-		return
-	}
-	w.wroteHeader = true
-	w.ResponseWriter.Header().Del("Content-Length") // Content-Length will be wrong after compression
-	w.ResponseWriter.WriteHeader(code)
-}
-
 // Gzip returns a middleware that compresses HTTP responses using gzip.
 // Uses default configuration (compression level -1, min size 1KB).
 //
@@ -126,12 +102,15 @@ func GzipWithConfig(config GzipConfig) kese.MiddlewareFunc {
 
 			// Set gzip headers
 			c.Writer.Header().Set("Content-Encoding", "gzip")
-			c.Writer.Header().Set("Vary", "Accept-Encoding")
+			c.Writer.Header().Add("Vary", "Accept-Encoding")
 
-			// Wrap response writer
-			gzWriter := &gzipResponseWriter{
-				Writer:         gz,
+			// Wrap response writer; Content-Length will be wrong after compression
+			gzWriter := &rw.Wrapper{
 				ResponseWriter: c.Writer,
+				Body:           gz,
+				OnWriteHeader: func(int) {
+					c.Writer.Header().Del("Content-Length")
+				},
 			}
 
 			// Replace the writer in context
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// RequireContentType returns a middleware that rejects requests whose body
+// is not one of the given media types with 415 Unsupported Media Type,
+// rendered as problem details, before the handler parses the body.
+// Parameters such as charset are ignored, and a type may use a wildcard
+// subtype ("text/*"). Requests without a body are passed through.
+// It panics with a *ConfigError if no media type is given.
+//
+// Example:
+//
+//	api := app.Group("/api", middleware.RequireContentType("application/json"))
+//
+//	// or for a single route
+//	app.POST("/upload", middleware.RequireContentType("multipart/form-data")(upload))
+func RequireContentType(mediaTypes ...string) kese.MiddlewareFunc {
+	if len(mediaTypes) == 0 {
+		panic(&kese.ConfigError{
+			Component: "require-content-type",
+			Problem:   "no media type given; every request with a body would be rejected",
+			Fix:       "pass the accepted types, e.g. RequireContentType(\"application/json\")",
+		})
+	}
+
+	allowed := make([]string, len(mediaTypes))
+	for i, mediaType := range mediaTypes {
+		allowed[i] = strings.ToLower(mediaType)
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if !hasBody(c.Request) {
+				return next(c)
+			}
+
+			contentType := c.Header("Content-Type")
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err == nil && matchMediaType(allowed, mediaType) {
+				return next(c)
+			}
+
+			detail := fmt.Sprintf("Content-Type %q is not supported", contentType)
+			if contentType == "" {
+				detail = "Content-Type header is required"
+			}
+			problem := kese.NewProblem(http.StatusUnsupportedMediaType, detail)
+			problem.Extensions = map[string]interface{}{"supported": mediaTypes}
+			return problem
+		}
+	}
+}
+
+// hasBody reports whether a request carries a body.
+func hasBody(r *http.Request) bool {
+	return r.ContentLength > 0 || (r.ContentLength < 0 && r.Body != nil && r.Body != http.NoBody)
+}
+
+// matchMediaType reports whether mediaType matches one of allowed, which may
+// contain wildcard subtypes.
+func matchMediaType(allowed []string, mediaType string) bool {
+	for _, candidate := range allowed {
+		if candidate == mediaType || candidate == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(candidate, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
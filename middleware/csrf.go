@@ -1,15 +1,38 @@
 package middleware
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/JedizLaPulga/kese"
 	"github.com/JedizLaPulga/kese/context"
 )
 
+// Errors passed to CSRFConfig.ErrorHandler for rejected requests.
+var (
+	// ErrCSRFCookieMissing reports a request without the token cookie
+	ErrCSRFCookieMissing = errors.New("CSRF token missing")
+
+	// ErrCSRFTokenMissing reports a request without a token in TokenLookup
+	ErrCSRFTokenMissing = errors.New("CSRF token not provided")
+
+	// ErrCSRFTokenInvalid reports a token not matching the cookie, or not
+	// signed for the session
+	ErrCSRFTokenInvalid = errors.New("CSRF token invalid")
+
+	// ErrCSRFOriginUntrusted reports a request from an origin not in
+	// TrustedOrigins
+	ErrCSRFOriginUntrusted = errors.New("CSRF origin not trusted")
+)
+
 // CSRFConfig holds configuration for CSRF protection middleware.
 type CSRFConfig struct {
 	// TokenLength is the length of the CSRF token. Default: 32
@@ -32,10 +55,56 @@ type CSRFConfig struct {
 	CookieHTTPOnly bool
 
 	// CookieSameSite sets SameSite attribute. Default: http.SameSiteStrictMode
+	// http.SameSiteNoneMode (for cross-site SPAs) requires CookieSecure.
 	CookieSameSite http.SameSite
 
+	// CookieSecure sets the Secure flag so the cookie is only sent over HTTPS. Default: false
+	CookieSecure bool
+
+	// ResponseHeader, if set, exposes the token in this response header on safe
+	// requests so single-page apps can read it and send it back (see SPACSRFConfig).
+	// Default: "" (disabled)
+	ResponseHeader string
+
 	// ContextKey is the key to store CSRF token in context. Default: "csrf_token"
 	ContextKey string
+
+	// Secret signs tokens with HMAC-SHA256, so only tokens issued by the
+	// server are accepted, even if an attacker can plant a cookie (e.g. from
+	// a sibling subdomain). Default: nil (unsigned tokens)
+	Secret []byte
+
+	// SessionFunc returns the session or user ID tokens are bound to when
+	// Secret is set. A token issued to one session is rejected for another,
+	// e.g. after logging in as someone else. Default: nil (not bound)
+	SessionFunc func(*context.Context) string
+
+	// TrustedOrigins, if set, enables checking the Origin header (or the
+	// Referer if there is none) of unsafe requests: they must come from the
+	// request's own host or one of these origins, e.g.
+	// "https://app.example.com" or "https://*.example.com".
+	// Requests without either header, such as from non-browser clients, pass.
+	TrustedOrigins []string
+
+	// RotateAfterUse issues a new token after each successful validation, so
+	// a leaked token can be used once at most. The new token is set in the
+	// cookie, the context and ResponseHeader. Pages holding the old token,
+	// e.g. in other tabs, must reload it before submitting again. Without
+	// it, a client keeps its token until the cookie is lost or, with
+	// SessionFunc, its session changes.
+	RotateAfterUse bool
+
+	// SkipPrefixes exempts paths under these prefixes, e.g. "/api/" for JSON
+	// APIs authenticated with tokens rather than cookies.
+	SkipPrefixes []string
+
+	// SkipFunc allows skipping CSRF protection for certain requests.
+	SkipFunc func(*context.Context) bool
+
+	// ErrorHandler renders rejected requests, e.g. as a branded page or
+	// problem details. err is one of the ErrCSRF errors.
+	// Default: 403 Forbidden with err's message
+	ErrorHandler func(c *context.Context, err error) error
 }
 
 // DefaultCSRFConfig returns the default CSRF configuration.
@@ -51,6 +120,19 @@ func DefaultCSRFConfig() CSRFConfig {
 	}
 }
 
+// SPACSRFConfig returns a CSRF configuration for single-page apps served from
+// another origin: the token is exposed in the X-CSRF-Token response header,
+// expected back in the same request header, and the cookie is sent cross-site
+// (SameSite=None; Secure). Add X-CSRF-Token to the CORS exposed and allowed headers.
+func SPACSRFConfig() CSRFConfig {
+	config := DefaultCSRFConfig()
+	config.TokenLookup = "header:X-CSRF-Token"
+	config.ResponseHeader = "X-CSRF-Token"
+	config.CookieSameSite = http.SameSiteNoneMode
+	config.CookieSecure = true
+	return config
+}
+
 // CSRF returns a middleware that provides CSRF protection.
 //
 // Example:
@@ -60,54 +142,141 @@ func DefaultCSRFConfig() CSRFConfig {
 //	// In handler, get token for forms:
 //	token := c.CSRFToken()
 //
-//	// In template:
-//	<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+//	// In templates rendered by kese.TemplateEngine:
+//	<form method="POST">{{csrfField}} ...</form>
 func CSRF() kese.MiddlewareFunc {
 	return CSRFWithConfig(DefaultCSRFConfig())
 }
 
+// Validate reports configuration mistakes that would disable CSRF protection.
+func (config CSRFConfig) Validate() error {
+	if config.CookieName == "" {
+		return &kese.ConfigError{
+			Component: "csrf",
+			Problem:   "CookieName is empty; without a token cookie there is no per-client state to validate submitted tokens against",
+			Fix:       "start from DefaultCSRFConfig() or set CookieName",
+		}
+	}
+	if config.TokenLength < 16 {
+		return &kese.ConfigError{
+			Component: "csrf",
+			Problem:   fmt.Sprintf("TokenLength %d is too short to resist guessing", config.TokenLength),
+			Fix:       "use a TokenLength of at least 16 (default 32)",
+		}
+	}
+	if !strings.HasPrefix(config.TokenLookup, "form:") && !strings.HasPrefix(config.TokenLookup, "header:") {
+		return &kese.ConfigError{
+			Component: "csrf",
+			Problem:   fmt.Sprintf("TokenLookup %q has an unsupported source; every unsafe request would be rejected", config.TokenLookup),
+			Fix:       "use \"form:<field>\" or \"header:<name>\"",
+		}
+	}
+	if len(config.Secret) > 0 && len(config.Secret) < 32 {
+		return &kese.ConfigError{
+			Component: "csrf",
+			Problem:   fmt.Sprintf("Secret is %d bytes; signatures could be brute-forced", len(config.Secret)),
+			Fix:       "use a random Secret of at least 32 bytes",
+		}
+	}
+	if config.SessionFunc != nil && len(config.Secret) == 0 {
+		return &kese.ConfigError{
+			Component: "csrf",
+			Problem:   "SessionFunc is set without Secret; tokens can only be bound to sessions when signed",
+			Fix:       "set Secret to a random key of at least 32 bytes",
+		}
+	}
+	for _, origin := range config.TrustedOrigins {
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return &kese.ConfigError{
+				Component: "csrf",
+				Problem:   fmt.Sprintf("trusted origin %q is not an origin", origin),
+				Fix:       "use scheme and host only, e.g. \"https://app.example.com\"",
+			}
+		}
+	}
+	if config.CookieSameSite == http.SameSiteNoneMode && !config.CookieSecure {
+		return &kese.ConfigError{
+			Component: "csrf",
+			Problem:   "CookieSameSite is None without CookieSecure; browsers reject such cookies, so every unsafe request would fail",
+			Fix:       "set CookieSecure to true (and serve over HTTPS)",
+		}
+	}
+	return nil
+}
+
 // CSRFWithConfig returns a CSRF middleware with custom configuration.
+// Panics with a *kese.ConfigError if the configuration is invalid.
+//
+// Example:
+//
+//	config := middleware.DefaultCSRFConfig()
+//	config.SkipPrefixes = []string{"/api/"}
+//	config.ErrorHandler = func(c *context.Context, err error) error {
+//	    return kese.NewProblem(http.StatusForbidden, "your session expired, please reload the page")
+//	}
+//	app.Use(middleware.CSRFWithConfig(config))
 func CSRFWithConfig(config CSRFConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c *context.Context, err error) error {
+			return c.Forbidden(err.Error())
+		}
+	}
+
 	return func(next kese.HandlerFunc) kese.HandlerFunc {
 		return func(c *context.Context) error {
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+			if len(config.SkipPrefixes) > 0 && hasAnyPrefix(c.Path(), config.SkipPrefixes) {
+				return next(c)
+			}
+
 			// Skip CSRF for safe methods
 			if c.Method() == "GET" || c.Method() == "HEAD" || c.Method() == "OPTIONS" {
-				// Generate and set token for safe methods
-				token, err := generateToken(config.TokenLength)
-				if err != nil {
+				// Keep the client's token so forms open in other tabs stay
+				// valid; issue one only if it is missing or invalid
+				if token, ok := currentCSRFToken(c, config); ok {
+					exposeCSRFToken(c, config, token)
+					return next(c)
+				}
+				if err := issueCSRFToken(c, config); err != nil {
 					return err
 				}
-
-				// Set cookie
-				http.SetCookie(c.Writer, &http.Cookie{
-					Name:     config.CookieName,
-					Value:    token,
-					Path:     config.CookiePath,
-					HttpOnly: config.CookieHTTPOnly,
-					SameSite: config.CookieSameSite,
-				})
-
-				// Store in context for templates
-				c.Set(config.ContextKey, token)
-
 				return next(c)
 			}
 
-			// For unsafe methods, validate token
+			// For unsafe methods, validate origin and token
+			if len(config.TrustedOrigins) > 0 && !trustedOrigin(c, config.TrustedOrigins) {
+				return config.ErrorHandler(c, ErrCSRFOriginUntrusted)
+			}
+
 			cookieToken, err := c.Cookie(config.CookieName)
 			if err != nil || cookieToken == nil {
-				return c.Forbidden("CSRF token missing")
+				return config.ErrorHandler(c, ErrCSRFCookieMissing)
 			}
 
 			// Extract token from request
 			requestToken := extractCSRFToken(c, config.TokenLookup)
 			if requestToken == "" {
-				return c.Forbidden("CSRF token not provided")
+				return config.ErrorHandler(c, ErrCSRFTokenMissing)
 			}
 
-			// Validate tokens match
-			if cookieToken.Value != requestToken {
-				return c.Forbidden("CSRF token invalid")
+			// Validate tokens match, in constant time
+			if subtle.ConstantTimeCompare([]byte(cookieToken.Value), []byte(requestToken)) != 1 {
+				return config.ErrorHandler(c, ErrCSRFTokenInvalid)
+			}
+			if len(config.Secret) > 0 && !validCSRFSignature(c, config, requestToken) {
+				return config.ErrorHandler(c, ErrCSRFTokenInvalid)
+			}
+
+			if config.RotateAfterUse {
+				if err := issueCSRFToken(c, config); err != nil {
+					return err
+				}
+				return next(c)
 			}
 
 			// Store in context
@@ -118,6 +287,21 @@ func CSRFWithConfig(config CSRFConfig) kese.MiddlewareFunc {
 	}
 }
 
+// CSRFTokenHandler returns a handler serving the current CSRF token as JSON
+// ({"csrf_token": "..."}), for clients that fetch it before submitting.
+// The route must be behind the CSRF middleware.
+//
+// Example:
+//
+//	app.GET("/csrf-token", middleware.CSRFTokenHandler())
+func CSRFTokenHandler() kese.HandlerFunc {
+	return func(c *context.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{
+			"csrf_token": c.CSRFToken(),
+		})
+	}
+}
+
 // generateToken generates a random CSRF token.
 func generateToken(length int) (string, error) {
 	bytes := make([]byte, length)
@@ -127,6 +311,99 @@ func generateToken(length int) (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
+// issueCSRFToken generates a token and sets it in the cookie, the context
+// and ResponseHeader.
+func issueCSRFToken(c *context.Context, config CSRFConfig) error {
+	token, err := generateToken(config.TokenLength)
+	if err != nil {
+		return err
+	}
+	if len(config.Secret) > 0 {
+		token += "." + csrfSignature(c, config, token)
+	}
+
+	// Set cookie
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     config.CookieName,
+		Value:    token,
+		Path:     config.CookiePath,
+		HttpOnly: config.CookieHTTPOnly,
+		Secure:   config.CookieSecure,
+		SameSite: config.CookieSameSite,
+	})
+
+	exposeCSRFToken(c, config, token)
+	return nil
+}
+
+// currentCSRFToken returns the token of the request's cookie if it can be
+// reused: present and, with Secret, signed for the request's session.
+func currentCSRFToken(c *context.Context, config CSRFConfig) (string, bool) {
+	cookie, err := c.Cookie(config.CookieName)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+	if len(config.Secret) > 0 && !validCSRFSignature(c, config, cookie.Value) {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+// exposeCSRFToken stores token in the context for templates and in
+// ResponseHeader for JavaScript clients.
+func exposeCSRFToken(c *context.Context, config CSRFConfig, token string) {
+	if config.ResponseHeader != "" {
+		c.SetHeader(config.ResponseHeader, token)
+	}
+	c.Set(config.ContextKey, token)
+}
+
+// csrfSignature returns the HMAC of the random part of a token, bound to
+// the request's session.
+func csrfSignature(c *context.Context, config CSRFConfig, random string) string {
+	var session string
+	if config.SessionFunc != nil {
+		session = config.SessionFunc(c)
+	}
+	mac := hmac.New(sha256.New, config.Secret)
+	mac.Write([]byte(session + "!" + random))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// validCSRFSignature reports whether token was issued by the server for the
+// request's session.
+func validCSRFSignature(c *context.Context, config CSRFConfig, token string) bool {
+	random, signature, ok := strings.Cut(token, ".")
+	return ok && hmac.Equal([]byte(signature), []byte(csrfSignature(c, config, random)))
+}
+
+// trustedOrigin reports whether the Origin, or else the Referer, of the
+// request is its own host or a trusted origin. Requests with neither pass.
+func trustedOrigin(c *context.Context, trusted []string) bool {
+	origin := c.Header("Origin")
+	if origin == "" {
+		origin = c.Header("Referer")
+	}
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	host := normalizeHost(u.Host)
+	if host == normalizeHost(c.Request.Host) {
+		return true
+	}
+	for _, t := range trusted {
+		if tu, err := url.Parse(t); err == nil && tu.Scheme == u.Scheme && matchHost([]string{normalizeHost(tu.Host)}, host) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractCSRFToken extracts CSRF token from request.
 func extractCSRFToken(c *context.Context, lookup string) string {
 	// Parse lookup format using safe string operations
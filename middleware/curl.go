@@ -0,0 +1,291 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/logger"
+)
+
+// redacted replaces secret values in logged curl commands.
+const redacted = "REDACTED"
+
+// DebugCurlConfig holds configuration for the DebugCurl middleware.
+type DebugCurlConfig struct {
+	// Logger receives the curl commands
+	Logger *logger.Logger
+
+	// MinStatus also logs requests that returned no error but responded with
+	// at least this status (default: 500)
+	MinStatus int
+
+	// RedactHeaders lists request headers whose values are replaced, in
+	// addition to Authorization, Proxy-Authorization, Cookie and headers
+	// whose name contains one of the RedactFields, like X-API-Key
+	RedactHeaders []string
+
+	// RedactFields lists the words of query parameters and JSON or form body
+	// fields whose values are replaced, in addition to password, secret,
+	// token and api_key. Names match if they contain a word, ignoring case,
+	// "_" and "-": "password" also redacts "new_password" and
+	// "currentPassword"
+	RedactFields []string
+
+	// MaxBodySize is the largest request body included in the command;
+	// larger bodies, bodies of unknown length and bodies that cannot be
+	// redacted (anything but valid JSON and forms) are omitted
+	// (default: 64KB)
+	MaxBodySize int64
+}
+
+// DefaultDebugCurlConfig returns the default DebugCurl configuration.
+func DefaultDebugCurlConfig(logger *logger.Logger) DebugCurlConfig {
+	return DebugCurlConfig{
+		Logger:      logger,
+		MinStatus:   500,
+		MaxBodySize: 64 << 10,
+	}
+}
+
+// DebugCurl returns a middleware that logs a curl command reproducing every
+// request that fails, so API consumers and maintainers can replay it
+// directly. Credentials, cookies and secret fields are redacted, but the
+// command still contains request data, so enable it in development only.
+//
+// Example:
+//
+//	if os.Getenv("APP_ENV") == "development" {
+//	    app.Use(middleware.DebugCurl(app.Logger))
+//	}
+//
+//	// Logged: Failed request curl="curl -X POST 'http://localhost:8080/todos'
+//	//   -H 'Authorization: REDACTED' -H 'Content-Type: application/json'
+//	//   --data-raw '{\"title\":\"\"}'" error="..."
+func DebugCurl(logger *logger.Logger) kese.MiddlewareFunc {
+	return DebugCurlWithConfig(DefaultDebugCurlConfig(logger))
+}
+
+// Validate reports configuration mistakes.
+func (config DebugCurlConfig) Validate() error {
+	if config.Logger == nil {
+		return &kese.ConfigError{
+			Component: "debug-curl",
+			Problem:   "Logger is nil; commands would not be logged anywhere",
+			Fix:       "pass the app logger, e.g. DebugCurl(app.Logger)",
+		}
+	}
+	return nil
+}
+
+// DebugCurlWithConfig returns a DebugCurl middleware with custom configuration.
+// Panics with a *kese.ConfigError if the configuration is invalid.
+func DebugCurlWithConfig(config DebugCurlConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.MinStatus <= 0 {
+		config.MinStatus = 500
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 64 << 10
+	}
+
+	secretFields := &secretNames{}
+	for _, word := range append([]string{"password", "secret", "token", "api_key"}, config.RedactFields...) {
+		secretFields.words = append(secretFields.words, normalizeSecretName(word))
+	}
+	secretHeaders := &secretNames{exact: map[string]bool{}, words: secretFields.words}
+	for _, name := range append([]string{"Authorization", "Proxy-Authorization", "Cookie"}, config.RedactHeaders...) {
+		secretHeaders.exact[normalizeSecretName(name)] = true
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			// Buffer small bodies up front, as the handler may consume them
+			var body []byte
+			bodyKnown := c.Request.ContentLength == 0
+			if size := c.Request.ContentLength; size > 0 && size <= config.MaxBodySize {
+				if data, err := c.BodyBytes(); err == nil {
+					body, bodyKnown = data, true
+					c.Request.Body = io.NopCloser(bytes.NewReader(data))
+				}
+			}
+
+			err := next(c)
+			if err == nil && c.StatusCode() < config.MinStatus {
+				return err
+			}
+
+			fields := []interface{}{
+				"curl", curlCommand(c, body, bodyKnown, secretHeaders, secretFields),
+				"method", c.Method(),
+				"path", c.Path(),
+				"request_id", c.RequestID(),
+			}
+			if err != nil {
+				fields = append(fields, "error", err.Error())
+			} else {
+				fields = append(fields, "status", c.StatusCode())
+			}
+			config.Logger.Warn("Failed request", fields...)
+			return err
+		}
+	}
+}
+
+// secretNames matches the names of headers and fields whose values are
+// redacted: names listed exactly, and names containing one of words. Names
+// are compared by normalizeSecretName.
+type secretNames struct {
+	exact map[string]bool
+	words []string
+}
+
+func (s *secretNames) match(name string) bool {
+	name = normalizeSecretName(name)
+	if s.exact[name] {
+		return true
+	}
+	for _, word := range s.words {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSecretName lowercases name and removes "_" and "-", so
+// "new_password", "newPassword" and "New-Password" compare equal.
+func normalizeSecretName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// curlCommand returns a shell command reproducing the request, with the
+// values of the given headers and fields redacted.
+func curlCommand(c *context.Context, body []byte, bodyKnown bool, headers, fields *secretNames) string {
+	r := c.Request
+	scheme := "http"
+	if c.IsTLS() {
+		scheme = "https"
+	}
+	target := url.URL{Scheme: scheme, Host: r.Host, Path: r.URL.Path, RawQuery: redactQuery(r.URL.RawQuery, fields)}
+
+	parts := []string{"curl"}
+	if r.Method != "GET" {
+		parts = append(parts, "-X", r.Method)
+	}
+	parts = append(parts, shellQuote(target.String()))
+
+	names := make([]string, 0, len(r.Header))
+	for name := range r.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		for _, value := range r.Header[name] {
+			if headers.match(name) {
+				value = redacted
+			}
+			parts = append(parts, "-H", shellQuote(name+": "+value))
+		}
+	}
+
+	if len(body) > 0 {
+		body, bodyKnown = redactBody(r.Header.Get("Content-Type"), body, fields)
+	}
+	switch {
+	case len(body) > 0:
+		parts = append(parts, "--data-raw", shellQuote(string(body)))
+	case !bodyKnown:
+		parts = append(parts, "# request body omitted")
+	}
+	return strings.Join(parts, " ")
+}
+
+// redactQuery replaces the values of secret query parameters. Each
+// "&"- or ";"-separated pair is redacted on its own, so malformed queries
+// that url.ParseQuery rejects are redacted too.
+func redactQuery(rawQuery string, fields *secretNames) string {
+	var b strings.Builder
+	for rawQuery != "" {
+		pair := rawQuery
+		sep := ""
+		if i := strings.IndexAny(rawQuery, "&;"); i >= 0 {
+			pair, sep, rawQuery = rawQuery[:i], rawQuery[i:i+1], rawQuery[i+1:]
+		} else {
+			rawQuery = ""
+		}
+
+		key, _, hasValue := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if hasValue && fields.match(name) {
+			pair = key + "=" + redacted
+		}
+		b.WriteString(pair + sep)
+	}
+	return b.String()
+}
+
+// redactBody replaces secret fields of JSON and form bodies, and reports
+// false for bodies it cannot redact: other media types and invalid JSON.
+func redactBody(contentType string, body []byte, fields *secretNames) ([]byte, bool) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return []byte(redactQuery(string(body), fields)), true
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value interface{}
+		if json.Unmarshal(body, &value) != nil {
+			return nil, false
+		}
+		if !redactJSON(value, fields) {
+			return body, true
+		}
+		if data, err := json.Marshal(value); err == nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// redactJSON replaces secret fields in a decoded JSON value in place and
+// reports whether anything was replaced.
+func redactJSON(value interface{}, fields *secretNames) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if fields.match(key) {
+				v[key] = redacted
+				changed = true
+			} else if redactJSON(item, fields) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactJSON(item, fields) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// ETagConfig holds configuration for ETag middleware.
+type ETagConfig struct {
+	// Weak sends weak ETags (W/"..."), for responses that are semantically
+	// but not byte-for-byte equivalent, e.g. when compressed differently
+	Weak bool
+
+	// SkipFunc allows skipping ETag generation for certain requests
+	SkipFunc func(*context.Context) bool
+}
+
+// DefaultETagConfig returns the default ETag configuration.
+func DefaultETagConfig() ETagConfig {
+	return ETagConfig{}
+}
+
+// ETag returns a middleware that adds an ETag to successful GET and HEAD
+// responses by hashing the body, and answers requests whose If-None-Match
+// matches with an empty 304 Not Modified. Responses that already carry an
+// ETag keep it. Responses are buffered, so register it after Compress and
+// skip streaming endpoints.
+//
+// Example:
+//
+//	app.Use(middleware.ETag())
+func ETag() kese.MiddlewareFunc {
+	return ETagWithConfig(DefaultETagConfig())
+}
+
+// ETagWithConfig returns ETag middleware with custom configuration.
+func ETagWithConfig(config ETagConfig) kese.MiddlewareFunc {
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			method := c.Method()
+			if (method != http.MethodGet && method != http.MethodHead) ||
+				(config.SkipFunc != nil && config.SkipFunc(c)) {
+				return next(c)
+			}
+
+			resp, err := c.BufferResponse(next)
+			if err != nil || resp.Status() != http.StatusOK {
+				resp.Commit()
+				return err
+			}
+
+			etag := resp.Header().Get("ETag")
+			if etag == "" {
+				etag = context.ContentETag(resp.Body.Bytes())
+				if config.Weak {
+					etag = "W/" + etag
+				}
+				resp.Header().Set("ETag", etag)
+			}
+
+			if c.IfNoneMatch(etag) {
+				return c.NotModified(etag)
+			}
+			return resp.Commit()
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/geoip"
+)
+
+// geoLocationKey is the context key of the request's *geoip.Location.
+const geoLocationKey = "geoip_location"
+
+// GeoIPConfig holds configuration for the GeoIP middleware.
+type GeoIPConfig struct {
+	// Resolver looks up client IPs, e.g. geoip.MaxMind(reader)
+	Resolver geoip.Resolver
+
+	// AllowCountries lists the ISO 3166-1 alpha-2 codes ("DE", "US") allowed
+	// to connect. If empty, every country not denied is allowed.
+	AllowCountries []string
+
+	// DenyCountries lists the country codes that are always rejected
+	DenyCountries []string
+
+	// AllowUnknown lets requests whose country is unknown, such as those
+	// from private networks or health probes, pass AllowCountries
+	AllowUnknown bool
+
+	// IPFunc returns the client IP of a request.
+	// Default: the connection's remote address
+	IPFunc func(*context.Context) string
+
+	// SkipFunc allows skipping the lookup for certain requests.
+	SkipFunc func(*context.Context) bool
+}
+
+// DefaultGeoIPConfig returns the default GeoIP configuration using resolver,
+// which only enriches requests.
+func DefaultGeoIPConfig(resolver geoip.Resolver) GeoIPConfig {
+	return GeoIPConfig{
+		Resolver: resolver,
+		IPFunc:   remoteIP,
+	}
+}
+
+// GeoIP returns a middleware that resolves the client IP of each request to
+// its country and ASN, available to handlers via RequestLocation. The Metrics
+// middleware counts requests by country when it runs before GeoIP.
+//
+// Example:
+//
+//	app.Use(middleware.Metrics())
+//	app.Use(middleware.GeoIP(geoip.MaxMind(countries, asns)))
+//
+//	// In handler
+//	if middleware.RequestLocation(c).Country == "DE" {
+//	    ...
+//	}
+func GeoIP(resolver geoip.Resolver) kese.MiddlewareFunc {
+	return GeoIPWithConfig(DefaultGeoIPConfig(resolver))
+}
+
+// Validate reports configuration mistakes such as malformed country codes.
+func (config GeoIPConfig) Validate() error {
+	if config.Resolver == nil {
+		return &kese.ConfigError{
+			Component: "geoip",
+			Problem:   "Resolver is nil; client IPs cannot be resolved",
+			Fix:       "set Resolver, e.g. to geoip.MaxMind(reader)",
+		}
+	}
+	for _, code := range append(append([]string(nil), config.AllowCountries...), config.DenyCountries...) {
+		if len(code) != 2 {
+			return &kese.ConfigError{
+				Component: "geoip",
+				Problem:   fmt.Sprintf("invalid country code %q", code),
+				Fix:       "use ISO 3166-1 alpha-2 codes such as \"DE\" or \"US\"",
+			}
+		}
+	}
+	return nil
+}
+
+// GeoIPWithConfig returns a GeoIP middleware with custom configuration.
+// Requests from countries that are not allowed are rejected with 403
+// Forbidden, rendered as problem details. Panics with a *kese.ConfigError if
+// the configuration is invalid.
+//
+// Example:
+//
+//	app.Use(middleware.GeoIPWithConfig(middleware.GeoIPConfig{
+//	    Resolver:       geoip.MaxMind(countries),
+//	    AllowCountries: []string{"DE", "AT", "CH"},
+//	    AllowUnknown:   true, // internal traffic and probes
+//	}))
+func GeoIPWithConfig(config GeoIPConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.IPFunc == nil {
+		config.IPFunc = remoteIP
+	}
+	allow := countrySet(config.AllowCountries)
+	deny := countrySet(config.DenyCountries)
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+
+			// Lookup failures leave the location empty, like unknown IPs
+			location := &geoip.Location{}
+			if ip := net.ParseIP(config.IPFunc(c)); ip != nil {
+				if found, err := config.Resolver.Lookup(ip); err == nil && found != nil {
+					location = found
+				}
+			}
+			c.Set(geoLocationKey, location)
+
+			country := strings.ToUpper(location.Country)
+			switch {
+			case deny[country],
+				len(allow) > 0 && country == "" && !config.AllowUnknown,
+				len(allow) > 0 && country != "" && !allow[country]:
+				return kese.NewProblem(http.StatusForbidden, "access from your location is not allowed")
+			}
+			return next(c)
+		}
+	}
+}
+
+// countrySet returns the upper-case codes as a set.
+func countrySet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(code)] = true
+	}
+	return set
+}
+
+// RequestLocation returns the location of the request's client resolved by
+// the GeoIP middleware, with empty fields if it is unknown, or nil if the
+// middleware did not run.
+func RequestLocation(c *context.Context) *geoip.Location {
+	location, _ := c.Get(geoLocationKey).(*geoip.Location)
+	return location
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/logger"
+)
+
+// DefaultHoneypotPaths are paths probed by vulnerability scanners that a Go
+// API never serves.
+var DefaultHoneypotPaths = []string{
+	"/.env",
+	"/.git/config",
+	"/wp-login.php",
+	"/wp-admin",
+	"/xmlrpc.php",
+	"/phpmyadmin",
+	"/admin.php",
+	"/config.php",
+	"/.aws/credentials",
+	"/server-status",
+}
+
+// HoneypotConfig holds configuration for honeypot endpoints.
+type HoneypotConfig struct {
+	// Paths are the decoy endpoints, registered for GET and POST.
+	// Default: DefaultHoneypotPaths
+	Paths []string
+
+	// Blocklist receives the IPs that hit a decoy; pass the same Blocklist to
+	// IPFilter to reject their later requests. Nil only logs them.
+	Blocklist *Blocklist
+
+	// BlockFor is how long a flagged IP stays blocked (0 = until removed).
+	// Default: 24 hours
+	BlockFor time.Duration
+
+	// Delay holds each decoy response for this long (a tarpit), slowing down
+	// scanners that wait for an answer. Default: 0 (respond immediately)
+	Delay time.Duration
+
+	// Status is the status of decoy responses, rendered by the error handler
+	// like any other error. Default: 404, indistinguishable from a missing route
+	Status int
+
+	// Logger logs each hit as a warning. Default: the app's Logger
+	Logger *logger.Logger
+
+	// IPFunc returns the client IP of a request; use the same function as
+	// IPFilter. Default: the connection's remote address
+	IPFunc func(*context.Context) string
+
+	// OnTrigger is called for each hit, e.g. to report the IP to a shared
+	// blocklist or an abuse database
+	OnTrigger func(c *context.Context, ip string)
+}
+
+// DefaultHoneypotConfig returns the default honeypot configuration feeding
+// blocklist.
+func DefaultHoneypotConfig(blocklist *Blocklist) HoneypotConfig {
+	return HoneypotConfig{
+		Paths:     DefaultHoneypotPaths,
+		Blocklist: blocklist,
+		BlockFor:  24 * time.Hour,
+		Status:    http.StatusNotFound,
+		IPFunc:    remoteIP,
+	}
+}
+
+// Honeypot registers decoy endpoints for the paths scanners probe, such as
+// /.env and /wp-login.php. Clients that request one are logged and added to
+// blocklist for 24 hours, so an IPFilter using the same blocklist rejects
+// everything else they send. Decoys are registered on the router directly:
+// they bypass middleware and are left out of Routes, the schema and
+// generated clients.
+//
+// Example:
+//
+//	blocklist := middleware.NewBlocklist()
+//	app.Use(middleware.IPFilter(blocklist))
+//	middleware.Honeypot(app, blocklist)
+func Honeypot(app *kese.App, blocklist *Blocklist) {
+	HoneypotWithConfig(app, DefaultHoneypotConfig(blocklist))
+}
+
+// Validate reports configuration mistakes such as relative paths.
+func (config HoneypotConfig) Validate() error {
+	for _, path := range config.Paths {
+		if len(path) == 0 || path[0] != '/' {
+			return &kese.ConfigError{
+				Component: "honeypot",
+				Problem:   "path " + path + " does not start with /",
+				Fix:       "use paths such as \"/.env\"",
+			}
+		}
+	}
+	if config.Status != 0 && (config.Status < 100 || config.Status > 599) {
+		return &kese.ConfigError{
+			Component: "honeypot",
+			Problem:   "Status is not an HTTP status code",
+			Fix:       "use a status such as 404 or 403, or 0 for 404",
+		}
+	}
+	return nil
+}
+
+// HoneypotWithConfig is Honeypot with custom configuration.
+// Panics with a *kese.ConfigError if the configuration is invalid.
+//
+// Example:
+//
+//	middleware.HoneypotWithConfig(app, middleware.HoneypotConfig{
+//	    Paths:     append(middleware.DefaultHoneypotPaths, "/actuator/env"),
+//	    Blocklist: blocklist,
+//	    BlockFor:  time.Hour,
+//	    Delay:     10 * time.Second, // tarpit
+//	})
+func HoneypotWithConfig(app *kese.App, config HoneypotConfig) {
+	if config.Paths == nil {
+		config.Paths = DefaultHoneypotPaths
+	}
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.Status == 0 {
+		config.Status = http.StatusNotFound
+	}
+	if config.IPFunc == nil {
+		config.IPFunc = remoteIP
+	}
+
+	handler := func(c *context.Context) error {
+		ip := config.IPFunc(c)
+		log := config.Logger
+		if log == nil {
+			log = app.Logger
+		}
+		log.Warn("Honeypot triggered",
+			"ip", ip,
+			"method", c.Method(),
+			"path", c.Path(),
+			"user_agent", c.Header("User-Agent"),
+		)
+		if config.Blocklist != nil {
+			config.Blocklist.Add(ip, config.BlockFor)
+		}
+		if config.OnTrigger != nil {
+			config.OnTrigger(c, ip)
+		}
+
+		if config.Delay > 0 {
+			timer := time.NewTimer(config.Delay)
+			select {
+			case <-timer.C:
+			case <-c.Done():
+				timer.Stop()
+				return context.ErrClientClosed
+			}
+		}
+		if config.Status == http.StatusNotFound {
+			return kese.ErrNotFound.WithMessage("404 Not Found")
+		}
+		return kese.NewHTTPError(config.Status, "")
+	}
+
+	router := app.Router()
+	for _, path := range config.Paths {
+		router.Add(http.MethodGet, path, handler)
+		router.Add(http.MethodPost, path, handler)
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// RequireIfMatch returns a middleware that rejects PUT and PATCH requests
+// without an If-Match header with 428 Precondition Required, so clients cannot
+// skip optimistic concurrency checks. Handlers still compare the ETag with
+// c.IfMatch and answer mismatches with c.PreconditionFailed (412).
+//
+// Example:
+//
+//	api := app.Group("/api", middleware.RequireIfMatch())
+//	api.PUT("/todos/:id", func(c *context.Context) error {
+//	    todo := load(c.Param("id"))
+//	    if !c.IfMatch(context.ResourceETag(todo.Version)) {
+//	        return c.PreconditionFailed("todo was modified by another request")
+//	    }
+//	    ...
+//	})
+func RequireIfMatch() kese.MiddlewareFunc {
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if (c.Method() == "PUT" || c.Method() == "PATCH") && !c.HasIfMatch() {
+				return c.PreconditionRequired("If-Match header is required")
+			}
+			return next(c)
+		}
+	}
+}
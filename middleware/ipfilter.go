@@ -0,0 +1,268 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// Blocklist is a set of client IPs blocked by IPFilter, each until an expiry
+// time. It is filled at runtime, e.g. by Honeypot, and is safe for
+// concurrent use.
+type Blocklist struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+
+	// pruneAt is the size at which Add next drops expired entries
+	pruneAt int
+}
+
+// minBlocklistPrune is the smallest size at which Add drops expired entries.
+const minBlocklistPrune = 64
+
+// NewBlocklist creates an empty Blocklist.
+func NewBlocklist() *Blocklist {
+	return &Blocklist{entries: make(map[string]time.Time)}
+}
+
+// Add blocks ip for ttl, or until Remove if ttl is 0. Adding an IP that is
+// already blocked extends its block.
+func (b *Blocklist) Add(ip string, ttl time.Duration) {
+	var until time.Time
+	if ttl > 0 {
+		until = time.Now().Add(ttl)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[normalizeIP(ip)] = until
+
+	// Expired entries are otherwise only dropped when looked up again. Sweep
+	// each time the list doubles, so IPs seen once don't accumulate
+	if len(b.entries) >= b.pruneAt {
+		now := time.Now()
+		for key, until := range b.entries {
+			if !until.IsZero() && !now.Before(until) {
+				delete(b.entries, key)
+			}
+		}
+		b.pruneAt = 2 * len(b.entries)
+		if b.pruneAt < minBlocklistPrune {
+			b.pruneAt = minBlocklistPrune
+		}
+	}
+}
+
+// Remove unblocks ip.
+func (b *Blocklist) Remove(ip string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.entries, normalizeIP(ip))
+}
+
+// Contains reports whether ip is blocked.
+func (b *Blocklist) Contains(ip string) bool {
+	key := normalizeIP(ip)
+	b.mu.RLock()
+	until, ok := b.entries[key]
+	b.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if until.IsZero() || time.Now().Before(until) {
+		return true
+	}
+
+	// Drop the expired entry unless it was extended meanwhile
+	b.mu.Lock()
+	if until, ok := b.entries[key]; ok && !until.IsZero() && !time.Now().Before(until) {
+		delete(b.entries, key)
+	}
+	b.mu.Unlock()
+	return false
+}
+
+// List returns the blocked IPs with their expiry (zero for permanent blocks).
+func (b *Blocklist) List() map[string]time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	now := time.Now()
+	list := make(map[string]time.Time, len(b.entries))
+	for ip, until := range b.entries {
+		if until.IsZero() || now.Before(until) {
+			list[ip] = until
+		}
+	}
+	return list
+}
+
+// normalizeIP returns the canonical form of ip, so "::ffff:10.0.0.1" and
+// "10.0.0.1" are the same entry.
+func normalizeIP(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil {
+		return parsed.String()
+	}
+	return ip
+}
+
+// remoteIP returns the IP of the connection, without port. Like RateLimit,
+// it ignores X-Forwarded-For, which clients can spoof.
+func remoteIP(c *context.Context) string {
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		return c.Request.RemoteAddr
+	}
+	return host
+}
+
+// IPFilterConfig holds configuration for the IP filter middleware.
+type IPFilterConfig struct {
+	// Allow lists the IPs and CIDR ranges ("10.0.0.0/8") allowed to connect.
+	// If empty, every IP not denied is allowed.
+	Allow []string
+
+	// Deny lists IPs and CIDR ranges that are always rejected
+	Deny []string
+
+	// Blocklist holds IPs blocked at runtime, e.g. by Honeypot
+	Blocklist *Blocklist
+
+	// IPFunc returns the client IP of a request.
+	// Default: the connection's remote address. Behind a proxy, return the
+	// address it reports, and use the same function for Honeypot.
+	IPFunc func(*context.Context) string
+
+	// SkipFunc allows skipping the filter for certain requests.
+	SkipFunc func(*context.Context) bool
+}
+
+// DefaultIPFilterConfig returns the default IP filter configuration rejecting
+// the IPs in blocklist.
+func DefaultIPFilterConfig(blocklist *Blocklist) IPFilterConfig {
+	return IPFilterConfig{
+		Blocklist: blocklist,
+		IPFunc:    remoteIP,
+	}
+}
+
+// IPFilter returns a middleware that rejects requests from the IPs in
+// blocklist with 403 Forbidden, rendered as problem details.
+//
+// Example:
+//
+//	blocklist := middleware.NewBlocklist()
+//	app.Use(middleware.IPFilter(blocklist))
+//	middleware.Honeypot(app, blocklist) // scanners block themselves
+func IPFilter(blocklist *Blocklist) kese.MiddlewareFunc {
+	return IPFilterWithConfig(DefaultIPFilterConfig(blocklist))
+}
+
+// Validate reports configuration mistakes such as unparseable ranges.
+func (config IPFilterConfig) Validate() error {
+	if len(config.Allow) == 0 && len(config.Deny) == 0 && config.Blocklist == nil {
+		return &kese.ConfigError{
+			Component: "ip-filter",
+			Problem:   "no Allow, Deny or Blocklist given; every request would pass",
+			Fix:       "set Allow or Deny to IPs or CIDR ranges, or pass a Blocklist",
+		}
+	}
+	for _, entry := range append(append([]string(nil), config.Allow...), config.Deny...) {
+		if _, err := parseIPNet(entry); err != nil {
+			return &kese.ConfigError{
+				Component: "ip-filter",
+				Problem:   err.Error(),
+				Fix:       "use IPs such as \"203.0.113.7\" or CIDR ranges such as \"10.0.0.0/8\"",
+			}
+		}
+	}
+	return nil
+}
+
+// IPFilterWithConfig returns an IP filter middleware with custom configuration.
+// Panics with a *kese.ConfigError if the configuration is invalid.
+//
+// Example:
+//
+//	// Admin routes only from the office network and VPN
+//	admin := app.Group("/admin", middleware.IPFilterWithConfig(middleware.IPFilterConfig{
+//	    Allow: []string{"198.51.100.0/24", "10.8.0.0/16"},
+//	}))
+func IPFilterWithConfig(config IPFilterConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.IPFunc == nil {
+		config.IPFunc = remoteIP
+	}
+	allow := parseIPNets(config.Allow)
+	deny := parseIPNets(config.Deny)
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+
+			ip := config.IPFunc(c)
+			parsed := net.ParseIP(ip)
+			switch {
+			case config.Blocklist != nil && config.Blocklist.Contains(ip),
+				containsIP(deny, parsed),
+				len(allow) > 0 && !containsIP(allow, parsed):
+				return kese.NewProblem(http.StatusForbidden, "access from this address is not allowed")
+			}
+			return next(c)
+		}
+	}
+}
+
+// parseIPNet parses a CIDR range or a single IP.
+func parseIPNet(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q", entry)
+		}
+		return network, nil
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP %q", entry)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// parseIPNets parses validated entries.
+func parseIPNets(entries []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		network, _ := parseIPNet(entry)
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// containsIP reports whether ip is in one of networks.
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
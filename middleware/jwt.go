@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"crypto"
 	"strings"
 
 	"github.com/JedizLaPulga/kese"
@@ -10,9 +11,22 @@ import (
 
 // JWTConfig holds configuration for JWT middleware.
 type JWTConfig struct {
-	// Secret is the key used to sign and validate tokens
+	// Secret is the key used to validate HS256 tokens
 	Secret string
 
+	// Algorithm is the only signing algorithm accepted: auth.HS256, auth.RS256,
+	// auth.ES256 or auth.EdDSA. Tokens signed with any other algorithm are
+	// rejected. Default: auth.HS256
+	Algorithm string
+
+	// PublicKey validates tokens signed with an asymmetric Algorithm,
+	// e.g. loaded with auth.ParsePublicKeyPEM
+	PublicKey crypto.PublicKey
+
+	// Validation checks registered claims beyond expiry, such as the
+	// expected issuer and audience and the clock-skew leeway
+	Validation auth.ValidationOptions
+
 	// ContextKey is the key used to store claims in context.
 	// Default: "jwt_claims"
 	ContextKey string
@@ -32,6 +46,7 @@ type JWTConfig struct {
 func DefaultJWTConfig(secret string) JWTConfig {
 	return JWTConfig{
 		Secret:      secret,
+		Algorithm:   auth.HS256,
 		ContextKey:  "jwt_claims",
 		TokenLookup: "header:Authorization",
 		SkipFunc:    nil,
@@ -51,7 +66,36 @@ func JWT(secret string) kese.MiddlewareFunc {
 	return JWTWithConfig(DefaultJWTConfig(secret))
 }
 
+// Validate reports configuration mistakes that would reject every token.
+func (config JWTConfig) Validate() error {
+	if err := auth.CheckKey(config.algorithm(), config.key()); err != nil {
+		return &kese.ConfigError{
+			Component: "jwt",
+			Problem:   err.Error(),
+			Fix:       "set Algorithm to auth.HS256 with Secret, or to auth.RS256, auth.ES256 or auth.EdDSA with a matching PublicKey",
+		}
+	}
+	return nil
+}
+
+// algorithm returns the configured algorithm, defaulting to HS256.
+func (config JWTConfig) algorithm() string {
+	if config.Algorithm == "" {
+		return auth.HS256
+	}
+	return config.Algorithm
+}
+
+// key returns the key tokens are validated with.
+func (config JWTConfig) key() interface{} {
+	if config.algorithm() == auth.HS256 {
+		return []byte(config.Secret)
+	}
+	return config.PublicKey
+}
+
 // JWTWithConfig returns a JWT middleware with custom configuration.
+// Panics with a *kese.ConfigError if the configuration is invalid.
 //
 // Example:
 //
@@ -63,7 +107,30 @@ func JWT(secret string) kese.MiddlewareFunc {
 //	        return c.Path() == "/login" || c.Path() == "/register"
 //	    },
 //	}))
+//
+//	// Tokens issued by an identity provider signing with RS256
+//	key, _ := auth.ParsePublicKeyPEM(publicPEM)
+//	app.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+//	    Algorithm: auth.RS256,
+//	    PublicKey: key,
+//	    Validation: auth.ValidationOptions{
+//	        Issuer:   "https://id.example.com",
+//	        Audience: "orders-api",
+//	        Leeway:   30 * time.Second,
+//	    },
+//	}))
 func JWTWithConfig(config JWTConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = "jwt_claims"
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = "header:Authorization"
+	}
+	alg, key := config.algorithm(), config.key()
+
 	return func(next kese.HandlerFunc) kese.HandlerFunc {
 		return func(c *context.Context) error {
 			// Check if we should skip JWT validation
@@ -78,10 +145,13 @@ func JWTWithConfig(config JWTConfig) kese.MiddlewareFunc {
 			}
 
 			// Validate token
-			claims, err := auth.ValidateToken(token, config.Secret)
+			claims, err := auth.ValidateTokenWithOptions(token, alg, key, config.Validation)
 			if err != nil {
-				if err == auth.ErrTokenExpired {
+				switch err {
+				case auth.ErrTokenExpired:
 					return c.Unauthorized("token has expired")
+				case auth.ErrTokenNotYetValid:
+					return c.Unauthorized("token is not valid yet")
 				}
 				return c.Unauthorized("invalid token")
 			}
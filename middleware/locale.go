@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/i18n"
+)
+
+// LocaleConfig holds configuration for locale negotiation middleware.
+type LocaleConfig struct {
+	// Supported lists the locale tags the application supports; the first is
+	// the fallback. Default: ["en"]
+	Supported []string
+
+	// QueryParam, if set, lets clients override negotiation (e.g. ?lang=fr)
+	QueryParam string
+
+	// ContextKey is the key the negotiated tag is stored under with c.Set. Default: "locale"
+	ContextKey string
+}
+
+// DefaultLocaleConfig returns default locale configuration for the supported tags.
+func DefaultLocaleConfig(supported ...string) LocaleConfig {
+	if len(supported) == 0 {
+		supported = []string{i18n.DefaultTag}
+	}
+	return LocaleConfig{
+		Supported:  supported,
+		QueryParam: "lang",
+		ContextKey: "locale",
+	}
+}
+
+// Locale returns a middleware that negotiates the request locale from the
+// Accept-Language header and stores it in the request context for i18n.FromContext.
+// The response gets a Content-Language header.
+//
+// Example:
+//
+//	app.Use(middleware.Locale("en", "fr", "de"))
+//
+//	func handler(c *context.Context) error {
+//	    price := i18n.FromContext(c).Currency(19.99, "EUR")
+//	    ...
+//	}
+func Locale(supported ...string) kese.MiddlewareFunc {
+	return LocaleWithConfig(DefaultLocaleConfig(supported...))
+}
+
+// LocaleWithConfig returns locale middleware with custom configuration.
+func LocaleWithConfig(config LocaleConfig) kese.MiddlewareFunc {
+	if len(config.Supported) == 0 {
+		config.Supported = []string{i18n.DefaultTag}
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = "locale"
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			accept := c.Header("Accept-Language")
+			if config.QueryParam != "" {
+				if requested := c.Query(config.QueryParam); requested != "" {
+					accept = requested
+				}
+			}
+
+			tag := i18n.Negotiate(accept, config.Supported...)
+			c.Set(config.ContextKey, tag)
+			c.SetContext(i18n.NewContext(c.Context(), tag))
+			c.SetHeader("Content-Language", tag)
+
+			return next(c)
+		}
+	}
+}
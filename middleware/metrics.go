@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"time"
 
 	"github.com/JedizLaPulga/kese"
@@ -34,7 +35,6 @@ func DefaultMetricsConfig() MetricsConfig {
 //	// Expose metrics endpoint
 //	app.GET("/metrics", func(c *context.Context) error {
 //	    metrics.Handler().ServeHTTP(c.Writer, c.Request)
-//	    c.SetWritten()
 //	    return nil
 //	})
 func Metrics() kese.MiddlewareFunc {
@@ -67,8 +67,26 @@ func MetricsWithConfig(config MetricsConfig) kese.MiddlewareFunc {
 				statusCode = 200
 			}
 
+			if errors.Is(err, context.ErrClientClosed) {
+				config.Metrics.RecordClientClosed()
+			}
+
 			config.Metrics.RecordRequest(c.Method(), c.Path(), duration, statusCode)
 
+			if files, size := c.MultipartTempFiles(); files > 0 {
+				config.Metrics.RecordUploadTempFiles(files, size)
+			}
+			if location := RequestLocation(c); location != nil {
+				config.Metrics.RecordCountry(location.Country)
+			}
+			if spilled, size := c.BodySpilled(); spilled {
+				config.Metrics.RecordBodyTempFile(size)
+			}
+
+			if _, deprecated := c.RouteMeta(kese.DeprecationKey).(*kese.Deprecation); deprecated {
+				config.Metrics.RecordDeprecated(c.Method(), c.RoutePath())
+			}
+
 			return err
 		}
 	}
@@ -3,8 +3,8 @@ package middleware
 import (
 	"fmt"
 	"runtime/debug"
+	"strconv"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"github.com/JedizLaPulga/kese"
@@ -13,7 +13,8 @@ import (
 )
 
 // Logger returns a middleware that logs HTTP requests using structured logging.
-// It logs the method, path, status code, and response time for each request.
+// It logs the method, path, status code, response size, response time and client
+// (the parsed User-Agent, flagging bots) for each request.
 // Accepts a logger instance to ensure consistent structured logging across the application.
 func Logger(logger *logger.Logger) kese.MiddlewareFunc {
 	return func(next kese.HandlerFunc) kese.HandlerFunc {
@@ -25,12 +26,20 @@ func Logger(logger *logger.Logger) kese.MiddlewareFunc {
 
 			// Log after handler completes using structured logging
 			duration := time.Since(start)
-			logger.Info("Request completed",
+			fields := []interface{}{
 				"method", c.Method(),
 				"path", c.Path(),
 				"status", c.StatusCode(),
+				"bytes", c.ResponseSize(),
 				"duration_ms", duration.Milliseconds(),
-			)
+			}
+			if ua := c.UserAgent(); ua.Browser != "" || ua.OS != "" {
+				fields = append(fields, "client", ua.String())
+				if ua.Bot {
+					fields = append(fields, "bot", true)
+				}
+			}
+			logger.Info("Request completed", fields...)
 
 			return err
 		}
@@ -49,12 +58,17 @@ func Recovery(logger *logger.Logger) kese.MiddlewareFunc {
 					logger.Error("Panic recovered",
 						"panic", fmt.Sprintf("%v", r),
 						"stack", string(debug.Stack()),
+						"request_id", c.RequestID(),
 					)
 					// Only write response if nothing has been written yet
 					if !c.IsWritten() {
-						c.JSON(500, map[string]interface{}{
+						response := map[string]interface{}{
 							"error": "Internal Server Error",
-						})
+						}
+						if id := c.RequestID(); id != "" {
+							response["request_id"] = id
+						}
+						c.JSON(500, response)
 					}
 				}
 			}()
@@ -76,64 +90,250 @@ func CORS() kese.MiddlewareFunc {
 
 // CORSConfig holds configuration for the CORS middleware.
 type CORSConfig struct {
+	// AllowOrigins lists the origins allowed to make requests, e.g.
+	// "https://app.example.com". A "*." subdomain pattern such as
+	// "https://*.example.com" matches any subdomain but not example.com
+	// itself; "*" alone allows any origin.
 	AllowOrigins []string
+
+	// AllowOriginFunc, if set, is asked about origins not in AllowOrigins,
+	// e.g. to look up the domains of tenants.
+	AllowOriginFunc func(origin string) bool
+
 	AllowMethods []string
 	AllowHeaders []string
+
+	// ExposeHeaders lists response headers that scripts may read, such as
+	// X-Request-ID or X-CSRF-Token.
+	ExposeHeaders []string
+
+	// MaxAge is how long browsers may cache preflight responses, saving a
+	// round trip before each request. Default: 0 (not sent)
+	MaxAge time.Duration
+
+	// AllowCredentials sends Access-Control-Allow-Credentials to origins
+	// matched by a wildcard-subdomain pattern or AllowOriginFunc, letting
+	// their scripts make requests with cookies and Authorization headers and
+	// read the responses. Origins listed exactly in AllowOrigins always allow
+	// credentials. Browsers reject credentials with a wildcard origin, so
+	// this cannot be combined with "*". Default: false
+	AllowCredentials bool
+}
+
+// Validate reports configuration mistakes that browsers would silently reject.
+func (config CORSConfig) Validate() error {
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" && config.AllowCredentials {
+			return &kese.ConfigError{
+				Component: "cors",
+				Problem:   "AllowCredentials is set with wildcard origin \"*\"; browsers reject credentialed responses for any origin",
+				Fix:       "list the trusted origins explicitly in AllowOrigins",
+			}
+		}
+		if origin != "*" && strings.Contains(origin, "*") && !strings.Contains(origin, "://*.") {
+			return &kese.ConfigError{
+				Component: "cors",
+				Problem:   fmt.Sprintf("origin pattern %q is not supported; only whole subdomains can be wildcards", origin),
+				Fix:       "use a pattern like \"https://*.example.com\", or AllowOriginFunc for other rules",
+			}
+		}
+	}
+	if config.MaxAge < 0 {
+		return &kese.ConfigError{
+			Component: "cors",
+			Problem:   "MaxAge is negative",
+			Fix:       "set MaxAge to how long browsers may cache preflight responses, e.g. 10 * time.Minute",
+		}
+	}
+	return nil
 }
 
 // CORSWithConfig returns a CORS middleware with custom configuration.
 // Properly handles multiple allowed origins by checking the request origin.
+// CORS headers are only sent in response to requests with an Origin header,
+// and only if the origin is allowed. Vary: Origin is always set so caches
+// keep responses for different origins apart. Preflight requests (OPTIONS
+// with Access-Control-Request-Method) are answered with 204 No Content,
+// listing the allowed methods and those requested headers that are allowed;
+// other OPTIONS requests reach the route's handler.
+//
+// An invalid configuration is reported with kese.ReportConfigError, so
+// App.Validate lists it and Run refuses to start; until it is fixed, every
+// request through the middleware fails with 500 Internal Server Error.
+//
+// Example:
+//
+//	app.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+//	    AllowOrigins:  []string{"https://example.com", "https://*.example.com"},
+//	    AllowMethods:  []string{"GET", "POST"},
+//	    AllowHeaders:  []string{"Content-Type", "X-CSRF-Token"},
+//	    ExposeHeaders: []string{"X-Request-ID"},
+//	    MaxAge:        10 * time.Minute,
+//	}))
 func CORSWithConfig(config CORSConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		cfgErr := err.(*kese.ConfigError)
+		kese.ReportConfigError(cfgErr)
+		return func(next kese.HandlerFunc) kese.HandlerFunc {
+			return func(c *context.Context) error {
+				return kese.ErrInternalServerError.WithInternal(cfgErr)
+			}
+		}
+	}
+
+	wildcard := false
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			wildcard = true
+		}
+	}
+	maxAge := strconv.Itoa(int(config.MaxAge / time.Second))
+
 	return func(next kese.HandlerFunc) kese.HandlerFunc {
 		return func(c *context.Context) error {
-			// Set CORS headers based on configuration
-			if len(config.AllowOrigins) > 0 {
-				// Check if wildcard is allowed
-				if len(config.AllowOrigins) == 1 && config.AllowOrigins[0] == "*" {
-					c.SetHeader("Access-Control-Allow-Origin", "*")
-				} else {
-					// Match request origin against allowed origins
-					requestOrigin := c.Header("Origin")
-					for _, allowedOrigin := range config.AllowOrigins {
-						if allowedOrigin == requestOrigin {
-							c.SetHeader("Access-Control-Allow-Origin", requestOrigin)
-							c.SetHeader("Access-Control-Allow-Credentials", "true")
-							break
-						}
-					}
-				}
-			}
+			c.Writer.Header().Add("Vary", "Origin")
 
-			if len(config.AllowMethods) > 0 {
-				c.SetHeader("Access-Control-Allow-Methods", strings.Join(config.AllowMethods, ", "))
+			requestOrigin := c.Header("Origin")
+			if requestOrigin == "" {
+				return next(c)
 			}
 
-			if len(config.AllowHeaders) > 0 {
-				c.SetHeader("Access-Control-Allow-Headers", strings.Join(config.AllowHeaders, ", "))
+			// Set CORS headers based on configuration
+			allowed := true
+			if wildcard {
+				c.SetHeader("Access-Control-Allow-Origin", "*")
+			} else if allowedOrigin(requestOrigin, config.AllowOrigins) ||
+				(config.AllowOriginFunc != nil && config.AllowOriginFunc(requestOrigin)) {
+				c.SetHeader("Access-Control-Allow-Origin", requestOrigin)
+				if config.AllowCredentials || listedOrigin(requestOrigin, config.AllowOrigins) {
+					c.SetHeader("Access-Control-Allow-Credentials", "true")
+				}
+			} else {
+				allowed = false
 			}
 
-			// Handle preflight requests
-			if c.Method() == "OPTIONS" {
+			// Handle preflight requests; other OPTIONS requests reach the
+			// route's handler
+			requestMethod := c.Header("Access-Control-Request-Method")
+			if c.Method() == "OPTIONS" && requestMethod != "" {
+				c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+				c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+				if !allowed || !corsMethodAllowed(requestMethod, config.AllowMethods) {
+					// Without CORS headers the browser blocks the request
+					c.Writer.Header().Del("Access-Control-Allow-Origin")
+					c.Writer.Header().Del("Access-Control-Allow-Credentials")
+					c.NoContent()
+					return nil
+				}
+
+				if len(config.AllowMethods) > 0 {
+					c.SetHeader("Access-Control-Allow-Methods", strings.Join(config.AllowMethods, ", "))
+				} else {
+					c.SetHeader("Access-Control-Allow-Methods", requestMethod)
+				}
+				if headers := corsAllowedHeaders(c.Header("Access-Control-Request-Headers"), config.AllowHeaders); headers != "" {
+					c.SetHeader("Access-Control-Allow-Headers", headers)
+				}
+				if config.MaxAge > 0 {
+					c.SetHeader("Access-Control-Max-Age", maxAge)
+				}
 				c.NoContent()
 				return nil
 			}
 
+			if allowed && len(config.ExposeHeaders) > 0 {
+				c.SetHeader("Access-Control-Expose-Headers", strings.Join(config.ExposeHeaders, ", "))
+			}
+
 			return next(c)
 		}
 	}
 }
 
-// RequestID returns a middleware that adds a unique request ID to each request.
-// The ID is set in the X-Request-ID header.
-// Uses atomic operations to safely increment the counter across concurrent requests.
-func RequestID() kese.MiddlewareFunc {
-	var counter atomic.Uint64
+// corsMethodAllowed reports whether a preflight for method may pass.
+// Without AllowMethods every method is allowed.
+func corsMethodAllowed(method string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, m := range allowed {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsAllowedHeaders returns the headers of an Access-Control-Request-Headers
+// value that are allowed, for the preflight response. Without allowed, every
+// requested header is reflected.
+func corsAllowedHeaders(requested string, allowed []string) string {
+	var headers []string
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if len(allowed) == 0 {
+			headers = append(headers, header)
+			continue
+		}
+		for _, h := range allowed {
+			if strings.EqualFold(h, header) {
+				headers = append(headers, header)
+				break
+			}
+		}
+	}
+	return strings.Join(headers, ", ")
+}
+
+// allowedOrigin reports whether origin is listed in allowed or matches one
+// of its "*." subdomain patterns.
+// listedOrigin reports whether origin is listed exactly in allowed, rather
+// than matched by a pattern.
+func listedOrigin(origin string, allowed []string) bool {
+	for _, listed := range allowed {
+		if strings.EqualFold(listed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func allowedOrigin(origin string, allowed []string) bool {
+	origin = strings.ToLower(origin)
+	scheme, host, ok := strings.Cut(origin, "://")
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if pattern == origin {
+			return true
+		}
+		patternScheme, patternHost, isPattern := strings.Cut(pattern, "://")
+		if ok && isPattern && patternScheme == scheme && strings.HasPrefix(patternHost, "*.") && matchHost([]string{patternHost}, host) {
+			return true
+		}
+	}
+	return false
+}
 
+// RequestID returns a middleware that assigns an ID to each request, available
+// via c.RequestID and included in error responses generated by the framework.
+// A valid X-Request-ID sent by the client or an upstream proxy is kept so the
+// ID follows the request across services; otherwise a new one is generated.
+// The ID is echoed in the X-Request-ID response header. An ID already
+// assigned by the app (see App.RequestIDs) is kept.
+//
+// Middleware only runs for matched routes; set app.RequestIDs so 404s for
+// unmatched paths carry an ID too.
+func RequestID() kese.MiddlewareFunc {
 	return func(next kese.HandlerFunc) kese.HandlerFunc {
 		return func(c *context.Context) error {
-			count := counter.Add(1)
-			requestID := fmt.Sprintf("%d-%d", time.Now().Unix(), count)
-			c.SetHeader("X-Request-ID", requestID)
+			requestID := c.RequestID()
+			if requestID == "" {
+				requestID = context.NewRequestID()
+			}
+			c.SetRequestID(requestID)
 			return next(c)
 		}
 	}
@@ -2,16 +2,41 @@ package middleware
 
 import (
 	"bytes"
+	stdcontext "context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/auth"
+	"github.com/JedizLaPulga/kese/baggage"
+	"github.com/JedizLaPulga/kese/cache"
 	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/geoip"
+	"github.com/JedizLaPulga/kese/i18n"
 	"github.com/JedizLaPulga/kese/logger"
+	"github.com/JedizLaPulga/kese/metrics"
+	"github.com/JedizLaPulga/kese/ratelimit"
+	"github.com/JedizLaPulga/kese/security"
 )
 
 func TestLogger(t *testing.T) {
@@ -88,6 +113,47 @@ func TestRecovery(t *testing.T) {
 	}
 }
 
+func TestRequestIDPropagation(t *testing.T) {
+	app := kese.New()
+	app.Logger = logger.NewWithConfig(logger.ErrorLevel, &bytes.Buffer{})
+	app.Use(RequestID(), Recovery(app.Logger))
+	app.GET("/id", func(c *context.Context) error {
+		return c.String(200, c.RequestID())
+	})
+	app.GET("/panic", func(c *context.Context) error {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("GET", "/id", nil)
+	req.Header.Set("X-Request-ID", "upstream-1")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Body.String() != "upstream-1" || w.Header().Get("X-Request-ID") != "upstream-1" {
+		t.Errorf("Expected upstream request ID kept, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/id", nil))
+	if w.Body.String() == "" || w.Body.String() != w.Header().Get("X-Request-ID") {
+		t.Errorf("Expected generated request ID, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	id := w.Header().Get("X-Request-ID")
+	if w.Code != 500 || id == "" || !strings.Contains(w.Body.String(), `"request_id":"`+id+`"`) {
+		t.Errorf("Expected request ID in recovery response, got %s", w.Body.String())
+	}
+
+	// The ID assigned by the app is kept
+	app.RequestIDs = true
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/id", nil))
+	if values := w.Header().Values("X-Request-ID"); len(values) != 1 || values[0] != w.Body.String() {
+		t.Errorf("Expected the app's request ID to be kept, got %q and %v", w.Body.String(), values)
+	}
+}
+
 func TestRecoveryDoesNotAffectNormalRequests(t *testing.T) {
 	app := kese.New()
 	app.Use(Recovery(app.Logger))
@@ -118,6 +184,7 @@ func TestCORS(t *testing.T) {
 	})
 
 	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Origin", "https://example.com")
 	w := httptest.NewRecorder()
 
 	app.ServeHTTP(w, req)
@@ -126,16 +193,6 @@ func TestCORS(t *testing.T) {
 	if w.Header().Get("Access-Control-Allow-Origin") != "*" {
 		t.Error("Access-Control-Allow-Origin header should be set to *")
 	}
-
-	allowMethods := w.Header().Get("Access-Control-Allow-Methods")
-	if !strings.Contains(allowMethods, "GET") || !strings.Contains(allowMethods, "POST") {
-		t.Errorf("Access-Control-Allow-Methods should contain GET and POST, got %s", allowMethods)
-	}
-
-	allowHeaders := w.Header().Get("Access-Control-Allow-Headers")
-	if !strings.Contains(allowHeaders, "Content-Type") {
-		t.Errorf("Access-Control-Allow-Headers should contain Content-Type, got %s", allowHeaders)
-	}
 }
 
 func TestCORSPreflight(t *testing.T) {
@@ -149,6 +206,9 @@ func TestCORSPreflight(t *testing.T) {
 
 	// Send OPTIONS request (preflight)
 	req := httptest.NewRequest("OPTIONS", "/test", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "content-type, x-custom")
 	w := httptest.NewRecorder()
 
 	app.ServeHTTP(w, req)
@@ -162,6 +222,46 @@ func TestCORSPreflight(t *testing.T) {
 	if w.Header().Get("Access-Control-Allow-Origin") == "" {
 		t.Error("CORS headers should be set for preflight")
 	}
+
+	allowMethods := w.Header().Get("Access-Control-Allow-Methods")
+	if !strings.Contains(allowMethods, "GET") || !strings.Contains(allowMethods, "POST") {
+		t.Errorf("Access-Control-Allow-Methods should contain GET and POST, got %s", allowMethods)
+	}
+
+	// Allowed requested headers are reflected, others left out
+	allowHeaders := w.Header().Get("Access-Control-Allow-Headers")
+	if allowHeaders != "content-type" {
+		t.Errorf("Access-Control-Allow-Headers should be content-type, got %s", allowHeaders)
+	}
+}
+
+func TestCORSNonPreflightOptions(t *testing.T) {
+	app := kese.New()
+	app.Use(CORSWithConfig(CORSConfig{
+		AllowOrigins: []string{"https://example.com"},
+		AllowMethods: []string{"GET", "POST"},
+	}))
+	app.OPTIONS("/test", func(c *context.Context) error {
+		c.SetHeader("Allow", "GET, POST, OPTIONS")
+		return c.NoContent()
+	})
+
+	// Plain OPTIONS requests reach the handler
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/test", nil))
+	if w.Header().Get("Allow") == "" {
+		t.Error("Expected non-preflight OPTIONS to reach the handler")
+	}
+
+	// Preflights for methods not allowed get no CORS headers
+	req := httptest.NewRequest("OPTIONS", "/test", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Header().Get("Allow") != "" || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected rejected preflight without CORS headers, got %v", w.Header())
+	}
 }
 
 func TestCORSWithConfig(t *testing.T) {
@@ -193,13 +293,22 @@ func TestCORSWithConfig(t *testing.T) {
 		t.Error("Expected Access-Control-Allow-Credentials to be true")
 	}
 
-	// Check custom methods
+	// Check custom methods and headers on preflight
+	app.OPTIONS("/test", func(c *context.Context) error {
+		return c.NoContent()
+	})
+	req = httptest.NewRequest("OPTIONS", "/test", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Authorization")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
 	allowMethods := w.Header().Get("Access-Control-Allow-Methods")
 	if !strings.Contains(allowMethods, "GET") || !strings.Contains(allowMethods, "POST") {
 		t.Errorf("Expected GET, POST in methods, got %s", allowMethods)
 	}
 
-	// Check custom headers
 	if w.Header().Get("Access-Control-Allow-Headers") != "Authorization" {
 		t.Errorf("Expected Authorization in headers, got %s", w.Header().Get("Access-Control-Allow-Headers"))
 	}
@@ -313,6 +422,7 @@ func TestMiddlewareChaining(t *testing.T) {
 	})
 
 	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Origin", "https://example.com")
 	w := httptest.NewRecorder()
 
 	app.ServeHTTP(w, req)
@@ -332,3 +442,1767 @@ func TestMiddlewareChaining(t *testing.T) {
 		t.Error("RequestID middleware should have set header")
 	}
 }
+
+func TestCORSCredentialsPatternsAndFunc(t *testing.T) {
+	app := kese.New()
+	app.Use(CORSWithConfig(CORSConfig{
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowOriginFunc:  func(origin string) bool { return origin == "https://tenant.test" },
+		AllowCredentials: true,
+	}))
+	app.GET("/test", func(c *context.Context) error {
+		return c.String(200, "OK")
+	})
+
+	for _, origin := range []string{"https://app.example.com", "https://tenant.test"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Errorf("%s: expected credentials with AllowCredentials, got %v", origin, w.Header())
+		}
+	}
+}
+
+func TestCORSCredentialsWithWildcardFailsValidation(t *testing.T) {
+	app := kese.New()
+	app.Use(CORSWithConfig(CORSConfig{
+		AllowOrigins:     []string{"*"},
+		AllowCredentials: true,
+	}))
+	app.GET("/test", func(c *context.Context) error {
+		return c.String(200, "OK")
+	})
+
+	var problems kese.ConfigErrors
+	if err := app.Validate(); !errors.As(err, &problems) || len(problems) != 1 || problems[0].Component != "cors" {
+		t.Fatalf("Expected Validate to report the CORS configuration, got %v", err)
+	}
+	if err := app.Run(":0"); err == nil || !strings.Contains(err.Error(), "cors") {
+		t.Errorf("Expected Run to refuse to start, got %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	if w.Code != 500 {
+		t.Errorf("Expected misconfigured CORS to fail requests, got %d", w.Code)
+	}
+}
+
+func TestCORSOrigins(t *testing.T) {
+	app := kese.New()
+	app.Use(CORSWithConfig(CORSConfig{
+		AllowOrigins:    []string{"https://example.com", "https://*.example.com"},
+		AllowOriginFunc: func(origin string) bool { return origin == "https://tenant.test" },
+		AllowMethods:    []string{"GET", "POST"},
+		ExposeHeaders:   []string{"X-Request-ID"},
+		MaxAge:          10 * time.Minute,
+	}))
+	handler := func(c *context.Context) error {
+		return c.String(200, "OK")
+	}
+	app.GET("/test", handler)
+	app.OPTIONS("/test", handler)
+
+	// Only the exactly listed origin gets credentials without AllowCredentials
+	tests := []struct {
+		origin      string
+		allowed     bool
+		credentials bool
+	}{
+		{"https://example.com", true, true},
+		{"https://app.example.com", true, false},
+		{"https://a.b.example.com", true, false},
+		{"http://app.example.com", false, false},
+		{"https://evilexample.com", false, false},
+		{"https://tenant.test", true, false},
+		{"https://other.test", false, false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", test.origin)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		got := w.Header().Get("Access-Control-Allow-Origin")
+		if test.allowed && got != test.origin {
+			t.Errorf("%s: expected origin to be allowed, got %q", test.origin, got)
+		}
+		if !test.allowed && (got != "" || w.Header().Get("Access-Control-Allow-Methods") != "") {
+			t.Errorf("%s: expected no CORS headers, got %v", test.origin, w.Header())
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); (got == "true") != test.credentials {
+			t.Errorf("%s: expected credentials %v, got %q", test.origin, test.credentials, got)
+		}
+		if test.allowed && w.Header().Get("Access-Control-Expose-Headers") != "X-Request-ID" {
+			t.Errorf("%s: expected exposed headers, got %v", test.origin, w.Header())
+		}
+		if w.Header().Get("Vary") != "Origin" {
+			t.Errorf("%s: expected Vary: Origin, got %q", test.origin, w.Header().Get("Vary"))
+		}
+	}
+
+	// Same-origin requests get no CORS headers, but still Vary
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	if w.Header().Get("Access-Control-Allow-Methods") != "" || w.Header().Get("Vary") != "Origin" {
+		t.Errorf("Expected only Vary without Origin header, got %v", w.Header())
+	}
+
+	req := httptest.NewRequest("OPTIONS", "/test", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Header().Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("Expected Access-Control-Max-Age 600 on preflight, got %q", w.Header().Get("Access-Control-Max-Age"))
+	}
+}
+
+func TestCacheRoutePolicy(t *testing.T) {
+	app := kese.New()
+	app.Use(CacheWithConfig(CacheConfig{
+		TTL:       time.Minute,
+		Store:     cache.NewMemoryStore(),
+		KeyFunc:   func(c *context.Context) string { return c.Method() + ":" + c.Path() },
+		RouteOnly: true,
+	}))
+
+	calls := map[string]int{}
+	handler := func(c *context.Context) error {
+		calls[c.Path()]++
+		return c.String(200, c.Query("page"))
+	}
+	app.GET("/products", handler).Cache(time.Minute, kese.VaryQuery("page"))
+	app.GET("/live", handler)
+	app.GET("/private", handler).NoCache()
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w
+	}
+
+	if w := get("/products?page=1&utm=a"); w.Header().Get("X-Cache") != "MISS" || w.Body.String() != "1" {
+		t.Fatalf("Expected MISS with body 1, got %q %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := get("/products?page=1&utm=b"); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "1" {
+		t.Errorf("Expected HIT ignoring unvaried query, got %q %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := get("/products?page=2"); w.Header().Get("X-Cache") != "MISS" || w.Body.String() != "2" {
+		t.Errorf("Expected MISS for a different page, got %q %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if calls["/products"] != 2 {
+		t.Errorf("Expected 2 handler calls, got %d", calls["/products"])
+	}
+
+	get("/live")
+	get("/private")
+	if w := get("/live"); w.Header().Get("X-Cache") != "" {
+		t.Error("Routes without a policy should not be cached in RouteOnly mode")
+	}
+	if w := get("/private"); w.Header().Get("X-Cache") != "" {
+		t.Error("NoCache routes should not be cached")
+	}
+}
+
+func TestPrimeCache(t *testing.T) {
+	app := kese.New()
+	app.Use(Cache(time.Minute))
+
+	version := 1
+	app.GET("/products", func(c *context.Context) error {
+		return c.String(200, fmt.Sprintf("v%d", version))
+	})
+
+	if err := app.PrimeCache([]string{"/products"}); err != nil {
+		t.Fatalf("PrimeCache failed: %v", err)
+	}
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/products", nil))
+		return w
+	}
+	if w := get(); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "v1" {
+		t.Errorf("Expected primed HIT v1, got %q %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+
+	// Priming again refreshes the cached response
+	version = 2
+	app.PrimeCache([]string{"/products"})
+	if w := get(); w.Body.String() != "v2" {
+		t.Errorf("Expected refreshed v2, got %q", w.Body.String())
+	}
+
+	if err := app.PrimeCache([]string{"/missing"}); err == nil || !strings.Contains(err.Error(), "/missing") {
+		t.Errorf("Expected an error naming /missing, got %v", err)
+	}
+	if err := app.PrimeCache([]string{"/%zz"}); err == nil || !strings.Contains(err.Error(), "/%zz") {
+		t.Errorf("Expected an error naming the invalid path, got %v", err)
+	}
+
+	// Priming requests carry a Host that AllowedHosts accepts
+	app = kese.New()
+	app.Use(AllowedHosts("shop.example.com"), Cache(time.Minute))
+	app.GET("/products", func(c *context.Context) error {
+		return c.String(200, c.Request.Host)
+	})
+	if err := app.PrimeCache([]string{"/products"}); err == nil {
+		t.Error("Expected the default host to be rejected by AllowedHosts")
+	}
+	app.PrimeHost = "shop.example.com"
+	if err := app.PrimeCache([]string{"/products"}); err != nil {
+		t.Errorf("PrimeCache with PrimeHost failed: %v", err)
+	}
+	app.PrimeHost = ""
+	if err := app.PrimeCache([]string{"https://shop.example.com/products?page=2"}); err != nil {
+		t.Errorf("PrimeCache with an absolute URL failed: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/products", nil)
+	req.Host = "shop.example.com"
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "shop.example.com" {
+		t.Errorf("Expected primed HIT, got %q %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+}
+
+func TestLoggerDirectWrites(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithConfig(logger.InfoLevel, &buf)
+
+	app := kese.New()
+	app.Use(Logger(log))
+	app.GET("/teapot", func(c *context.Context) error {
+		c.Writer.WriteHeader(http.StatusTeapot)
+		c.Writer.Write([]byte("short and stout"))
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/teapot", nil))
+
+	logOutput := buf.String()
+	if !strings.Contains(logOutput, `"status":418`) {
+		t.Errorf("Expected status 418 in log, got %s", logOutput)
+	}
+	if !strings.Contains(logOutput, `"bytes":15`) {
+		t.Errorf("Expected 15 bytes in log, got %s", logOutput)
+	}
+}
+
+var allocSink [][]byte
+
+func TestAllocProfile(t *testing.T) {
+	profiler := NewAllocProfiler(2)
+	config := DefaultAllocProfileConfig(profiler)
+	config.SampleRate = 1
+
+	app := kese.New()
+	app.Use(AllocProfileWithConfig(config))
+	app.GET("/small", func(c *context.Context) error {
+		return c.String(200, "ok")
+	})
+	app.GET("/big/:id", func(c *context.Context) error {
+		allocSink = append(allocSink, make([]byte, 1<<20))
+		return c.String(200, "ok")
+	})
+	app.GET("/debug/allocs", profiler.Handler())
+
+	for _, path := range []string{"/small", "/big/1", "/small", "/small"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	allocSink = nil
+
+	top := profiler.Top()
+	if len(top) != 2 {
+		t.Fatalf("Expected 2 samples, got %d", len(top))
+	}
+	if top[0].Route != "/big/:id" || top[0].Bytes < 1<<20 {
+		t.Errorf("Expected /big/:id as worst offender, got %+v", top[0])
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/debug/allocs", nil))
+	if !strings.Contains(w.Body.String(), `"route":"/big/:id"`) {
+		t.Errorf("Expected handler to list samples, got %s", w.Body.String())
+	}
+}
+
+func TestBaggage(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithConfig(logger.InfoLevel, &buf)
+
+	app := kese.New()
+	app.Use(BaggageWithConfig(BaggageConfig{
+		Headers: map[string]string{"X-Customer-ID": "customer_id"},
+		Logger:  log,
+	}))
+
+	var propagated baggage.Baggage
+	app.GET("/orders", func(c *context.Context) error {
+		propagated = baggage.FromContext(c.Context())
+		RequestLogger(c, log).Info("Order placed")
+		return c.NoContent()
+	})
+
+	req := httptest.NewRequest("GET", "/orders", nil)
+	req.Header.Set("baggage", "order_id=7")
+	req.Header.Set("X-Customer-ID", "42")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	if propagated["order_id"] != "7" || propagated["customer_id"] != "42" {
+		t.Errorf("Unexpected propagated baggage: %v", propagated)
+	}
+	if !strings.Contains(buf.String(), `"customer_id":"42"`) || !strings.Contains(buf.String(), `"order_id":"7"`) {
+		t.Errorf("Expected baggage fields in log, got %s", buf.String())
+	}
+}
+
+func TestCSRFSPAMode(t *testing.T) {
+	app := kese.New()
+	app.Use(CSRFWithConfig(SPACSRFConfig()))
+	app.GET("/csrf-token", CSRFTokenHandler())
+	app.POST("/orders", func(c *context.Context) error {
+		return c.NoContent()
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/csrf-token", nil))
+
+	token := w.Header().Get("X-CSRF-Token")
+	if token == "" || !strings.Contains(w.Body.String(), token) {
+		t.Fatalf("Expected token in header and body, got %q / %s", token, w.Body.String())
+	}
+	cookie := w.Result().Cookies()[0]
+	if cookie.SameSite != http.SameSiteNoneMode || !cookie.Secure {
+		t.Errorf("Expected SameSite=None; Secure cookie, got %+v", cookie)
+	}
+
+	req := httptest.NewRequest("POST", "/orders", nil)
+	req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	req.Header.Set("X-CSRF-Token", token)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204 with header token, got %d", w.Code)
+	}
+}
+
+func TestCSRFSameSiteNoneRequiresSecure(t *testing.T) {
+	config := DefaultCSRFConfig()
+	config.CookieSameSite = http.SameSiteNoneMode
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for SameSite=None without Secure")
+	}
+}
+
+func TestCSRFSignedTokens(t *testing.T) {
+	config := DefaultCSRFConfig()
+	config.Secret = []byte("0123456789abcdef0123456789abcdef")
+	config.SessionFunc = func(c *context.Context) string { return c.Header("X-User") }
+	config.TrustedOrigins = []string{"https://*.example.com"}
+	config.RotateAfterUse = true
+
+	app := kese.New()
+	app.Use(CSRFWithConfig(config))
+	app.GET("/form", func(c *context.Context) error {
+		return c.String(200, c.Get("csrf_token").(string))
+	})
+	app.POST("/orders", func(c *context.Context) error {
+		return c.NoContent()
+	})
+
+	req := httptest.NewRequest("GET", "/form", nil)
+	req.Header.Set("X-User", "alice")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	token := w.Body.String()
+	if !strings.Contains(token, ".") {
+		t.Fatalf("Expected signed token, got %q", token)
+	}
+
+	post := func(user, origin, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader("csrf_token="+token))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-User", user)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		req.AddCookie(&http.Cookie{Name: config.CookieName, Value: token})
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("mallory", "", token); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for another session's token, got %d", w.Code)
+	}
+	if w := post("alice", "", "forged."+strings.Split(token, ".")[1]); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for forged token, got %d", w.Code)
+	}
+	if w := post("alice", "https://evil.com", token); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for untrusted origin, got %d", w.Code)
+	}
+
+	w = post("alice", "https://app.example.com", token)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 for valid token from trusted origin, got %d", w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == token {
+		t.Errorf("Expected rotated token cookie, got %+v", cookies)
+	}
+}
+
+func TestCSRFTokenReusedAcrossPageViews(t *testing.T) {
+	signed := DefaultCSRFConfig()
+	signed.Secret = []byte("0123456789abcdef0123456789abcdef")
+	signed.SessionFunc = func(c *context.Context) string { return c.Header("X-User") }
+
+	for name, config := range map[string]CSRFConfig{"unsigned": DefaultCSRFConfig(), "signed": signed} {
+		app := kese.New()
+		app.Use(CSRFWithConfig(config))
+		app.GET("/form", func(c *context.Context) error {
+			return c.String(200, c.CSRFToken())
+		})
+
+		get := func(user string, cookie *http.Cookie) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/form", nil)
+			req.Header.Set("X-User", user)
+			if cookie != nil {
+				req.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			return w
+		}
+
+		first := get("alice", nil)
+		cookie := first.Result().Cookies()[0]
+		second := get("alice", cookie)
+		if second.Body.String() != first.Body.String() {
+			t.Errorf("%s: expected the same token on the second GET, got %q and %q", name, first.Body.String(), second.Body.String())
+		}
+		if len(second.Result().Cookies()) != 0 {
+			t.Errorf("%s: expected no new cookie for a valid token", name)
+		}
+
+		if name == "signed" {
+			// Tokens of another session, or forged ones, are replaced
+			if w := get("mallory", cookie); w.Body.String() == first.Body.String() {
+				t.Errorf("expected a new token for another session")
+			}
+			if w := get("alice", &http.Cookie{Name: config.CookieName, Value: "planted"}); w.Body.String() == "planted" {
+				t.Errorf("expected a planted token to be replaced")
+			}
+		}
+	}
+}
+
+func TestCSRFSkipAndErrorHandler(t *testing.T) {
+	config := DefaultCSRFConfig()
+	config.SkipPrefixes = []string{"/api/"}
+	config.ErrorHandler = func(c *context.Context, err error) error {
+		if !errors.Is(err, ErrCSRFCookieMissing) {
+			t.Errorf("Expected ErrCSRFCookieMissing, got %v", err)
+		}
+		return kese.NewProblem(http.StatusForbidden, "please reload the page")
+	}
+
+	app := kese.New()
+	app.Use(CSRFWithConfig(config))
+	handler := func(c *context.Context) error {
+		return c.NoContent()
+	}
+	app.POST("/api/orders", handler)
+	app.POST("/orders", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/api/orders", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204 for skipped prefix, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/orders", nil))
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "please reload the page") {
+		t.Errorf("Expected custom 403, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCSRFValidate(t *testing.T) {
+	config := DefaultCSRFConfig()
+	config.SessionFunc = func(c *context.Context) string { return "" }
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for SessionFunc without Secret")
+	}
+
+	config = DefaultCSRFConfig()
+	config.TrustedOrigins = []string{"example.com"}
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for trusted origin without scheme")
+	}
+}
+
+func TestSecureHeadersCSPNonce(t *testing.T) {
+	config := DefaultSecurityConfig()
+	config.ContentSecurityPolicy = "script-src 'self' 'nonce-{nonce}'"
+	config.CSPNonce = true
+	config.PermissionsPolicy = "camera=()"
+
+	app := kese.New()
+	app.Use(SecureHeadersWithConfig(config))
+	app.GET("/", func(c *context.Context) error {
+		return c.String(200, c.CSPNonce())
+	})
+
+	nonces := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		nonce := w.Body.String()
+		if nonce == "" || w.Header().Get("Content-Security-Policy") != "script-src 'self' 'nonce-"+nonce+"'" {
+			t.Errorf("Expected nonce %q in CSP, got %q", nonce, w.Header().Get("Content-Security-Policy"))
+		}
+		nonces[nonce] = true
+
+		for header, want := range map[string]string{
+			"Permissions-Policy":                "camera=()",
+			"Cross-Origin-Opener-Policy":        "same-origin",
+			"Cross-Origin-Resource-Policy":      "same-origin",
+			"X-Permitted-Cross-Domain-Policies": "none",
+		} {
+			if got := w.Header().Get(header); got != want {
+				t.Errorf("Expected %s %q, got %q", header, want, got)
+			}
+		}
+	}
+	if len(nonces) != 2 {
+		t.Error("Expected a new nonce for each request")
+	}
+
+	config.ContentSecurityPolicy = "default-src 'self'"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected error for CSPNonce without {nonce} placeholder")
+	}
+
+	// Policies built with security.CSP announce their report endpoint
+	config.ContentSecurityPolicy = ""
+	config.CSP = security.CSP().ScriptSrc(security.Self, security.Nonce).ReportTo(security.ReportPath)
+	app = kese.New()
+	app.Use(SecureHeadersWithConfig(config))
+	app.GET("/", func(c *context.Context) error {
+		return c.String(200, c.CSPNonce())
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "'nonce-"+w.Body.String()+"'") || !strings.Contains(csp, "report-to csp-endpoint") {
+		t.Errorf("Unexpected CSP %q", csp)
+	}
+	if w.Header().Get("Reporting-Endpoints") != `csp-endpoint="/csp-report"` {
+		t.Errorf("Unexpected Reporting-Endpoints %q", w.Header().Get("Reporting-Endpoints"))
+	}
+}
+
+func TestLocale(t *testing.T) {
+	app := kese.New()
+	app.Use(Locale("en", "fr"))
+	app.GET("/price", func(c *context.Context) error {
+		return c.String(200, i18n.FromContext(c).Currency(1234.5, "EUR"))
+	})
+
+	tests := []struct {
+		url      string
+		accept   string
+		expected string
+	}{
+		{"/price", "fr-FR,fr;q=0.9", "1\u202f234,50\u00a0€"},
+		{"/price", "de", "€1,234.50"},
+		{"/price?lang=fr", "en", "1\u202f234,50\u00a0€"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.url, nil)
+		req.Header.Set("Accept-Language", test.accept)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("%s (%s): expected %q, got %q", test.url, test.accept, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Paris"); err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	app := kese.New()
+	app.Use(func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if tz := c.Header("X-Test-Claim"); tz != "" {
+				c.Set("jwt_claims", auth.Claims{"tz": tz})
+			}
+			return next(c)
+		}
+	})
+	app.Use(Timezone())
+	app.GET("/now", func(c *context.Context) error {
+		instant := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+		return c.String(200, i18n.InZone(c, instant).Format("15:04 MST"))
+	})
+
+	tests := []struct {
+		url      string
+		header   string
+		claim    string
+		expected string
+	}{
+		{"/now", "", "", "12:00 UTC"},
+		{"/now", "Europe/Paris", "", "13:00 CET"},
+		{"/now", "", "America/New_York", "07:00 EST"},
+		{"/now?tz=Asia/Tokyo", "Europe/Paris", "", "21:00 JST"},
+		{"/now", "Mars/Olympus", "", "12:00 UTC"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.url, nil)
+		if test.header != "" {
+			req.Header.Set("Time-Zone", test.header)
+		}
+		if test.claim != "" {
+			req.Header.Set("X-Test-Claim", test.claim)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Body.String() != test.expected {
+			t.Errorf("%s header=%q claim=%q: expected %q, got %q", test.url, test.header, test.claim, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestMetricsDeprecatedRoutes(t *testing.T) {
+	collector := metrics.New()
+	app := kese.New()
+	app.Logger = logger.NewWithConfig(logger.ErrorLevel, &bytes.Buffer{})
+	app.Use(MetricsWithConfig(MetricsConfig{Metrics: collector}))
+
+	app.GET("/v1/items/:id", func(c *context.Context) error {
+		return c.String(200, "ok")
+	}).Deprecated(time.Time{}, "")
+	app.GET("/v2/items/:id", func(c *context.Context) error {
+		return c.String(200, "ok")
+	})
+
+	for _, url := range []string{"/v1/items/1", "/v1/items/2", "/v2/items/1"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	}
+
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if !strings.Contains(w.Body.String(), `kese_deprecated_requests_total{route="GET /v1/items/:id"} 2`) {
+		t.Errorf("Expected deprecated usage count, got:\n%s", w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), `kese_deprecated_requests_total{route="GET /v2`) {
+		t.Error("Current route should not be counted as deprecated")
+	}
+}
+
+func TestGeoIP(t *testing.T) {
+	resolver := geoip.Static(map[string]geoip.Location{
+		"203.0.113.0/24":   {Country: "DE", ASN: 64500, Organization: "Example GmbH"},
+		"203.0.113.128/25": {Country: "AT"},
+		"198.51.100.0/24":  {Country: "US"},
+	})
+	collector := metrics.New()
+
+	app := kese.New()
+	app.Use(MetricsWithConfig(MetricsConfig{Metrics: collector}))
+	app.Use(GeoIPWithConfig(GeoIPConfig{
+		Resolver:       resolver,
+		AllowCountries: []string{"de", "AT"},
+	}))
+	app.GET("/where", func(c *context.Context) error {
+		location := RequestLocation(c)
+		return c.String(200, fmt.Sprintf("%s/%d", location.Country, location.ASN))
+	})
+
+	for _, tt := range []struct {
+		ip   string
+		want int
+		body string
+	}{
+		{"203.0.113.7", 200, "DE/64500"},
+		{"203.0.113.200", 200, "AT/0"},
+		{"198.51.100.1", 403, ""},
+		{"10.0.0.1", 403, ""},
+	} {
+		r := httptest.NewRequest("GET", "/where", nil)
+		r.RemoteAddr = tt.ip + ":4711"
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code != tt.want || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.ip, tt.want, tt.body, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, line := range []string{
+		`kese_requests_by_country_total{country="DE"} 1`,
+		`kese_requests_by_country_total{country="US"} 1`,
+		`kese_requests_by_country_total{country="unknown"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), line) {
+			t.Errorf("Expected %s, got:\n%s", line, w.Body.String())
+		}
+	}
+	collector.RecordCountry("X\"} 1\nfake_metric{a=\"\\")
+	w = httptest.NewRecorder()
+	collector.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if line := `kese_requests_by_country_total{country="X\"} 1\nfake_metric{a=\"\\"} 1`; !strings.Contains(w.Body.String(), line) {
+		t.Errorf("Expected escaped country %s, got:\n%s", line, w.Body.String())
+	}
+
+	// Enrichment only, with a MaxMind-style reader
+	reader := fakeMaxMind{"CH", 64501}
+	handler := GeoIP(geoip.MaxMind(reader))(func(c *context.Context) error {
+		return c.String(200, RequestLocation(c).Country)
+	})
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.1:4711"
+	rec := httptest.NewRecorder()
+	if err := handler(context.New(rec, r, 1024)); err != nil || rec.Body.String() != "CH" {
+		t.Errorf("Expected MaxMind country, got %q (%v)", rec.Body.String(), err)
+	}
+
+	func() {
+		defer func() {
+			if _, ok := recover().(*kese.ConfigError); !ok {
+				t.Error("Expected ConfigError for an invalid country code")
+			}
+		}()
+		GeoIPWithConfig(GeoIPConfig{Resolver: resolver, DenyCountries: []string{"Germany"}})
+	}()
+}
+
+// fakeMaxMind decodes a fixed record like a MaxMind DB reader.
+type fakeMaxMind struct {
+	country string
+	asn     uint
+}
+
+func (f fakeMaxMind) Lookup(ip net.IP, result interface{}) error {
+	data, _ := json.Marshal(map[string]interface{}{
+		"Country": map[string]string{"ISOCode": f.country},
+		"ASN":     f.asn,
+	})
+	return json.Unmarshal(data, result)
+}
+
+func TestMetricsUploadTempFiles(t *testing.T) {
+	collector := metrics.New()
+	app := kese.New()
+	app.Use(MetricsWithConfig(MetricsConfig{Metrics: collector}))
+	app.POST("/upload", func(c *context.Context) error {
+		if err := c.Request.ParseMultipartForm(1024); err != nil {
+			return err
+		}
+		return c.NoContent()
+	})
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	part, _ := mw.CreateFormFile("file", "big.bin")
+	part.Write(bytes.Repeat([]byte("x"), 4096))
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/upload", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if !strings.Contains(w.Body.String(), "kese_upload_temp_files_total 1") ||
+		!strings.Contains(w.Body.String(), "kese_upload_temp_bytes_total 4096") {
+		t.Errorf("Expected upload temp file metrics, got:\n%s", w.Body.String())
+	}
+}
+
+func TestShadow(t *testing.T) {
+	mirrored := make(chan string, 1)
+	shadow := func(c *context.Context) error {
+		body, _ := c.BodyBytes()
+		mirrored <- c.Param("id") + ":" + string(body) + ":" + c.Header(ShadowHeader)
+		return c.String(500, "shadow response is discarded")
+	}
+
+	app := kese.New()
+	orders := app.Group("/orders", Shadow(shadow, 1))
+	orders.POST("/:id", func(c *context.Context) error {
+		body, _ := c.BodyBytes()
+		return c.String(200, "primary:"+string(body))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/orders/7", strings.NewReader("payload")))
+
+	if w.Code != 200 || w.Body.String() != "primary:payload" {
+		t.Errorf("Primary response changed: %d %q", w.Code, w.Body.String())
+	}
+
+	select {
+	case got := <-mirrored:
+		if got != "7:payload:1" {
+			t.Errorf("Unexpected mirrored request %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected request to be mirrored")
+	}
+}
+
+func TestShadowUpstream(t *testing.T) {
+	received := make(chan string, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Method + " " + r.URL.RequestURI()
+	}))
+	defer upstream.Close()
+
+	app := kese.New()
+	app.Use(ShadowWithConfig(ShadowConfig{Upstream: upstream.URL, SampleRate: 1}))
+	app.GET("/search", func(c *context.Context) error {
+		return c.String(200, "ok")
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/search?q=go", nil))
+
+	select {
+	case got := <-received:
+		if got != "GET /search?q=go" {
+			t.Errorf("Unexpected upstream request %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected request to be mirrored upstream")
+	}
+
+	defer func() {
+		if _, ok := recover().(*kese.ConfigError); !ok {
+			t.Error("Expected ConfigError panic without Handler or Upstream")
+		}
+	}()
+	ShadowWithConfig(ShadowConfig{SampleRate: 1})
+}
+
+func TestRewriteBody(t *testing.T) {
+	app := kese.New()
+	app.Use(InjectHTML(`<script src="/a.js"></script>`))
+	app.GET("/page", func(c *context.Context) error {
+		return c.HTML(200, "<html><body><h1>Hi</h1></body></html>")
+	})
+	app.GET("/data", func(c *context.Context) error {
+		return c.JSON(200, map[string]string{"ok": "</body>"})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/page", nil))
+	if w.Body.String() != `<html><body><h1>Hi</h1><script src="/a.js"></script></body></html>` {
+		t.Errorf("Unexpected HTML: %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/data", nil))
+	if strings.Contains(w.Body.String(), "script") {
+		t.Errorf("Expected JSON to be left alone, got %q", w.Body.String())
+	}
+}
+
+func TestStripJSONFields(t *testing.T) {
+	app := kese.New()
+	app.Use(StripJSONFields("cost"))
+	app.GET("/items", func(c *context.Context) error {
+		return c.JSON(200, []map[string]interface{}{{"id": 1, "cost": 9}, {"id": 2, "cost": 4}})
+	})
+	app.GET("/fail", func(c *context.Context) error {
+		return c.BadRequest("nope")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/items", nil))
+	if w.Body.String() != "[{\"id\":1},{\"id\":2}]\n" {
+		t.Errorf("Unexpected body: %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+	if w.Code != 400 {
+		t.Errorf("Expected 400 to pass through, got %d", w.Code)
+	}
+}
+
+func TestRequireContentType(t *testing.T) {
+	app := kese.New()
+	api := app.Group("/api", RequireContentType("application/json"))
+	api.POST("/items", func(c *context.Context) error {
+		return c.String(201, "created")
+	})
+	api.DELETE("/items", func(c *context.Context) error {
+		return c.NoContent()
+	})
+
+	tests := []struct {
+		contentType string
+		body        string
+		method      string
+		want        int
+	}{
+		{"application/json; charset=utf-8", `{}`, "POST", 201},
+		{"text/plain", "hi", "POST", 415},
+		{"", "hi", "POST", 415},
+		{"", "", "DELETE", 204},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/api/items", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != tt.want {
+			t.Errorf("%s %q: expected %d, got %d", tt.method, tt.contentType, tt.want, w.Code)
+		}
+		if tt.want == 415 && w.Header().Get("Content-Type") != kese.MIMEProblemJSON {
+			t.Errorf("Expected problem details, got %q", w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestAllowedHosts(t *testing.T) {
+	app := kese.New()
+	app.Use(AllowedHosts("api.example.com", "*.internal", "[::1]"))
+	app.GET("/users", func(c *context.Context) error {
+		return c.String(200, "ok")
+	})
+	app.GET("/healthz", func(c *context.Context) error {
+		return c.String(200, "healthy")
+	})
+
+	for _, tt := range []struct {
+		host, path string
+		want       int
+	}{
+		{"api.example.com", "/users", 200},
+		{"API.example.com.:8443", "/users", 200},
+		{"db.internal", "/users", 200},
+		{"a.b.internal:80", "/users", 200},
+		{"[::1]:8080", "/users", 200},
+		{"internal", "/users", 400},
+		{"evil.example.com", "/users", 400},
+		{"api.example.com.evil.com", "/users", 400},
+		{"10.0.0.7:8080", "/users", 400},
+		{"10.0.0.7:8080", "/healthz", 200},
+	} {
+		r := httptest.NewRequest("GET", tt.path, nil)
+		r.Host = tt.host
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("Host %q %s: expected %d, got %d", tt.host, tt.path, tt.want, w.Code)
+		}
+	}
+
+	for _, hosts := range [][]string{nil, {"https://api.example.com"}, {"api.example.com:443"}, {"api.*.com"}} {
+		func() {
+			defer func() {
+				if _, ok := recover().(*kese.ConfigError); !ok {
+					t.Errorf("Expected ConfigError for %q", hosts)
+				}
+			}()
+			AllowedHosts(hosts...)
+		}()
+	}
+}
+
+func TestETag(t *testing.T) {
+	app := kese.New()
+	app.Use(ETag())
+	app.GET("/todos", func(c *context.Context) error {
+		return c.JSON(200, []string{"a", "b"})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/todos", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || etag == "" || w.Body.Len() == 0 {
+		t.Fatalf("Expected 200 with ETag, got %d %q", w.Code, etag)
+	}
+
+	r := httptest.NewRequest("GET", "/todos", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != 304 || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("Expected empty 304, got %d %q", w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest("GET", "/todos", nil)
+	r.Header.Set("If-None-Match", `"stale"`)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.Len() == 0 {
+		t.Errorf("Expected 200 for stale ETag, got %d", w.Code)
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	keys := map[string]*APIKey{
+		"reader": {Owner: "svc-reports", Scopes: []string{"orders:read"}},
+		"writer": {Owner: "svc-admin", Scopes: []string{"orders:read", "orders:write"}},
+	}
+	lookup := func(c *context.Context, key string) (*APIKey, error) {
+		if key == "broken" {
+			return nil, errors.New("store unavailable")
+		}
+		if k, ok := keys[key]; ok {
+			return k, nil
+		}
+		return nil, ErrAPIKeyNotFound
+	}
+
+	app := kese.New()
+	api := app.Group("/api", APIKeyAuthWithConfig(APIKeyConfig{
+		Lookup:    lookup,
+		KeyLookup: "header:X-API-Key,query:api_key",
+		Scopes:    []string{"orders:read"},
+	}))
+	api.GET("/orders", func(c *context.Context) error {
+		return c.String(200, RequestAPIKey(c).Owner)
+	})
+	api.DELETE("/orders", RequireScopes("orders:write")(func(c *context.Context) error {
+		return c.NoContent()
+	}))
+
+	tests := []struct {
+		method, target, header string
+		want                   int
+	}{
+		{"GET", "/api/orders", "", 401},
+		{"GET", "/api/orders", "unknown", 401},
+		{"GET", "/api/orders", "broken", 500},
+		{"GET", "/api/orders", "reader", 200},
+		{"GET", "/api/orders?api_key=writer", "", 200},
+		{"DELETE", "/api/orders", "reader", 403},
+		{"DELETE", "/api/orders", "writer", 204},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.target, nil)
+		if tt.header != "" {
+			r.Header.Set("X-API-Key", tt.header)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != tt.want {
+			t.Errorf("%s %s key %q: expected %d, got %d", tt.method, tt.target, tt.header, tt.want, w.Code)
+		}
+		if (tt.want == 401 || tt.want == 403) && w.Header().Get("Content-Type") != kese.MIMEProblemJSON {
+			t.Errorf("Expected problem details for %d, got %q", tt.want, w.Header().Get("Content-Type"))
+		}
+		if tt.want == 403 && !strings.Contains(w.Body.String(), "orders:write") {
+			t.Errorf("Expected missing scope in body, got %s", w.Body.String())
+		}
+	}
+
+	func() {
+		defer func() {
+			if _, ok := recover().(*kese.ConfigError); !ok {
+				t.Error("Expected ConfigError for invalid KeyLookup")
+			}
+		}()
+		APIKeyAuthWithConfig(APIKeyConfig{Lookup: lookup, KeyLookup: "body:key"})
+	}()
+}
+
+func TestJWTAsymmetricAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for _, tt := range []struct {
+		alg string
+		key crypto.Signer
+	}{
+		{auth.RS256, rsaKey},
+		{auth.ES256, ecKey},
+		{auth.EdDSA, edKey},
+	} {
+		// Round-trip the keys through PEM as they would be loaded from files
+		privateDER, _ := x509.MarshalPKCS8PrivateKey(tt.key)
+		privateKey, err := auth.ParsePrivateKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.alg, err)
+		}
+		publicDER, _ := x509.MarshalPKIXPublicKey(tt.key.Public())
+		publicKey, err := auth.ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.alg, err)
+		}
+
+		app := kese.New()
+		app.Use(JWTWithConfig(JWTConfig{Algorithm: tt.alg, PublicKey: publicKey}))
+		app.GET("/me", func(c *context.Context) error {
+			return c.String(200, c.Get("userID").(string))
+		})
+
+		token, err := auth.GenerateTokenWithKey(auth.Claims{"userID": "42"}, tt.alg, privateKey, time.Hour)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.alg, err)
+		}
+		// An HS256 token using the public key as the secret must not be accepted
+		confused, _ := auth.GenerateTokenWithKey(auth.Claims{"userID": "42"}, auth.HS256, publicDER, time.Hour)
+		hs256, _ := auth.GenerateToken(auth.Claims{"userID": "42"}, "secret", time.Hour)
+
+		for _, c := range []struct {
+			token string
+			want  int
+		}{
+			{token, 200},
+			{confused, 401},
+			{hs256, 401},
+			{token[:len(token)-4] + "AAAA", 401},
+		} {
+			r := httptest.NewRequest("GET", "/me", nil)
+			r.Header.Set("Authorization", "Bearer "+c.token)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+			if w.Code != c.want {
+				t.Errorf("%s: expected %d, got %d", tt.alg, c.want, w.Code)
+			}
+		}
+	}
+
+	func() {
+		defer func() {
+			if _, ok := recover().(*kese.ConfigError); !ok {
+				t.Error("Expected ConfigError for RS256 without a public key")
+			}
+		}()
+		JWTWithConfig(JWTConfig{Algorithm: auth.RS256})
+	}()
+}
+
+func TestJWTRegisteredClaims(t *testing.T) {
+	config := DefaultJWTConfig("secret")
+	config.Validation = auth.ValidationOptions{
+		Issuer:   "https://id.example.com",
+		Audience: "orders-api",
+		Leeway:   time.Minute,
+	}
+	app := kese.New()
+	app.Use(JWTWithConfig(config))
+	app.GET("/me", func(c *context.Context) error {
+		claims := c.Get("jwt_claims").(auth.Claims)
+		return c.String(200, claims.Registered().Subject)
+	})
+
+	token := func(registered auth.RegisteredClaims, ttl time.Duration) string {
+		claims := auth.Claims{}
+		claims.SetRegistered(registered)
+		token, _ := auth.GenerateToken(claims, "secret", ttl)
+		return token
+	}
+	valid := auth.RegisteredClaims{
+		Issuer:   "https://id.example.com",
+		Subject:  "42",
+		Audience: []string{"billing-api", "orders-api"},
+	}
+	otherIssuer, otherAudience, notYet, skewed := valid, valid, valid, valid
+	otherIssuer.Issuer = "https://evil.example.com"
+	otherAudience.Audience = []string{"billing-api"}
+	notYet.NotBefore = time.Now().Add(time.Hour)
+	skewed.NotBefore = time.Now().Add(30 * time.Second)
+
+	for _, tt := range []struct {
+		name  string
+		token string
+		want  int
+		body  string
+	}{
+		{"valid", token(valid, time.Hour), 200, "42"},
+		{"expired within leeway", token(valid, -30*time.Second), 200, "42"},
+		{"expired", token(valid, -time.Hour), 401, "token has expired"},
+		{"issuer", token(otherIssuer, time.Hour), 401, "invalid token"},
+		{"audience", token(otherAudience, time.Hour), 401, "invalid token"},
+		{"not before", token(notYet, time.Hour), 401, "token is not valid yet"},
+		{"not before within leeway", token(skewed, time.Hour), 200, "42"},
+	} {
+		r := httptest.NewRequest("GET", "/me", nil)
+		r.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Code != tt.want || !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.name, tt.want, tt.body, w.Code, w.Body.String())
+		}
+	}
+
+	claims := auth.Claims{"aud": []interface{}{"a", "b"}, "exp": float64(1700000000)}
+	registered := claims.Registered()
+	if len(registered.Audience) != 2 || registered.ExpiresAt.Unix() != 1700000000 {
+		t.Errorf("Expected decoded registered claims, got %+v", registered)
+	}
+	if _, err := auth.ValidateTokenWithOptions(token(valid, time.Hour), auth.HS256, []byte("secret"),
+		auth.ValidationOptions{Audience: "admin-api"}); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Expected audience errors to wrap ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestHoneypotAndIPFilter(t *testing.T) {
+	var buf bytes.Buffer
+	blocklist := NewBlocklist()
+
+	app := kese.New()
+	app.Logger = logger.NewWithConfig(logger.InfoLevel, &buf)
+	app.Use(IPFilter(blocklist))
+	app.GET("/users", func(c *context.Context) error {
+		return c.String(200, "ok")
+	})
+	config := DefaultHoneypotConfig(blocklist)
+	config.Delay = 20 * time.Millisecond
+	HoneypotWithConfig(app, config)
+
+	request := func(path, ip string) int {
+		r := httptest.NewRequest("GET", path, nil)
+		r.RemoteAddr = ip + ":4711"
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := request("/users", "203.0.113.7"); code != 200 {
+		t.Fatalf("Expected 200 before the honeypot, got %d", code)
+	}
+	start := time.Now()
+	if code := request("/.env", "203.0.113.7"); code != 404 {
+		t.Errorf("Expected decoy to look like a missing route, got %d", code)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("Expected decoy response to be delayed")
+	}
+	if !strings.Contains(buf.String(), "Honeypot triggered") || !strings.Contains(buf.String(), "203.0.113.7") {
+		t.Errorf("Expected hit to be logged, got %q", buf.String())
+	}
+	if code := request("/users", "203.0.113.7"); code != 403 {
+		t.Errorf("Expected flagged IP to be blocked, got %d", code)
+	}
+	if code := request("/users", "198.51.100.1"); code != 200 {
+		t.Errorf("Expected other IPs to pass, got %d", code)
+	}
+	for _, route := range app.Routes() {
+		if route.Path == "/.env" {
+			t.Error("Expected decoys to be left out of Routes")
+		}
+	}
+
+	blocklist.Remove("203.0.113.7")
+	blocklist.Add("::ffff:198.51.100.1", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if code := request("/users", "203.0.113.7"); code != 200 {
+		t.Errorf("Expected removed IP to pass, got %d", code)
+	}
+	if blocklist.Contains("198.51.100.1") || len(blocklist.List()) != 0 {
+		t.Error("Expected block to expire")
+	}
+
+	// Expired entries are dropped as new IPs are added
+	for i := 0; i < 1000; i++ {
+		blocklist.Add(fmt.Sprintf("10.9.%d.%d", i/256, i%256), time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 100; i++ {
+		blocklist.Add(fmt.Sprintf("10.8.0.%d", i), time.Hour)
+	}
+	if n := len(blocklist.entries); n > 2*minBlocklistPrune {
+		t.Errorf("Expected expired entries to be pruned, got %d entries", n)
+	}
+
+	// Static allow and deny lists
+	admin := IPFilterWithConfig(IPFilterConfig{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.66"}})(func(c *context.Context) error {
+		return c.String(200, "admin")
+	})
+	for ip, want := range map[string]int{"10.1.2.3": 200, "10.0.0.66": 403, "203.0.113.7": 403} {
+		r := httptest.NewRequest("GET", "/admin", nil)
+		r.RemoteAddr = ip + ":4711"
+		w := httptest.NewRecorder()
+		c := context.New(w, r, 1024)
+		if err := admin(c); err != nil {
+			if want != 403 {
+				t.Errorf("%s: expected %d, got %v", ip, want, err)
+			}
+		} else if want != 200 {
+			t.Errorf("%s: expected %d, got 200", ip, want)
+		}
+	}
+
+	for _, config := range []IPFilterConfig{{}, {Deny: []string{"10.0.0.0/33"}}, {Allow: []string{"example.com"}}} {
+		func() {
+			defer func() {
+				if _, ok := recover().(*kese.ConfigError); !ok {
+					t.Errorf("Expected ConfigError for %+v", config)
+				}
+			}()
+			IPFilterWithConfig(config)
+		}()
+	}
+}
+
+func TestTokenPairRotation(t *testing.T) {
+	tokens := auth.TokenPairConfig{Key: []byte("secret"), Store: auth.NewMemoryRefreshStore()}
+	refresh := auth.RefreshHandler(tokens)
+
+	app := kese.New()
+	app.POST("/token/refresh", func(c *context.Context) error {
+		refresh.ServeHTTP(c.Writer, c.Request)
+		return nil
+	})
+	app.GET("/me", JWT("secret")(func(c *context.Context) error {
+		return c.String(200, c.Get("userID").(string))
+	}))
+
+	get := func(token string) int {
+		r := httptest.NewRequest("GET", "/me", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w.Code
+	}
+	rotate := func(token string) (*auth.TokenPair, int) {
+		r := httptest.NewRequest("POST", "/token/refresh", strings.NewReader(`{"refresh_token":"`+token+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		var pair auth.TokenPair
+		json.Unmarshal(w.Body.Bytes(), &pair)
+		return &pair, w.Code
+	}
+
+	first, err := auth.GenerateTokenPair(stdcontext.Background(), auth.Claims{"userID": "42"}, tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.TokenType != "Bearer" || first.ExpiresIn != 900 {
+		t.Errorf("Expected 15 minute bearer token, got %+v", first)
+	}
+	if code := get(first.AccessToken); code != 200 {
+		t.Errorf("Expected access token accepted, got %d", code)
+	}
+	if code := get(first.RefreshToken); code != 401 {
+		t.Errorf("Expected refresh token rejected as access token, got %d", code)
+	}
+
+	second, code := rotate(first.RefreshToken)
+	if code != 200 || second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("Expected rotated pair, got %d %+v", code, second)
+	}
+	if code := get(second.AccessToken); code != 200 {
+		t.Errorf("Expected rotated access token to keep the claims, got %d", code)
+	}
+
+	// Replaying the first refresh token revokes the whole family
+	if _, code := rotate(first.RefreshToken); code != 401 {
+		t.Errorf("Expected reused refresh token rejected, got %d", code)
+	}
+	if _, code := rotate(second.RefreshToken); code != 401 {
+		t.Errorf("Expected family revoked after reuse, got %d", code)
+	}
+	_, err = auth.RotateRefreshToken(stdcontext.Background(), first.RefreshToken, tokens)
+	if !errors.Is(err, auth.ErrRefreshTokenRevoked) {
+		t.Errorf("Expected ErrRefreshTokenRevoked, got %v", err)
+	}
+
+	// Reuse is reported as such while the family is alive
+	third, _ := auth.GenerateTokenPair(stdcontext.Background(), auth.Claims{"userID": "7"}, tokens)
+	auth.RotateRefreshToken(stdcontext.Background(), third.RefreshToken, tokens)
+	if _, err := auth.RotateRefreshToken(stdcontext.Background(), third.RefreshToken, tokens); !errors.Is(err, auth.ErrRefreshTokenReused) {
+		t.Errorf("Expected ErrRefreshTokenReused, got %v", err)
+	}
+
+	if _, code := rotate(second.AccessToken); code != 401 {
+		t.Errorf("Expected access token rejected as refresh token, got %d", code)
+	}
+	if _, code := rotate(""); code != 400 {
+		t.Errorf("Expected 400 without a refresh token, got %d", code)
+	}
+}
+
+func TestDebugCurl(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewWithConfig(logger.InfoLevel, &buf)
+
+	app := kese.New()
+	app.Use(DebugCurl(log))
+	app.POST("/login", func(c *context.Context) error {
+		var input map[string]interface{}
+		if err := c.BindJSON(&input); err != nil {
+			return err
+		}
+		return errors.New("user store unavailable")
+	})
+	app.GET("/ok", func(c *context.Context) error {
+		return c.String(200, "ok")
+	})
+
+	r := httptest.NewRequest("POST", "/login?token=abc&page=2", strings.NewReader(`{"user":"ann","password":"hunter2"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer secret-token")
+	r.Header.Set("X-Trace", "it's")
+	app.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected one JSON log entry, got %q", buf.String())
+	}
+	curl, _ := entry["curl"].(string)
+	for _, want := range []string{
+		"curl -X POST 'http://example.com/login?token=REDACTED&page=2'",
+		"-H 'Authorization: REDACTED'",
+		`-H 'X-Trace: it'\''s'`,
+		`--data-raw '{"password":"REDACTED","user":"ann"}'`,
+	} {
+		if !strings.Contains(curl, want) {
+			t.Errorf("Expected curl command to contain %q, got %s", want, curl)
+		}
+	}
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "secret-token") {
+		t.Errorf("Secrets leaked into log: %s", buf.String())
+	}
+	if entry["error"] != "user store unavailable" {
+		t.Errorf("Expected error to be logged, got %v", entry["error"])
+	}
+
+	buf.Reset()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	if buf.Len() != 0 {
+		t.Errorf("Successful requests should not be logged, got %s", buf.String())
+	}
+
+	// Names containing a secret word are redacted, in malformed queries too,
+	// and bodies that cannot be redacted are omitted
+	for _, test := range []struct {
+		target, contentType, body, want string
+	}{
+		{"/login?password=hunter2;a=1", "application/json", `{"new_password":"hunter2","currentPassword":"hunter2","user":"ann"}`,
+			`--data-raw '{"currentPassword":"REDACTED","new_password":"REDACTED","user":"ann"}'`},
+		{"/login?apiKey=hunter2&bad=%zz", "application/x-www-form-urlencoded", "user=ann&Old-Password=hunter2&x=%zz",
+			"--data-raw 'user=ann&Old-Password=REDACTED&x=%zz'"},
+		{"/login", "application/json", `{"password":"hunter2"`, "# request body omitted"},
+		{"/login", "multipart/form-data; boundary=x", "--x\r\npassword=hunter2", "# request body omitted"},
+		{"/login", "text/plain", "password=hunter2", "# request body omitted"},
+	} {
+		buf.Reset()
+		r := httptest.NewRequest("POST", test.target, strings.NewReader(test.body))
+		r.Header.Set("Content-Type", test.contentType)
+		r.Header.Set("X-Auth-Token", "hunter2")
+		app.ServeHTTP(httptest.NewRecorder(), r)
+		if strings.Contains(buf.String(), "hunter2") {
+			t.Errorf("%s %s: secrets leaked into log: %s", test.target, test.body, buf.String())
+		}
+		var entry map[string]interface{}
+		json.Unmarshal(buf.Bytes(), &entry)
+		if curl, _ := entry["curl"].(string); !strings.Contains(curl, test.want) {
+			t.Errorf("%s %s: expected %q in curl command, got %s", test.target, test.body, test.want, curl)
+		}
+	}
+}
+
+func TestOIDC(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	var issuer string
+	var keyFetches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/keys"})
+		case "/keys":
+			keyFetches++
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []auth.JWK{{
+				Kty: "EC",
+				Crv: "P-256",
+				X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+				Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+			}}})
+		}
+	}))
+	defer server.Close()
+	issuer = server.URL
+
+	app := kese.New()
+	app.Use(OIDC(issuer, "orders-api"))
+	app.GET("/orders", func(c *context.Context) error {
+		user := RequestIdentity(c)
+		if !user.HasScope("orders:read") {
+			return c.Forbidden("missing scope")
+		}
+		return c.String(200, user.Subject+" "+user.Email)
+	})
+
+	request := func(claims auth.Claims) int {
+		req := httptest.NewRequest("GET", "/orders", nil)
+		if claims != nil {
+			token, _ := auth.GenerateTokenWithKey(claims, auth.ES256, key, time.Minute)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	valid := auth.Claims{"iss": issuer, "aud": "orders-api", "sub": "u-1", "email": "ada@example.com", "scope": "openid orders:read"}
+	if code := request(valid); code != 200 {
+		t.Errorf("valid token: expected 200, got %d", code)
+	}
+	if code := request(auth.Claims{"iss": issuer, "aud": "orders-api", "sub": "u-1", "scope": "openid"}); code != 403 {
+		t.Errorf("missing scope: expected 403, got %d", code)
+	}
+	if code := request(auth.Claims{"iss": issuer, "aud": "billing-api", "sub": "u-1"}); code != 401 {
+		t.Errorf("foreign audience: expected 401, got %d", code)
+	}
+	if code := request(auth.Claims{"iss": "https://evil.example.com", "aud": "orders-api", "sub": "u-1"}); code != 401 {
+		t.Errorf("foreign issuer: expected 401, got %d", code)
+	}
+	if code := request(nil); code != 401 {
+		t.Errorf("missing token: expected 401, got %d", code)
+	}
+
+	// Tokens signed with another key are rejected, and the cached keys reused
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key, other = other, key
+	if code := request(valid); code != 401 {
+		t.Errorf("unknown key: expected 401, got %d", code)
+	}
+	key = other
+	if keyFetches != 1 {
+		t.Errorf("expected keys to be fetched once, got %d", keyFetches)
+	}
+
+	// Discovery failures are reported as 503
+	broken := kese.New()
+	broken.Use(OIDC(server.URL+"/missing", "orders-api"))
+	broken.GET("/", func(c *context.Context) error { return c.String(200, "OK") })
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer a.b.c")
+	w := httptest.NewRecorder()
+	broken.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("unreachable provider: expected 503, got %d", w.Code)
+	}
+
+	// Discovery is detached from the request that triggers it, and zero-value
+	// configs get the default clock skew tolerance
+	fresh := kese.New()
+	fresh.Use(OIDCWithConfig(OIDCConfig{Issuer: issuer, Audience: "orders-api"}))
+	fresh.GET("/", func(c *context.Context) error { return c.String(200, "OK") })
+	serveFresh := func(ctx stdcontext.Context, ttl time.Duration) int {
+		token, _ := auth.GenerateTokenWithKey(valid, auth.ES256, key, ttl)
+		req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		fresh.ServeHTTP(w, req)
+		return w.Code
+	}
+	gone, cancel := stdcontext.WithCancel(stdcontext.Background())
+	cancel()
+	serveFresh(gone, time.Minute)
+	if code := serveFresh(stdcontext.Background(), time.Minute); code != 200 {
+		t.Errorf("after a client disconnected during discovery: expected 200, got %d", code)
+	}
+	if code := serveFresh(stdcontext.Background(), -10*time.Second); code != 200 {
+		t.Errorf("token expired within the default leeway: expected 200, got %d", code)
+	}
+}
+
+func TestSignatureAuth(t *testing.T) {
+	app := kese.New()
+	app.Use(SignatureAuthWithConfig(SignatureAuthConfig{
+		Secret:  "shared-secret",
+		Secrets: map[string]string{"acme": "acme-secret"},
+	}))
+	app.POST("/webhooks/orders", func(c *context.Context) error {
+		body, _ := c.BodyBytes()
+		return c.String(200, SignatureKeyID(c)+":"+string(body))
+	})
+
+	signed := func(keyID, secret, body string) *http.Request {
+		req := httptest.NewRequest("POST", "/webhooks/orders?v=1", strings.NewReader(body))
+		if err := auth.SignRequest(req, keyID, []byte(secret)); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	req := signed("acme", "acme-secret", `{"id":1}`)
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"id":1}`))
+	if w := serve(req); w.Code != 200 || w.Body.String() != `acme:{"id":1}` {
+		t.Errorf("signed request: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve(replay); w.Code != 401 {
+		t.Errorf("replayed request: expected 401, got %d", w.Code)
+	}
+	if w := serve(signed("", "shared-secret", "{}")); w.Code != 200 {
+		t.Errorf("request signed with Secret: expected 200, got %d", w.Code)
+	}
+
+	// Tampered bodies, wrong secrets and unknown keys are rejected
+	tampered := signed("acme", "acme-secret", `{"id":2}`)
+	tampered.Body = io.NopCloser(strings.NewReader(`{"id":3}`))
+	if w := serve(tampered); w.Code != 401 {
+		t.Errorf("tampered body: expected 401, got %d", w.Code)
+	}
+	if w := serve(signed("acme", "shared-secret", "{}")); w.Code != 401 {
+		t.Errorf("wrong secret: expected 401, got %d", w.Code)
+	}
+	if w := serve(signed("globex", "acme-secret", "{}")); w.Code != 401 {
+		t.Errorf("unknown key: expected 401, got %d", w.Code)
+	}
+
+	stale := signed("acme", "acme-secret", "{}")
+	old := time.Now().Add(-10 * time.Minute).Unix()
+	stale.Header.Set(auth.SignatureTimestampHeader, strconv.FormatInt(old, 10))
+	stale.Header.Set(auth.SignatureHeader, auth.RequestSignature([]byte("acme-secret"), "POST", "/webhooks/orders?v=1", old, []byte("{}")))
+	if w := serve(stale); w.Code != 401 {
+		t.Errorf("stale signature: expected 401, got %d", w.Code)
+	}
+
+	// A failing replay store rejects requests instead of accepting replays
+	var logs bytes.Buffer
+	app = kese.New()
+	app.Use(SignatureAuthWithConfig(SignatureAuthConfig{
+		Secret: "shared-secret",
+		ReplayStore: ratelimit.NewRedisStore(ratelimit.RedisClientFunc(
+			func(ctx stdcontext.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+				return nil, errors.New("connection refused")
+			})),
+		Logger: logger.NewWithConfig(logger.InfoLevel, &logs),
+	}))
+	app.POST("/webhooks/orders", func(c *context.Context) error { return c.String(200, "OK") })
+	if w := serve(signed("", "shared-secret", "{}")); w.Code != 503 {
+		t.Errorf("failing replay store: expected 503, got %d", w.Code)
+	}
+	if !strings.Contains(logs.String(), "connection refused") {
+		t.Errorf("expected replay store error to be logged, got %q", logs.String())
+	}
+}
+
+func TestLoadShedding(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 4)
+	app := kese.New()
+	app.Use(LoadSheddingWithConfig(LoadSheddingConfig{MaxInFlight: 2, BulkMaxInFlight: 1}))
+	app.GET("/slow", func(c *context.Context) error {
+		started <- struct{}{}
+		<-release
+		return c.String(200, "OK")
+	})
+	app.GET("/export", func(c *context.Context) error { return c.String(200, "OK") }).Priority(kese.PriorityBulk)
+	app.GET("/orders", func(c *context.Context) error { return c.String(200, "OK") })
+	app.GET("/checkout", func(c *context.Context) error { return c.String(200, "OK") }).Priority(kese.PriorityCritical)
+	app.GET("/healthz", func(c *context.Context) error { return c.String(200, "OK") })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	// One request in flight: bulk is shed, normal still served
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { defer wg.Done(); serve("/slow") }()
+	<-started
+	if w := serve("/export"); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("bulk: expected 503 with Retry-After, got %d", w.Code)
+	}
+	if w := serve("/orders"); w.Code != 200 {
+		t.Errorf("normal: expected 200, got %d", w.Code)
+	}
+
+	// Two in flight: normal is shed too, critical and health checks are not
+	wg.Add(1)
+	go func() { defer wg.Done(); serve("/slow") }()
+	<-started
+	if w := serve("/orders"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("normal under load: expected 503, got %d", w.Code)
+	}
+	for _, path := range []string{"/checkout", "/healthz"} {
+		if w := serve(path); w.Code != 200 {
+			t.Errorf("%s under load: expected 200, got %d", path, w.Code)
+		}
+	}
+
+	close(release)
+	wg.Wait()
+	if w := serve("/export"); w.Code != 200 {
+		t.Errorf("bulk after load: expected 200, got %d", w.Code)
+	}
+}
+
+func TestSignedURL(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	app := kese.New()
+	app.Use(SignedURLWithConfig(SignedURLConfig{Key: key, Prefixes: []string{"/files/"}}))
+	app.GET("/files/:name", func(c *context.Context) error { return c.String(200, c.Param("name")) })
+	app.DELETE("/files/:name", func(c *context.Context) error { return c.String(200, "deleted") })
+	app.GET("/public", func(c *context.Context) error { return c.String(200, "OK") })
+
+	serveMethod := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+	serve := func(target string) *httptest.ResponseRecorder {
+		return serveMethod("GET", target)
+	}
+
+	if w := serve(kese.SignURL("/files/report.pdf", time.Minute, key)); w.Code != 200 || w.Body.String() != "report.pdf" {
+		t.Errorf("signed link: expected 200, got %d", w.Code)
+	}
+	if w := serve("/files/report.pdf"); w.Code != http.StatusForbidden {
+		t.Errorf("unsigned link: expected 403, got %d", w.Code)
+	}
+	if w := serve(kese.SignURL("/files/report.pdf", -time.Minute, key)); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "expired") {
+		t.Errorf("expired link: expected 403 expired, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("/public"); w.Code != 200 {
+		t.Errorf("path outside prefixes: expected 200, got %d", w.Code)
+	}
+
+	// Alternative spellings of protected paths must not bypass the check;
+	// they are rejected, or not routed at all
+	for _, target := range []string{"//files/x", "/./files/x", "/public/../files/x", "/files//x"} {
+		if w := serve(target); w.Code == 200 {
+			t.Errorf("%s: served without a signature: %s", target, w.Body.String())
+		}
+	}
+	if w := serve("//files/x"); w.Code != http.StatusForbidden {
+		t.Errorf("//files/x: expected 403, got %d", w.Code)
+	}
+
+	// A signed GET link can't be replayed with another method
+	if w := serveMethod("DELETE", kese.SignURL("/files/report.pdf", time.Minute, key)); w.Code != http.StatusForbidden {
+		t.Errorf("DELETE with a GET link: expected 403, got %d", w.Code)
+	}
+}
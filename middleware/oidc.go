@@ -0,0 +1,305 @@
+package middleware
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/auth"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// oidcIdentityKey is the context key of the request's *Identity.
+const oidcIdentityKey = "oidc_identity"
+
+// oidcRetryInterval is how long a failed discovery is remembered before the
+// provider is asked again.
+const oidcRetryInterval = 10 * time.Second
+
+// oidcDiscoveryTimeout bounds discovery. It runs detached from the request
+// that triggered it, so one client disconnecting doesn't fail discovery for
+// everyone.
+const oidcDiscoveryTimeout = 10 * time.Second
+
+// OIDCConfig holds configuration for the OIDC middleware.
+type OIDCConfig struct {
+	// Issuer is the identity provider's issuer URL, e.g.
+	// "https://login.example.com/realms/main". Its discovery document
+	// (/.well-known/openid-configuration) names the JWKS URL.
+	Issuer string
+
+	// Audience is the "aud" every token must contain: the client ID for ID
+	// tokens or the API identifier for access tokens
+	Audience string
+
+	// Leeway tolerates clock skew with the provider. Default: 30 seconds;
+	// a negative value allows no skew
+	Leeway time.Duration
+
+	// ContextKey is the key used to store claims in context.
+	// Default: "jwt_claims", like the JWT middleware
+	ContextKey string
+
+	// TokenLookup is where to look for the token, see JWTConfig.
+	// Default: "header:Authorization"
+	TokenLookup string
+
+	// HTTPClient fetches the discovery document and keys.
+	// Default: a client with a 10 second timeout
+	HTTPClient *http.Client
+
+	// SkipFunc allows skipping token validation for certain requests.
+	SkipFunc func(*context.Context) bool
+}
+
+// DefaultOIDCConfig returns the default OIDC configuration for tokens of
+// issuer intended for audience.
+func DefaultOIDCConfig(issuer, audience string) OIDCConfig {
+	return OIDCConfig{
+		Issuer:      issuer,
+		Audience:    audience,
+		Leeway:      30 * time.Second,
+		ContextKey:  "jwt_claims",
+		TokenLookup: "header:Authorization",
+	}
+}
+
+// Identity is the user of a request authenticated by the OIDC middleware,
+// read from the standard claims of their token.
+type Identity struct {
+	// Subject is the user's ID at the provider ("sub")
+	Subject string
+
+	Email         string
+	EmailVerified bool
+	Name          string
+
+	// Username is the "preferred_username" claim
+	Username string
+
+	// Scopes are the scopes granted to an access token ("scope" or "scp")
+	Scopes []string
+
+	// Claims are all claims of the token
+	Claims auth.Claims
+}
+
+// HasScope reports whether the token was granted scope.
+func (i *Identity) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// OIDC returns a middleware that validates bearer tokens issued by an OpenID
+// Connect provider, such as Keycloak, Auth0, Okta or Microsoft Entra ID. The
+// provider's signing keys are discovered from issuer on the first request and
+// refetched when it rotates them. Handlers read the caller with
+// RequestIdentity; the claims are also stored like the JWT middleware does.
+//
+// Example:
+//
+//	app.Use(middleware.OIDC("https://login.example.com/realms/main", "orders-api"))
+//
+//	// In handler
+//	user := middleware.RequestIdentity(c)
+//	if !user.HasScope("orders:write") {
+//	    return c.Forbidden("missing scope orders:write")
+//	}
+func OIDC(issuer, audience string) kese.MiddlewareFunc {
+	return OIDCWithConfig(DefaultOIDCConfig(issuer, audience))
+}
+
+// Validate reports configuration mistakes that would accept foreign tokens or
+// reject every token.
+func (config OIDCConfig) Validate() error {
+	if issuer, err := url.Parse(config.Issuer); err != nil || !issuer.IsAbs() || issuer.Host == "" {
+		return &kese.ConfigError{
+			Component: "oidc",
+			Problem:   fmt.Sprintf("Issuer %q is not an absolute URL", config.Issuer),
+			Fix:       "set Issuer to the provider's issuer, e.g. \"https://login.example.com/realms/main\"",
+		}
+	}
+	if config.Audience == "" {
+		return &kese.ConfigError{
+			Component: "oidc",
+			Problem:   "Audience is empty; tokens issued to any other application would be accepted",
+			Fix:       "set Audience to this API's identifier or client ID",
+		}
+	}
+	return nil
+}
+
+// OIDCWithConfig returns an OIDC middleware with custom configuration.
+// Missing, invalid and expired tokens are rejected with 401 Unauthorized;
+// while the provider cannot be reached, requests fail with 503 Service
+// Unavailable. Panics with a *kese.ConfigError if the configuration is invalid.
+func OIDCWithConfig(config OIDCConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = "jwt_claims"
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = "header:Authorization"
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	switch {
+	case config.Leeway == 0:
+		config.Leeway = 30 * time.Second
+	case config.Leeway < 0:
+		config.Leeway = 0
+	}
+	provider := &oidcProvider{issuer: strings.TrimSuffix(config.Issuer, "/"), client: config.HTTPClient}
+	opts := auth.ValidationOptions{
+		Audience:      config.Audience,
+		Leeway:        config.Leeway,
+		RequireExpiry: true,
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+
+			token, err := extractToken(c, config.TokenLookup)
+			if err != nil || token == "" {
+				return c.Unauthorized("missing or invalid token")
+			}
+
+			keys, issuer, err := provider.keySet()
+			if err != nil {
+				return kese.ErrServiceUnavailable.WithMessage("identity provider is unavailable").WithInternal(err)
+			}
+			opts := opts
+			opts.Issuer = issuer
+			claims, err := auth.ValidateTokenWithKeySet(c, token, keys, opts)
+			if err != nil {
+				switch {
+				case errors.Is(err, auth.ErrTokenExpired):
+					return c.Unauthorized("token has expired")
+				case errors.Is(err, auth.ErrInvalidToken):
+					return c.Unauthorized("invalid token")
+				}
+				return kese.ErrServiceUnavailable.WithMessage("identity provider is unavailable").WithInternal(err)
+			}
+
+			identity := identityFromClaims(claims)
+			c.Set(config.ContextKey, claims)
+			c.Set(oidcIdentityKey, identity)
+			c.Set("userID", identity.Subject)
+			if identity.Email != "" {
+				c.Set("email", identity.Email)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequestIdentity returns the user authenticated by the OIDC middleware, or
+// nil if the middleware did not run.
+func RequestIdentity(c *context.Context) *Identity {
+	identity, _ := c.Get(oidcIdentityKey).(*Identity)
+	return identity
+}
+
+// identityFromClaims reads the standard claims.
+func identityFromClaims(claims auth.Claims) *Identity {
+	identity := &Identity{Claims: claims}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+	identity.Name, _ = claims["name"].(string)
+	identity.Username, _ = claims["preferred_username"].(string)
+
+	// "scope" is space-separated (RFC 9068), some providers send a "scp" array
+	if scope, ok := claims["scope"].(string); ok {
+		identity.Scopes = strings.Fields(scope)
+	} else if scp, ok := claims["scp"].([]interface{}); ok {
+		for _, s := range scp {
+			if s, ok := s.(string); ok {
+				identity.Scopes = append(identity.Scopes, s)
+			}
+		}
+	}
+	return identity
+}
+
+// oidcProvider discovers the key set of an issuer once.
+type oidcProvider struct {
+	issuer string
+	client *http.Client
+
+	mu       sync.Mutex
+	keys     *auth.KeySet
+	iss      string
+	err      error
+	failedAt time.Time
+}
+
+// keySet returns the provider's key set and the exact "iss" of its tokens,
+// discovering them on first use. A failed discovery is retried after
+// oidcRetryInterval.
+func (p *oidcProvider) keySet() (*auth.KeySet, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil {
+		return p.keys, p.iss, nil
+	}
+	if p.err != nil && time.Since(p.failedAt) < oidcRetryInterval {
+		return nil, "", p.err
+	}
+
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	p.err = func() error {
+		ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), oidcDiscoveryTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.issuer+"/.well-known/openid-configuration", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("oidc discovery of %s: %s", p.issuer, resp.Status)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+			return fmt.Errorf("oidc discovery of %s: %w", p.issuer, err)
+		}
+		// The issuer must match exactly, or tokens of another tenant could pass
+		if strings.TrimSuffix(discovery.Issuer, "/") != p.issuer || discovery.JWKSURI == "" {
+			return fmt.Errorf("oidc discovery of %s: document is for issuer %q", p.issuer, discovery.Issuer)
+		}
+		return nil
+	}()
+	if p.err != nil {
+		p.failedAt = time.Now()
+		return nil, "", p.err
+	}
+
+	p.keys = auth.NewKeySet(discovery.JWKSURI, p.client)
+	p.iss = discovery.Issuer
+	return p.keys, p.iss, nil
+}
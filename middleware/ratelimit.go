@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/JedizLaPulga/kese"
@@ -16,6 +17,11 @@ type RateLimitConfig struct {
 	// Limit is the maximum number of requests allowed in the window
 	Limit int
 
+	// LimitFunc returns the limit for a request, overriding Limit when it
+	// returns a positive value (optional). Use it for limits that change at
+	// runtime, such as kese.RuntimeConfig.RateLimit.
+	LimitFunc func(*context.Context) int
+
 	// Window is the time window for rate limiting
 	Window time.Duration
 
@@ -38,6 +44,49 @@ type RateLimitConfig struct {
 	// ErrorHandler calls this function if the rate limit store fails.
 	// Default: log error to stderr
 	ErrorHandler func(error)
+
+	// Thresholds are soft limits registered with OnThreshold
+	Thresholds []RateLimitThreshold
+}
+
+// RateLimitThreshold is a soft limit at a percentage of the rate limit.
+type RateLimitThreshold struct {
+	// Percent of the limit, between 1 and 100
+	Percent int
+
+	// Func is called with the key and its request count when the key reaches
+	// Percent, once per window
+	Func func(key string, count int)
+}
+
+// OnThreshold registers fn to be called when a key has used pct percent of
+// its limit, once per window, e.g. to alert before clients start receiving
+// 429s. From then until the window resets, responses carry an
+// X-RateLimit-Warning header so well-behaved clients can slow down.
+// Panics with a *kese.ConfigError if pct is not between 1 and 100.
+//
+// Example:
+//
+//	config := middleware.DefaultRateLimitConfig(1000, time.Hour)
+//	config.OnThreshold(80, func(key string, count int) {
+//	    log.Warn("Client close to its rate limit", "key", key, "count", count)
+//	})
+//	app.Use(middleware.RateLimitWithConfig(config))
+func (config *RateLimitConfig) OnThreshold(pct int, fn func(key string, count int)) {
+	if pct < 1 || pct > 100 {
+		panic(&kese.ConfigError{
+			Component: "rate-limit",
+			Problem:   fmt.Sprintf("threshold %d%% is not a percentage of the limit", pct),
+			Fix:       "use a percentage between 1 and 100, e.g. 80",
+		})
+	}
+	config.Thresholds = append(config.Thresholds, RateLimitThreshold{Percent: pct, Func: fn})
+}
+
+// thresholdCount returns the request count at which pct percent of limit is
+// used, rounded up.
+func thresholdCount(limit, pct int) int {
+	return (limit*pct + 99) / 100
 }
 
 // DefaultRateLimitConfig returns the default rate limit configuration.
@@ -65,6 +114,9 @@ func DefaultRateLimitConfig(limit int, window time.Duration) RateLimitConfig {
 }
 
 // RateLimit returns a middleware that limits requests based on IP address.
+// Responses carry the RateLimit-Limit, -Remaining, -Reset and -Policy headers
+// of the IETF draft and their X-RateLimit- equivalents, with resets taken from
+// the store; rejected requests get 429 with Retry-After.
 //
 // limit: Maximum number of requests allowed
 // window: Time window for the limit (e.g., time.Minute, time.Hour)
@@ -83,6 +135,9 @@ func RateLimit(limit int, window time.Duration) kese.MiddlewareFunc {
 //
 //	app.Use(middleware.RateLimitWithConfig(RateLimitConfig{
 //	    Limit: 1000,
+//	    LimitFunc: func(c *context.Context) int {
+//	        return app.RuntimeConfig().RateLimit // reloadable, falls back to Limit
+//	    },
 //	    Window: time.Hour,
 //	    KeyFunc: func(c *context.Context) string {
 //	        // Rate limit per user instead of IP
@@ -112,20 +167,51 @@ func RateLimitWithConfig(config RateLimitConfig) kese.MiddlewareFunc {
 			key := config.KeyFunc(c)
 
 			// Increment counter
-			count, err := config.Store.Increment(key, config.Window)
+			count, reset, err := config.Store.Increment(key, config.Window)
 			if err != nil {
 				// On error, allow the request but log it
 				config.ErrorHandler(err)
 				return next(c)
 			}
 
-			// Set rate limit headers
-			c.SetHeader("X-RateLimit-Limit", fmt.Sprintf("%d", config.Limit))
-			c.SetHeader("X-RateLimit-Remaining", fmt.Sprintf("%d", max(0, config.Limit-count)))
+			limit := config.Limit
+			if config.LimitFunc != nil {
+				if dynamic := config.LimitFunc(c); dynamic > 0 {
+					limit = dynamic
+				}
+			}
+
+			// Set rate limit headers: the IETF draft fields, with the reset
+			// in seconds, and the X- variants, with the reset as a Unix time
+			remaining := max(0, limit-count)
+			resetIn := secondsUntil(reset)
+			c.SetHeader("RateLimit-Limit", strconv.Itoa(limit))
+			c.SetHeader("RateLimit-Remaining", strconv.Itoa(remaining))
+			c.SetHeader("RateLimit-Reset", strconv.Itoa(resetIn))
+			c.SetHeader("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit, int(config.Window.Seconds())))
+			c.SetHeader("X-RateLimit-Limit", strconv.Itoa(limit))
+			c.SetHeader("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			c.SetHeader("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+			// Warn about the highest threshold reached; counts grow by one,
+			// so equality fires each callback once per window
+			warned := 0
+			for _, threshold := range config.Thresholds {
+				at := thresholdCount(limit, threshold.Percent)
+				if count == at && threshold.Func != nil {
+					threshold.Func(key, count)
+				}
+				if count >= at && threshold.Percent > warned {
+					warned = threshold.Percent
+				}
+			}
+			if warned > 0 && count <= limit {
+				c.SetHeader("X-RateLimit-Warning", fmt.Sprintf("%d%% of the rate limit used", warned))
+			}
 
 			// Check if limit exceeded
-			if count > config.Limit {
-				c.SetHeader("Retry-After", fmt.Sprintf("%d", int(config.Window.Seconds())))
+			if count > limit {
+				c.SetHeader("Retry-After", strconv.Itoa(resetIn))
 				return c.JSON(429, map[string]string{
 					"error": config.Message,
 				})
@@ -136,6 +222,12 @@ func RateLimitWithConfig(config RateLimitConfig) kese.MiddlewareFunc {
 	}
 }
 
+// secondsUntil returns the whole seconds until t, rounded up so clients
+// waiting that long find the window reset, and never negative.
+func secondsUntil(t time.Time) int {
+	return max(0, int((time.Until(t)+time.Second-1)/time.Second))
+}
+
 // max returns the maximum of two integers
 func max(a, b int) int {
 	if a > b {
@@ -1,13 +1,16 @@
 package middleware
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/JedizLaPulga/kese"
 	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/logger"
 )
 
 func TestRateLimit(t *testing.T) {
@@ -53,6 +56,45 @@ func TestRateLimit(t *testing.T) {
 	}
 }
 
+func TestRateLimitResetHeaders(t *testing.T) {
+	app := kese.New()
+	app.Use(RateLimit(1, time.Minute))
+	app.GET("/test", func(c *context.Context) error {
+		return c.String(200, "OK")
+	})
+
+	w1 := httptest.NewRecorder()
+	app.ServeHTTP(w1, httptest.NewRequest("GET", "/test", nil))
+	time.Sleep(1100 * time.Millisecond)
+	w2 := httptest.NewRecorder()
+	app.ServeHTTP(w2, httptest.NewRequest("GET", "/test", nil))
+
+	h := w1.Header()
+	if h.Get("RateLimit-Limit") != "1" || h.Get("RateLimit-Remaining") != "0" || h.Get("RateLimit-Reset") != "60" {
+		t.Errorf("Unexpected RateLimit headers: limit %q, remaining %q, reset %q",
+			h.Get("RateLimit-Limit"), h.Get("RateLimit-Remaining"), h.Get("RateLimit-Reset"))
+	}
+	if policy := h.Get("RateLimit-Policy"); policy != "1;w=60" {
+		t.Errorf("Expected RateLimit-Policy 1;w=60, got %q", policy)
+	}
+
+	// The reset is the end of the window started by the first request,
+	// not a full window from now
+	if w2.Code != 429 {
+		t.Fatalf("Expected 429, got %d", w2.Code)
+	}
+	if retry := w2.Header().Get("Retry-After"); retry != "59" {
+		t.Errorf("Expected Retry-After 59, got %q", retry)
+	}
+	if w2.Header().Get("X-RateLimit-Reset") != h.Get("X-RateLimit-Reset") {
+		t.Errorf("Expected the same reset time, got %q and %q", h.Get("X-RateLimit-Reset"), w2.Header().Get("X-RateLimit-Reset"))
+	}
+	reset, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if until := time.Until(time.Unix(reset, 0)); until < 57*time.Second || until > time.Minute {
+		t.Errorf("Expected X-RateLimit-Reset about a minute ahead, got %v", until)
+	}
+}
+
 func TestRateLimitSecurity(t *testing.T) {
 	// Verify that X-Forwarded-For is ignored by default
 	app := kese.New()
@@ -136,3 +178,72 @@ func TestRateLimitCustomKey(t *testing.T) {
 		t.Error("Req 3 failed")
 	}
 }
+
+func TestRateLimitReloadedLimit(t *testing.T) {
+	app := kese.New()
+	app.Logger = logger.NewWithConfig(logger.ErrorLevel, &bytes.Buffer{})
+
+	config := DefaultRateLimitConfig(1, time.Minute)
+	config.LimitFunc = func(c *context.Context) int {
+		return app.RuntimeConfig().RateLimit
+	}
+	app.Use(RateLimitWithConfig(config))
+	app.GET("/test", func(c *context.Context) error {
+		return c.String(200, "OK")
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		return w
+	}
+
+	if serve().Code != 200 || serve().Code != 429 {
+		t.Fatal("Expected configured limit of 1 before any reload")
+	}
+
+	app.ApplyConfig(kese.RuntimeConfig{RateLimit: 3}, "test")
+	w := serve()
+	if w.Code != 200 || w.Header().Get("X-RateLimit-Limit") != "3" {
+		t.Errorf("Expected reloaded limit of 3, got %d (limit %q)", w.Code, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestRateLimitThreshold(t *testing.T) {
+	config := DefaultRateLimitConfig(10, time.Minute)
+	var warnings []int
+	config.OnThreshold(80, func(key string, count int) {
+		warnings = append(warnings, count)
+	})
+
+	app := kese.New()
+	app.Use(RateLimitWithConfig(config))
+	app.GET("/test", func(c *context.Context) error {
+		return c.String(200, "OK")
+	})
+
+	for i := 1; i <= 11; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+		warning := w.Header().Get("X-RateLimit-Warning")
+		switch {
+		case i < 8 && warning != "":
+			t.Errorf("request %d: unexpected warning %q", i, warning)
+		case i >= 8 && i <= 10 && warning != "80% of the rate limit used":
+			t.Errorf("request %d: expected warning, got %q", i, warning)
+		case i == 11 && w.Code != 429:
+			t.Errorf("request %d: expected 429, got %d", i, w.Code)
+		}
+	}
+	if len(warnings) != 1 || warnings[0] != 8 {
+		t.Errorf("expected one callback at count 8, got %v", warnings)
+	}
+
+	defer func() {
+		if _, ok := recover().(*kese.ConfigError); !ok {
+			t.Error("expected a ConfigError for a threshold above 100%")
+		}
+	}()
+	config.OnThreshold(120, nil)
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// RewriteFunc transforms a buffered response before it is sent.
+// It may change resp.Body, the status and the headers.
+type RewriteFunc func(c *context.Context, resp *context.BufferedResponse) error
+
+// RewriteConfig holds configuration for response rewriting middleware.
+type RewriteConfig struct {
+	// Rewrite transforms each captured response
+	Rewrite RewriteFunc
+
+	// ContentTypes restricts rewriting to responses whose Content-Type starts
+	// with one of these values (e.g. "text/html"). Empty means all responses.
+	ContentTypes []string
+
+	// SkipFunc allows skipping rewriting for certain requests
+	SkipFunc func(*context.Context) bool
+}
+
+// RewriteBody returns a middleware that buffers responses and passes them to
+// rewrite before they are sent. Already-encoded (e.g. gzipped) responses are
+// sent unchanged, so register it after Compress.
+//
+// Example:
+//
+//	app.Use(middleware.RewriteBody(func(c *context.Context, resp *context.BufferedResponse) error {
+//	    out := bytes.ReplaceAll(resp.Body.Bytes(), []byte("http://cdn."), []byte("https://cdn."))
+//	    resp.Body.Reset()
+//	    resp.Body.Write(out)
+//	    return nil
+//	}))
+func RewriteBody(rewrite RewriteFunc) kese.MiddlewareFunc {
+	return RewriteBodyWithConfig(RewriteConfig{Rewrite: rewrite})
+}
+
+// RewriteBodyWithConfig returns response rewriting middleware with custom configuration.
+func RewriteBodyWithConfig(config RewriteConfig) kese.MiddlewareFunc {
+	if config.Rewrite == nil {
+		panic(&kese.ConfigError{
+			Component: "rewrite",
+			Problem:   "Rewrite is nil",
+			Fix:       "set Rewrite to the function transforming the response",
+		})
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+
+			resp, err := c.BufferResponse(next)
+			if err != nil || !resp.Written() {
+				// Keep whatever the handler wrote before failing
+				resp.Commit()
+				return err
+			}
+
+			if resp.Header().Get("Content-Encoding") == "" && matchesContentType(resp.Header().Get("Content-Type"), config.ContentTypes) {
+				if err := config.Rewrite(c, resp); err != nil {
+					return err
+				}
+			}
+			return resp.Commit()
+		}
+	}
+}
+
+// InjectHTML returns a middleware that inserts snippet before the closing
+// </body> tag of HTML responses (or appends it if there is none), for
+// analytics tags, live-reload scripts or environment banners.
+//
+// Example:
+//
+//	app.Use(middleware.InjectHTML(`<script src="/analytics.js" defer></script>`))
+func InjectHTML(snippet string) kese.MiddlewareFunc {
+	return RewriteBodyWithConfig(RewriteConfig{
+		ContentTypes: []string{"text/html"},
+		Rewrite: func(c *context.Context, resp *context.BufferedResponse) error {
+			body := resp.Body.Bytes()
+			index := bytes.LastIndex(bytes.ToLower(body), []byte("</body>"))
+			if index < 0 {
+				resp.Body.WriteString(snippet)
+				return nil
+			}
+
+			out := make([]byte, 0, len(body)+len(snippet))
+			out = append(out, body[:index]...)
+			out = append(out, snippet...)
+			out = append(out, body[index:]...)
+			resp.Body.Reset()
+			resp.Body.Write(out)
+			return nil
+		},
+	})
+}
+
+// StripJSONFields returns a middleware that removes the named top-level
+// fields from JSON object responses, and from each object of JSON array
+// responses, for example to hide internal fields from public clients.
+//
+// Example:
+//
+//	public := app.Group("/public", middleware.StripJSONFields("internal_id", "cost"))
+func StripJSONFields(fields ...string) kese.MiddlewareFunc {
+	return RewriteBodyWithConfig(RewriteConfig{
+		ContentTypes: []string{"application/json"},
+		Rewrite: func(c *context.Context, resp *context.BufferedResponse) error {
+			var document interface{}
+			if err := json.Unmarshal(resp.Body.Bytes(), &document); err != nil {
+				// Not valid JSON; send it unchanged
+				return nil
+			}
+
+			switch value := document.(type) {
+			case map[string]interface{}:
+				deleteFields(value, fields)
+			case []interface{}:
+				for _, item := range value {
+					if object, ok := item.(map[string]interface{}); ok {
+						deleteFields(object, fields)
+					}
+				}
+			default:
+				return nil
+			}
+
+			resp.Body.Reset()
+			return json.NewEncoder(resp.Body).Encode(document)
+		},
+	})
+}
+
+// deleteFields removes the given keys from object.
+func deleteFields(object map[string]interface{}, fields []string) {
+	for _, field := range fields {
+		delete(object, field)
+	}
+}
+
+// matchesContentType reports whether contentType starts with one of prefixes.
+// An empty prefix list matches every content type.
+func matchesContentType(contentType string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"github.com/JedizLaPulga/kese"
 	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/security"
 )
 
 // SecurityConfig holds configuration for security headers middleware.
@@ -25,8 +29,44 @@ type SecurityConfig struct {
 	// ContentSecurityPolicy sets CSP header. Empty string disables CSP.
 	ContentSecurityPolicy string
 
+	// CSP is the Content-Security-Policy built with security.CSP(), used
+	// instead of ContentSecurityPolicy. Its ReportTo endpoint is announced
+	// in the Reporting-Endpoints header.
+	CSP *security.Policy
+
+	// CSPNonce generates a random nonce for each request, replacing "{nonce}"
+	// in the policy (see security.Nonce), so inline scripts carrying it may
+	// run while injected ones may not. Handlers read it with c.CSPNonce() or
+	// c.Get("csp_nonce"), templates with {{cspNonce}}. Default: false
+	CSPNonce bool
+
 	// ReferrerPolicy controls Referer header. Default: "strict-origin-when-cross-origin"
 	ReferrerPolicy string
+
+	// PermissionsPolicy restricts browser features such as the camera or
+	// geolocation, e.g. "camera=(), microphone=(), geolocation=()".
+	// Empty string disables it.
+	PermissionsPolicy string
+
+	// CrossOriginOpenerPolicy isolates the page from cross-origin windows it
+	// opens or was opened by. Use "same-origin-allow-popups" for OAuth or
+	// payment popups. Default: "same-origin"
+	CrossOriginOpenerPolicy string
+
+	// CrossOriginEmbedderPolicy, set to "require-corp" together with COOP
+	// "same-origin", enables cross-origin isolation (needed for
+	// SharedArrayBuffer); every embedded resource must then opt in.
+	// Empty string disables it.
+	CrossOriginEmbedderPolicy string
+
+	// CrossOriginResourcePolicy controls which sites may embed responses.
+	// Use "cross-origin" for public assets such as images served to other
+	// sites. Default: "same-origin"
+	CrossOriginResourcePolicy string
+
+	// XPermittedCrossDomainPolicies controls Flash and PDF readers loading
+	// data from the site. Default: "none"
+	XPermittedCrossDomainPolicies string
 }
 
 // DefaultSecurityConfig returns the default security configuration.
@@ -38,6 +78,10 @@ func DefaultSecurityConfig() SecurityConfig {
 		HSTSIncludeSubdomains: false,
 		ContentSecurityPolicy: "",
 		ReferrerPolicy:        "strict-origin-when-cross-origin",
+
+		CrossOriginOpenerPolicy:       "same-origin",
+		CrossOriginResourcePolicy:     "same-origin",
+		XPermittedCrossDomainPolicies: "none",
 	}
 }
 
@@ -49,6 +93,9 @@ func DefaultSecurityConfig() SecurityConfig {
 //   - X-Content-Type-Options: nosniff
 //   - Strict-Transport-Security: max-age=31536000
 //   - Referrer-Policy: strict-origin-when-cross-origin
+//   - Cross-Origin-Opener-Policy: same-origin
+//   - Cross-Origin-Resource-Policy: same-origin
+//   - X-Permitted-Cross-Domain-Policies: none
 //
 // Note: X-XSS-Protection header is NOT included as it's deprecated
 // and can introduce vulnerabilities. Modern browsers ignore it.
@@ -60,16 +107,67 @@ func SecureHeaders() kese.MiddlewareFunc {
 	return SecureHeadersWithConfig(DefaultSecurityConfig())
 }
 
+// Validate reports configuration mistakes such as a CSP nonce that would
+// never be sent.
+func (config SecurityConfig) Validate() error {
+	if config.CSP != nil && config.ContentSecurityPolicy != "" {
+		return &kese.ConfigError{
+			Component: "security-headers",
+			Problem:   "both CSP and ContentSecurityPolicy are set; only one policy can be sent",
+			Fix:       "move the policy into the CSP builder, or drop CSP",
+		}
+	}
+	hasPlaceholder := strings.Contains(config.contentSecurityPolicy(), "{nonce}")
+	if config.CSPNonce && !hasPlaceholder {
+		return &kese.ConfigError{
+			Component: "security-headers",
+			Problem:   "CSPNonce is set but ContentSecurityPolicy has no {nonce} placeholder; browsers would block every inline script",
+			Fix:       "add 'nonce-{nonce}' to script-src, e.g. \"script-src 'self' 'nonce-{nonce}'\"",
+		}
+	}
+	if hasPlaceholder && !config.CSPNonce {
+		return &kese.ConfigError{
+			Component: "security-headers",
+			Problem:   "ContentSecurityPolicy has a {nonce} placeholder but CSPNonce is not set",
+			Fix:       "set CSPNonce to true",
+		}
+	}
+	return nil
+}
+
+// contentSecurityPolicy returns the configured policy as a header value.
+func (config SecurityConfig) contentSecurityPolicy() string {
+	if config.CSP != nil {
+		return config.CSP.String()
+	}
+	return config.ContentSecurityPolicy
+}
+
 // SecureHeadersWithConfig returns a middleware with custom security configuration.
+// Panics with a *kese.ConfigError if the configuration is invalid.
 //
 // Example:
 //
 //	app.Use(middleware.SecureHeadersWithConfig(SecurityConfig{
 //	    XFrameOptions: "SAMEORIGIN",
 //	    HSTSMaxAge: 63072000, // 2 years
-//	    ContentSecurityPolicy: "default-src 'self'",
+//	    ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'nonce-{nonce}'",
+//	    CSPNonce: true,
+//	    PermissionsPolicy: "camera=(), microphone=(), geolocation=()",
 //	}))
+//
+//	// In templates rendered by kese.TemplateEngine:
+//	<script nonce="{{cspNonce}}">...</script>
 func SecureHeadersWithConfig(config SecurityConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	policy := config.contentSecurityPolicy()
+	var reportingEndpoints string
+	if config.CSP != nil {
+		reportingEndpoints = config.CSP.ReportingEndpoints()
+	}
+
 	return func(next kese.HandlerFunc) kese.HandlerFunc {
 		return func(c *context.Context) error {
 			// X-Frame-Options: prevents clickjacking
@@ -92,8 +190,20 @@ func SecureHeadersWithConfig(config SecurityConfig) kese.MiddlewareFunc {
 			}
 
 			// Content-Security-Policy: prevents XSS and injection attacks
-			if config.ContentSecurityPolicy != "" {
-				c.SetHeader("Content-Security-Policy", config.ContentSecurityPolicy)
+			if policy != "" {
+				csp := policy
+				if config.CSPNonce {
+					nonce, err := generateNonce()
+					if err != nil {
+						return err
+					}
+					c.Set("csp_nonce", nonce)
+					csp = strings.ReplaceAll(csp, "{nonce}", nonce)
+				}
+				c.SetHeader("Content-Security-Policy", csp)
+			}
+			if reportingEndpoints != "" {
+				c.SetHeader("Reporting-Endpoints", reportingEndpoints)
 			}
 
 			// Referrer-Policy: controls referrer information
@@ -101,7 +211,37 @@ func SecureHeadersWithConfig(config SecurityConfig) kese.MiddlewareFunc {
 				c.SetHeader("Referrer-Policy", config.ReferrerPolicy)
 			}
 
+			// Permissions-Policy: restricts browser features
+			if config.PermissionsPolicy != "" {
+				c.SetHeader("Permissions-Policy", config.PermissionsPolicy)
+			}
+
+			// Cross-origin isolation policies
+			if config.CrossOriginOpenerPolicy != "" {
+				c.SetHeader("Cross-Origin-Opener-Policy", config.CrossOriginOpenerPolicy)
+			}
+			if config.CrossOriginEmbedderPolicy != "" {
+				c.SetHeader("Cross-Origin-Embedder-Policy", config.CrossOriginEmbedderPolicy)
+			}
+			if config.CrossOriginResourcePolicy != "" {
+				c.SetHeader("Cross-Origin-Resource-Policy", config.CrossOriginResourcePolicy)
+			}
+
+			// X-Permitted-Cross-Domain-Policies: blocks Flash and PDF data access
+			if config.XPermittedCrossDomainPolicies != "" {
+				c.SetHeader("X-Permitted-Cross-Domain-Policies", config.XPermittedCrossDomainPolicies)
+			}
+
 			return next(c)
 		}
 	}
 }
+
+// generateNonce returns a random base64 CSP nonce.
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"bytes"
+	stdcontext "context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/router"
+)
+
+// ShadowHeader is set on mirrored requests so the shadow path can skip side
+// effects such as sending emails or charging cards.
+const ShadowHeader = "X-Shadow-Request"
+
+// ShadowConfig holds configuration for shadow traffic middleware.
+type ShadowConfig struct {
+	// Handler receives mirrored requests in-process. Either Handler or Upstream must be set.
+	Handler kese.HandlerFunc
+
+	// Upstream is the base URL mirrored requests are sent to (e.g. "http://search-v2:8080").
+	// The request path and query are appended to it.
+	Upstream string
+
+	// Client sends requests to Upstream (default: http.DefaultClient)
+	Client *http.Client
+
+	// SampleRate is the fraction of requests mirrored, between 0 and 1
+	SampleRate float64
+
+	// Timeout bounds each mirrored request (default: 5s)
+	Timeout time.Duration
+
+	// MaxInFlight caps concurrent mirrored requests; requests beyond it are
+	// not mirrored so a slow shadow path cannot exhaust resources (default: 100)
+	MaxInFlight int
+
+	// OnError is called with failures of the shadow path, including panics (optional)
+	OnError func(method, path string, err error)
+
+	// SkipFunc allows skipping mirroring for certain requests
+	SkipFunc func(*context.Context) bool
+}
+
+// DefaultShadowConfig returns default shadow configuration mirroring
+// sampleRate of the requests to handler.
+func DefaultShadowConfig(handler kese.HandlerFunc, sampleRate float64) ShadowConfig {
+	return ShadowConfig{
+		Handler:     handler,
+		SampleRate:  sampleRate,
+		Timeout:     5 * time.Second,
+		MaxInFlight: 100,
+	}
+}
+
+// Validate reports configuration mistakes.
+func (config ShadowConfig) Validate() error {
+	if config.Handler == nil && config.Upstream == "" {
+		return &kese.ConfigError{
+			Component: "shadow",
+			Problem:   "neither Handler nor Upstream is set",
+			Fix:       "set Handler to mirror in-process or Upstream to mirror to another service",
+		}
+	}
+	if config.Upstream != "" {
+		if u, err := url.Parse(config.Upstream); err != nil || u.Scheme == "" || u.Host == "" {
+			return &kese.ConfigError{
+				Component: "shadow",
+				Problem:   fmt.Sprintf("Upstream %q is not an absolute URL", config.Upstream),
+				Fix:       "use a URL such as \"http://search-v2:8080\"",
+			}
+		}
+	}
+	return nil
+}
+
+// Shadow returns a middleware that mirrors a fraction of requests to a
+// secondary handler in the background (dark launch). The mirrored response is
+// discarded and never affects the client, so new code paths can be validated
+// against production traffic. Mirrored requests carry the X-Shadow-Request header.
+//
+// Example:
+//
+//	// Exercise the new search implementation with 5% of real traffic
+//	search := app.Group("/search", middleware.Shadow(searchV2, 0.05))
+//	search.GET("", searchV1)
+func Shadow(handler kese.HandlerFunc, sampleRate float64) kese.MiddlewareFunc {
+	return ShadowWithConfig(DefaultShadowConfig(handler, sampleRate))
+}
+
+// ShadowWithConfig returns shadow traffic middleware with custom configuration.
+// Panics with a *kese.ConfigError if the configuration is invalid.
+//
+// Example:
+//
+//	app.Use(middleware.ShadowWithConfig(middleware.ShadowConfig{
+//	    Upstream:   "http://orders-v2.internal:8080",
+//	    SampleRate: 0.1,
+//	    OnError: func(method, path string, err error) {
+//	        log.Warn("shadow failed", "path", path, "error", err)
+//	    },
+//	}))
+func ShadowWithConfig(config ShadowConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.MaxInFlight <= 0 {
+		config.MaxInFlight = 100
+	}
+	upstream := strings.TrimSuffix(config.Upstream, "/")
+	inFlight := make(chan struct{}, config.MaxInFlight)
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SampleRate <= 0 || rand.Float64() >= config.SampleRate {
+				return next(c)
+			}
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+
+			// Drop the mirror rather than queue it when the shadow path is saturated
+			select {
+			case inFlight <- struct{}{}:
+			default:
+				return next(c)
+			}
+
+			// Buffer the body so both paths can read it
+			body, err := c.BodyBytes()
+			if err != nil {
+				<-inFlight
+				return next(c)
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+			mirror := c.Request.Clone(stdcontext.WithoutCancel(c.Request.Context()))
+			mirror.Header.Set(ShadowHeader, "1")
+			params := append(router.Params(nil), c.Params()...)
+			route := c.RoutePath()
+			maxBodySize := c.MaxBodySize
+
+			err = next(c)
+
+			go func() {
+				defer func() { <-inFlight }()
+
+				var shadowErr error
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						shadowErr = fmt.Errorf("shadow panic: %v", recovered)
+					}
+					if shadowErr != nil && config.OnError != nil {
+						config.OnError(mirror.Method, mirror.URL.Path, shadowErr)
+					}
+				}()
+
+				ctx, cancel := stdcontext.WithTimeout(mirror.Context(), config.Timeout)
+				defer cancel()
+				mirror = mirror.WithContext(ctx)
+				mirror.Body = io.NopCloser(bytes.NewReader(body))
+
+				if config.Handler != nil {
+					shadowErr = serveShadow(config.Handler, mirror, params, route, maxBodySize)
+					return
+				}
+				shadowErr = forwardShadow(config.Client, upstream, mirror, body)
+			}()
+
+			return err
+		}
+	}
+}
+
+// serveShadow runs handler on the mirrored request, discarding the response.
+func serveShadow(handler kese.HandlerFunc, r *http.Request, params router.Params, route string, maxBodySize int64) error {
+	ctx := context.New(discardResponseWriter{header: make(http.Header)}, r, maxBodySize)
+	ctx.SetParams(params)
+	ctx.SetRoute(route, nil)
+	return handler(ctx)
+}
+
+// forwardShadow sends the mirrored request to upstream, discarding the response.
+func forwardShadow(client *http.Client, upstream string, r *http.Request, body []byte) error {
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, upstream+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = r.Header.Clone()
+	req.Header.Del("Connection")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
+// discardResponseWriter is an http.ResponseWriter that drops everything written to it.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w discardResponseWriter) Header() http.Header         { return w.header }
+func (w discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w discardResponseWriter) WriteHeader(int)             {}
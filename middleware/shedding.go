@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// latencySampleMaxAge is how long the latency average stays meaningful
+// without new samples, so shedding stops once the traffic it shed is gone.
+const latencySampleMaxAge = time.Second
+
+// LoadSheddingConfig holds configuration for the LoadShedding middleware.
+type LoadSheddingConfig struct {
+	// MaxInFlight is the number of concurrent requests from which normal
+	// priority requests are rejected. Critical requests are never rejected.
+	// Default: 1000
+	MaxInFlight int
+
+	// BulkMaxInFlight is the number of concurrent requests from which bulk
+	// priority requests are rejected. Default: half of MaxInFlight
+	BulkMaxInFlight int
+
+	// LatencySLO is the latency objective: while the moving average latency
+	// of recent requests exceeds it, bulk requests are rejected, and above
+	// twice it normal requests too. 0 only limits concurrency.
+	//
+	// Independently, requests whose context deadline ends before the
+	// average request completes are rejected unless critical.
+	LatencySLO time.Duration
+
+	// RetryAfter is sent to rejected clients in the Retry-After header.
+	// Default: 1 second
+	RetryAfter time.Duration
+
+	// SkipPaths are treated as critical, for health checks registered as
+	// regular routes.
+	// Default: "/livez", "/readyz", "/startupz", "/health", "/healthz"
+	SkipPaths []string
+
+	// OnShed is called for each rejected request, e.g. to count them by priority
+	OnShed func(c *context.Context, priority kese.Priority)
+}
+
+// DefaultLoadSheddingConfig returns the default load shedding configuration
+// for maxInFlight concurrent requests.
+func DefaultLoadSheddingConfig(maxInFlight int) LoadSheddingConfig {
+	return LoadSheddingConfig{
+		MaxInFlight:     maxInFlight,
+		BulkMaxInFlight: maxInFlight / 2,
+		RetryAfter:      time.Second,
+		SkipPaths:       []string{"/livez", "/readyz", "/startupz", "/health", "/healthz"},
+	}
+}
+
+// LoadShedding returns a middleware that rejects requests with 503 Service
+// Unavailable when the server is overloaded, lowest priority first: bulk
+// routes once maxInFlight/2 requests are in flight, normal routes at
+// maxInFlight, critical routes never. Rejecting early keeps latency low for
+// the requests that are served instead of slowing everything down. Routes
+// declare their priority with Route.Priority.
+//
+// Example:
+//
+//	app.Use(middleware.LoadShedding(500))
+//
+//	app.POST("/checkout", checkout).Priority(kese.PriorityCritical)
+//	app.GET("/reports/export", exportReport).Priority(kese.PriorityBulk)
+func LoadShedding(maxInFlight int) kese.MiddlewareFunc {
+	return LoadSheddingWithConfig(DefaultLoadSheddingConfig(maxInFlight))
+}
+
+// Validate reports configuration mistakes such as negative limits.
+func (config LoadSheddingConfig) Validate() error {
+	if config.MaxInFlight < 0 || config.BulkMaxInFlight < 0 {
+		return &kese.ConfigError{
+			Component: "load-shedding",
+			Problem:   "MaxInFlight and BulkMaxInFlight must not be negative",
+			Fix:       "set MaxInFlight to the number of concurrent requests the server handles well, e.g. 500",
+		}
+	}
+	if config.MaxInFlight > 0 && config.BulkMaxInFlight > config.MaxInFlight {
+		return &kese.ConfigError{
+			Component: "load-shedding",
+			Problem:   "BulkMaxInFlight is above MaxInFlight; bulk requests would outlast normal ones",
+			Fix:       "set BulkMaxInFlight below MaxInFlight, or 0 for half of it",
+		}
+	}
+	return nil
+}
+
+// LoadSheddingWithConfig returns a LoadShedding middleware with custom
+// configuration. Panics with a *kese.ConfigError if the configuration is
+// invalid.
+//
+// Example:
+//
+//	app.Use(middleware.LoadSheddingWithConfig(middleware.LoadSheddingConfig{
+//	    MaxInFlight: 500,
+//	    LatencySLO:  300 * time.Millisecond,
+//	}))
+func LoadSheddingWithConfig(config LoadSheddingConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.MaxInFlight == 0 {
+		config.MaxInFlight = 1000
+	}
+	if config.BulkMaxInFlight == 0 {
+		config.BulkMaxInFlight = config.MaxInFlight / 2
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	if config.SkipPaths == nil {
+		config.SkipPaths = DefaultLoadSheddingConfig(0).SkipPaths
+	}
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[path] = true
+	}
+	retryAfter := strconv.Itoa(int((config.RetryAfter + time.Second - 1) / time.Second))
+	shedder := &loadShedder{slo: config.LatencySLO}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			priority, ok := c.RouteMeta(kese.PriorityKey).(kese.Priority)
+			if !ok {
+				priority = kese.PriorityNormal
+			}
+			if skip[c.Path()] {
+				priority = kese.PriorityCritical
+			}
+
+			inFlight := int(shedder.inFlight.Add(1))
+			defer shedder.inFlight.Add(-1)
+
+			if shedder.shed(c, priority, inFlight, config) {
+				if config.OnShed != nil {
+					config.OnShed(c, priority)
+				}
+				c.SetHeader("Retry-After", retryAfter)
+				return kese.NewProblem(http.StatusServiceUnavailable, "the server is overloaded, please retry later")
+			}
+
+			start := time.Now()
+			err := next(c)
+			shedder.observe(time.Since(start))
+			return err
+		}
+	}
+}
+
+// loadShedder tracks the load of the server.
+type loadShedder struct {
+	slo      time.Duration
+	inFlight atomic.Int64
+
+	// latency is the moving average latency in nanoseconds, updated at
+	// lastSample (Unix nanoseconds)
+	latency    atomic.Int64
+	lastSample atomic.Int64
+}
+
+// shed reports whether a request of priority must be rejected with
+// inFlight requests running.
+func (s *loadShedder) shed(c *context.Context, priority kese.Priority, inFlight int, config LoadSheddingConfig) bool {
+	if priority >= kese.PriorityCritical {
+		return false
+	}
+
+	// A request whose deadline ends before a typical request completes
+	// would time out anyway
+	latency := s.averageLatency()
+	if deadline, ok := c.Deadline(); ok && time.Until(deadline) < latency {
+		return true
+	}
+
+	if priority < kese.PriorityNormal {
+		return inFlight > config.BulkMaxInFlight || (s.slo > 0 && latency > s.slo)
+	}
+	return inFlight > config.MaxInFlight || (s.slo > 0 && latency > 2*s.slo)
+}
+
+// averageLatency returns the moving average latency of recent requests, or
+// 0 if no request completed lately.
+func (s *loadShedder) averageLatency() time.Duration {
+	if time.Since(time.Unix(0, s.lastSample.Load())) > latencySampleMaxAge {
+		return 0
+	}
+	return time.Duration(s.latency.Load())
+}
+
+// observe adds a request latency to the moving average.
+func (s *loadShedder) observe(latency time.Duration) {
+	for {
+		old := s.latency.Load()
+		// Exponentially weighted, each sample counting for a tenth
+		updated := old + (int64(latency)-old)/10
+		if old == 0 {
+			updated = int64(latency)
+		}
+		if s.latency.CompareAndSwap(old, updated) {
+			break
+		}
+	}
+	s.lastSample.Store(time.Now().UnixNano())
+}
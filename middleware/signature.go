@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"strconv"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/auth"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/logger"
+	"github.com/JedizLaPulga/kese/ratelimit"
+)
+
+// signatureKeyIDKey is the context key of the ID of the secret that signed
+// the request.
+const signatureKeyIDKey = "signature_key_id"
+
+// SignatureAuthConfig holds configuration for the SignatureAuth middleware.
+type SignatureAuthConfig struct {
+	// Secret verifies requests without an X-Signature-Key header
+	Secret string
+
+	// Secrets maps the key IDs sent in X-Signature-Key to secrets, so each
+	// partner has its own secret and secrets can be rotated by adding a key
+	Secrets map[string]string
+
+	// Tolerance is how far the signing time may be from the server's clock.
+	// Older requests are rejected as replays. Default: 5 minutes
+	Tolerance time.Duration
+
+	// ReplayStore remembers signatures so each signed request is accepted
+	// once. Use a shared store when running several instances. While it
+	// fails, requests are rejected with 503 Service Unavailable, since a
+	// replay could not be detected. Default: in-memory store
+	ReplayStore ratelimit.Store
+
+	// Logger logs ReplayStore errors. Default: a logger writing to stdout
+	Logger *logger.Logger
+
+	// SkipFunc allows skipping signature verification for certain requests.
+	SkipFunc func(*context.Context) bool
+}
+
+// DefaultSignatureAuthConfig returns the default configuration for requests
+// signed with secret.
+func DefaultSignatureAuthConfig(secret string) SignatureAuthConfig {
+	return SignatureAuthConfig{
+		Secret:      secret,
+		Tolerance:   5 * time.Minute,
+		ReplayStore: ratelimit.NewMemoryStore(),
+	}
+}
+
+// SignatureAuth returns a middleware that authenticates requests signed with
+// an HMAC-SHA256 over the method, path, timestamp and body, as sent by
+// webhooks and partner integrations. Clients sign requests with
+// auth.SignRequest. Requests signed more than 5 minutes ago, and repeated
+// requests, are rejected.
+//
+// Example:
+//
+//	webhooks := app.Group("/webhooks", middleware.SignatureAuth(os.Getenv("WEBHOOK_SECRET")))
+//	webhooks.POST("/orders", handleOrderWebhook)
+func SignatureAuth(secret string) kese.MiddlewareFunc {
+	return SignatureAuthWithConfig(DefaultSignatureAuthConfig(secret))
+}
+
+// Validate reports configuration mistakes that would reject every request or
+// accept forged ones.
+func (config SignatureAuthConfig) Validate() error {
+	if config.Secret == "" && len(config.Secrets) == 0 {
+		return &kese.ConfigError{
+			Component: "signature-auth",
+			Problem:   "no Secret or Secrets; no request could be verified",
+			Fix:       "set Secret, or Secrets for a secret per key ID",
+		}
+	}
+	for id, secret := range config.Secrets {
+		if secret == "" {
+			return &kese.ConfigError{
+				Component: "signature-auth",
+				Problem:   "the secret of key " + strconv.Quote(id) + " is empty",
+				Fix:       "set a random secret of at least 32 bytes for each key",
+			}
+		}
+	}
+	return nil
+}
+
+// SignatureAuthWithConfig returns a SignatureAuth middleware with custom
+// configuration. Requests with a missing, invalid, stale or repeated
+// signature are rejected with 401 Unauthorized, and all signed requests
+// with 503 Service Unavailable while the ReplayStore fails. Panics with a
+// *kese.ConfigError if the configuration is invalid.
+//
+// Example:
+//
+//	app.Use(middleware.SignatureAuthWithConfig(middleware.SignatureAuthConfig{
+//	    Secrets: map[string]string{
+//	        "acme-2024": os.Getenv("ACME_SECRET"),
+//	        "globex":    os.Getenv("GLOBEX_SECRET"),
+//	    },
+//	}))
+//
+//	// In handler
+//	partner := middleware.SignatureKeyID(c)
+func SignatureAuthWithConfig(config SignatureAuthConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = 5 * time.Minute
+	}
+	if config.ReplayStore == nil {
+		config.ReplayStore = ratelimit.NewMemoryStore()
+	}
+	if config.Logger == nil {
+		config.Logger = logger.New()
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+
+			keyID := c.Header(auth.SignatureKeyHeader)
+			secret := config.Secret
+			if keyID != "" {
+				secret = config.Secrets[keyID]
+			}
+			signature := c.Header(auth.SignatureHeader)
+			if secret == "" || signature == "" {
+				return c.Unauthorized("missing or invalid signature")
+			}
+
+			timestamp, err := strconv.ParseInt(c.Header(auth.SignatureTimestampHeader), 10, 64)
+			if err != nil {
+				return c.Unauthorized("missing or invalid signature")
+			}
+			if age := time.Since(time.Unix(timestamp, 0)); age > config.Tolerance || age < -config.Tolerance {
+				return c.Unauthorized("signature has expired")
+			}
+
+			body, err := c.BodyBytes()
+			if err != nil {
+				return err
+			}
+			expected := auth.RequestSignature([]byte(secret), c.Method(), c.Request.URL.RequestURI(), timestamp, body)
+			if !hmac.Equal([]byte(signature), []byte(expected)) {
+				return c.Unauthorized("missing or invalid signature")
+			}
+
+			// Signatures older than the tolerance are rejected above, so they
+			// need to be remembered for twice the tolerance only
+			count, _, err := config.ReplayStore.Increment("signature:"+signature, 2*config.Tolerance)
+			if err != nil {
+				config.Logger.Error("Signature replay store error", "error", err.Error())
+				return kese.ErrServiceUnavailable.WithMessage("signature could not be verified").WithInternal(err)
+			}
+			if count > 1 {
+				return c.Unauthorized("signature was already used")
+			}
+
+			c.Set(signatureKeyIDKey, keyID)
+			return next(c)
+		}
+	}
+}
+
+// SignatureKeyID returns the key ID of the secret that signed the request,
+// or "" for requests signed with SignatureAuthConfig.Secret.
+func SignatureKeyID(c *context.Context) string {
+	keyID, _ := c.Get(signatureKeyIDKey).(string)
+	return keyID
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// SignedURLConfig holds configuration for the SignedURL middleware.
+type SignedURLConfig struct {
+	// Key verifies the signatures created with kese.SignURL
+	Key []byte
+
+	// Prefixes limits the check to paths under these prefixes, e.g. the
+	// prefix of a Static directory. Empty checks every request.
+	Prefixes []string
+
+	// SkipFunc allows skipping the check for certain requests, e.g. for
+	// users who are logged in.
+	SkipFunc func(*context.Context) bool
+}
+
+// SignedURL returns a middleware that only lets through requests for URLs
+// signed with key by kese.SignURL, so apps can hand out temporary links to
+// files without requiring a login. Expired and tampered links are rejected
+// with 403 Forbidden, rendered as problem details.
+//
+// Example:
+//
+//	app.Use(middleware.SignedURLWithConfig(middleware.SignedURLConfig{
+//	    Key:      urlKey,
+//	    Prefixes: []string{"/files/"},
+//	}))
+//	app.Static("/files", "./uploads")
+//
+//	// In an authenticated handler
+//	return c.JSON(200, map[string]string{
+//	    "download": kese.SignURL("/files/"+name, 15*time.Minute, urlKey),
+//	})
+func SignedURL(key []byte) kese.MiddlewareFunc {
+	return SignedURLWithConfig(SignedURLConfig{Key: key})
+}
+
+// Validate reports configuration mistakes such as a missing or short key.
+func (config SignedURLConfig) Validate() error {
+	if len(config.Key) < 16 {
+		return &kese.ConfigError{
+			Component: "signed-url",
+			Problem:   "Key is shorter than 16 bytes; signatures could be guessed",
+			Fix:       "use a random key of at least 32 bytes, e.g. from crypto/rand",
+		}
+	}
+	return nil
+}
+
+// SignedURLWithConfig returns a SignedURL middleware with custom
+// configuration. Panics with a *kese.ConfigError if the configuration is
+// invalid.
+func SignedURLWithConfig(config SignedURLConfig) kese.MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			if config.SkipFunc != nil && config.SkipFunc(c) {
+				return next(c)
+			}
+			if len(config.Prefixes) > 0 && !hasAnyPrefix(c.Path(), config.Prefixes) {
+				return next(c)
+			}
+
+			if err := kese.VerifySignedURL(c.Method(), c.Request.URL, config.Key); err != nil {
+				if errors.Is(err, kese.ErrURLExpired) {
+					return kese.NewProblem(http.StatusForbidden, "this link has expired")
+				}
+				return kese.NewProblem(http.StatusForbidden, "this link is invalid")
+			}
+			return next(c)
+		}
+	}
+}
+
+// hasAnyPrefix reports whether the cleaned path starts with one of prefixes.
+// The router ignores empty segments, so "//files/a" reaches the same route
+// as "/files/a" and must be matched the same way.
+func hasAnyPrefix(p string, prefixes []string) bool {
+	p = cleanPath(p)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanPath collapses empty, "." and ".." segments of p, keeping a trailing
+// slash so "/files/" still matches the prefix "/files/".
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
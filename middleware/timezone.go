@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/auth"
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/i18n"
+)
+
+// TimezoneConfig holds configuration for time zone resolution middleware.
+// Sources are tried in order: query parameter, header, profile claim.
+// Values must be IANA names such as "Europe/Paris"; invalid names are ignored.
+type TimezoneConfig struct {
+	// QueryParam is the query parameter carrying the zone. Default: "tz"
+	QueryParam string
+
+	// Header is the request header carrying the zone. Default: "Time-Zone"
+	Header string
+
+	// ClaimsKey is the context key of the JWT claims (see JWTConfig.ContextKey).
+	// Default: "jwt_claims"
+	ClaimsKey string
+
+	// Claim is the claim holding the user's profile time zone. Default: "tz"
+	Claim string
+
+	// Default is used when no source provides a valid zone. Default: time.UTC
+	Default *time.Location
+
+	// ContextKey is the key the resolved *time.Location is stored under with c.Set.
+	// Default: "timezone"
+	ContextKey string
+}
+
+// DefaultTimezoneConfig returns default time zone configuration.
+func DefaultTimezoneConfig() TimezoneConfig {
+	return TimezoneConfig{
+		QueryParam: "tz",
+		Header:     "Time-Zone",
+		ClaimsKey:  "jwt_claims",
+		Claim:      "tz",
+		Default:    time.UTC,
+		ContextKey: "timezone",
+	}
+}
+
+// Timezone returns a middleware that resolves the client time zone into the
+// request context. Render times with i18n.InZone(c, t); templates rendered by
+// kese.TemplateEngine convert times automatically.
+//
+// Example:
+//
+//	app.Use(middleware.Timezone())
+//
+//	// JavaScript clients send their zone:
+//	// fetch(url, {headers: {"Time-Zone": Intl.DateTimeFormat().resolvedOptions().timeZone}})
+func Timezone() kese.MiddlewareFunc {
+	return TimezoneWithConfig(DefaultTimezoneConfig())
+}
+
+// TimezoneWithConfig returns time zone middleware with custom configuration.
+func TimezoneWithConfig(config TimezoneConfig) kese.MiddlewareFunc {
+	if config.Default == nil {
+		config.Default = time.UTC
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = "timezone"
+	}
+
+	return func(next kese.HandlerFunc) kese.HandlerFunc {
+		return func(c *context.Context) error {
+			loc := resolveTimezone(c, config)
+			c.Set(config.ContextKey, loc)
+			c.SetContext(i18n.WithLocation(c.Context(), loc))
+			return next(c)
+		}
+	}
+}
+
+// resolveTimezone returns the first valid zone from the configured sources.
+func resolveTimezone(c *context.Context, config TimezoneConfig) *time.Location {
+	var candidates []string
+	if config.QueryParam != "" {
+		candidates = append(candidates, c.Query(config.QueryParam))
+	}
+	if config.Header != "" {
+		candidates = append(candidates, c.Header(config.Header))
+	}
+	if config.ClaimsKey != "" && config.Claim != "" {
+		if claims, ok := c.Get(config.ClaimsKey).(auth.Claims); ok {
+			if name, ok := claims[config.Claim].(string); ok {
+				candidates = append(candidates, name)
+			}
+		}
+	}
+
+	for _, name := range candidates {
+		// time.LoadLocation treats "" as UTC and "Local" as the server zone; skip both
+		if name == "" || name == "Local" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return config.Default
+}
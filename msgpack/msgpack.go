@@ -0,0 +1,622 @@
+// Package msgpack implements MessagePack (https://msgpack.org) encoding and
+// decoding without external dependencies.
+//
+// Values are mapped like encoding/json: structs become maps keyed by the
+// `msgpack` tag, falling back to the `json` tag and then the field name, and
+// decoding into an interface{} yields map[string]interface{}, []interface{},
+// int64, uint64, float64, string, []byte, bool or nil. time.Time values are
+// encoded as RFC 3339 strings.
+//
+// Example:
+//
+//	data, err := msgpack.Marshal(user)
+//	...
+//	var decoded User
+//	err = msgpack.Unmarshal(data, &decoded)
+package msgpack
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// ErrShortData is returned by Unmarshal when the input ends in the middle of a value.
+var ErrShortData = errors.New("msgpack: unexpected end of data")
+
+// Marshal returns the MessagePack encoding of v.
+func Marshal(v interface{}) ([]byte, error) {
+	e := &encoder{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Unmarshal decodes MessagePack data into the value pointed to by v.
+func Unmarshal(data []byte, v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("msgpack: Unmarshal target must be a non-nil pointer, got %T", v)
+	}
+
+	d := &decoder{data: data}
+	if err := d.decode(target.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("msgpack: %d trailing bytes after value", len(d.data)-d.pos)
+	}
+	return nil
+}
+
+// encoder appends the MessagePack encoding of values to buf.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+
+	if v.Type() == timeType {
+		e.writeString(v.Interface().(time.Time).Format(time.RFC3339Nano))
+		return nil
+	}
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface && v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.writeString(string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.writeUint(v.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.writeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.writeBinary(v.Bytes())
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		return e.encodeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		e.writeLength(v.Len(), 0x80, 0xde, 0xdf)
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := e.encode(iter.Key()); err != nil {
+				return err
+			}
+			if err := e.encode(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := structFields(v.Type())
+		present := fields[:0:0]
+		for _, field := range fields {
+			if !field.omitEmpty || !v.FieldByIndex(field.index).IsZero() {
+				present = append(present, field)
+			}
+		}
+		e.writeLength(len(present), 0x80, 0xde, 0xdf)
+		for _, field := range present {
+			e.writeString(field.name)
+			if err := e.encode(v.FieldByIndex(field.index)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *encoder) encodeArray(v reflect.Value) error {
+	e.writeLength(v.Len(), 0x90, 0xdc, 0xdd)
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLength writes a map or array header using the fix, 16-bit or 32-bit form.
+func (e *encoder) writeLength(n int, fix, code16, code32 byte) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, code16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, code32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *encoder) writeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) writeBinary(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, b...)
+}
+
+// writeInt writes a signed integer in the smallest representation.
+func (e *encoder) writeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.writeUint(uint64(n))
+	case n >= -32:
+		e.buf = append(e.buf, byte(n))
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(n))
+	}
+}
+
+// writeUint writes an unsigned integer in the smallest representation.
+func (e *encoder) writeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf = append(e.buf, byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+	}
+}
+
+// field is an encodable struct field.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields lists the encodable fields of a struct type. Embedded structs
+// without a tag contribute their fields at the same level.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("msgpack")
+		if tag == "" {
+			tag = sf.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			for _, inner := range structFields(sf.Type) {
+				inner.index = append([]int{i}, inner.index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: []int{i}, omitEmpty: strings.Contains(options, "omitempty")})
+	}
+	return fields
+}
+
+// decoder reads MessagePack values from data.
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next n bytes of input.
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, ErrShortData
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// readValue decodes the next value into its natural Go representation.
+func (d *decoder) readValue() (interface{}, error) {
+	head, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	code := head[0]
+
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return d.readMap(int(code & 0x0f))
+	case code&0xf0 == 0x90:
+		return d.readArray(int(code & 0x0f))
+	case code&0xe0 == 0xa0:
+		return d.readString(int(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLength(code - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xca:
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 0xcb:
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := d.next(1 << (code - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return readUint(b), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		b, err := d.next(1 << (code - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+		return readInt(b), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLength(code - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.readString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readLength(code - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.readArray(n)
+	case 0xde, 0xdf:
+		n, err := d.readLength(code - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.readMap(n)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type code 0x%02x", code)
+}
+
+// readLength reads a 1, 2 or 4 byte big-endian length (size 0, 1 or 2).
+func (d *decoder) readLength(size byte) (int, error) {
+	b, err := d.next(1 << size)
+	if err != nil {
+		return 0, err
+	}
+	return int(readUint(b)), nil
+}
+
+func (d *decoder) readString(n int) (string, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) readArray(n int) ([]interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, ErrShortData
+	}
+	items := make([]interface{}, n)
+	for i := range items {
+		item, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func (d *decoder) readMap(n int) (map[string]interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, ErrShortData
+	}
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.readValue()
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(key)] = value
+	}
+	return m, nil
+}
+
+func readUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
+
+func readInt(b []byte) int64 {
+	n := readUint(b)
+	shift := 64 - 8*uint(len(b))
+	return int64(n<<shift) >> shift
+}
+
+// decode reads the next value and assigns it to target.
+func (d *decoder) decode(target reflect.Value) error {
+	value, err := d.readValue()
+	if err != nil {
+		return err
+	}
+	return assign(target, value)
+}
+
+// assign converts a decoded value into target, following encoding/json's rules.
+func assign(target reflect.Value, value interface{}) error {
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return assign(target.Elem(), value)
+	}
+
+	if target.Kind() == reflect.Interface && target.NumMethod() == 0 {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if s, ok := value.(string); ok {
+		if target.Type() == timeType {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("msgpack: %w", err)
+			}
+			target.Set(reflect.ValueOf(t))
+			return nil
+		}
+		if target.CanAddr() && target.Addr().Type().Implements(textUnmarshalerType) {
+			return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+	}
+
+	mismatch := fmt.Errorf("msgpack: cannot decode %T into %s", value, target.Type())
+
+	switch target.Kind() {
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch
+		}
+		target.SetBool(b)
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return mismatch
+		}
+		target.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch number := value.(type) {
+		case int64:
+			n = number
+		case uint64:
+			if number > math.MaxInt64 {
+				return mismatch
+			}
+			n = int64(number)
+		default:
+			return mismatch
+		}
+		if target.OverflowInt(n) {
+			return fmt.Errorf("msgpack: %d overflows %s", n, target.Type())
+		}
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch number := value.(type) {
+		case uint64:
+			n = number
+		case int64:
+			if number < 0 {
+				return mismatch
+			}
+			n = uint64(number)
+		default:
+			return mismatch
+		}
+		if target.OverflowUint(n) {
+			return fmt.Errorf("msgpack: %d overflows %s", n, target.Type())
+		}
+		target.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch number := value.(type) {
+		case float64:
+			target.SetFloat(number)
+		case int64:
+			target.SetFloat(float64(number))
+		case uint64:
+			target.SetFloat(float64(number))
+		default:
+			return mismatch
+		}
+	case reflect.Slice:
+		if b, ok := value.([]byte); ok && target.Type().Elem().Kind() == reflect.Uint8 {
+			target.SetBytes(b)
+			return nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assign(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+	case reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok || len(items) != target.Len() {
+			return mismatch
+		}
+		for i, item := range items {
+			if err := assign(target.Index(i), item); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			return mismatch
+		}
+		result := reflect.MakeMapWithSize(target.Type(), len(m))
+		for key, item := range m {
+			elem := reflect.New(target.Type().Elem()).Elem()
+			if err := assign(elem, item); err != nil {
+				return err
+			}
+			result.SetMapIndex(reflect.ValueOf(key).Convert(target.Type().Key()), elem)
+		}
+		target.Set(result)
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		for _, field := range structFields(target.Type()) {
+			item, exists := m[field.name]
+			if !exists {
+				continue
+			}
+			if err := assign(target.FieldByIndex(field.index), item); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package msgpack
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City string `json:"city"`
+}
+
+type user struct {
+	ID      int               `msgpack:"id"`
+	Name    string            `json:"name"`
+	Score   float64           `json:"score"`
+	Active  bool              `json:"active"`
+	Tags    []string          `json:"tags"`
+	Avatar  []byte            `json:"avatar"`
+	Joined  time.Time         `json:"joined"`
+	Address *address          `json:"address"`
+	Extra   map[string]string `json:"extra,omitempty"`
+	secret  string
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := user{
+		ID:      -70000,
+		Name:    strings.Repeat("x", 40),
+		Score:   9.5,
+		Active:  true,
+		Tags:    []string{"a", "b"},
+		Avatar:  []byte{1, 2, 3},
+		Joined:  time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Address: &address{City: "Lagos"},
+		secret:  "hidden",
+	}
+
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var out user
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	in.secret = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Round trip mismatch:\n got %+v\nwant %+v", out, in)
+	}
+
+	var generic map[string]interface{}
+	if err := Unmarshal(data, &generic); err != nil {
+		t.Fatalf("Unmarshal into map error: %v", err)
+	}
+	if generic["id"] != int64(-70000) || generic["name"] != in.Name {
+		t.Errorf("Unexpected generic decoding: %v", generic)
+	}
+	if _, exists := generic["extra"]; exists {
+		t.Error("Expected omitempty field to be skipped")
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{5, []byte{0x05}},
+		{-1, []byte{0xff}},
+		{200, []byte{0xcc, 0xc8}},
+		{-200, []byte{0xd1, 0xff, 0x38}},
+		{"hi", []byte{0xa2, 'h', 'i'}},
+		{[]int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{map[string]int{"a": 1}, []byte{0x81, 0xa1, 'a', 0x01}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		data, err := Marshal(test.value)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error: %v", test.value, err)
+		}
+		if !bytes.Equal(data, test.expected) {
+			t.Errorf("Marshal(%v) = % x, want % x", test.value, data, test.expected)
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var n int8
+	if err := Unmarshal([]byte{0xcd, 0x01, 0x00}, &n); err == nil {
+		t.Error("Expected overflow error")
+	}
+
+	var s string
+	if err := Unmarshal([]byte{0xa5, 'a'}, &s); err != ErrShortData {
+		t.Errorf("Expected ErrShortData, got %v", err)
+	}
+
+	var items []int
+	if err := Unmarshal([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}, &items); err != ErrShortData {
+		t.Errorf("Expected ErrShortData for oversized array header, got %v", err)
+	}
+
+	if err := Unmarshal([]byte{0x01}, n); err == nil {
+		t.Error("Expected error for non-pointer target")
+	}
+}
@@ -0,0 +1,335 @@
+// Package oauth implements social login with OAuth 2.0 and OpenID Connect
+// providers such as Google and GitHub, using the authorization code flow with
+// PKCE.
+//
+// LoginHandler redirects the user to the provider; CallbackHandler verifies
+// the state, exchanges the code for tokens, loads the user's profile and
+// hands it to Config.OnLogin, which typically finds or creates the user and
+// issues the app's own tokens with the auth package.
+//
+// Example:
+//
+//	google := oauth.Config{
+//	    Provider:     oauth.Google(),
+//	    ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+//	    ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+//	    RedirectURL:  "https://app.example.com/auth/google/callback",
+//	    OnLogin: func(c *context.Context, profile *oauth.Profile, token *oauth.Token) error {
+//	        user, err := users.FindOrCreate(c, profile)
+//	        if err != nil {
+//	            return err
+//	        }
+//	        pair, err := auth.GenerateTokenPair(c, auth.Claims{"userID": user.ID}, tokens)
+//	        if err != nil {
+//	            return err
+//	        }
+//	        return c.JSON(200, pair)
+//	    },
+//	}
+//	app.GET("/auth/google", oauth.LoginHandler(google))
+//	app.GET("/auth/google/callback", oauth.CallbackHandler(google))
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/auth"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// ErrInvalidState is returned by CallbackHandler when the state parameter
+// does not match the one set by LoginHandler, e.g. for a forged callback or
+// an expired login attempt.
+var ErrInvalidState = errors.New("oauth: invalid state")
+
+// Token is the token response of the provider.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	// ExpiresIn is the lifetime of the access token in seconds (0 = unknown)
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+
+	// IDToken is the OpenID Connect ID token, if the provider issued one
+	IDToken string `json:"id_token,omitempty"`
+
+	// Scope lists the granted scopes, which may differ from the requested ones
+	Scope string `json:"scope,omitempty"`
+}
+
+// Profile is the user's identity at the provider.
+type Profile struct {
+	// Provider is the Provider.Name, e.g. "github"
+	Provider string
+
+	// ID is the user's stable ID at the provider. Identify users by
+	// Provider and ID, not by Email, which can change.
+	ID string
+
+	// Email is the user's email, and EmailVerified whether the provider
+	// verified it. Don't link accounts by unverified emails.
+	Email         string
+	EmailVerified bool
+
+	Name      string
+	AvatarURL string
+
+	// Raw is the provider's profile response
+	Raw map[string]interface{}
+}
+
+// Claims returns the profile as JWT claims, with "sub" set to
+// "<provider>:<id>", for apps that issue tokens straight from the profile.
+func (p *Profile) Claims() auth.Claims {
+	claims := auth.Claims{
+		"sub":      p.Provider + ":" + p.ID,
+		"provider": p.Provider,
+	}
+	if p.Email != "" {
+		claims["email"] = p.Email
+		claims["email_verified"] = p.EmailVerified
+	}
+	if p.Name != "" {
+		claims["name"] = p.Name
+	}
+	return claims
+}
+
+// Config holds the configuration of a login with one provider.
+type Config struct {
+	// Provider is the authorization server, e.g. Google() or GitHub()
+	Provider Provider
+
+	// ClientID and ClientSecret are the app's credentials at the provider
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the absolute URL of CallbackHandler, registered at the provider
+	RedirectURL string
+
+	// Scopes replaces Provider.Scopes
+	Scopes []string
+
+	// OnLogin is called with the verified profile and must write the
+	// response, e.g. issue a session or tokens and redirect
+	OnLogin func(c *context.Context, profile *Profile, token *Token) error
+
+	// StateTTL is how long a user has to complete the login.
+	// Default: 10 minutes
+	StateTTL time.Duration
+
+	// HTTPClient calls the provider. Default: a client with a 10 second timeout
+	HTTPClient *http.Client
+}
+
+// Validate reports configuration mistakes that would make every login fail.
+func (config Config) Validate() error {
+	problem := func(problem, fix string) error {
+		return &kese.ConfigError{Component: "oauth", Problem: problem, Fix: fix}
+	}
+	switch {
+	case config.Provider.AuthURL == "" || config.Provider.TokenURL == "":
+		return problem("Provider has no AuthURL or TokenURL", "use oauth.Google(), oauth.GitHub() or oauth.OIDC(...)")
+	case config.ClientID == "":
+		return problem("ClientID is empty", "set the client ID registered at "+config.Provider.Name)
+	case config.OnLogin == nil:
+		return problem("OnLogin is nil; logins would never complete", "set OnLogin to create the user's session")
+	}
+	if redirect, err := url.Parse(config.RedirectURL); err != nil || !redirect.IsAbs() {
+		return problem(fmt.Sprintf("RedirectURL %q is not an absolute URL", config.RedirectURL),
+			"set RedirectURL to the full URL of the callback route, e.g. \"https://app.example.com/auth/callback\"")
+	}
+	return nil
+}
+
+// withDefaults returns config with defaults applied.
+func (config Config) withDefaults() Config {
+	if config.StateTTL <= 0 {
+		config.StateTTL = 10 * time.Minute
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.Scopes == nil {
+		config.Scopes = config.Provider.Scopes
+	}
+	if config.Provider.FetchProfile == nil {
+		config.Provider.FetchProfile = fetchOIDCProfile
+	}
+	return config
+}
+
+// stateCookie returns the name of the cookie holding the state and PKCE
+// verifier of a pending login.
+func (config Config) stateCookie() string {
+	return "kese_oauth_" + config.Provider.Name
+}
+
+// cookieOptions returns the options of the state cookie, which is only sent
+// to the callback.
+func (config Config) cookieOptions() context.CookieOptions {
+	path := "/"
+	if redirect, err := url.Parse(config.RedirectURL); err == nil && redirect.Path != "" {
+		path = redirect.Path
+	}
+	return context.CookieOptions{Path: path, MaxAge: config.StateTTL}
+}
+
+// LoginHandler returns a handler that starts a login: it stores a random
+// state and PKCE verifier in a short-lived cookie and redirects to the
+// provider. Panics with a *kese.ConfigError if the configuration is invalid.
+func LoginHandler(config Config) kese.HandlerFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	config = config.withDefaults()
+
+	return func(c *context.Context) error {
+		state, err := randomString()
+		if err != nil {
+			return err
+		}
+		verifier, err := randomString()
+		if err != nil {
+			return err
+		}
+		if err := c.SetCookieSecure(config.stateCookie(), state+"."+verifier, config.cookieOptions()); err != nil {
+			return err
+		}
+
+		challenge := sha256.Sum256([]byte(verifier))
+		query := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {config.ClientID},
+			"redirect_uri":          {config.RedirectURL},
+			"state":                 {state},
+			"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+			"code_challenge_method": {"S256"},
+		}
+		if len(config.Scopes) > 0 {
+			query.Set("scope", strings.Join(config.Scopes, " "))
+		}
+
+		separator := "?"
+		if strings.Contains(config.Provider.AuthURL, "?") {
+			separator = "&"
+		}
+		return c.Redirect(http.StatusFound, config.Provider.AuthURL+separator+query.Encode())
+	}
+}
+
+// CallbackHandler returns the handler of Config.RedirectURL. It checks the
+// state against the cookie set by LoginHandler, exchanges the code for
+// tokens with the PKCE verifier, fetches the profile and calls
+// Config.OnLogin. Denied consent and invalid callbacks are rejected with
+// 401 Unauthorized. Panics with a *kese.ConfigError if the configuration is
+// invalid.
+func CallbackHandler(config Config) kese.HandlerFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	config = config.withDefaults()
+
+	return func(c *context.Context) error {
+		// The state is single-use
+		cookie, cookieErr := c.Cookie(config.stateCookie())
+		if err := c.ClearCookie(config.stateCookie(), config.cookieOptions()); err != nil {
+			return err
+		}
+
+		if reason := c.Query("error"); reason != "" {
+			return kese.ErrUnauthorized.WithMessage("login was not completed: " + reason)
+		}
+
+		var verifier string
+		if cookieErr == nil {
+			var state string
+			state, verifier, _ = strings.Cut(cookie.Value, ".")
+			if subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+				verifier = ""
+			}
+		}
+		if verifier == "" || c.Query("code") == "" {
+			return kese.ErrUnauthorized.WithMessage("login failed, please try again").WithInternal(ErrInvalidState)
+		}
+
+		token, err := Exchange(c, config, c.Query("code"), verifier)
+		if err != nil {
+			return kese.ErrUnauthorized.WithMessage("login failed, please try again").WithInternal(err)
+		}
+		profile, err := config.Provider.FetchProfile(c, config.HTTPClient, config.Provider, token)
+		if err != nil {
+			return kese.ErrUnauthorized.WithMessage("login failed, please try again").WithInternal(err)
+		}
+
+		return config.OnLogin(c, profile, token)
+	}
+}
+
+// Exchange exchanges an authorization code for tokens at the provider's
+// token endpoint. CallbackHandler calls it; use it directly for custom
+// callback flows.
+func Exchange(c *context.Context, config Config, code, verifier string) (*Token, error) {
+	config = config.withDefaults()
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {config.RedirectURL},
+		"client_id":     {config.ClientID},
+		"code_verifier": {verifier},
+	}
+	if config.ClientSecret != "" {
+		form.Set("client_secret", config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(c, http.MethodPost, config.Provider.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json") // GitHub answers form-encoded otherwise
+
+	resp, err := config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Token
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("oauth: token response of %s: %w", config.Provider.Name, err)
+	}
+	if body.Error != "" {
+		return nil, fmt.Errorf("oauth: token request to %s failed: %s %s", config.Provider.Name, body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: token request to %s failed: %s", config.Provider.Name, resp.Status)
+	}
+	return &body.Token, nil
+}
+
+// randomString returns 32 random bytes, base64url-encoded.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,141 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// fakeProvider is an OpenID Connect provider accepting the code "good-code"
+// with the PKCE verifier matching challenge.
+type fakeProvider struct {
+	challenge string
+	form      url.Values
+}
+
+func (p *fakeProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/token":
+		r.ParseForm()
+		p.form = r.PostForm
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "at-1", "token_type": "Bearer", "expires_in": 3600})
+	case "/userinfo":
+		if r.Header.Get("Authorization") != "Bearer at-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"sub": "42", "email": "ada@example.com", "email_verified": true, "name": "Ada"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestLoginAndCallback(t *testing.T) {
+	provider := &fakeProvider{}
+	server := httptest.NewServer(provider)
+	defer server.Close()
+
+	var profile *Profile
+	config := Config{
+		Provider:    Provider{Name: "test", AuthURL: server.URL + "/authorize", TokenURL: server.URL + "/token", UserInfoURL: server.URL + "/userinfo", Scopes: []string{"openid", "email"}},
+		ClientID:    "client",
+		RedirectURL: "https://app.example.com/auth/callback",
+		OnLogin: func(c *context.Context, p *Profile, token *Token) error {
+			profile = p
+			return c.String(http.StatusOK, token.AccessToken)
+		},
+	}
+
+	app := kese.New()
+	app.GET("/auth/login", LoginHandler(config))
+	app.GET("/auth/callback", CallbackHandler(config))
+
+	// Login redirects to the provider with state and PKCE challenge
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/auth/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("login: expected 302, got %d", w.Code)
+	}
+	location, _ := url.Parse(w.Header().Get("Location"))
+	query := location.Query()
+	if !strings.HasPrefix(location.String(), server.URL+"/authorize?") || query.Get("client_id") != "client" ||
+		query.Get("scope") != "openid email" || query.Get("code_challenge_method") != "S256" || query.Get("state") == "" {
+		t.Fatalf("unexpected authorization URL %s", location)
+	}
+	provider.challenge = query.Get("code_challenge")
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Path != "/auth/callback" || !cookies[0].HttpOnly {
+		t.Fatalf("expected one HttpOnly state cookie scoped to the callback, got %v", cookies)
+	}
+
+	callback := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/auth/callback?"+query, nil)
+		req.AddCookie(cookies[0])
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	// A forged state is rejected before the code is exchanged
+	if w := callback("code=good-code&state=forged"); w.Code != http.StatusUnauthorized {
+		t.Errorf("forged state: expected 401, got %d", w.Code)
+	}
+	if provider.form != nil {
+		t.Error("forged state: code should not be exchanged")
+	}
+
+	// Denied consent
+	if w := callback("error=access_denied&state=" + query.Get("state")); w.Code != http.StatusUnauthorized {
+		t.Errorf("access denied: expected 401, got %d", w.Code)
+	}
+
+	// A valid callback exchanges the code with the verifier and hands the profile to OnLogin
+	w = callback("code=good-code&state=" + url.QueryEscape(query.Get("state")))
+	if w.Code != http.StatusOK || w.Body.String() != "at-1" {
+		t.Fatalf("callback: expected 200 at-1, got %d %s", w.Code, w.Body.String())
+	}
+	if provider.form.Get("redirect_uri") != config.RedirectURL || provider.form.Get("grant_type") != "authorization_code" {
+		t.Errorf("unexpected token request %v", provider.form)
+	}
+	if profile == nil || profile.ID != "42" || profile.Email != "ada@example.com" || !profile.EmailVerified || profile.Provider != "test" {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+	if claims := profile.Claims(); claims["sub"] != "test:42" || claims["email"] != "ada@example.com" {
+		t.Errorf("unexpected claims %v", claims)
+	}
+
+	// The state cookie is cleared by the callback
+	var cleared bool
+	for _, cookie := range w.Result().Cookies() {
+		cleared = cleared || (cookie.Name == cookies[0].Name && cookie.MaxAge < 0)
+	}
+	if !cleared {
+		t.Error("callback should clear the state cookie")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := Config{Provider: Google(), ClientID: "client", RedirectURL: "/auth/callback", OnLogin: func(*context.Context, *Profile, *Token) error { return nil }}
+	if _, ok := config.Validate().(*kese.ConfigError); !ok {
+		t.Error("relative RedirectURL should be rejected")
+	}
+	config.RedirectURL = "https://app.example.com/auth/callback"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
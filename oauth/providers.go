@@ -0,0 +1,173 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Provider describes an OAuth 2.0 authorization server.
+type Provider struct {
+	// Name identifies the provider in profiles and cookie names, e.g. "google"
+	Name string
+
+	// AuthURL is the authorization endpoint users are redirected to
+	AuthURL string
+
+	// TokenURL is the endpoint exchanging the authorization code for tokens
+	TokenURL string
+
+	// UserInfoURL returns the profile of the token's user. For OpenID
+	// Connect providers it is the UserInfo endpoint.
+	UserInfoURL string
+
+	// Scopes are requested unless Config.Scopes is set
+	Scopes []string
+
+	// FetchProfile loads the user's profile with token. Default: an OpenID
+	// Connect UserInfo request to UserInfoURL
+	FetchProfile func(ctx context.Context, client *http.Client, provider Provider, token *Token) (*Profile, error)
+}
+
+// Google returns the Google provider, requesting the user's email and profile.
+// Create credentials in the Google Cloud console.
+func Google() Provider {
+	return Provider{
+		Name:        "google",
+		AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:    "https://oauth2.googleapis.com/token",
+		UserInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:      []string{"openid", "email", "profile"},
+	}
+}
+
+// GitHub returns the GitHub provider, requesting the user's profile and
+// email addresses. Register an OAuth app in the GitHub developer settings.
+func GitHub() Provider {
+	return Provider{
+		Name:         "github",
+		AuthURL:      "https://github.com/login/oauth/authorize",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		UserInfoURL:  "https://api.github.com/user",
+		Scopes:       []string{"read:user", "user:email"},
+		FetchProfile: fetchGitHubProfile,
+	}
+}
+
+// OIDC returns a provider for an OpenID Connect issuer, such as Keycloak,
+// Auth0, Okta or Microsoft Entra ID, read from the issuer's discovery
+// document (/.well-known/openid-configuration).
+//
+// Example:
+//
+//	provider, err := oauth.OIDC(ctx, "https://login.example.com/realms/main", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func OIDC(ctx context.Context, issuer string, client *http.Client) (Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var discovery struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, client, url, "", &discovery); err != nil {
+		return Provider{}, fmt.Errorf("oauth: discovering %s: %w", issuer, err)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserinfoEndpoint == "" {
+		return Provider{}, fmt.Errorf("oauth: discovery document of %s lacks endpoints", issuer)
+	}
+
+	return Provider{
+		Name:        "oidc",
+		AuthURL:     discovery.AuthorizationEndpoint,
+		TokenURL:    discovery.TokenEndpoint,
+		UserInfoURL: discovery.UserinfoEndpoint,
+		Scopes:      []string{"openid", "email", "profile"},
+	}, nil
+}
+
+// fetchOIDCProfile reads an OpenID Connect UserInfo response.
+func fetchOIDCProfile(ctx context.Context, client *http.Client, provider Provider, token *Token) (*Profile, error) {
+	var raw map[string]interface{}
+	if err := getJSON(ctx, client, provider.UserInfoURL, token.AccessToken, &raw); err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{Provider: provider.Name, Raw: raw}
+	profile.ID, _ = raw["sub"].(string)
+	profile.Email, _ = raw["email"].(string)
+	profile.EmailVerified, _ = raw["email_verified"].(bool)
+	profile.Name, _ = raw["name"].(string)
+	profile.AvatarURL, _ = raw["picture"].(string)
+	if profile.ID == "" {
+		return nil, fmt.Errorf("oauth: %s userinfo has no subject", provider.Name)
+	}
+	return profile, nil
+}
+
+// fetchGitHubProfile reads the GitHub user and, if their email is private,
+// their primary verified email.
+func fetchGitHubProfile(ctx context.Context, client *http.Client, provider Provider, token *Token) (*Profile, error) {
+	var raw map[string]interface{}
+	if err := getJSON(ctx, client, provider.UserInfoURL, token.AccessToken, &raw); err != nil {
+		return nil, err
+	}
+
+	id, ok := raw["id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("oauth: %s user has no id", provider.Name)
+	}
+	profile := &Profile{Provider: provider.Name, ID: strconv.FormatInt(int64(id), 10), Raw: raw}
+	profile.Name, _ = raw["name"].(string)
+	if profile.Name == "" {
+		profile.Name, _ = raw["login"].(string)
+	}
+	profile.AvatarURL, _ = raw["avatar_url"].(string)
+
+	// The user endpoint only shows a public email, which GitHub doesn't verify
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, strings.TrimSuffix(provider.UserInfoURL, "/")+"/emails", token.AccessToken, &emails); err == nil {
+		for _, email := range emails {
+			if email.Primary {
+				profile.Email, profile.EmailVerified = email.Email, email.Verified
+			}
+		}
+	}
+	return profile, nil
+}
+
+// getJSON fetches url, authenticated with accessToken if set, and decodes
+// the JSON response into v.
+func getJSON(ctx context.Context, client *http.Client, url, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,383 @@
+package kese
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// postmanSchema identifies the Postman collection format written by PostmanCollection.
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// PostmanConfig configures the collection built by App.PostmanCollection.
+type PostmanConfig struct {
+	// Name is the collection name (default: "API")
+	Name string
+
+	// BaseURL is the initial value of the {{baseUrl}} collection variable
+	// every request URL starts with (default: "http://localhost:8080")
+	BaseURL string
+
+	// Auth maps route auth requirements (see Route.Auth) to request
+	// authorization. Requirements not listed here are recognized by name:
+	// "JWT", "Bearer" and "OAuth" use a bearer {{token}}, "API key" sends
+	// {{apiKey}} in the X-API-Key header and "Basic" uses {{username}} and
+	// {{password}}.
+	Auth map[string]*PostmanAuth
+}
+
+// DefaultPostmanConfig returns the default Postman configuration.
+func DefaultPostmanConfig() PostmanConfig {
+	return PostmanConfig{
+		Name:    "API",
+		BaseURL: "http://localhost:8080",
+	}
+}
+
+// PostmanCollection is a Postman collection (format v2.1), also importable
+// by Bruno, Insomnia and Hoppscotch.
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+// PostmanInfo holds the collection metadata.
+type PostmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// PostmanItem is a request with its saved example responses, or a folder of items.
+type PostmanItem struct {
+	Name     string            `json:"name"`
+	Item     []PostmanItem     `json:"item,omitempty"`
+	Request  *PostmanRequest   `json:"request,omitempty"`
+	Response []PostmanResponse `json:"response,omitempty"`
+}
+
+// PostmanRequest describes a request.
+type PostmanRequest struct {
+	Method      string            `json:"method"`
+	Header      []PostmanKeyValue `json:"header"`
+	URL         PostmanURL        `json:"url"`
+	Body        *PostmanBody      `json:"body,omitempty"`
+	Auth        *PostmanAuth      `json:"auth,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// PostmanURL is a request URL. Path parameters keep the route's ":name"
+// syntax and are listed in Variable.
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []PostmanKeyValue `json:"query,omitempty"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+// PostmanBody is a raw request body.
+type PostmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// PostmanAuth is the authorization of a request. Type is "bearer", "apikey"
+// or "basic" and the matching field holds its parameters.
+type PostmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []PostmanKeyValue `json:"bearer,omitempty"`
+	APIKey []PostmanKeyValue `json:"apikey,omitempty"`
+	Basic  []PostmanKeyValue `json:"basic,omitempty"`
+}
+
+// PostmanResponse is a saved example response.
+type PostmanResponse struct {
+	Name            string            `json:"name"`
+	OriginalRequest *PostmanRequest   `json:"originalRequest,omitempty"`
+	Code            int               `json:"code"`
+	Status          string            `json:"status"`
+	Header          []PostmanKeyValue `json:"header,omitempty"`
+	Body            string            `json:"body,omitempty"`
+}
+
+// PostmanKeyValue is a header, query parameter, variable or auth parameter.
+type PostmanKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PostmanCollection exports the registered routes as a Postman collection,
+// for QA teams working in Postman or Bruno. Routes are grouped in folders by
+// their first path segment. Route examples (see Route.Example) provide the
+// request bodies and saved responses; without examples, the body is the zero
+// value of the type documented with Route.Request. Query parameters documented
+// with Route.Query are listed disabled.
+//
+// Example:
+//
+//	collection := app.PostmanCollection(kese.PostmanConfig{
+//	    Name:    "Todo API",
+//	    BaseURL: "https://staging.example.com",
+//	})
+//	data, _ := json.MarshalIndent(collection, "", "  ")
+//	os.WriteFile("todo.postman_collection.json", data, 0o644)
+func (a *App) PostmanCollection(config PostmanConfig) *PostmanCollection {
+	defaults := DefaultPostmanConfig()
+	if config.Name == "" {
+		config.Name = defaults.Name
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaults.BaseURL
+	}
+
+	builder := &postmanBuilder{config: config, variables: make(map[string]bool)}
+	collection := &PostmanCollection{
+		Info:     PostmanInfo{Name: config.Name, Schema: postmanSchema},
+		Item:     []PostmanItem{},
+		Variable: []PostmanKeyValue{{Key: "baseUrl", Value: config.BaseURL}},
+	}
+
+	// Routes are sorted by path, so each folder's routes are contiguous
+	for _, route := range a.Routes() {
+		item := builder.item(route)
+		name, _, _ := strings.Cut(strings.TrimPrefix(route.Path, "/"), "/")
+		last := len(collection.Item) - 1
+		if last < 0 || collection.Item[last].Name != name {
+			collection.Item = append(collection.Item, PostmanItem{Name: name})
+			last++
+		}
+		collection.Item[last].Item = append(collection.Item[last].Item, item)
+	}
+
+	// Folders holding a single request are flattened
+	for i, folder := range collection.Item {
+		if len(folder.Item) == 1 {
+			collection.Item[i] = folder.Item[0]
+		}
+	}
+
+	for _, name := range []string{"token", "apiKey", "username", "password"} {
+		if builder.variables[name] {
+			collection.Variable = append(collection.Variable, PostmanKeyValue{Key: name, Value: ""})
+		}
+	}
+	return collection
+}
+
+// PostmanHandler returns a handler that serves App.PostmanCollection as a
+// downloadable JSON file.
+//
+// Example:
+//
+//	admin.GET("/postman", app.PostmanHandler(kese.PostmanConfig{Name: "Todo API"}))
+func (a *App) PostmanHandler(config PostmanConfig) HandlerFunc {
+	return func(c *context.Context) error {
+		collection := a.PostmanCollection(config)
+		c.SetHeader("Content-Disposition", "attachment; filename="+
+			strconv.Quote(collection.Info.Name+".postman_collection.json"))
+		return c.JSON(http.StatusOK, collection)
+	}
+}
+
+// postmanBuilder converts routes to collection items.
+type postmanBuilder struct {
+	config PostmanConfig
+
+	// variables records the auth variables referenced by requests
+	variables map[string]bool
+}
+
+// item returns the collection item for route.
+func (b *postmanBuilder) item(route *Route) PostmanItem {
+	request := &PostmanRequest{
+		Method:      route.Method,
+		Header:      []PostmanKeyValue{},
+		URL:         postmanURL(route.Path),
+		Auth:        b.auth(route.AuthRequirement),
+		Description: route.Description,
+	}
+	for _, name := range pathParams(route.Path) {
+		request.URL.Variable = append(request.URL.Variable, PostmanKeyValue{Key: name, Value: ""})
+	}
+	if route.QueryType != nil {
+		request.URL.Query = postmanQuery(route.QueryType)
+	}
+
+	if len(route.Examples) > 0 {
+		example := route.Examples[0]
+		request.Body = postmanBody(example.Request)
+		request.Header = postmanHeaders(example.Headers)
+	} else if route.RequestType != nil {
+		request.Body = postmanBody(reflect.New(route.RequestType).Interface())
+	}
+	request.Header = withContentType(request.Header, request.Body)
+
+	item := PostmanItem{Name: route.Method + " " + route.Path, Request: request}
+	for _, example := range route.Examples {
+		item.Response = append(item.Response, b.response(route, request, example))
+	}
+	return item
+}
+
+// response returns the saved response of a route example.
+func (b *postmanBuilder) response(route *Route, request *PostmanRequest, example Example) PostmanResponse {
+	original := *request
+	original.Body = postmanBody(example.Request)
+	original.Header = withContentType(postmanHeaders(example.Headers), original.Body)
+	if example.Path != "" {
+		original.URL = postmanURL(example.Path)
+	}
+
+	status := example.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := PostmanResponse{
+		Name:            example.Name,
+		OriginalRequest: &original,
+		Code:            status,
+		Status:          http.StatusText(status),
+	}
+	if example.Response != nil {
+		if data, err := json.MarshalIndent(example.Response, "", "  "); err == nil {
+			response.Body = string(data)
+			response.Header = []PostmanKeyValue{{Key: "Content-Type", Value: "application/json"}}
+		}
+	}
+	return response
+}
+
+// auth returns the request authorization for an auth requirement, or nil.
+func (b *postmanBuilder) auth(requirement string) *PostmanAuth {
+	if requirement == "" {
+		return nil
+	}
+	if auth, exists := b.config.Auth[requirement]; exists {
+		return auth
+	}
+
+	variable := func(key, name string) PostmanKeyValue {
+		b.variables[name] = true
+		return PostmanKeyValue{Key: key, Value: "{{" + name + "}}", Type: "string"}
+	}
+
+	switch lower := strings.ToLower(requirement); {
+	case strings.Contains(lower, "jwt"), strings.Contains(lower, "bearer"), strings.Contains(lower, "oauth"):
+		return &PostmanAuth{Type: "bearer", Bearer: []PostmanKeyValue{variable("token", "token")}}
+	case strings.Contains(lower, "api key"), strings.Contains(lower, "apikey"):
+		return &PostmanAuth{Type: "apikey", APIKey: []PostmanKeyValue{
+			{Key: "key", Value: "X-API-Key", Type: "string"},
+			variable("value", "apiKey"),
+			{Key: "in", Value: "header", Type: "string"},
+		}}
+	case strings.Contains(lower, "basic"):
+		return &PostmanAuth{Type: "basic", Basic: []PostmanKeyValue{
+			variable("username", "username"),
+			variable("password", "password"),
+		}}
+	}
+	return nil
+}
+
+// postmanURL returns the URL of a path, which may include a query string.
+func postmanURL(path string) PostmanURL {
+	path, rawQuery, _ := strings.Cut(path, "?")
+	u := PostmanURL{Raw: "{{baseUrl}}" + path, Host: []string{"{{baseUrl}}"}}
+	if trimmed := strings.Trim(path, "/"); trimmed != "" {
+		u.Path = strings.Split(trimmed, "/")
+	}
+	if rawQuery != "" {
+		u.Raw += "?" + rawQuery
+		for _, pair := range strings.Split(rawQuery, "&") {
+			key, value, _ := strings.Cut(pair, "=")
+			key, _ = url.QueryUnescape(key)
+			value, _ = url.QueryUnescape(value)
+			u.Query = append(u.Query, PostmanKeyValue{Key: key, Value: value})
+		}
+	}
+	return u
+}
+
+// postmanQuery lists the fields of a documented query type as disabled parameters.
+func postmanQuery(t reflect.Type) []PostmanKeyValue {
+	builder := &schemaBuilder{types: make(map[string]*TypeSchema), names: make(map[schemaKey]string)}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	object := builder.objectSchema(t, "query")
+	names := make([]string, 0, len(object.Properties))
+	for name := range object.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	query := make([]PostmanKeyValue, 0, len(names))
+	for _, name := range names {
+		query = append(query, PostmanKeyValue{
+			Key:         name,
+			Disabled:    true,
+			Description: object.Properties[name].Type,
+		})
+	}
+	return query
+}
+
+// postmanBody returns the raw body of an example request, or nil.
+func postmanBody(body interface{}) *PostmanBody {
+	switch b := body.(type) {
+	case nil:
+		return nil
+	case string:
+		return &PostmanBody{Mode: "raw", Raw: b}
+	case []byte:
+		return &PostmanBody{Mode: "raw", Raw: string(b)}
+	}
+
+	data, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return nil
+	}
+	return &PostmanBody{
+		Mode:    "raw",
+		Raw:     string(data),
+		Options: map[string]interface{}{"raw": map[string]string{"language": "json"}},
+	}
+}
+
+// postmanHeaders converts example headers, sorted by name.
+func postmanHeaders(headers map[string]string) []PostmanKeyValue {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]PostmanKeyValue, 0, len(names))
+	for _, name := range names {
+		result = append(result, PostmanKeyValue{Key: name, Value: headers[name]})
+	}
+	return result
+}
+
+// withContentType adds a JSON Content-Type header for JSON bodies.
+func withContentType(headers []PostmanKeyValue, body *PostmanBody) []PostmanKeyValue {
+	if body == nil || body.Options == nil {
+		return headers
+	}
+	return append(headers, PostmanKeyValue{Key: "Content-Type", Value: "application/json"})
+}
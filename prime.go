@@ -0,0 +1,123 @@
+package kese
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/rw"
+)
+
+// primingKey marks the request context of requests made by PrimeCache.
+type primingKey struct{}
+
+// PrimeCache requests each of paths with GET through the app, including its
+// middleware, so middleware.Cache stores their responses before real traffic
+// arrives, e.g. right after a deploy. Priming requests always refresh the
+// cached response. It returns an error listing the paths that did not
+// respond with 2xx.
+//
+// Requests are sent with App.PrimeHost as their Host, or "localhost" if it is
+// empty; set it to a host accepted by middleware.AllowedHosts. A path may
+// also be an absolute URL such as "https://shop.example.com/products", whose
+// host is used instead.
+//
+// Example:
+//
+//	app.PrimeHost = "shop.example.com"
+//	if err := app.PrimeCache([]string{"/products", "/categories"}); err != nil {
+//	    app.Logger.Warn("Cache priming failed", "error", err.Error())
+//	}
+//	app.RunWithShutdown(":8080", 10*time.Second)
+func (a *App) PrimeCache(paths []string) error {
+	var errs []error
+	ctx := stdcontext.WithValue(stdcontext.Background(), primingKey{}, true)
+	for _, path := range paths {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("GET %s: %w", path, err))
+			continue
+		}
+		if req.URL.IsAbs() {
+			// Served requests carry the host in Host, not in the URL
+			req.URL.Scheme, req.URL.Host = "", ""
+		} else if a.PrimeHost != "" {
+			req.Host = a.PrimeHost
+		} else {
+			req.Host = "localhost"
+		}
+		req.RequestURI = req.URL.RequestURI()
+
+		// The response only matters to the middleware caching it
+		w := rw.NewRecorder(discardResponseWriter{header: make(http.Header)})
+		a.ServeHTTP(w, req)
+		status := w.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status < 200 || status > 299 {
+			errs = append(errs, fmt.Errorf("GET %s: status %d", path, status))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("priming cache: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// PrimeCacheEvery primes paths now and then every interval, keeping their
+// cached responses fresh so no user waits for them to be regenerated. Pick an
+// interval shorter than the cache TTL. Failures are logged. The returned
+// function stops priming.
+//
+// Example:
+//
+//	stop := app.PrimeCacheEvery(4*time.Minute, []string{"/products"})
+//	app.OnShutdown(stop)
+func (a *App) PrimeCacheEvery(interval time.Duration, paths []string) (stop func()) {
+	done := make(chan struct{})
+	prime := func() {
+		if err := a.PrimeCache(paths); err != nil {
+			a.Logger.Warn("Cache priming failed", "error", err.Error())
+		}
+	}
+
+	go func() {
+		prime()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				prime()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// discardResponseWriter is an http.ResponseWriter that drops everything written to it.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w discardResponseWriter) Header() http.Header         { return w.header }
+func (w discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w discardResponseWriter) WriteHeader(int)             {}
+
+// IsPriming reports whether the request was made by PrimeCache. Caching
+// middleware must then skip cached responses and store a fresh one.
+func IsPriming(c *context.Context) bool {
+	priming, _ := c.Request.Context().Value(primingKey{}).(bool)
+	return priming
+}
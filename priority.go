@@ -0,0 +1,43 @@
+package kese
+
+// PriorityKey is the route metadata key under which Route.Priority stores its Priority.
+const PriorityKey = "kese.priority"
+
+// Priority is the importance of a route, consumed by middleware.LoadShedding
+// to decide which requests to reject first under load.
+type Priority int
+
+const (
+	// PriorityBulk is for work that can wait, such as exports, reports and
+	// batch imports. It is shed first.
+	PriorityBulk Priority = iota - 1
+
+	// PriorityNormal is the default of routes without a priority
+	PriorityNormal
+
+	// PriorityCritical is for requests that must be served even when the
+	// server is overloaded, such as health checks, login and checkout.
+	// It is never shed.
+	PriorityCritical
+)
+
+// String returns "bulk", "normal" or "critical".
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "bulk"
+	case p > PriorityNormal:
+		return "critical"
+	}
+	return "normal"
+}
+
+// Priority sets the priority of the route under load, see middleware.LoadShedding.
+//
+// Example:
+//
+//	app.POST("/checkout", checkout).Priority(kese.PriorityCritical)
+//	app.GET("/reports/export", exportReport).Priority(kese.PriorityBulk)
+func (r *Route) Priority(priority Priority) *Route {
+	return r.Set(PriorityKey, priority)
+}
@@ -0,0 +1,190 @@
+package kese
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// MIMEProblemJSON is the content type of RFC 7807 problem details.
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Handlers can return it as an
+// error to control the response precisely; it is always rendered as
+// application/problem+json, whichever error handler is installed.
+//
+// Example:
+//
+//	return &kese.Problem{
+//	    Type:       "https://example.com/probs/out-of-credit",
+//	    Title:      "You do not have enough credit.",
+//	    Status:     403,
+//	    Detail:     "Your current balance is 30, but that costs 50.",
+//	    Extensions: map[string]interface{}{"balance": 30},
+//	}
+type Problem struct {
+	// Type is a URI identifying the problem type (default: "about:blank")
+	Type string
+
+	// Title is a short, human-readable summary of the problem type
+	Title string
+
+	// Status is the HTTP status code
+	Status int
+
+	// Detail is a human-readable explanation specific to this occurrence
+	Detail string
+
+	// Instance is a URI identifying this occurrence (default: the request path)
+	Instance string
+
+	// Extensions are additional members serialized alongside the standard ones
+	Extensions map[string]interface{}
+}
+
+// NewProblem creates a Problem with the standard title for status.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// MarshalJSON flattens Extensions into the problem object. Extensions cannot
+// override the standard members.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	members["type"] = p.Type
+	if p.Type == "" {
+		members["type"] = "about:blank"
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// ProblemErrorHandler renders every error as an RFC 7807 problem. Errors are
+// classified like DefaultErrorHandler; its extra fields (validation failures,
+// offending parameter) become problem extensions.
+//
+// Example:
+//
+//	app.SetErrorHandler(kese.ProblemErrorHandler)
+func ProblemErrorHandler(err error) (int, interface{}) {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return problemStatus(problem), problem
+	}
+
+	status, body := DefaultErrorHandler(err)
+	problem = NewProblem(status, "")
+
+	switch fields := body.(type) {
+	case map[string]string:
+		if status != http.StatusInternalServerError {
+			problem.Detail = fields["error"]
+		}
+	case map[string]interface{}:
+		for key, value := range fields {
+			if key == "error" {
+				problem.Detail, _ = value.(string)
+				continue
+			}
+			if problem.Extensions == nil {
+				problem.Extensions = make(map[string]interface{})
+			}
+			problem.Extensions[key] = value
+		}
+	}
+	return status, problem
+}
+
+// problemStatus returns the status of a problem, defaulting to 500.
+func problemStatus(p *Problem) int {
+	if p.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
+
+// writeError writes the response produced by the error handler, adding the
+// request ID (if any) to problems and map responses as "request_id".
+// Problems are sent as application/problem+json with the request path as instance.
+func writeError(c *context.Context, status int, response interface{}) error {
+	requestID := c.RequestID()
+
+	problem, ok := response.(*Problem)
+	if !ok {
+		return c.JSON(status, withRequestID(response, requestID))
+	}
+
+	copied := *problem
+	if copied.Instance == "" {
+		copied.Instance = c.Path()
+	}
+	if _, set := copied.Extensions["request_id"]; requestID != "" && !set {
+		extensions := make(map[string]interface{}, len(copied.Extensions)+1)
+		for key, value := range copied.Extensions {
+			extensions[key] = value
+		}
+		extensions["request_id"] = requestID
+		copied.Extensions = extensions
+	}
+	problem = &copied
+	body, err := context.JSONMarshal(problem)
+	if err != nil {
+		return err
+	}
+	return c.Bytes(status, MIMEProblemJSON, body)
+}
+
+// withRequestID returns a copy of a map response with the request ID added.
+// Other response types are returned unchanged.
+func withRequestID(response interface{}, requestID string) interface{} {
+	if requestID == "" {
+		return response
+	}
+
+	switch fields := response.(type) {
+	case map[string]string:
+		copied := make(map[string]string, len(fields)+1)
+		for key, value := range fields {
+			copied[key] = value
+		}
+		copied["request_id"] = requestID
+		return copied
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(fields)+1)
+		for key, value := range fields {
+			copied[key] = value
+		}
+		copied["request_id"] = requestID
+		return copied
+	}
+	return response
+}
@@ -0,0 +1,45 @@
+package kese
+
+import (
+	"net/http"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// Produces declares the media types the route can respond with. Requests
+// whose Accept header allows none of them are rejected with 406 Not
+// Acceptable before middleware and the handler run, and responses carry
+// "Vary: Accept". Handlers pick the representation with c.Negotiate(),
+// which negotiates between the declared types.
+//
+// Example:
+//
+//	app.GET("/reports/:id", getReport).Produces("application/json", "text/csv")
+//
+//	func getReport(c *context.Context) error {
+//	    if c.Negotiate() == "text/csv" {
+//	        return c.Bytes(200, "text/csv", report.CSV())
+//	    }
+//	    return c.JSON(200, report)
+//	}
+func (r *Route) Produces(mediaTypes ...string) *Route {
+	return r.Set(context.ProducesKey, mediaTypes)
+}
+
+// checkAccept rejects requests that accept none of the media types declared
+// with Route.Produces. Routes without declared types accept everything.
+func checkAccept(c *context.Context) error {
+	produces, _ := c.RouteMeta(context.ProducesKey).([]string)
+	if len(produces) == 0 {
+		return nil
+	}
+
+	c.Writer.Header().Add("Vary", "Accept")
+	if c.Negotiate() != "" {
+		return nil
+	}
+
+	problem := NewProblem(http.StatusNotAcceptable, "none of the available representations matches the Accept header")
+	problem.Extensions = map[string]interface{}{"available": produces}
+	return problem
+}
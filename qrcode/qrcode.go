@@ -0,0 +1,419 @@
+// Package qrcode encodes QR codes (ISO/IEC 18004) without external
+// dependencies, for 2FA provisioning URIs, payment links and tickets.
+// Content is encoded in byte mode as UTF-8, in the smallest version that
+// fits.
+//
+// Example:
+//
+//	png, err := qrcode.PNG("otpauth://totp/Example:ada?secret=JBSWY3DPEHPK3PXP", qrcode.Medium, 256)
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// ErrTooLong is returned for content that does not fit in a QR code.
+var ErrTooLong = errors.New("qrcode: content too long")
+
+// Level is the error correction level: the share of the code that can be
+// damaged or covered, e.g. by a logo, while staying readable.
+type Level int
+
+const (
+	Low      Level = iota // recovers 7%
+	Medium                // recovers 15%
+	Quartile              // recovers 25%
+	High                  // recovers 30%
+)
+
+// quietZone is the light border around the code, in modules.
+const quietZone = 4
+
+// Code is an encoded QR code.
+type Code struct {
+	// Size is the width and height in modules, without the quiet zone
+	Size int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Black reports whether the module at column x and row y is dark.
+func (c *Code) Black(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// Image returns the code as a black on white image of about size pixels
+// square, including the quiet zone. Modules are scaled to whole pixels; if
+// size is too small for one pixel per module, the image is larger.
+func (c *Code) Image(size int) image.Image {
+	total := c.Size + 2*quietZone
+	scale := size / total
+	if scale < 1 {
+		scale = 1
+	}
+	if size < total*scale {
+		size = total * scale
+	}
+	offset := (size-total*scale)/2 + quietZone*scale
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[(offset+y*scale+dy)*img.Stride:]
+				for dx := 0; dx < scale; dx++ {
+					row[offset+x*scale+dx] = 1
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG encodes content and returns it as a PNG image of about size pixels
+// square.
+func PNG(content string, level Level, size int) ([]byte, error) {
+	code, err := Encode(content, level)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code.Image(size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode encodes content at the error correction level.
+func Encode(content string, level Level) (*Code, error) {
+	if level < Low || level > High {
+		return nil, errors.New("qrcode: invalid level")
+	}
+
+	data := []byte(content)
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if 4+countBits(v)+8*len(data) <= 8*dataCodewords(v, level) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	// Byte mode segment, terminator and padding
+	var bits bitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := 8 * dataCodewords(version, level)
+	bits.append(0, min(4, capacity-bits.len()))
+	bits.append(0, (8-bits.len()%8)%8)
+	for pad := 0xEC; bits.len() < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	c := newCode(version)
+	c.drawFunctionPatterns(version, level)
+	c.drawCodewords(addECCAndInterleave(bits.bytes(), version, level))
+
+	// Pick the mask that leaves the fewest patterns confusing scanners
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(level, mask)
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormatBits(level, best)
+	return c, nil
+}
+
+// countBits returns the length of the byte mode character count.
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+func newCode(version int) *Code {
+	size := version*4 + 17
+	c := &Code{Size: size, modules: make([][]bool, size), isFunction: make([][]bool, size)}
+	for i := range c.modules {
+		c.modules[i] = make([]bool, size)
+		c.isFunction[i] = make([]bool, size)
+	}
+	return c
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns and
+// reserves the format and version areas.
+func (c *Code) drawFunctionPatterns(version int, level Level) {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	positions := alignmentPositions(version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Skip the corners taken by finders
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignment(x, y)
+		}
+	}
+
+	c.drawFormatBits(level, 0)
+	c.drawVersion(version)
+}
+
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the level and mask, and the dark
+// module.
+func (c *Code) drawFormatBits(level Level, mask int) {
+	levelBits := [...]int{Low: 1, Medium: 0, Quartile: 3, High: 2}[level]
+	data := levelBits<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+	c.setFunction(8, c.Size-8, true)
+}
+
+// drawVersion draws both copies of the version, for versions 7 and up.
+func (c *Code) drawVersion(version int) {
+	if version < 7 {
+		return
+	}
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := version<<12 | rem
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places data in the zigzag pattern from the bottom right.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !c.isFunction[y][x] && i < len(data)*8 {
+					c.modules[y][x] = bit(int(data[i>>3]), 7-i&7)
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by mask; applying it twice
+// undoes it.
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip && !c.isFunction[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+var (
+	finderLike1 = []bool{true, false, true, true, true, false, true, false, false, false, false}
+	finderLike2 = []bool{false, false, false, false, true, false, true, true, true, false, true}
+)
+
+// penalty scores patterns that are hard to scan: long runs, 2x2 blocks,
+// finder-like sequences and an unbalanced share of dark modules.
+func (c *Code) penalty() int {
+	penalty, dark := 0, 0
+	line := make([]bool, c.Size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < c.Size; i++ {
+			for j := range line {
+				if vertical {
+					line[j] = c.modules[j][i]
+				} else {
+					line[j] = c.modules[i][j]
+				}
+			}
+
+			run := 1
+			for j := 1; j <= c.Size; j++ {
+				if j < c.Size && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+
+			for j := 0; j+len(finderLike1) <= c.Size; j++ {
+				if matches(line[j:], finderLike1) || matches(line[j:], finderLike2) {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 {
+				m := c.modules[y][x]
+				if m == c.modules[y-1][x] && m == c.modules[y][x-1] && m == c.modules[y-1][x-1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	penalty += abs(dark*100/total-50) / 5 * 10
+	return penalty
+}
+
+func matches(line, pattern []bool) bool {
+	for i, p := range pattern {
+		if line[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+func bit(x, i int) bool {
+	return x>>i&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// bitBuffer accumulates bits, most significant first.
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, bit(value, i))
+	}
+}
+
+func (b bitBuffer) len() int {
+	return len(b)
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, set := range b {
+		if set {
+			out[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// "HELLO WORLD" as 1-M in alphanumeric mode, from the standard's worked example
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("Expected ECC %v, got %v", want, got)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	tests := []struct {
+		version int
+		level   Level
+		want    int
+	}{
+		{1, Medium, 16},
+		{5, Quartile, 62},
+		{10, High, 122},
+		{40, Low, 2956},
+		{40, High, 1276},
+	}
+	for _, test := range tests {
+		if got := dataCodewords(test.version, test.level); got != test.want {
+			t.Errorf("dataCodewords(%d, %d) = %d, want %d", test.version, test.level, got, test.want)
+		}
+	}
+
+	if got := alignmentPositions(32); len(got) != 6 || got[1] != 34 || got[5] != 138 {
+		t.Errorf("Unexpected alignment positions for version 32: %v", got)
+	}
+}
+
+// readFormat reads the level and mask from the first format copy.
+func readFormat(c *Code) (Level, int) {
+	bits := 0
+	set := func(i int, dark bool) {
+		if dark {
+			bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		set(i, c.modules[i][8])
+	}
+	set(6, c.modules[7][8])
+	set(7, c.modules[8][8])
+	set(8, c.modules[8][7])
+	for i := 9; i < 15; i++ {
+		set(i, c.modules[8][14-i])
+	}
+	data := (bits ^ 0x5412) >> 10
+	return [...]Level{Medium, Low, High, Quartile}[data>>3], data & 7
+}
+
+// decode reads content back from c, checking every block's error
+// correction.
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	level, mask := readFormat(c)
+	version := (c.Size - 17) / 4
+
+	c.applyMask(mask)
+	defer c.applyMask(mask)
+	var raw []byte
+	var cur byte
+	n := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.isFunction[y][x] {
+					continue
+				}
+				cur = cur<<1 | map[bool]byte{false: 0, true: 1}[c.modules[y][x]]
+				if n++; n%8 == 0 {
+					raw = append(raw, cur)
+				}
+			}
+		}
+	}
+
+	// De-interleave and check that each block is a Reed-Solomon codeword
+	numBlocks := eccBlocks[level][version]
+	eccLen := eccCodewordsPerBlock[level][version]
+	total := rawModules(version) / 8
+	numShort := numBlocks - total%numBlocks
+	shortLen := total / numBlocks
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i < shortLen+1; i++ {
+		for j := range blocks {
+			if i == shortLen-eccLen && j < numShort {
+				continue
+			}
+			blocks[j] = append(blocks[j], raw[k])
+			k++
+		}
+	}
+	var data []byte
+	divisor := rsDivisor(eccLen)
+	for _, block := range blocks {
+		dataLen := len(block) - eccLen
+		if !bytes.Equal(rsRemainder(block[:dataLen], divisor), block[dataLen:]) {
+			t.Fatalf("Block fails error correction check")
+		}
+		data = append(data, block[:dataLen]...)
+	}
+
+	// Byte mode segment
+	if data[0]>>4 != 0x4 {
+		t.Fatalf("Expected byte mode, got %x", data[0]>>4)
+	}
+	readBits := func(offset, n int) int {
+		v := 0
+		for i := offset; i < offset+n; i++ {
+			v = v<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+		return v
+	}
+	length := readBits(4, countBits(version))
+	out := make([]byte, length)
+	for i := range out {
+		out[i] = byte(readBits(4+countBits(version)+8*i, 8))
+	}
+	return string(out)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		content string
+		level   Level
+		version int
+	}{
+		{"hello", Medium, 1},
+		{"otpauth://totp/Example:ada@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example", Medium, 5},
+		{strings.Repeat("ticket-42;", 30), High, 18},
+		{strings.Repeat("Ωmega ", 300), Low, 0},
+	}
+	for _, test := range tests {
+		c, err := Encode(test.content, test.level)
+		if err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		if version := (c.Size - 17) / 4; test.version != 0 && version != test.version {
+			t.Errorf("%.20q: expected version %d, got %d", test.content, test.version, version)
+		}
+		if level, _ := readFormat(c); level != test.level {
+			t.Errorf("%.20q: expected level %d in format bits, got %d", test.content, test.level, level)
+		}
+		if got := decode(t, c); got != test.content {
+			t.Errorf("Round trip mismatch: %.40q", got)
+		}
+
+		// Finder pattern corners and the dark module
+		if !c.Black(0, 0) || !c.Black(c.Size-1, 0) || !c.Black(0, c.Size-1) || c.Black(7, 7) || !c.Black(8, c.Size-8) {
+			t.Errorf("%.20q: finder patterns or dark module misplaced", test.content)
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(strings.Repeat("x", 3000), Low); err != ErrTooLong {
+		t.Errorf("Expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	data, err := PNG("https://example.com/pay/42", Medium, 200)
+	if err != nil {
+		t.Fatalf("PNG: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 200 {
+		t.Errorf("Expected 200x200 image, got %v", b)
+	}
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedisClient runs Lua scripts on Redis. The framework ships no Redis
+// driver; adapt the client of your choice with RedisClientFunc.
+type RedisClient interface {
+	// Eval runs script with keys and args and returns its reply
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisClientFunc adapts a function to the RedisClient interface.
+type RedisClientFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+
+// Eval calls f(ctx, script, keys, args...).
+func (f RedisClientFunc) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return f(ctx, script, keys, args...)
+}
+
+// redisTimeout bounds each Redis round trip, since Store methods take no
+// context and a stalled Redis must not hang requests.
+const redisTimeout = time.Second
+
+// incrementScript counts a request, starts the window on the first one and
+// returns the count and the milliseconds left in the window. Checking PTTL
+// rather than the count also repairs keys left without an expiry, e.g. by a
+// manual SET, which would otherwise block a client forever.
+const incrementScript = `local count = redis.call("INCR", KEYS[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}`
+
+// getScript returns the count, or 0 for a missing key instead of a nil
+// reply, which some clients report as an error.
+const getScript = `return tonumber(redis.call("GET", KEYS[1]) or "0")`
+
+const resetScript = `return redis.call("DEL", KEYS[1])`
+
+// RedisStore is a Store on Redis, sharing limits across all replicas of an
+// application. Counts use fixed windows, like MemoryStore, and are updated
+// atomically by Lua scripts.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a store on client, with keys prefixed "ratelimit:".
+//
+// Example:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	store := ratelimit.NewRedisStore(ratelimit.RedisClientFunc(
+//	    func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//	        return rdb.Eval(ctx, script, keys, args...).Result()
+//	    }))
+//
+//	config := middleware.DefaultRateLimitConfig(100, time.Minute)
+//	config.Store = store
+//	app.Use(middleware.RateLimitWithConfig(config))
+func NewRedisStore(client RedisClient) *RedisStore {
+	return NewRedisStoreWithPrefix(client, "ratelimit:")
+}
+
+// NewRedisStoreWithPrefix creates a store on client with keys prefixed by
+// prefix, e.g. to keep the limits of several applications apart.
+func NewRedisStoreWithPrefix(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get returns the current count for the given key.
+func (s *RedisStore) Get(key string) (int, error) {
+	reply, err := s.eval(getScript, key)
+	if err != nil {
+		return 0, err
+	}
+	return redisInt(reply)
+}
+
+// Increment increments the count for the given key.
+func (s *RedisStore) Increment(key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now()
+	reply, err := s.eval(incrementScript, key, window.Milliseconds())
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return 0, time.Time{}, fmt.Errorf("ratelimit: unexpected redis reply %T", reply)
+	}
+	count, err := redisInt(values[0])
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	ttl, err := redisInt(values[1])
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, now.Add(time.Duration(ttl) * time.Millisecond), nil
+}
+
+// Reset resets the count for the given key.
+func (s *RedisStore) Reset(key string) error {
+	_, err := s.eval(resetScript, key)
+	return err
+}
+
+func (s *RedisStore) eval(script, key string, args ...interface{}) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	reply, err := s.client.Eval(ctx, script, []string{s.prefix + key}, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: redis: %w", err)
+	}
+	return reply, nil
+}
+
+// redisInt converts an integer reply, as returned by the common clients.
+func redisInt(reply interface{}) (int, error) {
+	switch v := reply.(type) {
+	case int64:
+		return int(v), nil
+	case int:
+		return v, nil
+	case string:
+		return strconv.Atoi(v)
+	case []byte:
+		return strconv.Atoi(string(v))
+	}
+	return 0, fmt.Errorf("ratelimit: unexpected redis reply %T", reply)
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeRedis runs the store's scripts against a map.
+type fakeRedis struct {
+	counts map[string]int
+	ttls   map[string]int64
+}
+
+func (f *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	key := keys[0]
+	switch script {
+	case incrementScript:
+		f.counts[key]++
+		if _, ok := f.ttls[key]; !ok {
+			f.ttls[key] = args[0].(int64)
+		}
+		return []interface{}{int64(f.counts[key]), f.ttls[key]}, nil
+	case getScript:
+		return int64(f.counts[key]), nil
+	case resetScript:
+		delete(f.counts, key)
+		delete(f.ttls, key)
+		return int64(1), nil
+	}
+	return nil, errors.New("unknown script")
+}
+
+func TestRedisStore(t *testing.T) {
+	redis := &fakeRedis{counts: map[string]int{}, ttls: map[string]int64{}}
+	store := NewRedisStoreWithPrefix(redis, "app:")
+
+	for i := 1; i <= 3; i++ {
+		count, reset, err := store.Increment("1.2.3.4", time.Minute)
+		if err != nil || count != i {
+			t.Fatalf("Increment = %d, %v; want %d", count, err, i)
+		}
+		if until := time.Until(reset); until < 59*time.Second || until > time.Minute {
+			t.Errorf("Expected reset in a minute, got %v", until)
+		}
+	}
+	if ttl := redis.ttls["app:1.2.3.4"]; ttl != 60000 {
+		t.Errorf("Expected window of 60000ms, got %d", ttl)
+	}
+	if count, _ := store.Get("1.2.3.4"); count != 3 {
+		t.Errorf("Expected count 3, got %d", count)
+	}
+
+	if err := store.Reset("1.2.3.4"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if count, _ := store.Get("1.2.3.4"); count != 0 {
+		t.Errorf("Expected count 0 after reset, got %d", count)
+	}
+}
+
+func TestRedisStoreErrors(t *testing.T) {
+	down := errors.New("connection refused")
+	store := NewRedisStore(RedisClientFunc(func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected a deadline on Redis calls")
+		}
+		if keys[0] != "ratelimit:k" {
+			t.Errorf("Expected default prefix, got %q", keys[0])
+		}
+		return nil, down
+	}))
+	if _, _, err := store.Increment("k", time.Second); !errors.Is(err, down) {
+		t.Errorf("Expected wrapped client error, got %v", err)
+	}
+
+	store = NewRedisStore(RedisClientFunc(func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+		return []interface{}{}, nil
+	}))
+	if _, err := store.Get("k"); err == nil {
+		t.Error("Expected error for unexpected reply type")
+	}
+}
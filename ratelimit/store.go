@@ -10,8 +10,9 @@ type Store interface {
 	// Get returns the current count for the given key
 	Get(key string) (int, error)
 
-	// Increment increments the count for the given key and returns the new count
-	Increment(key string, window time.Duration) (int, error)
+	// Increment increments the count for the given key and returns the new
+	// count and when its window ends, at which point the count resets
+	Increment(key string, window time.Duration) (count int, reset time.Time, err error)
 
 	// Reset resets the count for the given key
 	Reset(key string) error
@@ -55,7 +56,7 @@ func (s *MemoryStore) Get(key string) (int, error) {
 }
 
 // Increment increments the count for the given key.
-func (s *MemoryStore) Increment(key string, window time.Duration) (int, error) {
+func (s *MemoryStore) Increment(key string, window time.Duration) (int, time.Time, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -64,17 +65,18 @@ func (s *MemoryStore) Increment(key string, window time.Duration) (int, error) {
 	if e, exists := s.data[key]; exists {
 		if now.Before(e.expiry) {
 			e.count++
-			return e.count, nil
+			return e.count, e.expiry, nil
 		}
 	}
 
 	// Create new entry
+	expiry := now.Add(window)
 	s.data[key] = &entry{
 		count:  1,
-		expiry: now.Add(window),
+		expiry: expiry,
 	}
 
-	return 1, nil
+	return 1, expiry, nil
 }
 
 // Reset resets the count for the given key.
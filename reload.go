@@ -0,0 +1,180 @@
+package kese
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/logger"
+)
+
+// MaintenanceExemptKey is the route metadata key set by AllowInMaintenance.
+const MaintenanceExemptKey = "kese.maintenanceExempt"
+
+// ErrNoConfigLoader is returned by ReloadConfig when no loader was set with SetConfigLoader.
+var ErrNoConfigLoader = errors.New("kese: no config loader set")
+
+// RuntimeConfig holds the settings that can change while the app is running,
+// without a restart. It is applied with ApplyConfig, or reloaded on SIGHUP and
+// through ReloadHandler once a loader is set with SetConfigLoader.
+type RuntimeConfig struct {
+	// LogLevel is the minimum level of app.Logger
+	LogLevel logger.Level `json:"log_level"`
+
+	// RateLimit is the request limit for rate limiters that read it through
+	// their LimitFunc (0 = their configured limit)
+	RateLimit int `json:"rate_limit"`
+
+	// Features are feature flags, read with app.Feature
+	Features map[string]bool `json:"features"`
+
+	// Maintenance makes every route respond 503 Service Unavailable, except
+	// routes marked with AllowInMaintenance such as health checks
+	Maintenance bool `json:"maintenance"`
+}
+
+// AllowInMaintenance keeps the route available in maintenance mode.
+// Mark health checks with it so orchestrators don't restart the app.
+func (r *Route) AllowInMaintenance() *Route {
+	return r.Set(MaintenanceExemptKey, true)
+}
+
+// SetConfigLoader sets the function that reads the runtime configuration, for
+// example from a file or environment variables. It is called by ReloadConfig,
+// on SIGHUP when running with RunWithShutdown, and by ReloadHandler.
+//
+// Example:
+//
+//	app.SetConfigLoader(func() (kese.RuntimeConfig, error) {
+//	    var cfg kese.RuntimeConfig
+//	    data, err := os.ReadFile("runtime.json")
+//	    if err != nil {
+//	        return cfg, err
+//	    }
+//	    return cfg, json.Unmarshal(data, &cfg)
+//	})
+func (a *App) SetConfigLoader(loader func() (RuntimeConfig, error)) {
+	a.configLoader = loader
+}
+
+// ReloadConfig reads the runtime configuration with the loader set by
+// SetConfigLoader and applies it. source identifies what triggered the reload
+// in the audit log (e.g. "SIGHUP"). The current configuration is kept if the
+// loader fails.
+func (a *App) ReloadConfig(source string) error {
+	if a.configLoader == nil {
+		return ErrNoConfigLoader
+	}
+
+	cfg, err := a.configLoader()
+	if err != nil {
+		a.Logger.Error("Configuration reload failed", "source", source, "error", err.Error())
+		return fmt.Errorf("kese: reload config: %w", err)
+	}
+	a.ApplyConfig(cfg, source)
+	return nil
+}
+
+// ApplyConfig replaces the runtime configuration and logs every changed
+// setting, with source, as an audit trail. It is safe to call while the app
+// is serving requests.
+func (a *App) ApplyConfig(cfg RuntimeConfig, source string) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	features := make(map[string]bool, len(cfg.Features))
+	for name, enabled := range cfg.Features {
+		features[name] = enabled
+	}
+	cfg.Features = features
+
+	changes := configChanges(a.RuntimeConfig(), cfg)
+	a.runtimeConfig.Store(&cfg)
+	a.Logger.SetLevel(cfg.LogLevel)
+
+	// Logged as a warning so the audit trail survives a raised log level
+	a.Logger.Warn("Configuration reloaded", "source", source, "changes", changes)
+}
+
+// RuntimeConfig returns the current runtime configuration. Before any config
+// is applied it reports the logger's level and no flags. The Features map
+// must not be modified.
+func (a *App) RuntimeConfig() RuntimeConfig {
+	if cfg := a.runtimeConfig.Load(); cfg != nil {
+		return *cfg
+	}
+	return RuntimeConfig{LogLevel: a.Logger.Level()}
+}
+
+// Feature reports whether the feature flag name is enabled in the runtime configuration.
+func (a *App) Feature(name string) bool {
+	if cfg := a.runtimeConfig.Load(); cfg != nil {
+		return cfg.Features[name]
+	}
+	return false
+}
+
+// Maintenance reports whether maintenance mode is enabled.
+func (a *App) Maintenance() bool {
+	cfg := a.runtimeConfig.Load()
+	return cfg != nil && cfg.Maintenance
+}
+
+// ReloadHandler returns an admin handler that reloads the runtime
+// configuration and responds with the applied configuration. Protect it
+// with authentication middleware.
+//
+// Example:
+//
+//	admin := app.Group("/admin", middleware.JWT(secret))
+//	admin.POST("/reload", app.ReloadHandler())
+func (a *App) ReloadHandler() HandlerFunc {
+	return func(c *context.Context) error {
+		if err := a.ReloadConfig("admin:" + callerIdentity(c)); err != nil {
+			return ErrInternalServerError.WithMessage("configuration reload failed").WithInternal(err)
+		}
+		return c.JSON(200, a.RuntimeConfig())
+	}
+}
+
+// serveMaintenance rejects requests while maintenance mode is enabled, unless
+// the route is marked with AllowInMaintenance.
+func (a *App) serveMaintenance(c *context.Context, next HandlerFunc) error {
+	if a.Maintenance() && c.RouteMeta(MaintenanceExemptKey) == nil {
+		return ErrServiceUnavailable.WithMessage("service is under maintenance")
+	}
+	return next(c)
+}
+
+// configChanges describes the settings that differ between old and updated,
+// e.g. "log_level=DEBUG (was INFO)".
+func configChanges(old, updated RuntimeConfig) []string {
+	changes := []string{}
+	if old.LogLevel != updated.LogLevel {
+		changes = append(changes, fmt.Sprintf("log_level=%s (was %s)", updated.LogLevel, old.LogLevel))
+	}
+	if old.RateLimit != updated.RateLimit {
+		changes = append(changes, fmt.Sprintf("rate_limit=%d (was %d)", updated.RateLimit, old.RateLimit))
+	}
+	if old.Maintenance != updated.Maintenance {
+		changes = append(changes, fmt.Sprintf("maintenance=%t (was %t)", updated.Maintenance, old.Maintenance))
+	}
+
+	names := make([]string, 0, len(old.Features)+len(updated.Features))
+	for name := range old.Features {
+		names = append(names, name)
+	}
+	for name := range updated.Features {
+		if _, ok := old.Features[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if old.Features[name] != updated.Features[name] {
+			changes = append(changes, fmt.Sprintf("feature.%s=%t (was %t)", name, updated.Features[name], old.Features[name]))
+		}
+	}
+	return changes
+}
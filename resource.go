@@ -0,0 +1,276 @@
+package kese
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// ErrResourceNotFound should be returned by a Repository when the requested
+// record does not exist. Resource handlers translate it into a 404 response.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Repository is the storage interface used by Resource.
+// IDs are passed as the raw path parameter so implementations can parse them as needed.
+type Repository[T any] interface {
+	// List returns a page of items and the total number of items
+	List(offset, limit int) ([]T, int, error)
+
+	// Get returns a single item or ErrResourceNotFound
+	Get(id string) (T, error)
+
+	// Create stores a new item and returns it (with any generated fields populated)
+	Create(item T) (T, error)
+
+	// Update replaces an existing item or returns ErrResourceNotFound
+	Update(id string, item T) (T, error)
+
+	// Delete removes (or soft-deletes) an item or returns ErrResourceNotFound
+	Delete(id string) error
+}
+
+// SoftDeleteRepository is a Repository whose Delete only marks records as deleted.
+// When a Resource has SoftDelete enabled, it registers endpoints to list and restore them.
+type SoftDeleteRepository[T any] interface {
+	Repository[T]
+
+	// ListDeleted returns a page of soft-deleted items and their total count
+	ListDeleted(offset, limit int) ([]T, int, error)
+
+	// Restore undeletes an item or returns ErrResourceNotFound
+	Restore(id string) (T, error)
+}
+
+// RouteRegistrar is implemented by both *App and *RouterGroup.
+type RouteRegistrar interface {
+	GET(path string, handler HandlerFunc) *Route
+	POST(path string, handler HandlerFunc) *Route
+	PUT(path string, handler HandlerFunc) *Route
+	DELETE(path string, handler HandlerFunc) *Route
+}
+
+// Resource wires standard REST routes for a Repository.
+//
+// Registered routes (for path "/todos"):
+//
+//	GET    /todos              list with ?page=1&per_page=20
+//	GET    /todos/:id          get one
+//	POST   /todos              create
+//	PUT    /todos/:id          update
+//	DELETE /todos/:id          delete
+//	GET    /todos/deleted      list soft-deleted (SoftDelete only)
+//	POST   /todos/:id/restore  restore (SoftDelete only)
+type Resource[T any] struct {
+	// Repo is the storage backend
+	Repo Repository[T]
+
+	// Validate is called on decoded bodies before Create and Update.
+	// Return a *ValidationError to produce a field-level 400 response.
+	Validate func(item *T) error
+
+	// DefaultPageSize is used when per_page is not given. Default: 20
+	DefaultPageSize int
+
+	// MaxPageSize caps per_page. Default: 100
+	MaxPageSize int
+
+	// SoftDelete registers list-deleted and restore endpoints.
+	// Repo must implement SoftDeleteRepository.
+	SoftDelete bool
+}
+
+// NewResource creates a Resource with default pagination settings.
+func NewResource[T any](repo Repository[T]) *Resource[T] {
+	return &Resource[T]{
+		Repo:            repo,
+		DefaultPageSize: 20,
+		MaxPageSize:     100,
+	}
+}
+
+// Page is the JSON envelope returned by list endpoints.
+type Page[T any] struct {
+	Items   []T `json:"items"`
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+	Total   int `json:"total"`
+}
+
+// Mount registers the resource routes under path.
+// Panics with a *ConfigError if SoftDelete is set but Repo does not support it.
+//
+// Example:
+//
+//	res := kese.NewResource[Todo](todoRepo)
+//	res.Mount(app, "/todos")
+func (res *Resource[T]) Mount(r RouteRegistrar, path string) {
+	path = strings.TrimSuffix(path, "/")
+
+	var softRepo SoftDeleteRepository[T]
+	if res.SoftDelete {
+		repo, ok := res.Repo.(SoftDeleteRepository[T])
+		if !ok {
+			panic(&ConfigError{
+				Component: "resource",
+				Problem:   "SoftDelete is enabled for " + path + " but the repository does not implement SoftDeleteRepository",
+				Fix:       "implement ListDeleted and Restore, or disable SoftDelete",
+			})
+		}
+		softRepo = repo
+	}
+
+	r.GET(path, res.list(res.Repo.List))
+	if softRepo != nil {
+		r.GET(path+"/deleted", res.list(softRepo.ListDeleted))
+		r.POST(path+"/:id/restore", res.restore(softRepo))
+	}
+	r.GET(path+"/:id", res.get)
+	r.POST(path, res.create)
+	r.PUT(path+"/:id", res.update)
+	r.DELETE(path+"/:id", res.delete)
+}
+
+// list returns a handler serving a paginated list from the given source.
+func (res *Resource[T]) list(source func(offset, limit int) ([]T, int, error)) HandlerFunc {
+	return func(c *context.Context) error {
+		page, perPage, ok := res.pagination(c)
+		if !ok {
+			return c.BadRequest("page and per_page must be positive integers")
+		}
+		if page > math.MaxInt/perPage {
+			return c.BadRequest("page is out of range")
+		}
+
+		items, total, err := source((page-1)*perPage, perPage)
+		if err != nil {
+			return err
+		}
+		if items == nil {
+			items = []T{}
+		}
+
+		return c.JSON(http.StatusOK, Page[T]{
+			Items:   items,
+			Page:    page,
+			PerPage: perPage,
+			Total:   total,
+		})
+	}
+}
+
+// get returns a single item.
+func (res *Resource[T]) get(c *context.Context) error {
+	item, err := res.Repo.Get(c.Param("id"))
+	if err != nil {
+		return res.repoError(c, err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// create decodes, validates and stores a new item.
+func (res *Resource[T]) create(c *context.Context) error {
+	item, err := res.decode(c)
+	if err != nil {
+		return err
+	}
+	if c.IsWritten() {
+		return nil
+	}
+
+	created, err := res.Repo.Create(item)
+	if err != nil {
+		return err
+	}
+	return c.Created(created)
+}
+
+// update decodes, validates and replaces an existing item.
+func (res *Resource[T]) update(c *context.Context) error {
+	item, err := res.decode(c)
+	if err != nil {
+		return err
+	}
+	if c.IsWritten() {
+		return nil
+	}
+
+	updated, err := res.Repo.Update(c.Param("id"), item)
+	if err != nil {
+		return res.repoError(c, err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// delete removes an item.
+func (res *Resource[T]) delete(c *context.Context) error {
+	if err := res.Repo.Delete(c.Param("id")); err != nil {
+		return res.repoError(c, err)
+	}
+	return c.NoContent()
+}
+
+// restore returns a handler that undeletes a soft-deleted item.
+func (res *Resource[T]) restore(repo SoftDeleteRepository[T]) HandlerFunc {
+	return func(c *context.Context) error {
+		item, err := repo.Restore(c.Param("id"))
+		if err != nil {
+			return res.repoError(c, err)
+		}
+		return c.JSON(http.StatusOK, item)
+	}
+}
+
+// decode parses the request body and runs validation.
+// A malformed body is answered with 400 directly; validation errors are returned.
+func (res *Resource[T]) decode(c *context.Context) (T, error) {
+	var item T
+	if err := c.Body(&item); err != nil {
+		return item, c.BadRequest("Invalid request body")
+	}
+
+	if res.Validate != nil {
+		if err := res.Validate(&item); err != nil {
+			return item, err
+		}
+	}
+	return item, nil
+}
+
+// repoError translates ErrResourceNotFound into a 404 response.
+func (res *Resource[T]) repoError(c *context.Context, err error) error {
+	if errors.Is(err, ErrResourceNotFound) {
+		return c.NotFoundError(err.Error())
+	}
+	return err
+}
+
+// pagination reads page and per_page from the query string.
+func (res *Resource[T]) pagination(c *context.Context) (int, int, bool) {
+	defaultSize := res.DefaultPageSize
+	if defaultSize <= 0 {
+		defaultSize = 20
+	}
+	maxSize := res.MaxPageSize
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+
+	page, err := strconv.Atoi(c.QueryDefault("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, false
+	}
+
+	perPage, err := strconv.Atoi(c.QueryDefault("per_page", strconv.Itoa(defaultSize)))
+	if err != nil || perPage < 1 {
+		return 0, 0, false
+	}
+	if perPage > maxSize {
+		perPage = maxSize
+	}
+
+	return page, perPage, true
+}
@@ -0,0 +1,190 @@
+package kese
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testNote struct {
+	ID      int    `json:"id"`
+	Text    string `json:"text"`
+	Deleted bool   `json:"-"`
+}
+
+// memoryNotes is a minimal SoftDeleteRepository used by the resource tests.
+type memoryNotes struct {
+	mu     sync.Mutex
+	notes  map[int]*testNote
+	nextID int
+}
+
+func newMemoryNotes() *memoryNotes {
+	return &memoryNotes{notes: make(map[int]*testNote), nextID: 1}
+}
+
+func (m *memoryNotes) page(deleted bool, offset, limit int) ([]testNote, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var all []testNote
+	for _, n := range m.notes {
+		if n.Deleted == deleted {
+			all = append(all, *n)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	if offset >= len(all) {
+		return nil, len(all), nil
+	}
+	end := offset + limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[offset:end], len(all), nil
+}
+
+func (m *memoryNotes) List(offset, limit int) ([]testNote, int, error) {
+	return m.page(false, offset, limit)
+}
+
+func (m *memoryNotes) ListDeleted(offset, limit int) ([]testNote, int, error) {
+	return m.page(true, offset, limit)
+}
+
+func (m *memoryNotes) lookup(id string, deleted bool) (*testNote, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, ErrResourceNotFound
+	}
+	note, ok := m.notes[n]
+	if !ok || note.Deleted != deleted {
+		return nil, ErrResourceNotFound
+	}
+	return note, nil
+}
+
+func (m *memoryNotes) Get(id string) (testNote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	note, err := m.lookup(id, false)
+	if err != nil {
+		return testNote{}, err
+	}
+	return *note, nil
+}
+
+func (m *memoryNotes) Create(item testNote) (testNote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item.ID = m.nextID
+	m.nextID++
+	m.notes[item.ID] = &item
+	return item, nil
+}
+
+func (m *memoryNotes) Update(id string, item testNote) (testNote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	note, err := m.lookup(id, false)
+	if err != nil {
+		return testNote{}, err
+	}
+	note.Text = item.Text
+	return *note, nil
+}
+
+func (m *memoryNotes) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	note, err := m.lookup(id, false)
+	if err != nil {
+		return err
+	}
+	note.Deleted = true
+	return nil
+}
+
+func (m *memoryNotes) Restore(id string) (testNote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	note, err := m.lookup(id, true)
+	if err != nil {
+		return testNote{}, err
+	}
+	note.Deleted = false
+	return *note, nil
+}
+
+func TestResource(t *testing.T) {
+	app := New()
+	res := NewResource[testNote](newMemoryNotes())
+	res.SoftDelete = true
+	res.Validate = func(n *testNote) error {
+		verr := NewValidationError()
+		if n.Text == "" {
+			verr.Add("text", "is required")
+		}
+		if verr.HasErrors() {
+			return verr
+		}
+		return nil
+	}
+	res.Mount(app, "/notes")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/notes", `{"text":""}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid note, got %d", w.Code)
+	}
+	for _, text := range []string{"one", "two", "three"} {
+		if w := do("POST", "/notes", `{"text":"`+text+`"}`); w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", w.Code)
+		}
+	}
+
+	w := do("GET", "/notes?page=2&per_page=2", "")
+	var page Page[testNote]
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Failed to parse page: %v", err)
+	}
+	if page.Total != 3 || len(page.Items) != 1 || page.Items[0].Text != "three" {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+
+	if w := do("GET", "/notes?page=0", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid page, got %d", w.Code)
+	}
+	if w := do("GET", "/notes?page=922337203685477581&per_page=10", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an overflowing page, got %d", w.Code)
+	}
+	if w := do("PUT", "/notes/1", `{"text":"uno"}`); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "uno") {
+		t.Errorf("Expected update to succeed, got %d %s", w.Code, w.Body.String())
+	}
+	if w := do("DELETE", "/notes/1", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", w.Code)
+	}
+	if w := do("GET", "/notes/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for deleted note, got %d", w.Code)
+	}
+	if w := do("GET", "/notes/deleted", ""); !strings.Contains(w.Body.String(), "uno") {
+		t.Errorf("Expected deleted listing to contain note, got %s", w.Body.String())
+	}
+	if w := do("POST", "/notes/1/restore", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected restore to succeed, got %d", w.Code)
+	}
+	if w := do("GET", "/notes/1", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected restored note, got %d", w.Code)
+	}
+}
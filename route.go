@@ -0,0 +1,161 @@
+package kese
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// Route is a registered route. It is returned by the route registration methods
+// so metadata can be attached fluently.
+//
+// Example:
+//
+//	app.POST("/todos", createTodo).Doc("Creates a todo").Auth("JWT")
+type Route struct {
+	// Method is the HTTP method of the route
+	Method string
+
+	// Path is the route pattern as registered, including the group prefix
+	Path string
+
+	// Description is the human-readable documentation set via Doc
+	Description string
+
+	// AuthRequirement describes how the route is protected (e.g. "JWT", "API key")
+	AuthRequirement string
+
+	// Examples are documented request/response pairs set via Example
+	Examples []Example
+
+	// RequestType is the documented type of the JSON request body, set via Request
+	RequestType reflect.Type
+
+	// QueryType is the documented type of the query string, set via Query
+	QueryType reflect.Type
+
+	// ResponseTypes are the documented response body types by status, set via Response
+	ResponseTypes map[int]reflect.Type
+
+	// meta stores arbitrary metadata consumed by middleware via c.RouteMeta
+	meta map[string]interface{}
+}
+
+// newRoute creates a Route with initialized metadata.
+func newRoute(method, path string) *Route {
+	return &Route{
+		Method: method,
+		Path:   path,
+		meta:   make(map[string]interface{}),
+	}
+}
+
+// Doc sets the human-readable documentation for the route.
+func (r *Route) Doc(description string) *Route {
+	r.Description = description
+	return r
+}
+
+// Auth documents the authentication requirement of the route.
+// This is informational only; protection is still enforced by middleware.
+func (r *Route) Auth(requirement string) *Route {
+	r.AuthRequirement = requirement
+	return r
+}
+
+// Example documents a sample request and its expected response.
+// Examples are listed by RoutesHandler and can be replayed with App.VerifyExamples.
+//
+// Example:
+//
+//	app.POST("/todos", createTodo).Example(kese.Example{
+//	    Name:     "create a todo",
+//	    Request:  map[string]interface{}{"title": "Buy milk"},
+//	    Status:   201,
+//	    Response: map[string]interface{}{"id": 1, "title": "Buy milk", "done": false},
+//	})
+func (r *Route) Example(example Example) *Route {
+	r.Examples = append(r.Examples, example)
+	return r
+}
+
+// Set stores a metadata value on the route.
+// Middleware can read it during requests with c.RouteMeta(key).
+func (r *Route) Set(key string, value interface{}) *Route {
+	r.meta[key] = value
+	return r
+}
+
+// Get returns a metadata value set on the route, or nil if not present.
+func (r *Route) Get(key string) interface{} {
+	return r.meta[key]
+}
+
+// Routes returns all registered routes sorted by path and method.
+func (a *App) Routes() []*Route {
+	routes := a.registeredRoutes()
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// registeredRoutes returns a copy of the routes in registration order.
+// Routes may be added concurrently, e.g. by plugins starting late.
+func (a *App) registeredRoutes() []*Route {
+	a.routesMu.Lock()
+	defer a.routesMu.Unlock()
+
+	routes := make([]*Route, len(a.routes))
+	copy(routes, a.routes)
+	return routes
+}
+
+// routesTemplate renders the HTML route listing.
+var routesTemplate = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Routes</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.5rem 0.75rem; border-bottom: 1px solid #ddd; }
+th { background: #f5f5f5; }
+code { font-size: 0.95em; }
+</style>
+</head>
+<body>
+<h1>Routes ({{len .}})</h1>
+<table>
+<tr><th>Method</th><th>Path</th><th>Description</th><th>Auth</th><th>Examples</th></tr>
+{{range .}}<tr><td>{{.Method}}</td><td><code>{{.Path}}</code></td><td>{{.Description}}</td><td>{{.AuthRequirement}}</td><td>{{range .Examples}}{{.Name}}<br>{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// RoutesHandler returns a handler that serves a human-readable HTML listing of
+// all registered routes with their method, path, documentation and auth requirements.
+// Mount it on an internal path, ideally behind authentication.
+//
+// Example:
+//
+//	admin.GET("/routes", app.RoutesHandler())
+func (a *App) RoutesHandler() HandlerFunc {
+	return func(c *context.Context) error {
+		var buf bytes.Buffer
+		if err := routesTemplate.Execute(&buf, a.Routes()); err != nil {
+			return err
+		}
+		return c.HTML(http.StatusOK, buf.String())
+	}
+}
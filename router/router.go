@@ -1,10 +1,18 @@
 package router
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
+// ErrFrozen is the panic value (wrapped) of Add on a frozen router.
+var ErrFrozen = errors.New("router is frozen")
+
 // Param is a single URL parameter, consisting of a key and a value.
 type Param struct {
 	Key   string
@@ -37,17 +45,66 @@ var paramsPool = sync.Pool{
 }
 
 // Router is a generic radix tree router.
+//
+// Match is lock-free and never modifies the router: it reads a snapshot of
+// the routes. During startup Add updates the routes in place; once Share has
+// been called, Add builds a new snapshot instead, copying only the nodes on
+// the path of the new route, and publishes it atomically, so routes can be
+// registered while requests are being served (e.g. by plugins that start
+// late). Call Freeze once startup is complete to make the router immutable.
 type Router[T any] struct {
+	// table is the current snapshot of the routes, replaced on every Add
+	table atomic.Pointer[table[T]]
+
+	// mu serializes Add; readers never take it
+	mu sync.Mutex
+
+	// segments interns path segments, so routes sharing segments (the same
+	// path under several methods, common prefixes such as "api" or "v1") store
+	// each string once instead of keeping every registered path alive.
+	// It is guarded by mu.
+	segments map[string]string
+
+	// shared is set by Share, once Match may run concurrently with Add
+	shared atomic.Bool
+
+	// frozen is set by Freeze
+	frozen atomic.Bool
+
+	// RedirectTrailingSlash enables redirecting "/users/" to "/users" when only
+	// the latter is registered, instead of silently matching both.
+	RedirectTrailingSlash bool
+
+	// RedirectFixedPath enables redirecting requests whose path only matches a
+	// route after cleanup: "//users/./42" becomes "/users/42" and "/USERS" is
+	// case-corrected to "/users". Paths matching routes that differ only in
+	// case, like "/Docs" and "/docs", are not case-corrected.
+	RedirectFixedPath bool
+}
+
+// table is an immutable snapshot of the registered routes. Nodes and maps
+// reachable from a published table are never modified.
+type table[T any] struct {
 	trees map[string]*node[T] // one tree per HTTP method
+
+	// static maps method -> canonical path -> handler for routes without parameters,
+	// so the common case resolves with a single map access instead of a tree walk
+	static map[string]map[string]T
 }
 
 // node represents a node in the routing tree.
 // It can represent a static path segment, a parameter, or a wildcard.
+// Chains of static segments without branches are compressed into one node,
+// so "/api/v1/admin/users" is a single node until another route branches off it.
 type node[T any] struct {
 	// path is the path segment this node represents
 	path string
 
-	// children are the static child nodes
+	// rest are the static segments that must follow path, compressed into
+	// this node because no route branches or ends between them
+	rest []string
+
+	// children are the static child nodes, nil until the first one is added
 	children map[string]*node[T]
 
 	// paramChild is the child node for a parameter (e.g., :id)
@@ -65,87 +122,211 @@ type node[T any] struct {
 
 // New creates a new Router instance.
 func New[T any]() *Router[T] {
-	return &Router[T]{
-		trees: make(map[string]*node[T]),
+	r := &Router[T]{segments: make(map[string]string)}
+	r.table.Store(&table[T]{
+		trees:  make(map[string]*node[T]),
+		static: make(map[string]map[string]T),
+	})
+	return r
+}
+
+// Share declares that Match may now run concurrently with Add, typically
+// because the server started. From then on Add publishes copy-on-write
+// snapshots instead of updating the routes in place. The first call waits
+// for an Add in progress to finish, so no in-place update overlaps Match;
+// later calls are a single atomic load.
+func (r *Router[T]) Share() {
+	if r.shared.Load() {
+		return
 	}
+	r.mu.Lock()
+	r.shared.Store(true)
+	r.mu.Unlock()
+}
+
+// Freeze makes the router immutable: later calls to Add panic with an error
+// wrapping ErrFrozen, so routes cannot change once the app is serving.
+func (r *Router[T]) Freeze() {
+	r.frozen.Store(true)
+}
+
+// Frozen reports whether Freeze has been called.
+func (r *Router[T]) Frozen() bool {
+	return r.frozen.Load()
 }
 
 // Add registers a new route with the given method, path, and handler.
 // Path can contain parameters in the format ":paramName" (e.g., "/users/:id").
+// It is safe to call concurrently with other calls to Add, and with Match
+// once Share has been called. It panics once the router is frozen.
 func (r *Router[T]) Add(method, path string, handler T) {
-	// Get or create the tree for this HTTP method
-	root, exists := r.trees[method]
-	if !exists {
-		root = &node[T]{
-			path:     "/",
-			children: make(map[string]*node[T]),
+	if r.frozen.Load() {
+		panic(fmt.Errorf("%w: cannot add %s %s", ErrFrozen, method, path))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Once shared, build the next snapshot; untouched methods and subtrees
+	// are shared with the current one
+	copyOnWrite := r.shared.Load()
+	next := r.table.Load()
+	if copyOnWrite {
+		next = &table[T]{
+			trees:  maps.Clone(next.trees),
+			static: maps.Clone(next.static),
 		}
-		r.trees[method] = root
 	}
 
-	// If path is just "/", register at root
-	if path == "/" {
-		root.handler = handler
-		root.isLeaf = true
-		return
+	// Get or copy the tree for this HTTP method
+	root := next.trees[method]
+	switch {
+	case root == nil:
+		root = &node[T]{path: "/"}
+	case copyOnWrite:
+		root = root.clone()
 	}
+	next.trees[method] = root
 
-	// Split path into segments
+	// Split path into segments, sharing storage with routes registered before
 	segments := splitPath(path)
-	current := root
-
-	// Traverse/build the tree
 	for i, segment := range segments {
-		isLast := i == len(segments)-1
+		segments[i] = r.intern(segment)
+	}
 
-		// Check if this is a parameter segment
-		if strings.HasPrefix(segment, ":") {
-			paramName := segment[1:] // remove the ":"
-
-			// Create or get param child
-			if current.paramChild == nil {
-				current.paramChild = &node[T]{
-					path:      segment,
-					paramName: paramName,
-					children:  make(map[string]*node[T]),
-				}
-			}
+	// Record parameterless routes in the static fast-path map
+	if !hasParams(segments) {
+		methodRoutes := next.static[method]
+		if copyOnWrite {
+			methodRoutes = maps.Clone(methodRoutes)
+		}
+		if methodRoutes == nil {
+			methodRoutes = make(map[string]T)
+		}
+		methodRoutes["/"+strings.Join(segments, "/")] = handler
+		next.static[method] = methodRoutes
+	}
+
+	parent := root
 
-			current = current.paramChild
+	// Traverse/build the tree, copying each node on the way
+	for i := 0; i < len(segments); {
+		segment := segments[i]
 
-			if isLast {
-				current.handler = handler
-				current.isLeaf = true
+		// The static segments following this one can be compressed into its node
+		end := i + 1
+		for end < len(segments) && !strings.HasPrefix(segments[end], ":") {
+			end++
+		}
+		following := segments[i+1 : end]
+
+		var child *node[T]
+		if strings.HasPrefix(segment, ":") {
+			child = parent.paramChild
+			if child == nil {
+				child = &node[T]{path: segment, paramName: segment[1:], rest: following}
 			}
 		} else {
-			// Static segment
-			child, exists := current.children[segment]
-			if !exists {
-				child = &node[T]{
-					path:     segment,
-					children: make(map[string]*node[T]),
-				}
-				current.children[segment] = child
+			child = parent.children[segment]
+			if child == nil {
+				child = &node[T]{path: segment, rest: following}
 			}
+		}
 
-			current = child
+		// Split the compressed chain where this route leaves it
+		shared := commonPrefix(child.rest, following)
+		if shared < len(child.rest) {
+			child = child.split(shared)
+		} else if copyOnWrite {
+			child = child.clone()
+		}
 
-			if isLast {
-				current.handler = handler
-				current.isLeaf = true
+		if strings.HasPrefix(segment, ":") {
+			parent.paramChild = child
+		} else {
+			if parent.children == nil {
+				parent.children = make(map[string]*node[T])
 			}
+			parent.children[segment] = child
 		}
+
+		parent = child
+		i += 1 + len(child.rest)
+	}
+
+	parent.handler = handler
+	parent.isLeaf = true
+
+	r.table.Store(next)
+}
+
+// clone returns a copy of the node that can be modified without affecting
+// published snapshots. Children are shared; only the map holding them is copied.
+func (n *node[T]) clone() *node[T] {
+	copied := *n
+	copied.children = maps.Clone(n.children)
+	return &copied
+}
+
+// intern returns the shared copy of a path segment.
+func (r *Router[T]) intern(segment string) string {
+	if shared, exists := r.segments[segment]; exists {
+		return shared
+	}
+	// Clone so the segment doesn't keep the whole registered path alive
+	shared := strings.Clone(segment)
+	r.segments[shared] = shared
+	return shared
+}
+
+// split divides a compressed node after its first k rest segments and
+// returns the new upper node, which takes the node's place in the tree.
+// The node itself is left unchanged.
+func (n *node[T]) split(k int) *node[T] {
+	lower := &node[T]{
+		path:       n.rest[k],
+		rest:       n.rest[k+1:],
+		children:   n.children,
+		paramChild: n.paramChild,
+		handler:    n.handler,
+		isLeaf:     n.isLeaf,
+	}
+	return &node[T]{
+		path:      n.path,
+		paramName: n.paramName,
+		rest:      n.rest[:k:k],
+		children:  map[string]*node[T]{lower.path: lower},
 	}
 }
 
+// commonPrefix returns the number of leading segments a and b share.
+func commonPrefix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
 // Match finds a handler that matches the given method and path.
 // It returns the handler and any extracted parameters.
+// Routes without parameters are resolved from a flat map before walking the tree.
 // The third return value indicates whether a match was found.
-// Uses a sync.Pool to reduce allocations for better performance.
+// Uses a sync.Pool to reduce allocations for better performance; the
+// returned Params are a copy owned by the caller.
+// Match never modifies the router and is safe for concurrent use with
+// other calls to Match, and with Add once Share has returned.
 func (r *Router[T]) Match(method, path string) (T, Params, bool) {
 	var zero T
+	routes := r.table.Load()
+
+	// Fast path: exact match on a route without parameters
+	if handler, exists := routes.static[method][path]; exists {
+		return handler, nil, true
+	}
+
 	// Get the tree for this HTTP method
-	root, exists := r.trees[method]
+	root, exists := routes.trees[method]
 	if !exists {
 		return zero, nil, false
 	}
@@ -172,23 +353,31 @@ func (r *Router[T]) Match(method, path string) (T, Params, bool) {
 	current := root
 
 	// Traverse the tree
-	for _, segment := range segments {
-		// Try static match first
-		if child, exists := current.children[segment]; exists {
-			current = child
-			continue
+	for i := 0; i < len(segments); {
+		segment := segments[i]
+
+		// Try static match first, then parameter match
+		next, exists := current.children[segment]
+		if !exists {
+			next = current.paramChild
+			if next == nil {
+				// No match found
+				paramsPool.Put(paramsPtr)
+				return zero, nil, false
+			}
+			params = append(params, Param{Key: next.paramName, Value: segment})
 		}
+		i++
 
-		// Try parameter match
-		if current.paramChild != nil {
-			params = append(params, Param{Key: current.paramChild.paramName, Value: segment})
-			current = current.paramChild
-			continue
+		// A compressed node also consumes its chained static segments
+		for _, part := range next.rest {
+			if i >= len(segments) || segments[i] != part {
+				paramsPool.Put(paramsPtr)
+				return zero, nil, false
+			}
+			i++
 		}
-
-		// No match found
-		paramsPool.Put(paramsPtr)
-		return zero, nil, false
+		current = next
 	}
 
 	// Check if we're at a leaf node
@@ -204,6 +393,216 @@ func (r *Router[T]) Match(method, path string) (T, Params, bool) {
 	return zero, nil, false
 }
 
+// Stats describes the size and shape of a Router's trees.
+type Stats struct {
+	// Routes is the number of registered routes, across all methods
+	Routes int
+
+	// Nodes is the number of tree nodes, including one root per method
+	Nodes int
+
+	// CompressedNodes is the number of nodes holding a chain of static segments
+	CompressedNodes int
+
+	// Segments is the number of nodes the trees would have without compression
+	Segments int
+
+	// MaxDepth is the largest number of nodes walked to match a route
+	MaxDepth int
+
+	// InternedSegments is the number of distinct path segment strings stored
+	InternedSegments int
+}
+
+// Stats reports node counts and depth of the routing trees, to size the
+// router of apps with many routes.
+//
+// Example:
+//
+//	stats := app.Router().Stats()
+//	log.Printf("%d routes in %d nodes, depth %d", stats.Routes, stats.Nodes, stats.MaxDepth)
+func (r *Router[T]) Stats() Stats {
+	r.mu.Lock()
+	stats := Stats{InternedSegments: len(r.segments)}
+	r.mu.Unlock()
+
+	for _, root := range r.table.Load().trees {
+		root.collectStats(&stats, 0)
+	}
+	return stats
+}
+
+// collectStats adds the node and its descendants to stats.
+func (n *node[T]) collectStats(stats *Stats, depth int) {
+	stats.Nodes++
+	stats.Segments += 1 + len(n.rest)
+	if len(n.rest) > 0 {
+		stats.CompressedNodes++
+	}
+	if n.isLeaf {
+		stats.Routes++
+	}
+	if depth > stats.MaxDepth {
+		stats.MaxDepth = depth
+	}
+
+	for _, child := range n.children {
+		child.collectStats(stats, depth+1)
+	}
+	if n.paramChild != nil {
+		n.paramChild.collectStats(stats, depth+1)
+	}
+}
+
+// RedirectPath returns the canonical location for a request path when one of the
+// redirect options is enabled and the path only matches a route after being fixed.
+// Paths that already match a route only have their slashes canonicalized, so
+// the redirect leads to the route the path matched.
+// The second return value is false when no redirect should be issued.
+func (r *Router[T]) RedirectPath(method, reqPath string) (string, bool) {
+	if !r.RedirectTrailingSlash && !r.RedirectFixedPath {
+		return "", false
+	}
+
+	root, exists := r.table.Load().trees[method]
+	if !exists || reqPath == "/" {
+		return "", false
+	}
+
+	// A path that routes as written keeps its route: it is never
+	// case-corrected, e.g. "/users/ME" stays on "/users/:id" rather than
+	// being sent to "/users/me", and only its slashes are canonicalized
+	if _, _, found := r.Match(method, reqPath); found {
+		canonical := r.canonicalSlashes(reqPath)
+		return canonical, canonical != reqPath
+	}
+
+	candidate := reqPath
+	if r.RedirectFixedPath {
+		candidate = cleanPath(candidate)
+	}
+
+	if r.RedirectTrailingSlash && len(candidate) > 1 {
+		candidate = "/" + strings.Trim(candidate, "/")
+	}
+
+	if r.RedirectFixedPath {
+		if fixed, ok := root.findCaseInsensitive(splitPath(candidate)); ok {
+			if strings.HasSuffix(candidate, "/") && fixed != "/" {
+				fixed += "/"
+			}
+			candidate = fixed
+		}
+	}
+
+	if candidate == reqPath {
+		return "", false
+	}
+
+	if _, _, found := r.Match(method, candidate); !found {
+		return "", false
+	}
+
+	return candidate, true
+}
+
+// canonicalSlashes returns p with repeated slashes collapsed, if
+// RedirectFixedPath is set, and its trailing slash removed, if
+// RedirectTrailingSlash is set. The result has the same segments as p, so it
+// matches the same route.
+func (r *Router[T]) canonicalSlashes(p string) string {
+	canonical := p
+	if r.RedirectFixedPath {
+		canonical = "/" + strings.Join(splitPath(p), "/")
+		if strings.HasSuffix(p, "/") && canonical != "/" {
+			canonical += "/"
+		}
+	}
+	if r.RedirectTrailingSlash {
+		canonical = strings.TrimRight(canonical, "/")
+		if canonical == "" {
+			canonical = "/"
+		}
+	}
+	return canonical
+}
+
+// findCaseInsensitive walks the tree matching static segments without regard to
+// case and returns the path rebuilt with the registered spelling of each segment.
+// A segment matching several registered spellings (e.g. "/Docs" and "/docs")
+// is ambiguous, so no path is returned rather than picking one at random.
+func (n *node[T]) findCaseInsensitive(segments []string) (string, bool) {
+	current := n
+	fixed := make([]string, 0, len(segments))
+
+	for i := 0; i < len(segments); {
+		segment := segments[i]
+
+		next, exists := current.children[segment]
+		if !exists {
+			for key, child := range current.children {
+				if !strings.EqualFold(key, segment) {
+					continue
+				}
+				if next != nil {
+					return "", false
+				}
+				next = child
+			}
+		}
+
+		switch {
+		case next != nil:
+			fixed = append(fixed, next.path)
+		case current.paramChild != nil:
+			next = current.paramChild
+			fixed = append(fixed, segment)
+		default:
+			return "", false
+		}
+		i++
+
+		for _, part := range next.rest {
+			if i >= len(segments) || !strings.EqualFold(segments[i], part) {
+				return "", false
+			}
+			fixed = append(fixed, part)
+			i++
+		}
+		current = next
+	}
+
+	if !current.isLeaf {
+		return "", false
+	}
+
+	return "/" + strings.Join(fixed, "/"), true
+}
+
+// cleanPath resolves "." and ".." elements and collapses repeated slashes,
+// preserving a trailing slash so trailing-slash handling stays a separate option.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// hasParams reports whether any segment is a parameter.
+func hasParams(segments []string) bool {
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			return true
+		}
+	}
+	return false
+}
+
 // splitPath splits a path into segments, removing empty segments.
 // For example: "/users/:id/posts" -> ["users", ":id", "posts"]
 func splitPath(path string) []string {
@@ -1,6 +1,8 @@
 package router
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -12,7 +14,7 @@ func TestNew(t *testing.T) {
 	if r == nil {
 		t.Fatal("New() returned nil")
 	}
-	if r.trees == nil {
+	if r.table.Load().trees == nil {
 		t.Fatal("Router trees not initialized")
 	}
 }
@@ -221,3 +223,248 @@ func TestComplexRouting(t *testing.T) {
 		}
 	}
 }
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/users", mockHandler)
+
+	if _, ok := r.RedirectPath("GET", "/users/"); ok {
+		t.Fatal("Should not redirect when RedirectTrailingSlash is disabled")
+	}
+
+	r.RedirectTrailingSlash = true
+
+	location, ok := r.RedirectPath("GET", "/users/")
+	if !ok || location != "/users" {
+		t.Errorf("Expected redirect to /users, got %q (ok=%v)", location, ok)
+	}
+
+	if _, ok := r.RedirectPath("GET", "/users"); ok {
+		t.Error("Canonical path should not redirect")
+	}
+
+	if _, ok := r.RedirectPath("GET", "/posts/"); ok {
+		t.Error("Should not redirect to an unregistered route")
+	}
+}
+
+func TestRedirectFixedPath(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/users/:id/posts", mockHandler)
+	r.Add("GET", "/Docs", mockHandler)
+	r.Add("GET", "/docs", mockHandler)
+	r.RedirectFixedPath = true
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"//users//42/posts", "/users/42/posts"},
+		{"/USERS/42/Posts", "/users/42/posts"},
+		{"/users/./42/../42/posts", "/users/42/posts"},
+		{"/users/AbC/posts", ""},
+		{"/DOCS", ""}, // ambiguous between /Docs and /docs
+		{"/docs", ""},
+	}
+
+	for _, test := range tests {
+		location, ok := r.RedirectPath("GET", test.path)
+		if test.expected == "" {
+			if ok {
+				t.Errorf("%s: expected no redirect, got %q", test.path, location)
+			}
+			continue
+		}
+		if !ok || location != test.expected {
+			t.Errorf("%s: expected redirect to %q, got %q (ok=%v)", test.path, test.expected, location, ok)
+		}
+	}
+}
+
+func TestRedirectKeepsMatchedRoute(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/users", "list")
+	r.Add("GET", "/users/me", "me")
+	r.Add("GET", "/users/:id", "user")
+	r.RedirectTrailingSlash = true
+	r.RedirectFixedPath = true
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/users/ME", ""}, // routes to /users/:id, not case-corrected to /users/me
+		{"/users/me", ""},
+		{"/users/ME/", "/users/ME"},
+		{"//users//ME", "/users/ME"},
+		{"/USERS", "/users"},
+	}
+	for _, test := range tests {
+		location, ok := r.RedirectPath("GET", test.path)
+		if test.expected == "" {
+			if ok {
+				t.Errorf("%s: expected no redirect, got %q", test.path, location)
+			}
+			continue
+		}
+		if !ok || location != test.expected {
+			t.Errorf("%s: expected redirect to %q, got %q (ok=%v)", test.path, test.expected, location, ok)
+		}
+		if handler, _, _ := r.Match("GET", location); handler == "" {
+			t.Errorf("%s: redirect target %q does not match", test.path, location)
+		}
+	}
+}
+
+func TestStaticFastPath(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/healthz/", "health")
+	r.Add("GET", "/users/:id", "user")
+
+	handler, params, found := r.Match("GET", "/healthz")
+	if !found || handler != "health" {
+		t.Fatalf("Expected static route to match, got %q", handler)
+	}
+	if len(params) != 0 {
+		t.Errorf("Expected no params, got %d", len(params))
+	}
+
+	if _, exists := r.table.Load().static["GET"]["/users/:id"]; exists {
+		t.Error("Parameterized routes should not be added to the static map")
+	}
+	if _, _, found := r.Match("POST", "/healthz"); found {
+		t.Error("Static fast path should respect the method")
+	}
+}
+
+func TestCompressedRoutes(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/api/v1/admin/users", "users")
+	r.Add("GET", "/api/v1/admin/users/:id/roles", "roles")
+	r.Add("GET", "/api/v1", "v1")
+	r.Add("GET", "/api/v1/admin/settings", "settings")
+	r.Add("GET", "/api/:version/status", "status")
+
+	tests := []struct {
+		path    string
+		handler string
+		found   bool
+	}{
+		{"/api/v1/admin/users", "users", true},
+		{"/api/v1/admin/users/7/roles", "roles", true},
+		{"/api/v1", "v1", true},
+		{"/api/v1/admin/settings", "settings", true},
+		{"/api/v2/status", "status", true},
+		{"/api/v1/admin", "", false},
+		{"/api/v1/admin/users/7", "", false},
+		{"/api/v1/admin/users/7/roles/x", "", false},
+	}
+
+	for _, test := range tests {
+		handler, _, found := r.Match("GET", test.path)
+		if found != test.found || handler != test.handler {
+			t.Errorf("%s: expected %q (found=%v), got %q (found=%v)", test.path, test.handler, test.found, handler, found)
+		}
+	}
+
+	_, params, _ := r.Match("GET", "/api/v1/admin/users/7/roles")
+	if params.Get("id") != "7" {
+		t.Errorf("Expected id=7, got %q", params.Get("id"))
+	}
+}
+
+func TestStats(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/api/v1/admin/users", "users")
+	r.Add("POST", "/api/v1/admin/users", "create")
+	r.Add("GET", "/api/v1/admin/users/:id", "user")
+
+	stats := r.Stats()
+	if stats.Routes != 3 {
+		t.Errorf("Expected 3 routes, got %d", stats.Routes)
+	}
+	// GET: root, api/v1/admin/users, :id; POST: root, api/v1/admin/users
+	if stats.Nodes != 5 || stats.CompressedNodes != 2 || stats.MaxDepth != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.Segments != 11 {
+		t.Errorf("Expected 11 uncompressed nodes, got %d", stats.Segments)
+	}
+	if stats.InternedSegments != 5 {
+		t.Errorf("Expected 5 interned segments, got %d", stats.InternedSegments)
+	}
+}
+
+func TestConcurrentAdd(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/users/:id", "user")
+	r.Share()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			r.Add("GET", fmt.Sprintf("/plugins/p%d/status", i), "plugin")
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		if handler, _, found := r.Match("GET", "/users/7"); !found || handler != "user" {
+			t.Fatalf("Existing route lost during concurrent Add")
+		}
+		r.Match("GET", "/plugins/p5/status")
+	}
+	<-done
+
+	if _, _, found := r.Match("GET", "/plugins/p199/status"); !found {
+		t.Error("Expected routes added concurrently to match")
+	}
+}
+
+func TestShareDuringAdd(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/users/:id", "user")
+
+	// Routes added in place until Share, then copy-on-write
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			r.Add("GET", fmt.Sprintf("/plugins/p%d/:id", i), "plugin")
+		}
+	}()
+
+	r.Share()
+	for i := 0; i < 1000; i++ {
+		if handler, _, found := r.Match("GET", "/users/7"); !found || handler != "user" {
+			t.Fatalf("Existing route lost while sharing")
+		}
+		r.Match("GET", "/plugins/p5/1")
+	}
+	<-done
+
+	// Params are owned by the caller
+	_, params, _ := r.Match("GET", "/users/7")
+	params[0].Value = "changed"
+	if _, again, _ := r.Match("GET", "/users/8"); again.Get("id") != "8" || params.Get("id") != "changed" {
+		t.Error("Expected each Match to return its own Params")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	r := New[string]()
+	r.Add("GET", "/users", "users")
+	r.Freeze()
+
+	if _, _, found := r.Match("GET", "/users"); !found || !r.Frozen() {
+		t.Fatal("Expected frozen router to keep matching")
+	}
+
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrFrozen) {
+			t.Errorf("Expected ErrFrozen panic, got %v", err)
+		}
+	}()
+	r.Add("GET", "/late", "late")
+}
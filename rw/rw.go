@@ -0,0 +1,246 @@
+// Package rw provides the http.ResponseWriter wrappers shared by the framework
+// and its middleware: a Recorder that tracks the status and size of a
+// response, a Buffer that holds a response back so it can be rewritten, and a
+// Wrapper that redirects the body or hooks the moment headers are sent.
+//
+// All wrappers forward http.Flusher and expose Unwrap, so
+// http.ResponseController reaches the underlying connection through them.
+package rw
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// ErrHijackNotSupported is returned by Hijack when the underlying writer cannot be hijacked.
+var ErrHijackNotSupported = errors.New("response writer does not support hijacking")
+
+// Recorder wraps an http.ResponseWriter and records the status code and
+// number of body bytes written.
+type Recorder struct {
+	http.ResponseWriter
+	status  int
+	size    int64
+	written atomic.Bool
+}
+
+// NewRecorder returns a Recorder writing to w.
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w}
+}
+
+// Reset prepares the Recorder for a new response written to w.
+func (r *Recorder) Reset(w http.ResponseWriter) {
+	*r = Recorder{ResponseWriter: w}
+}
+
+// WriteHeader records the status code and forwards it.
+// Informational (1xx) statuses are forwarded without marking the response written.
+func (r *Recorder) WriteHeader(statusCode int) {
+	if !r.written.Load() && statusCode >= 200 {
+		r.status = statusCode
+		r.written.Store(true)
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the number of bytes written, sending a 200 status first if needed.
+func (r *Recorder) Write(b []byte) (int, error) {
+	if !r.written.Load() {
+		r.WriteHeader(http.StatusOK)
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+// Status returns the status code sent, or 0 if nothing was written yet.
+func (r *Recorder) Status() int {
+	return r.status
+}
+
+// Size returns the number of body bytes written.
+func (r *Recorder) Size() int64 {
+	return r.size
+}
+
+// Written reports whether the status code has been sent.
+// It is safe to call from other goroutines.
+func (r *Recorder) Written() bool {
+	return r.written.Load()
+}
+
+// Flush implements http.Flusher when the underlying writer supports it.
+func (r *Recorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		if !r.written.Load() {
+			r.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer supports it.
+func (r *Recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(r.ResponseWriter)
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Buffer captures a response in memory instead of sending it. Headers are
+// shared with the underlying writer; the status and body are held back until
+// Commit, so they can be inspected and rewritten.
+type Buffer struct {
+	// Body is the captured response body. It may be read or replaced before Commit.
+	Body *bytes.Buffer
+
+	w      http.ResponseWriter
+	status int
+}
+
+// NewBuffer returns a Buffer that commits to w.
+func NewBuffer(w http.ResponseWriter) *Buffer {
+	return &Buffer{Body: &bytes.Buffer{}, w: w}
+}
+
+// Header returns the response headers of the underlying writer.
+func (b *Buffer) Header() http.Header {
+	return b.w.Header()
+}
+
+// WriteHeader records the status code without sending it.
+func (b *Buffer) WriteHeader(statusCode int) {
+	if b.status == 0 && statusCode >= 200 {
+		b.status = statusCode
+	}
+}
+
+// Write appends to the buffered body.
+func (b *Buffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.Body.Write(p)
+}
+
+// Flush is a no-op: a buffered response is sent in one piece by Commit.
+func (b *Buffer) Flush() {}
+
+// Status returns the captured status code, or 0 if nothing was written.
+func (b *Buffer) Status() int {
+	return b.status
+}
+
+// SetStatus replaces the status code that Commit sends.
+func (b *Buffer) SetStatus(statusCode int) {
+	b.status = statusCode
+}
+
+// Written reports whether a response was captured.
+func (b *Buffer) Written() bool {
+	return b.status != 0
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (b *Buffer) Unwrap() http.ResponseWriter {
+	return b.w
+}
+
+// Commit sends the captured status and body to the underlying writer,
+// updating Content-Length if one was set. It does nothing if nothing was captured.
+func (b *Buffer) Commit() error {
+	if b.status == 0 {
+		return nil
+	}
+
+	header := b.Header()
+	if header.Get("Content-Length") != "" {
+		header.Set("Content-Length", strconv.Itoa(b.Body.Len()))
+	}
+
+	b.w.WriteHeader(b.status)
+	_, err := b.w.Write(b.Body.Bytes())
+	return err
+}
+
+// Wrapper passes a response through to the underlying writer, optionally
+// sending the body through another writer (such as a compressor) and running
+// a hook just before the headers are sent.
+type Wrapper struct {
+	http.ResponseWriter
+
+	// Body receives the response body instead of the underlying writer (optional).
+	// If it has a Flush() error method, Flush calls it first.
+	Body io.Writer
+
+	// OnWriteHeader runs once, just before the status is sent, and may still
+	// change the headers (optional)
+	OnWriteHeader func(statusCode int)
+
+	wroteHeader bool
+}
+
+// WriteHeader runs OnWriteHeader and sends the status once.
+func (w *Wrapper) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.OnWriteHeader != nil {
+		w.OnWriteHeader(statusCode)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write sends a 200 status first if needed, then writes to Body or the underlying writer.
+func (w *Wrapper) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.Body != nil {
+		return w.Body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes Body, if it supports flushing, and then the underlying writer,
+// so streaming responses are delivered incrementally.
+func (w *Wrapper) Flush() {
+	if flusher, ok := w.Body.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer supports it.
+func (w *Wrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *Wrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// hijack takes over the connection of w if it supports hijacking.
+func hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrHijackNotSupported
+	}
+	return hijacker.Hijack()
+}
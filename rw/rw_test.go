@@ -0,0 +1,105 @@
+package rw
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	interim := NewRecorder(httptest.NewRecorder())
+	interim.WriteHeader(http.StatusContinue)
+	if interim.Written() {
+		t.Error("Expected 1xx status not to mark the response written")
+	}
+
+	rec := NewRecorder(httptest.NewRecorder())
+	rec.Write([]byte("hello"))
+	rec.WriteHeader(http.StatusTeapot)
+	if rec.Status() != http.StatusOK || rec.Size() != 5 || !rec.Written() {
+		t.Errorf("Unexpected state: status=%d size=%d", rec.Status(), rec.Size())
+	}
+
+	rec.Reset(httptest.NewRecorder())
+	if rec.Written() || rec.Size() != 0 {
+		t.Error("Expected Reset to clear the recorded state")
+	}
+
+	if _, _, err := rec.Hijack(); err != ErrHijackNotSupported {
+		t.Errorf("Expected ErrHijackNotSupported, got %v", err)
+	}
+	if rec.Unwrap() == nil {
+		t.Error("Expected Unwrap to return the underlying writer")
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	w := httptest.NewRecorder()
+	buf := NewBuffer(w)
+
+	buf.Header().Set("Content-Length", "3")
+	buf.WriteHeader(http.StatusCreated)
+	buf.Write([]byte("abc"))
+	buf.Flush()
+
+	if w.Body.Len() != 0 || w.Code != http.StatusOK {
+		t.Fatal("Expected nothing to reach the client before Commit")
+	}
+
+	buf.Body.WriteString("def")
+	buf.SetStatus(http.StatusAccepted)
+	if err := buf.Commit(); err != nil {
+		t.Fatalf("Commit error: %v", err)
+	}
+	if w.Code != http.StatusAccepted || w.Body.String() != "abcdef" || w.Header().Get("Content-Length") != "6" {
+		t.Errorf("Unexpected response: %d %q %s", w.Code, w.Body.String(), w.Header().Get("Content-Length"))
+	}
+
+	empty := NewBuffer(httptest.NewRecorder())
+	if err := empty.Commit(); err != nil || empty.Written() {
+		t.Error("Expected Commit without a response to do nothing")
+	}
+}
+
+func TestWrapper(t *testing.T) {
+	w := httptest.NewRecorder()
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+
+	calls := 0
+	wrapper := &Wrapper{
+		ResponseWriter: w,
+		Body:           gz,
+		OnWriteHeader: func(status int) {
+			calls++
+			w.Header().Set("X-Status", http.StatusText(status))
+		},
+	}
+
+	wrapper.Write([]byte("hello"))
+	wrapper.WriteHeader(http.StatusNotFound)
+	wrapper.Flush()
+
+	if calls != 1 || w.Header().Get("X-Status") != "OK" {
+		t.Errorf("Expected OnWriteHeader once with 200, got %d calls (%q)", calls, w.Header().Get("X-Status"))
+	}
+	if !w.Flushed {
+		t.Error("Expected Flush to reach the underlying writer")
+	}
+
+	gz.Close()
+	reader, err := gzip.NewReader(&compressed)
+	if err != nil {
+		t.Fatalf("gzip error: %v", err)
+	}
+	body, _ := io.ReadAll(reader)
+	if string(body) != "hello" {
+		t.Errorf("Expected body to go through Body writer, got %q", body)
+	}
+
+	var _ http.Flusher = wrapper
+	var _ http.Hijacker = wrapper
+}
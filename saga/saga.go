@@ -0,0 +1,350 @@
+// Package saga runs operations spanning several services as a sequence of
+// steps, each with a compensating action that undoes it. When a step fails,
+// the steps completed before it are compensated in reverse order, so a
+// checkout that reserved stock and charged a card but could not create the
+// shipment releases the stock and refunds the card.
+//
+// Steps are retried according to their RetryPolicy. With a Store, the
+// progress of each execution is saved after every step, so executions
+// interrupted by a crash can be finished or compensated with Recover.
+//
+// Example:
+//
+//	checkout := saga.New("checkout",
+//	    saga.Step{Name: "reserve", Action: reserveStock, Compensate: releaseStock},
+//	    saga.Step{Name: "charge", Action: chargeCard, Compensate: refundCard,
+//	        Retry: saga.RetryPolicy{Attempts: 3, Backoff: 200 * time.Millisecond}},
+//	    saga.Step{Name: "notify", Action: sendConfirmation},
+//	)
+//
+//	app.POST("/orders/:id/checkout", func(c *context.Context) error {
+//	    ex, err := checkout.Run(c.Context(), c.RequestID(), map[string]interface{}{"order": c.Param("id")})
+//	    if err != nil {
+//	        return kese.ErrConflict.WithMessage("checkout failed").WithInternal(err)
+//	    }
+//	    return c.JSON(200, ex.Data)
+//	})
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JedizLaPulga/kese/store"
+)
+
+// ErrCompensationFailed is wrapped by the error of executions whose
+// compensation failed; they need manual repair.
+var ErrCompensationFailed = errors.New("saga: compensation failed")
+
+// Status is the state of an execution.
+type Status string
+
+const (
+	// StatusRunning executions are performing their steps
+	StatusRunning Status = "running"
+
+	// StatusCompleted executions performed every step
+	StatusCompleted Status = "completed"
+
+	// StatusCompensating executions had a step fail and are undoing the
+	// completed steps
+	StatusCompensating Status = "compensating"
+
+	// StatusCompensated executions had a step fail and undid the others
+	StatusCompensated Status = "compensated"
+
+	// StatusFailed executions could not undo a step
+	StatusFailed Status = "failed"
+)
+
+// Step is one step of a saga.
+type Step struct {
+	// Name identifies the step in executions and errors
+	Name string
+
+	// Action performs the step. It may store results needed by later steps
+	// or by Compensate in ex.Data, e.g. a reservation ID.
+	Action func(ctx context.Context, ex *Execution) error
+
+	// Compensate undoes Action; nil for steps that need no undo, such as a
+	// final notification. It must be safe to call again if it failed.
+	Compensate func(ctx context.Context, ex *Execution) error
+
+	// Retry is how Action and Compensate are retried. Default: the saga's
+	// Config.Retry
+	Retry RetryPolicy
+}
+
+// RetryPolicy configures retries of failing actions.
+type RetryPolicy struct {
+	// Attempts is how often an action is tried in total. Default: 1
+	Attempts int
+
+	// Backoff is the wait before the first retry, doubling for each further
+	// retry. Default: 100 milliseconds
+	Backoff time.Duration
+
+	// MaxBackoff caps the wait between retries. Default: 10 seconds
+	MaxBackoff time.Duration
+}
+
+// Execution is the persisted state of one run of a saga.
+type Execution struct {
+	// ID identifies the execution, e.g. an order or request ID
+	ID string `json:"id"`
+
+	// Saga is the name of the saga
+	Saga string `json:"saga"`
+
+	// Status is the state of the execution
+	Status Status `json:"status"`
+
+	// Completed lists the names of the steps performed, in order
+	Completed []string `json:"completed"`
+
+	// FailedStep and Error describe the step whose failure started the
+	// compensation
+	FailedStep string `json:"failed_step,omitempty"`
+	Error      string `json:"error,omitempty"`
+
+	// Data is shared by the steps; keep values serializable when the Store
+	// persists them outside the process
+	Data map[string]interface{} `json:"data"`
+
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StepError is returned by Run when a step failed and the saga was
+// compensated.
+type StepError struct {
+	Step string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *StepError) Error() string {
+	return fmt.Sprintf("saga step %q failed: %v", e.Step, e.Err)
+}
+
+// Unwrap returns the error of the step.
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Config holds configuration for a Saga.
+type Config struct {
+	// Store persists executions, keyed by ID. Default: nil (not persisted)
+	Store store.Store[string, Execution]
+
+	// Retry is the retry policy of steps without their own. Default: no retries
+	Retry RetryPolicy
+}
+
+// Saga is a sequence of steps with compensations. It is safe for concurrent
+// use; each Run is an independent execution.
+type Saga struct {
+	name   string
+	steps  []Step
+	config Config
+}
+
+// New returns a saga performing steps in order, without persistence.
+func New(name string, steps ...Step) *Saga {
+	return NewWithConfig(name, Config{}, steps...)
+}
+
+// NewWithConfig returns a saga with custom configuration. It panics if a
+// step has no Name or Action, or two steps share a name.
+func NewWithConfig(name string, config Config, steps ...Step) *Saga {
+	seen := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		if step.Name == "" || step.Action == nil {
+			panic("saga: " + name + ": every step needs a Name and an Action")
+		}
+		if seen[step.Name] {
+			panic("saga: " + name + ": duplicate step " + step.Name)
+		}
+		seen[step.Name] = true
+	}
+	return &Saga{name: name, steps: steps, config: config}
+}
+
+// Run performs the steps for a new execution with id and initial data. If
+// a step fails, the completed steps are compensated and a *StepError is
+// returned; if compensating fails too, the error wraps
+// ErrCompensationFailed. Compensation continues when ctx is cancelled, so a
+// client hanging up does not leave the saga half done.
+func (s *Saga) Run(ctx context.Context, id string, data map[string]interface{}) (*Execution, error) {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	now := time.Now()
+	ex := &Execution{
+		ID:        id,
+		Saga:      s.name,
+		Status:    StatusRunning,
+		Data:      data,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.save(ctx, ex); err != nil {
+		return ex, err
+	}
+	return ex, s.resume(ctx, ex)
+}
+
+// Resume finishes the stored execution id after an interruption: running
+// executions continue with their next step, compensating ones finish
+// compensating. Finished executions are returned unchanged. It requires a
+// Store.
+func (s *Saga) Resume(ctx context.Context, id string) (*Execution, error) {
+	if s.config.Store == nil {
+		return nil, errors.New("saga: " + s.name + ": Resume requires a Store")
+	}
+	stored, err := s.config.Store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ex := &stored
+	if ex.Data == nil {
+		ex.Data = make(map[string]interface{})
+	}
+	return ex, s.resume(ctx, ex)
+}
+
+// Recover resumes every stored execution of this saga that was interrupted,
+// e.g. at startup after a crash. Each execution must be recovered by one
+// instance only; see the leader package. It returns the errors of the
+// executions that failed again.
+func (s *Saga) Recover(ctx context.Context) error {
+	if s.config.Store == nil {
+		return errors.New("saga: " + s.name + ": Recover requires a Store")
+	}
+
+	var pending []string
+	for offset := 0; ; offset += 100 {
+		page, total, err := s.config.Store.List(ctx, offset, 100)
+		if err != nil {
+			return err
+		}
+		for _, ex := range page {
+			if ex.Saga == s.name && (ex.Status == StatusRunning || ex.Status == StatusCompensating) {
+				pending = append(pending, ex.ID)
+			}
+		}
+		if len(page) == 0 || offset+len(page) >= total {
+			break
+		}
+	}
+
+	var errs []error
+	for _, id := range pending {
+		if _, err := s.Resume(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("execution %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resume continues ex from its current status.
+func (s *Saga) resume(ctx context.Context, ex *Execution) error {
+	if ex.Status == StatusRunning {
+		for _, step := range s.steps[len(ex.Completed):] {
+			if err := s.retry(ctx, step, step.Action, ex); err != nil {
+				ex.Status = StatusCompensating
+				ex.FailedStep = step.Name
+				ex.Error = err.Error()
+				break
+			}
+			ex.Completed = append(ex.Completed, step.Name)
+			if err := s.save(ctx, ex); err != nil {
+				return err
+			}
+		}
+		if ex.Status == StatusRunning {
+			ex.Status = StatusCompleted
+			return s.save(ctx, ex)
+		}
+	}
+	if ex.Status != StatusCompensating {
+		return nil
+	}
+
+	// Undo even when the request was cancelled
+	ctx = context.WithoutCancel(ctx)
+	if err := s.save(ctx, ex); err != nil {
+		return err
+	}
+	for len(ex.Completed) > 0 {
+		step := s.steps[len(ex.Completed)-1]
+		if step.Compensate != nil {
+			if err := s.retry(ctx, step, step.Compensate, ex); err != nil {
+				ex.Status = StatusFailed
+				s.save(ctx, ex)
+				return fmt.Errorf("%w: step %q: %v (after step %q failed: %s)", ErrCompensationFailed, step.Name, err, ex.FailedStep, ex.Error)
+			}
+		}
+		ex.Completed = ex.Completed[:len(ex.Completed)-1]
+		if err := s.save(ctx, ex); err != nil {
+			return err
+		}
+	}
+
+	ex.Status = StatusCompensated
+	if err := s.save(ctx, ex); err != nil {
+		return err
+	}
+	return &StepError{Step: ex.FailedStep, Err: errors.New(ex.Error)}
+}
+
+// retry calls action according to the retry policy of step.
+func (s *Saga) retry(ctx context.Context, step Step, action func(context.Context, *Execution) error, ex *Execution) error {
+	policy := step.Retry
+	if policy.Attempts == 0 {
+		policy = s.config.Retry
+	}
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 10 * time.Second
+	}
+
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		err := action(ctx, ex)
+		if err == nil || attempt >= policy.Attempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		backoff = min(2*backoff, policy.MaxBackoff)
+	}
+}
+
+// save persists ex if the saga has a Store.
+func (s *Saga) save(ctx context.Context, ex *Execution) error {
+	ex.UpdatedAt = time.Now()
+	if s.config.Store == nil {
+		return nil
+	}
+	stored := *ex
+	stored.Completed = append([]string(nil), ex.Completed...)
+	if err := s.config.Store.Put(ctx, ex.ID, stored); err != nil {
+		return fmt.Errorf("saga: saving execution %s: %w", ex.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/JedizLaPulga/kese/store"
+)
+
+// journal records the actions of test steps.
+type journal []string
+
+func (j *journal) step(name string, fail bool) Step {
+	return Step{
+		Name: name,
+		Action: func(ctx context.Context, ex *Execution) error {
+			*j = append(*j, name)
+			if fail {
+				return errors.New(name + " unavailable")
+			}
+			ex.Data[name] = true
+			return nil
+		},
+		Compensate: func(ctx context.Context, ex *Execution) error {
+			*j = append(*j, "undo "+name)
+			return nil
+		},
+	}
+}
+
+func TestRunCompleted(t *testing.T) {
+	var j journal
+	s := New("checkout", j.step("reserve", false), j.step("charge", false))
+
+	ex, err := s.Run(context.Background(), "order-1", nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if ex.Status != StatusCompleted || !reflect.DeepEqual(ex.Completed, []string{"reserve", "charge"}) {
+		t.Errorf("Expected completed execution, got %+v", ex)
+	}
+	if ex.Data["charge"] != true {
+		t.Errorf("Expected step data, got %v", ex.Data)
+	}
+}
+
+func TestRunCompensates(t *testing.T) {
+	var j journal
+	executions := store.NewMemory[string, Execution]()
+	s := NewWithConfig("checkout", Config{Store: executions},
+		j.step("reserve", false), j.step("charge", false), j.step("ship", true))
+
+	ex, err := s.Run(context.Background(), "order-1", nil)
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "ship" {
+		t.Fatalf("Expected StepError for ship, got %v", err)
+	}
+	want := journal{"reserve", "charge", "ship", "undo charge", "undo reserve"}
+	if !reflect.DeepEqual(j, want) {
+		t.Errorf("Expected %v, got %v", want, j)
+	}
+	if ex.Status != StatusCompensated || len(ex.Completed) != 0 {
+		t.Errorf("Expected compensated execution, got %+v", ex)
+	}
+
+	stored, err := executions.Get(context.Background(), "order-1")
+	if err != nil || stored.Status != StatusCompensated || stored.FailedStep != "ship" {
+		t.Errorf("Expected stored compensated execution, got %+v, %v", stored, err)
+	}
+}
+
+func TestRunCompensationFails(t *testing.T) {
+	var j journal
+	reserve := j.step("reserve", false)
+	reserve.Compensate = func(ctx context.Context, ex *Execution) error {
+		return errors.New("inventory unavailable")
+	}
+	s := New("checkout", reserve, j.step("charge", true))
+
+	ex, err := s.Run(context.Background(), "order-1", nil)
+	if !errors.Is(err, ErrCompensationFailed) {
+		t.Fatalf("Expected ErrCompensationFailed, got %v", err)
+	}
+	if ex.Status != StatusFailed {
+		t.Errorf("Expected failed execution, got %s", ex.Status)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	s := NewWithConfig("checkout", Config{Retry: RetryPolicy{Attempts: 3, Backoff: time.Millisecond}},
+		Step{Name: "charge", Action: func(ctx context.Context, ex *Execution) error {
+			calls++
+			if calls < 3 {
+				return errors.New("timeout")
+			}
+			return nil
+		}})
+
+	if _, err := s.Run(context.Background(), "order-1", nil); err != nil {
+		t.Fatalf("Expected success on third attempt, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var j journal
+	executions := store.NewMemory[string, Execution]()
+	ctx := context.Background()
+
+	// An execution interrupted after its first step
+	executions.Put(ctx, "order-1", Execution{
+		ID:        "order-1",
+		Saga:      "checkout",
+		Status:    StatusRunning,
+		Completed: []string{"reserve"},
+		Data:      map[string]interface{}{},
+	})
+	executions.Put(ctx, "order-2", Execution{ID: "order-2", Saga: "checkout", Status: StatusCompleted})
+
+	s := NewWithConfig("checkout", Config{Store: executions}, j.step("reserve", false), j.step("charge", false))
+	if err := s.Recover(ctx); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if !reflect.DeepEqual(j, journal{"charge"}) {
+		t.Errorf("Expected only the remaining step, got %v", j)
+	}
+	if ex, _ := executions.Get(ctx, "order-1"); ex.Status != StatusCompleted {
+		t.Errorf("Expected recovered execution completed, got %s", ex.Status)
+	}
+}
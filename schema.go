@@ -0,0 +1,390 @@
+package kese
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// Request documents the Go type of the route's JSON request body, e.g.
+// Request(CreateTodoInput{}). Together with Query and Response it lets App.Schema
+// describe the API, so generators can emit TypeScript or OpenAPI clients
+// and validation schemas that stay in sync with the handlers.
+//
+// Example:
+//
+//	app.POST("/todos", createTodo).
+//	    Request(CreateTodoInput{}).
+//	    Response(201, Todo{})
+func (r *Route) Request(v interface{}) *Route {
+	r.RequestType = reflect.TypeOf(v)
+	return r
+}
+
+// Query documents the Go type the route binds its query string into with
+// c.BindQuery, e.g. Query(TodoFilter{}).
+func (r *Route) Query(v interface{}) *Route {
+	r.QueryType = reflect.TypeOf(v)
+	return r
+}
+
+// Response documents the Go type of the JSON response sent with status,
+// e.g. Response(200, []Todo{}). Pass nil for responses without a body.
+func (r *Route) Response(status int, v interface{}) *Route {
+	if r.ResponseTypes == nil {
+		r.ResponseTypes = make(map[int]reflect.Type)
+	}
+	r.ResponseTypes[status] = reflect.TypeOf(v)
+	return r
+}
+
+// APISchema describes the routes of an app and the types they exchange, for
+// client and documentation generators. Types use a JSON Schema vocabulary;
+// named struct types are listed once in Types and referenced as
+// {"$ref": "#/types/Name"}.
+type APISchema struct {
+	Routes []RouteSchema          `json:"routes"`
+	Types  map[string]*TypeSchema `json:"types"`
+}
+
+// RouteSchema describes a single route.
+type RouteSchema struct {
+	Method      string                 `json:"method"`
+	Path        string                 `json:"path"`
+	Description string                 `json:"description,omitempty"`
+	Params      []string               `json:"params,omitempty"`
+	Query       *TypeSchema            `json:"query,omitempty"`
+	Request     *TypeSchema            `json:"request,omitempty"`
+	Responses   map[string]*TypeSchema `json:"responses,omitempty"`
+	Deprecated  bool                   `json:"deprecated,omitempty"`
+}
+
+// TypeSchema is a JSON Schema describing a Go type. Constraints come from
+// `validate` struct tags: required, min, max, len, gt, gte, lt, lte, oneof,
+// email, url, uuid, alpha, alphanum and numeric.
+type TypeSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty"`
+	Properties           map[string]*TypeSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *TypeSchema            `json:"items,omitempty"`
+	AdditionalProperties *TypeSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+}
+
+// Schema describes every route with documented types (see Route.Request,
+// Route.Query and Route.Response) along with the types themselves. Routes
+// without documented types are listed with their method, path and parameters.
+func (a *App) Schema() *APISchema {
+	builder := &schemaBuilder{
+		types: make(map[string]*TypeSchema),
+		names: make(map[schemaKey]string),
+	}
+
+	routes := a.Routes()
+	schema := &APISchema{Routes: make([]RouteSchema, 0, len(routes)), Types: builder.types}
+	for _, route := range routes {
+		rs := RouteSchema{
+			Method:      route.Method,
+			Path:        route.Path,
+			Description: route.Description,
+			Params:      pathParams(route.Path),
+			Deprecated:  route.Get(DeprecationKey) != nil,
+		}
+		if route.QueryType != nil {
+			rs.Query = builder.schema(route.QueryType, "query")
+		}
+		if route.RequestType != nil {
+			rs.Request = builder.schema(route.RequestType, "json")
+		}
+		for status, t := range route.ResponseTypes {
+			if rs.Responses == nil {
+				rs.Responses = make(map[string]*TypeSchema)
+			}
+			var body *TypeSchema
+			if t != nil {
+				body = builder.schema(t, "json")
+			}
+			rs.Responses[strconv.Itoa(status)] = body
+		}
+		schema.Routes = append(schema.Routes, rs)
+	}
+	return schema
+}
+
+// SchemaHandler returns a handler that serves App.Schema as JSON, for client
+// generators to fetch from a running app (or a test server).
+//
+// Example:
+//
+//	admin.GET("/schema", app.SchemaHandler())
+func (a *App) SchemaHandler() HandlerFunc {
+	return func(c *context.Context) error {
+		return c.JSON(http.StatusOK, a.Schema())
+	}
+}
+
+// pathParams returns the parameter names of a route path.
+func pathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			params = append(params, segment[1:])
+		}
+	}
+	return params
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	timeSchemaType    = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+)
+
+// schemaBuilder converts Go types to schemas, collecting named struct types.
+type schemaBuilder struct {
+	types map[string]*TypeSchema
+	names map[schemaKey]string
+}
+
+// schemaKey identifies a struct type read with a given struct tag.
+type schemaKey struct {
+	t   reflect.Type
+	tag string
+}
+
+// schema returns the schema of t, reading field names from the given struct tag.
+func (b *schemaBuilder) schema(t reflect.Type, tag string) *TypeSchema {
+	if t.Kind() == reflect.Ptr {
+		s := b.schema(t.Elem(), tag)
+		if s.Ref != "" {
+			return &TypeSchema{Ref: s.Ref, Nullable: true}
+		}
+		copied := *s
+		copied.Nullable = true
+		return &copied
+	}
+
+	switch {
+	case t == timeSchemaType:
+		return &TypeSchema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &TypeSchema{Type: "integer", Format: "duration"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &TypeSchema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &TypeSchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &TypeSchema{Type: "string"}
+	case reflect.Bool:
+		return &TypeSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &TypeSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &TypeSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &TypeSchema{Type: "string", Format: "byte"}
+		}
+		return &TypeSchema{Type: "array", Items: b.schema(t.Elem(), tag)}
+	case reflect.Map:
+		return &TypeSchema{Type: "object", AdditionalProperties: b.schema(t.Elem(), tag)}
+	case reflect.Struct:
+		return b.structSchema(t, tag)
+	}
+	return &TypeSchema{}
+}
+
+// structSchema returns a reference to a named struct type, registering it on
+// first use, or the inline schema of an anonymous struct.
+func (b *schemaBuilder) structSchema(t reflect.Type, tag string) *TypeSchema {
+	if t.Name() == "" {
+		return b.objectSchema(t, tag)
+	}
+
+	key := schemaKey{t, tag}
+	name, exists := b.names[key]
+	if !exists {
+		name = b.typeName(t, tag)
+		b.names[key] = name
+		// Register before recursing so self-referencing types terminate
+		b.types[name] = &TypeSchema{}
+		*b.types[name] = *b.objectSchema(t, tag)
+	}
+	return &TypeSchema{Ref: "#/types/" + name}
+}
+
+// typeName picks a unique schema name for a named type, qualifying it with
+// its package name if another package has a type of the same name. Types
+// read from query strings get a "Query" suffix.
+func (b *schemaBuilder) typeName(t reflect.Type, tag string) string {
+	name := strings.NewReplacer("[", "_", "]", "", "/", "_", ".", "_", ",", "_", "*", "").Replace(t.Name())
+	if tag == "query" {
+		name += "Query"
+	}
+	if _, taken := b.types[name]; !taken {
+		return name
+	}
+
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	qualified := pkg + "_" + name
+	for i := 2; ; i++ {
+		if _, taken := b.types[qualified]; !taken {
+			return qualified
+		}
+		qualified = pkg + "_" + name + strconv.Itoa(i)
+	}
+}
+
+// objectSchema describes the exported fields of a struct.
+func (b *schemaBuilder) objectSchema(t reflect.Type, tag string) *TypeSchema {
+	object := &TypeSchema{Type: "object", Properties: make(map[string]*TypeSchema)}
+	b.addFields(object, t, tag)
+	sort.Strings(object.Required)
+	return object
+}
+
+// addFields adds the fields of t to object, flattening embedded structs.
+func (b *schemaBuilder) addFields(object *TypeSchema, t reflect.Type, tag string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			b.addFields(object, fieldType, tag)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := b.schema(field.Type, tag)
+		if rules := field.Tag.Get("validate"); rules != "" {
+			property = applyValidateRules(property, field.Type, rules)
+			if hasRule(rules, "required") {
+				object.Required = append(object.Required, name)
+			}
+		}
+		object.Properties[name] = property
+	}
+}
+
+// hasRule reports whether a validate tag contains the named rule.
+func hasRule(rules, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyValidateRules adds the constraints of a validate tag to a field schema.
+// References are wrapped so constraints don't alter the shared type.
+func applyValidateRules(s *TypeSchema, t reflect.Type, rules string) *TypeSchema {
+	copied := *s
+	s = &copied
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		limit, numeric := strconv.ParseFloat(param, 64)
+		count := int(limit)
+
+		switch name {
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "uuid":
+			s.Format = "uuid"
+		case "alpha":
+			s.Pattern = "^[a-zA-Z]+$"
+		case "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]+$"
+		case "numeric":
+			s.Pattern = "^[-+]?[0-9]+(\\.[0-9]+)?$"
+		case "oneof":
+			s.Enum = strings.Fields(param)
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			if numeric != nil {
+				continue
+			}
+			switch t.Kind() {
+			case reflect.String:
+				setBound(name, &s.MinLength, &s.MaxLength, count)
+			case reflect.Slice, reflect.Array, reflect.Map:
+				setBound(name, &s.MinItems, &s.MaxItems, count)
+			default:
+				switch name {
+				case "gt":
+					s.ExclusiveMinimum = &limit
+				case "lt":
+					s.ExclusiveMaximum = &limit
+				default:
+					setBound(name, &s.Minimum, &s.Maximum, limit)
+				}
+			}
+		}
+	}
+	return s
+}
+
+// setBound sets the lower and/or upper bound implied by a comparison rule.
+// For lengths and item counts, gt and lt are converted to inclusive bounds.
+func setBound[N int | float64](rule string, min, max **N, limit N) {
+	var one N = 1
+	switch rule {
+	case "min", "gte":
+		*min = &limit
+	case "max", "lte":
+		*max = &limit
+	case "len":
+		*min, *max = &limit, &limit
+	case "gt":
+		lower := limit + one
+		*min = &lower
+	case "lt":
+		upper := limit - one
+		*max = &upper
+	}
+}
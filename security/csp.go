@@ -0,0 +1,189 @@
+// Package security builds Content-Security-Policy headers and collects the
+// violation reports browsers send for them.
+//
+// Example:
+//
+//	config := middleware.DefaultSecurityConfig()
+//	config.CSP = security.CSP().
+//	    DefaultSrc(security.Self).
+//	    ScriptSrc(security.Self, security.Nonce).
+//	    ImgSrc(security.Self, "data:", "https://cdn.example.com").
+//	    ObjectSrc(security.None).
+//	    ReportTo("/csp-report")
+//	config.CSPNonce = true
+//	app.Use(middleware.SecureHeadersWithConfig(config))
+//
+//	app.POST(security.ReportPath, security.CSPReportHandler(func(r security.CSPReport) {
+//	    app.Logger.Warn("CSP violation", "directive", r.EffectiveDirective, "blocked", r.BlockedURI)
+//	}))
+package security
+
+import "strings"
+
+// Source keywords of CSP directives.
+const (
+	Self           = "'self'"
+	None           = "'none'"
+	UnsafeInline   = "'unsafe-inline'"
+	UnsafeEval     = "'unsafe-eval'"
+	StrictDynamic  = "'strict-dynamic'"
+	ReportSample   = "'report-sample'"
+	WasmUnsafeEval = "'wasm-unsafe-eval'"
+
+	// Nonce allows scripts or styles carrying the per-request nonce
+	// generated by middleware.SecureHeaders with CSPNonce enabled
+	Nonce = "'nonce-{nonce}'"
+)
+
+// ReportEndpointName is the Reporting API endpoint name used by ReportTo.
+const ReportEndpointName = "csp-endpoint"
+
+// Policy is a Content-Security-Policy built directive by directive. Methods
+// append sources and return the policy for chaining; directives keep the
+// order in which they were first added.
+type Policy struct {
+	directives []directive
+	reportURI  string
+}
+
+type directive struct {
+	name    string
+	sources []string
+}
+
+// CSP returns an empty policy.
+func CSP() *Policy {
+	return &Policy{}
+}
+
+// Directive adds sources to the directive name, for directives without a
+// dedicated method.
+func (p *Policy) Directive(name string, sources ...string) *Policy {
+	for i := range p.directives {
+		if p.directives[i].name == name {
+			p.directives[i].sources = appendNew(p.directives[i].sources, sources)
+			return p
+		}
+	}
+	p.directives = append(p.directives, directive{name: name, sources: appendNew(nil, sources)})
+	return p
+}
+
+// DefaultSrc sets the fallback for the other fetch directives.
+func (p *Policy) DefaultSrc(sources ...string) *Policy {
+	return p.Directive("default-src", sources...)
+}
+
+// ScriptSrc sets where scripts may be loaded from.
+func (p *Policy) ScriptSrc(sources ...string) *Policy {
+	return p.Directive("script-src", sources...)
+}
+
+// StyleSrc sets where stylesheets may be loaded from.
+func (p *Policy) StyleSrc(sources ...string) *Policy {
+	return p.Directive("style-src", sources...)
+}
+
+// ImgSrc sets where images may be loaded from.
+func (p *Policy) ImgSrc(sources ...string) *Policy {
+	return p.Directive("img-src", sources...)
+}
+
+// FontSrc sets where fonts may be loaded from.
+func (p *Policy) FontSrc(sources ...string) *Policy {
+	return p.Directive("font-src", sources...)
+}
+
+// ConnectSrc sets which URLs scripts may connect to with fetch, XHR and
+// WebSockets.
+func (p *Policy) ConnectSrc(sources ...string) *Policy {
+	return p.Directive("connect-src", sources...)
+}
+
+// MediaSrc sets where audio and video may be loaded from.
+func (p *Policy) MediaSrc(sources ...string) *Policy {
+	return p.Directive("media-src", sources...)
+}
+
+// ObjectSrc sets where plugins may be loaded from; usually None.
+func (p *Policy) ObjectSrc(sources ...string) *Policy {
+	return p.Directive("object-src", sources...)
+}
+
+// FrameSrc sets which pages may be embedded in frames.
+func (p *Policy) FrameSrc(sources ...string) *Policy {
+	return p.Directive("frame-src", sources...)
+}
+
+// WorkerSrc sets where workers may be loaded from.
+func (p *Policy) WorkerSrc(sources ...string) *Policy {
+	return p.Directive("worker-src", sources...)
+}
+
+// FrameAncestors sets which pages may embed this one, superseding
+// X-Frame-Options.
+func (p *Policy) FrameAncestors(sources ...string) *Policy {
+	return p.Directive("frame-ancestors", sources...)
+}
+
+// BaseURI restricts the URLs of <base> elements.
+func (p *Policy) BaseURI(sources ...string) *Policy {
+	return p.Directive("base-uri", sources...)
+}
+
+// FormAction restricts where forms may be submitted.
+func (p *Policy) FormAction(sources ...string) *Policy {
+	return p.Directive("form-action", sources...)
+}
+
+// UpgradeInsecureRequests makes browsers load http:// resources over HTTPS.
+func (p *Policy) UpgradeInsecureRequests() *Policy {
+	return p.Directive("upgrade-insecure-requests")
+}
+
+// ReportTo makes browsers send violation reports to endpoint, e.g.
+// ReportPath handled by CSPReportHandler. Both the report-uri directive and
+// the Reporting API (report-to, with the Reporting-Endpoints header sent by
+// middleware.SecureHeaders) are used, so every browser reports.
+func (p *Policy) ReportTo(endpoint string) *Policy {
+	p.reportURI = endpoint
+	return p
+}
+
+// ReportingEndpoints returns the value of the Reporting-Endpoints header
+// naming the ReportTo endpoint, or "" without one.
+func (p *Policy) ReportingEndpoints() string {
+	if p.reportURI == "" {
+		return ""
+	}
+	return ReportEndpointName + `="` + p.reportURI + `"`
+}
+
+// String returns the policy as a Content-Security-Policy header value.
+func (p *Policy) String() string {
+	parts := make([]string, 0, len(p.directives)+2)
+	for _, d := range p.directives {
+		parts = append(parts, strings.TrimSpace(d.name+" "+strings.Join(d.sources, " ")))
+	}
+	if p.reportURI != "" {
+		parts = append(parts, "report-uri "+p.reportURI, "report-to "+ReportEndpointName)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// appendNew appends the sources not in list yet.
+func appendNew(list, sources []string) []string {
+	for _, source := range sources {
+		exists := false
+		for _, s := range list {
+			if s == source {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, source)
+		}
+	}
+	return list
+}
@@ -0,0 +1,154 @@
+package security
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+// ReportPath is the conventional path of the CSP report collection endpoint.
+const ReportPath = "/csp-report"
+
+// CSPReport is a Content-Security-Policy violation reported by a browser.
+type CSPReport struct {
+	DocumentURI        string `json:"document_uri"`
+	Referrer           string `json:"referrer,omitempty"`
+	BlockedURI         string `json:"blocked_uri"`
+	EffectiveDirective string `json:"effective_directive"`
+	OriginalPolicy     string `json:"original_policy"`
+	Disposition        string `json:"disposition,omitempty"`
+	SourceFile         string `json:"source_file,omitempty"`
+	LineNumber         int    `json:"line_number,omitempty"`
+	ColumnNumber       int    `json:"column_number,omitempty"`
+	StatusCode         int    `json:"status_code,omitempty"`
+
+	// Sample is the start of the blocked inline script or style, sent when
+	// the directive includes ReportSample
+	Sample string `json:"sample,omitempty"`
+
+	// UserAgent is the User-Agent of the reporting browser
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// legacyReport is the body of report-uri reports (application/csp-report).
+type legacyReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		Referrer           string `json:"referrer"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		OriginalPolicy     string `json:"original-policy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ColumnNumber       int    `json:"column-number"`
+		StatusCode         int    `json:"status-code"`
+		ScriptSample       string `json:"script-sample"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport is an entry of Reporting API reports
+// (application/reports+json).
+type reportingAPIReport struct {
+	Type      string `json:"type"`
+	UserAgent string `json:"user_agent"`
+	Body      struct {
+		DocumentURL        string `json:"documentURL"`
+		Referrer           string `json:"referrer"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		OriginalPolicy     string `json:"originalPolicy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		ColumnNumber       int    `json:"columnNumber"`
+		StatusCode         int    `json:"statusCode"`
+		Sample             string `json:"sample"`
+	} `json:"body"`
+}
+
+// CSPReportHandler returns a handler collecting the violation reports sent to
+// Policy.ReportTo, in both the report-uri and the Reporting API format, and
+// passing each to onReport. Malformed reports are ignored; the browser
+// always gets 204 No Content.
+//
+// Reports come from any visitor and can be forged, so treat them as hints
+// and consider rate limiting the route. Browsers send them without CSRF
+// tokens; exempt the path from middleware.CSRF.
+//
+// Example:
+//
+//	app.POST(security.ReportPath, security.CSPReportHandler(func(r security.CSPReport) {
+//	    app.Logger.Warn("CSP violation", "directive", r.EffectiveDirective, "blocked", r.BlockedURI)
+//	}))
+func CSPReportHandler(onReport func(CSPReport)) func(*context.Context) error {
+	return func(c *context.Context) error {
+		body, err := c.BodyBytes()
+		if err != nil {
+			return c.NoContent()
+		}
+		for _, report := range parseCSPReports(body) {
+			if report.UserAgent == "" {
+				report.UserAgent = c.Header("User-Agent")
+			}
+			onReport(report)
+		}
+		return c.NoContent()
+	}
+}
+
+// parseCSPReports decodes the reports of either format in body.
+func parseCSPReports(body []byte) []CSPReport {
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		var entries []reportingAPIReport
+		if json.Unmarshal(body, &entries) != nil {
+			return nil
+		}
+		var reports []CSPReport
+		for _, e := range entries {
+			if e.Type != "csp-violation" {
+				continue
+			}
+			reports = append(reports, CSPReport{
+				DocumentURI:        e.Body.DocumentURL,
+				Referrer:           e.Body.Referrer,
+				BlockedURI:         e.Body.BlockedURL,
+				EffectiveDirective: e.Body.EffectiveDirective,
+				OriginalPolicy:     e.Body.OriginalPolicy,
+				Disposition:        e.Body.Disposition,
+				SourceFile:         e.Body.SourceFile,
+				LineNumber:         e.Body.LineNumber,
+				ColumnNumber:       e.Body.ColumnNumber,
+				StatusCode:         e.Body.StatusCode,
+				Sample:             e.Body.Sample,
+				UserAgent:          e.UserAgent,
+			})
+		}
+		return reports
+	}
+
+	var legacy legacyReport
+	if json.Unmarshal(body, &legacy) != nil || legacy.Report.DocumentURI == "" {
+		return nil
+	}
+	r := legacy.Report
+	directive := r.EffectiveDirective
+	if directive == "" {
+		directive = r.ViolatedDirective
+	}
+	return []CSPReport{{
+		DocumentURI:        r.DocumentURI,
+		Referrer:           r.Referrer,
+		BlockedURI:         r.BlockedURI,
+		EffectiveDirective: directive,
+		OriginalPolicy:     r.OriginalPolicy,
+		Disposition:        r.Disposition,
+		SourceFile:         r.SourceFile,
+		LineNumber:         r.LineNumber,
+		ColumnNumber:       r.ColumnNumber,
+		StatusCode:         r.StatusCode,
+		Sample:             r.ScriptSample,
+	}}
+}
@@ -0,0 +1,68 @@
+package security
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/JedizLaPulga/kese/context"
+)
+
+func TestPolicyString(t *testing.T) {
+	policy := CSP().
+		DefaultSrc(Self).
+		ScriptSrc(Self, Nonce).
+		ImgSrc(Self, "data:").
+		ScriptSrc(Self, "https://cdn.example.com").
+		ObjectSrc(None).
+		UpgradeInsecureRequests().
+		ReportTo("/csp-report")
+
+	expected := "default-src 'self'; script-src 'self' 'nonce-{nonce}' https://cdn.example.com; " +
+		"img-src 'self' data:; object-src 'none'; upgrade-insecure-requests; " +
+		"report-uri /csp-report; report-to csp-endpoint"
+	if got := policy.String(); got != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, got)
+	}
+	if got := policy.ReportingEndpoints(); got != `csp-endpoint="/csp-report"` {
+		t.Errorf("Unexpected Reporting-Endpoints %q", got)
+	}
+	if CSP().DefaultSrc(Self).ReportingEndpoints() != "" {
+		t.Error("Expected no Reporting-Endpoints without ReportTo")
+	}
+}
+
+func TestCSPReportHandler(t *testing.T) {
+	var reports []CSPReport
+	handler := CSPReportHandler(func(r CSPReport) {
+		reports = append(reports, r)
+	})
+
+	bodies := []string{
+		`{"csp-report": {"document-uri": "https://example.com/", "blocked-uri": "https://evil.com/x.js",
+			"violated-directive": "script-src-elem", "line-number": 3}}`,
+		`[{"type": "csp-violation", "user_agent": "Chrome", "body": {"documentURL": "https://example.com/",
+			"blockedURL": "inline", "effectiveDirective": "script-src-elem", "sample": "alert(1)"}},
+		  {"type": "deprecation", "body": {}}]`,
+		`not json`,
+	}
+	for _, body := range bodies {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", ReportPath, strings.NewReader(body))
+		req.Header.Set("User-Agent", "Firefox")
+		if err := handler(context.New(w, req, 1<<20)); err != nil || w.Code != 204 {
+			t.Errorf("Expected 204, got %d, %v", w.Code, err)
+		}
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got %+v", reports)
+	}
+	if r := reports[0]; r.BlockedURI != "https://evil.com/x.js" || r.EffectiveDirective != "script-src-elem" ||
+		r.LineNumber != 3 || r.UserAgent != "Firefox" {
+		t.Errorf("Unexpected report-uri report %+v", r)
+	}
+	if r := reports[1]; r.BlockedURI != "inline" || r.Sample != "alert(1)" || r.UserAgent != "Chrome" {
+		t.Errorf("Unexpected Reporting API report %+v", r)
+	}
+}
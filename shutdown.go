@@ -3,6 +3,7 @@ package kese
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,6 +14,7 @@ import (
 // RunWithShutdown starts the HTTP server with graceful shutdown support.
 // It listens for interrupt signals (SIGINT, SIGTERM) and gracefully shuts down the server,
 // allowing ongoing requests to complete within the specified timeout.
+// If a config loader is set, SIGHUP reloads the runtime configuration.
 //
 // address: Server address in format ":8080" or "localhost:8080"
 // timeout: Maximum time to wait for ongoing requests to complete
@@ -21,9 +23,22 @@ import (
 //
 //	app.RunWithShutdown(":8080", 10*time.Second)
 func (a *App) RunWithShutdown(address string, timeout time.Duration) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.logWarnings()
+
+	// Requests derive their context from requestCtx, so it can be cancelled
+	// when ShutdownRequestTimeout expires during shutdown
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	server := &http.Server{
 		Addr:    address,
 		Handler: a,
+		BaseContext: func(net.Listener) context.Context {
+			return requestCtx
+		},
 	}
 
 	// Channel to listen for errors from the server
@@ -39,26 +54,113 @@ func (a *App) RunWithShutdown(address string, timeout time.Duration) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
+	// Reload the runtime configuration on SIGHUP if a loader is set
+	reload := make(chan os.Signal, 1)
+	if a.configLoader != nil {
+		signal.Notify(reload, syscall.SIGHUP)
+		defer signal.Stop(reload)
+	}
+
 	// Block until we receive a signal or server error
-	select {
-	case err := <-serverErrors:
-		return fmt.Errorf("server error: %w", err)
-
-	case sig := <-shutdown:
-		a.Logger.Info(fmt.Sprintf("🛑 Received signal %v, starting graceful shutdown...", sig))
-
-		// Create context with timeout for shutdown
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		defer cancel()
-
-		// Attempt graceful shutdown
-		if err := server.Shutdown(ctx); err != nil {
-			// Force shutdown if graceful shutdown fails
-			server.Close()
-			return fmt.Errorf("failed to gracefully shutdown server: %w", err)
+	for {
+		select {
+		case err := <-serverErrors:
+			return fmt.Errorf("server error: %w", err)
+
+		case <-reload:
+			// Failures are logged and the current configuration is kept
+			a.ReloadConfig("SIGHUP")
+
+		case sig := <-shutdown:
+			a.Logger.Info(fmt.Sprintf("🛑 Received signal %v, starting graceful shutdown...", sig))
+
+			if err := a.shutdown(server, timeout, cancelRequests); err != nil {
+				return err
+			}
+
+			a.Logger.Info("✅ Server stopped gracefully")
+			return nil
+		}
+	}
+}
+
+// OnShutdown registers fn to run when graceful shutdown starts, before the
+// server stops accepting connections. Use it to fail readiness checks or flag
+// metrics while requests drain.
+//
+// Example:
+//
+//	app.OnShutdown(func() {
+//	    collector.SetDraining(true)
+//	})
+func (a *App) OnShutdown(fn func()) {
+	a.onShutdown = append(a.onShutdown, fn)
+}
+
+// InFlight returns the number of requests currently being served.
+func (a *App) InFlight() int64 {
+	return a.inFlight.Load()
+}
+
+// Draining reports whether graceful shutdown has started.
+func (a *App) Draining() bool {
+	return a.draining.Load()
+}
+
+// shutdown gracefully stops server after ShutdownDelay, logging the requests
+// still in flight every second. cancelRequests cancels the context of in-flight requests once
+// ShutdownRequestTimeout expires.
+func (a *App) shutdown(server *http.Server, timeout time.Duration, cancelRequests context.CancelFunc) error {
+	a.draining.Store(true)
+	for _, fn := range a.onShutdown {
+		fn()
+	}
+
+	if a.ShutdownDelay > 0 {
+		a.Logger.Info("Waiting before closing listeners", "delay", a.ShutdownDelay.String(), "in_flight", a.InFlight())
+		time.Sleep(a.ShutdownDelay)
+	}
+
+	if a.ShutdownRequestTimeout > 0 {
+		deadline := time.AfterFunc(a.ShutdownRequestTimeout, func() {
+			a.Logger.Warn("Shutdown request deadline reached, cancelling in-flight requests",
+				"in_flight", a.InFlight())
+			cancelRequests()
+		})
+		defer deadline.Stop()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				a.Logger.Info("Draining requests", "in_flight", a.InFlight())
+			}
 		}
+	}()
 
-		a.Logger.Info("✅ Server stopped gracefully")
-		return nil
+	// Create context with timeout for shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Attempt graceful shutdown
+	if err := server.Shutdown(ctx); err != nil {
+		// Force shutdown if graceful shutdown fails
+		server.Close()
+		return fmt.Errorf("failed to gracefully shutdown server: %w (%d requests in flight)", err, a.InFlight())
+	}
+
+	// Events tracked by the drained requests are still buffered
+	if a.Analytics != nil {
+		if err := a.Analytics.Close(ctx); err != nil {
+			a.Logger.Warn("Failed to flush analytics events", "error", err.Error())
+		}
 	}
+	return nil
 }
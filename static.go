@@ -2,6 +2,7 @@ package kese
 
 import (
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -11,7 +12,8 @@ import (
 // Static serves files from a directory at the given URL path prefix.
 // Example: app.Static("/assets", "./public") serves ./public/style.css at /assets/style.css
 // Note: Currently only supports single-level paths (e.g., /assets/file.css)
-// Nested paths (e.g., /assets/sub/file.css) are not supported due to router design
+// Nested paths (e.g., /assets/sub/file.css) are not supported due to router design;
+// if the directory has subdirectories, a "static-nested-paths" Warning is logged
 func (a *App) Static(urlPrefix, fsPath string) {
 	// Normalize the URL prefix
 	urlPrefix = strings.TrimSuffix(urlPrefix, "/")
@@ -45,13 +47,14 @@ func (a *App) Static(urlPrefix, fsPath string) {
 		}
 
 		// Serve the file - http.ServeFile handles existence checks, MIME types, caching, etc.
+		setFileETag(c, filePath)
 		http.ServeFile(c.Writer, c.Request, filePath)
-		c.SetWritten()
 		return nil
 	}
 
 	// Register a parameter-based route for this prefix
 	a.GET(urlPrefix+"/:filepath", handler)
+	a.warnNestedStatic(urlPrefix, fsPath)
 }
 
 // StaticFile serves a single file at the given URL path.
@@ -59,10 +62,18 @@ func (a *App) Static(urlPrefix, fsPath string) {
 func (a *App) StaticFile(urlPath, filePath string) {
 	handler := func(c *context.Context) error {
 		// Serve the file - http.ServeFile handles existence checks, directories, etc.
+		setFileETag(c, filePath)
 		http.ServeFile(c.Writer, c.Request, filePath)
-		c.SetWritten()
 		return nil
 	}
 
 	a.GET(urlPath, handler)
 }
+
+// setFileETag sets a weak ETag for a regular file, so http.ServeFile answers
+// If-None-Match with 304 in addition to If-Modified-Since.
+func setFileETag(c *context.Context, filePath string) {
+	if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
+		c.SetHeader("ETag", context.FileETag(info))
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/JedizLaPulga/kese/context"
 )
 
 func TestStaticFile(t *testing.T) {
@@ -207,3 +209,49 @@ func TestMultipleStaticFiles(t *testing.T) {
 		t.Errorf("Expected 200 for file2, got %d", w.Code)
 	}
 }
+
+func TestStaticFileDetectsWrite(t *testing.T) {
+	var written bool
+	var status int
+
+	app := New()
+	app.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *context.Context) error {
+			err := next(c)
+			written, status = c.IsWritten(), c.StatusCode()
+			return err
+		}
+	})
+	app.StaticFile("/missing", "/nonexistent/file.txt")
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	if !written || status != http.StatusNotFound {
+		t.Errorf("Expected the ServeFile response to be detected, got written=%v status=%d", written, status)
+	}
+}
+
+func TestStaticFileETag(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "app.css")
+	if err := os.WriteFile(testFile, []byte("body{}"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	app := New()
+	app.StaticFile("/app.css", testFile)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/app.css", nil))
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected ETag header")
+	}
+
+	req := httptest.NewRequest("GET", "/app.css", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304, got %d", w.Code)
+	}
+}
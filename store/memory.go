@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IndexFunc extracts the index value from a record.
+type IndexFunc[V any] func(V) string
+
+// Memory is a generic, concurrency-safe in-memory Store with secondary indexes
+// and optional expiration. Values are listed in insertion order.
+type Memory[K comparable, V any] struct {
+	mu      sync.RWMutex
+	items   map[K]*memoryItem[V]
+	order   []K
+	holes   int // entries of order whose key was removed
+	indexes map[string]IndexFunc[V]
+	ttl     time.Duration
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type memoryItem[V any] struct {
+	value  V
+	expiry time.Time // zero means no expiry
+	pos    int       // index of the key in order
+}
+
+// expired reports whether the item has expired at now.
+func (i *memoryItem[V]) expired(now time.Time) bool {
+	return !i.expiry.IsZero() && now.After(i.expiry)
+}
+
+// NewMemory creates an in-memory store whose values never expire.
+func NewMemory[K comparable, V any]() *Memory[K, V] {
+	return &Memory[K, V]{
+		items:   make(map[K]*memoryItem[V]),
+		indexes: make(map[string]IndexFunc[V]),
+	}
+}
+
+// NewMemoryWithTTL creates an in-memory store whose values expire after ttl.
+// Expired values are invisible immediately and removed by a periodic cleanup,
+// which runs until Close is called.
+//
+// Example:
+//
+//	sessions := store.NewMemoryWithTTL[string, *Session](30 * time.Minute)
+//	defer sessions.Close()
+func NewMemoryWithTTL[K comparable, V any](ttl time.Duration) *Memory[K, V] {
+	m := NewMemory[K, V]()
+	m.ttl = ttl
+	m.done = make(chan struct{})
+
+	// Start cleanup goroutine
+	go m.cleanup()
+
+	return m
+}
+
+// AddIndex registers a secondary index that can be queried with FindBy.
+// Indexes are evaluated on read, so they can be added at any time.
+//
+// Example:
+//
+//	todos.AddIndex("completed", func(t *Todo) string {
+//	    return strconv.FormatBool(t.Completed)
+//	})
+func (m *Memory[K, V]) AddIndex(name string, fn IndexFunc[V]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.indexes[name] = fn
+}
+
+// Get returns the value for key or ErrNotFound.
+func (m *Memory[K, V]) Get(ctx context.Context, key K) (V, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var zero V
+	item, exists := m.items[key]
+	if !exists || item.expired(time.Now()) {
+		return zero, ErrNotFound
+	}
+	return item.value, nil
+}
+
+// Put inserts or replaces the value for key using the store's default TTL.
+func (m *Memory[K, V]) Put(ctx context.Context, key K, value V) error {
+	return m.PutWithTTL(ctx, key, value, m.ttl)
+}
+
+// PutWithTTL inserts or replaces the value for key with a specific TTL.
+// A ttl of zero means the value never expires.
+func (m *Memory[K, V]) PutWithTTL(ctx context.Context, key K, value V, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := &memoryItem[V]{value: value}
+	if ttl > 0 {
+		item.expiry = time.Now().Add(ttl)
+	}
+
+	if existing, exists := m.items[key]; exists {
+		item.pos = existing.pos
+	} else {
+		item.pos = len(m.order)
+		m.order = append(m.order, key)
+	}
+	m.items[key] = item
+	return nil
+}
+
+// Delete removes the value for key or returns ErrNotFound.
+func (m *Memory[K, V]) Delete(ctx context.Context, key K) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, exists := m.items[key]
+	if !exists || item.expired(time.Now()) {
+		return ErrNotFound
+	}
+	m.remove(key)
+	return nil
+}
+
+// List returns a page of values in insertion order and the total number of values.
+func (m *Memory[K, V]) List(ctx context.Context, offset, limit int) ([]V, int, error) {
+	all := m.Filter(func(V) bool { return true })
+
+	if offset < 0 || offset >= len(all) {
+		return []V{}, len(all), nil
+	}
+	end := len(all)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return all[offset:end], len(all), nil
+}
+
+// Filter returns all values for which keep returns true, in insertion order.
+func (m *Memory[K, V]) Filter(keep func(V) bool) []V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	result := make([]V, 0, len(m.order))
+	for i, key := range m.order {
+		item, exists := m.items[key]
+		if !exists || item.pos != i || item.expired(now) || !keep(item.value) {
+			continue
+		}
+		result = append(result, item.value)
+	}
+	return result
+}
+
+// FindBy returns all values whose index matches value.
+// An unknown index returns ErrNotFound.
+func (m *Memory[K, V]) FindBy(ctx context.Context, index, value string) ([]V, error) {
+	m.mu.RLock()
+	fn, exists := m.indexes[index]
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return m.Filter(func(v V) bool { return fn(v) == value }), nil
+}
+
+// Len returns the number of live values.
+func (m *Memory[K, V]) Len() int {
+	return len(m.Filter(func(V) bool { return true }))
+}
+
+// Close stops the periodic cleanup of a store created with NewMemoryWithTTL.
+// The store remains usable. Close is a no-op for other stores and may be
+// called more than once.
+func (m *Memory[K, V]) Close() error {
+	if m.done != nil {
+		m.closeOnce.Do(func() { close(m.done) })
+	}
+	return nil
+}
+
+// remove deletes key from the map. Its entry in the insertion order is left
+// as a hole, and the order is compacted once half of it is holes.
+// Caller must hold the lock.
+func (m *Memory[K, V]) remove(key K) {
+	delete(m.items, key)
+	m.holes++
+	if m.holes > len(m.order)/2 {
+		m.compact()
+	}
+}
+
+// compact drops the holes from the insertion order.
+// Caller must hold the lock.
+func (m *Memory[K, V]) compact() {
+	order := make([]K, 0, len(m.items))
+	for i, key := range m.order {
+		if item, exists := m.items[key]; exists && item.pos == i {
+			item.pos = len(order)
+			order = append(order, key)
+		}
+	}
+	m.order = order
+	m.holes = 0
+}
+
+// cleanup removes expired items periodically until Close is called.
+func (m *Memory[K, V]) cleanup() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		now := time.Now()
+		for key, item := range m.items {
+			if item.expired(now) {
+				m.remove(key)
+			}
+		}
+		m.mu.Unlock()
+	}
+}
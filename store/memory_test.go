@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type todo struct {
+	ID    int64
+	Title string
+	Done  bool
+}
+
+func TestMemoryCRUD(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory[int64, *todo]()
+	var seq Sequence
+
+	for _, title := range []string{"a", "b", "c"} {
+		id := seq.Next()
+		if err := m.Put(ctx, id, &todo{ID: id, Title: title}); err != nil {
+			t.Fatalf("Put error: %v", err)
+		}
+	}
+
+	got, err := m.Get(ctx, 2)
+	if err != nil || got.Title != "b" {
+		t.Fatalf("Expected todo b, got %+v (err=%v)", got, err)
+	}
+
+	if err := m.Delete(ctx, 2); err != nil {
+		t.Fatalf("Delete error: %v", err)
+	}
+	if _, err := m.Get(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := m.Delete(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	page, total, err := m.List(ctx, 1, 10)
+	if err != nil || total != 2 || len(page) != 1 || page[0].Title != "c" {
+		t.Errorf("Unexpected page %+v total=%d err=%v", page, total, err)
+	}
+	if page, _, err := m.List(ctx, -10, 10); err != nil || len(page) != 0 {
+		t.Errorf("Expected empty page for a negative offset, got %+v err=%v", page, err)
+	}
+}
+
+func TestMemoryIndex(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory[int64, *todo]()
+	m.AddIndex("done", func(t *todo) string {
+		if t.Done {
+			return "true"
+		}
+		return "false"
+	})
+
+	m.Put(ctx, 1, &todo{ID: 1, Done: true})
+	m.Put(ctx, 2, &todo{ID: 2})
+	m.Put(ctx, 3, &todo{ID: 3, Done: true})
+
+	done, err := m.FindBy(ctx, "done", "true")
+	if err != nil || len(done) != 2 || done[0].ID != 1 || done[1].ID != 3 {
+		t.Errorf("Unexpected index result %+v (err=%v)", done, err)
+	}
+
+	if _, err := m.FindBy(ctx, "missing", "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown index, got %v", err)
+	}
+}
+
+func TestMemoryTTL(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory[string, string]()
+
+	m.PutWithTTL(ctx, "short", "x", 10*time.Millisecond)
+	m.Put(ctx, "forever", "y")
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := m.Get(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected expired value to be gone, got %v", err)
+	}
+	if m.Len() != 1 {
+		t.Errorf("Expected 1 live value, got %d", m.Len())
+	}
+
+	withTTL := NewMemoryWithTTL[string, string](time.Minute)
+	withTTL.Close()
+	withTTL.Close()
+	select {
+	case <-withTTL.done:
+	default:
+		t.Error("Expected Close to stop the cleanup")
+	}
+	if err := withTTL.Put(ctx, "k", "v"); err != nil || withTTL.Len() != 1 {
+		t.Errorf("Expected closed store to stay usable, err=%v", err)
+	}
+}
+
+func TestMemoryOrderAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Put(ctx, i, i)
+	}
+	for i := 0; i < 10; i += 2 {
+		m.Delete(ctx, i)
+	}
+	m.Put(ctx, 3, 33)
+	m.Put(ctx, 4, 4)
+	if page, _, _ := m.List(ctx, 0, 0); fmt.Sprint(page) != "[1 33 5 7 9 4]" {
+		t.Errorf("Expected re-added key at the end, got %v", page)
+	}
+
+	// Enough deletes compact the order
+	m.Delete(ctx, 1)
+	page, total, _ := m.List(ctx, 0, 0)
+	want := []int{33, 5, 7, 9, 4}
+	if total != len(want) || fmt.Sprint(page) != fmt.Sprint(want) || m.holes != 0 {
+		t.Errorf("Expected %v, got %v (total=%d)", want, page, total)
+	}
+}
+
+// Compile-time interface checks
+var (
+	_ Store[int64, *todo] = (*Memory[int64, *todo])(nil)
+	_ Indexed[*todo]      = (*Memory[int64, *todo])(nil)
+)
@@ -0,0 +1,48 @@
+// Package store provides reusable storage interfaces and a generic in-memory
+// implementation for examples, tests and small applications.
+//
+// The interfaces take a context.Context so SQL-backed implementations can
+// honor request cancellation and deadlines.
+package store
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrNotFound is returned when a key does not exist or has expired.
+var ErrNotFound = errors.New("store: not found")
+
+// Store is a key-value store for records of type V identified by keys of type K.
+type Store[K comparable, V any] interface {
+	// Get returns the value for key or ErrNotFound
+	Get(ctx context.Context, key K) (V, error)
+
+	// Put inserts or replaces the value for key
+	Put(ctx context.Context, key K, value V) error
+
+	// Delete removes the value for key or returns ErrNotFound
+	Delete(ctx context.Context, key K) error
+
+	// List returns a page of values in a stable order and the total number of values
+	List(ctx context.Context, offset, limit int) ([]V, int, error)
+}
+
+// Indexed is implemented by stores that can look up values by a secondary index.
+// In a SQL implementation an index name typically maps to a column.
+type Indexed[V any] interface {
+	// FindBy returns all values whose index matches value
+	FindBy(ctx context.Context, index, value string) ([]V, error)
+}
+
+// Sequence generates increasing integer IDs, starting at 1.
+// The zero value is ready to use and safe for concurrent use.
+type Sequence struct {
+	n atomic.Int64
+}
+
+// Next returns the next ID.
+func (s *Sequence) Next() int64 {
+	return s.n.Add(1)
+}
@@ -5,24 +5,122 @@ import (
 	"html/template"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/i18n"
 )
 
 // TemplateEngine manages HTML template rendering.
+//
+// Templates can call these request-aware functions:
+//
+//	{{csrfField}}  hidden <input> carrying the CSRF token for form posts
+//	{{csrfToken}}  the raw CSRF token (e.g. for a <meta> tag read by JavaScript)
+//	{{cspNonce}}   the CSP nonce for inline scripts: <script nonce="{{cspNonce}}">
+//
+// and these form functions, repopulating a form rejected by a handler with
+// c.FlashInput and redirected back to (POST-redirect-GET):
+//
+//	{{formStart "/signup"}}            <form method="post"> with the CSRF field; close with </form>
+//	{{field "email" "email" "Email"}}  labelled <input> with the previous value and its error
+//	{{textarea "bio" "About you"}}     labelled <textarea>, likewise
+//	{{old "email"}}                    the previously submitted value
+//	{{fieldError "email"}}             the validation error of a field
+//	{{flash "success"}}                a message set with c.Flash
+//
+// and these formatting functions using the locale negotiated by middleware.Locale
+// and the time zone resolved by middleware.Timezone:
+//
+//	{{formatNumber 1234.5 2}}          "1,234.50"
+//	{{formatCurrency .Price "EUR"}}    "€19.99" / "19,99 €"
+//	{{formatDate .CreatedAt}}          short date
+//	{{formatLongDate .CreatedAt}}      date with the month spelled out
+//	{{relativeTime .CreatedAt}}        "3 hours ago"
+//	{{localTime .CreatedAt}}           the time.Time in the client zone, e.g. {{(localTime .At).Format "15:04"}}
 type TemplateEngine struct {
 	templates *template.Template
 	dir       string
 	mu        sync.RWMutex
+
+	// clones holds copies of templates, each executed by one request at a
+	// time, since binding the request-aware functions changes the template
+	clones *sync.Pool
+
+	// CSRFFieldName is the form field name rendered by csrfField.
+	// It must match the CSRF middleware's TokenLookup. Default: "csrf_token"
+	CSRFFieldName string
 }
 
 // NewTemplateEngine creates a new template engine with the given directory.
 func NewTemplateEngine(dir string) *TemplateEngine {
 	return &TemplateEngine{
-		dir: dir,
+		dir:           dir,
+		CSRFFieldName: "csrf_token",
 	}
 }
 
+// requestFuncs returns the request-aware template functions for c.
+// With a nil context they are placeholders used while parsing.
+func (te *TemplateEngine) requestFuncs(c *context.Context) template.FuncMap {
+	token := func() string {
+		if c == nil {
+			return ""
+		}
+		return c.CSRFToken()
+	}
+
+	locale := func() *i18n.Locale {
+		if c == nil {
+			return i18n.Lookup(i18n.DefaultTag)
+		}
+		return i18n.FromContext(c)
+	}
+
+	inZone := func(t time.Time) time.Time {
+		if c == nil {
+			return t
+		}
+		return i18n.InZone(c, t)
+	}
+
+	csrfField := func() template.HTML {
+		return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(te.CSRFFieldName) +
+			`" value="` + template.HTMLEscapeString(token()) + `">`)
+	}
+
+	funcs := template.FuncMap{
+		"localTime": inZone,
+		"formatNumber": func(n float64, decimals int) string {
+			return locale().Number(n, decimals)
+		},
+		"formatCurrency": func(amount float64, code string) string {
+			return locale().Currency(amount, code)
+		},
+		"formatDate": func(t time.Time) string {
+			return locale().Date(inZone(t))
+		},
+		"formatLongDate": func(t time.Time) string {
+			return locale().LongDate(inZone(t))
+		},
+		"relativeTime": func(t time.Time) string {
+			return locale().RelativeTime(t, time.Now())
+		},
+		"csrfToken": token,
+		"cspNonce": func() string {
+			if c == nil {
+				return ""
+			}
+			return c.CSPNonce()
+		},
+		"csrfField": csrfField,
+	}
+	for name, fn := range te.formFuncs(c, csrfField) {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
 // LoadTemplates loads all templates from the template directory.
 // Supports glob patterns like "*.html" or "**/*.html"
 func (te *TemplateEngine) LoadTemplates(pattern string) error {
@@ -30,12 +128,22 @@ func (te *TemplateEngine) LoadTemplates(pattern string) error {
 	defer te.mu.Unlock()
 
 	// Parse all templates matching the pattern
-	tmpl, err := template.ParseGlob(filepath.Join(te.dir, pattern))
+	tmpl, err := template.New("").Funcs(te.requestFuncs(nil)).ParseGlob(filepath.Join(te.dir, pattern))
 	if err != nil {
 		return err
 	}
 
 	te.templates = tmpl
+	te.clones = &sync.Pool{
+		New: func() interface{} {
+			// The loaded set is never executed, so it can always be cloned
+			clone, err := tmpl.Clone()
+			if err != nil {
+				return err
+			}
+			return clone
+		},
+	}
 	return nil
 }
 
@@ -49,9 +157,21 @@ func (te *TemplateEngine) Render(c *context.Context, status int, name string, da
 		return c.InternalError("Templates not loaded")
 	}
 
+	// Bind the request-aware functions to a copy no other request is
+	// executing; copies are reused instead of cloning the set per request
+	var tmpl *template.Template
+	switch clone := te.clones.Get().(type) {
+	case *template.Template:
+		tmpl = clone
+	case error:
+		return clone
+	}
+	tmpl.Funcs(te.requestFuncs(c))
+	defer te.clones.Put(tmpl)
+
 	// Buffer the template output
 	var buf bytes.Buffer
-	err := te.templates.ExecuteTemplate(&buf, name, data)
+	err := tmpl.ExecuteTemplate(&buf, name, data)
 	if err != nil {
 		// Template execution failed - return error without writing partial response
 		return err
@@ -61,6 +181,5 @@ func (te *TemplateEngine) Render(c *context.Context, status int, name string, da
 	c.SetHeader("Content-Type", "text/html; charset=utf-8")
 	c.Writer.WriteHeader(status)
 	c.Writer.Write(buf.Bytes())
-	c.SetWritten()
 	return nil
 }
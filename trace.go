@@ -0,0 +1,182 @@
+package kese
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JedizLaPulga/kese/context"
+	"github.com/JedizLaPulga/kese/rw"
+)
+
+// traceKey is the context key under which the request trace is stored.
+const traceKey = "kese.trace"
+
+// Segment is the time spent in one layer of the request chain, excluding the
+// layers it called. The last segment of a request is named "handler".
+type Segment struct {
+	Name     string
+	Duration time.Duration
+}
+
+// requestTrace records when each layer of the chain was entered and left.
+type requestTrace struct {
+	mu     sync.Mutex
+	names  []string
+	enter  []time.Time
+	leave  []time.Time
+	layers int
+}
+
+// Segments returns the per-layer timings of the current request, in chain order.
+// It is empty unless App.TraceMiddleware was enabled when the route was registered.
+// Segments of layers still running are measured up to now, so calling it from a
+// deferred function or an outer middleware reports complete timings.
+//
+// Example (as span events):
+//
+//	for _, s := range kese.Segments(c) {
+//	    span.AddEvent(s.Name, trace.WithAttributes(attribute.Int64("duration_us", s.Duration.Microseconds())))
+//	}
+func Segments(c *context.Context) []Segment {
+	tr, ok := c.Get(traceKey).(*requestTrace)
+	if !ok {
+		return nil
+	}
+	return tr.segments(time.Now())
+}
+
+// traceLayer wraps next so entering and leaving it is recorded as layer index.
+func traceLayer(index int, name string, next HandlerFunc) HandlerFunc {
+	return func(c *context.Context) error {
+		tr, ok := c.Get(traceKey).(*requestTrace)
+		if !ok {
+			return next(c)
+		}
+
+		tr.start(index, name)
+		err := next(c)
+		tr.finish(index)
+		return err
+	}
+}
+
+// newRequestTrace creates a trace with room for the given number of layers.
+func newRequestTrace(layers int) *requestTrace {
+	return &requestTrace{
+		names: make([]string, layers),
+		enter: make([]time.Time, layers),
+		leave: make([]time.Time, layers),
+	}
+}
+
+// start records entering layer index.
+func (tr *requestTrace) start(index int, name string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if index >= len(tr.names) {
+		grow := index + 1 - len(tr.names)
+		tr.names = append(tr.names, make([]string, grow)...)
+		tr.enter = append(tr.enter, make([]time.Time, grow)...)
+		tr.leave = append(tr.leave, make([]time.Time, grow)...)
+	}
+	tr.names[index] = name
+	tr.enter[index] = time.Now()
+	if index+1 > tr.layers {
+		tr.layers = index + 1
+	}
+}
+
+// finish records leaving layer index.
+func (tr *requestTrace) finish(index int) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.leave[index] = time.Now()
+}
+
+// segments computes the exclusive duration of every entered layer.
+// Layers that have not returned yet are measured up to now.
+func (tr *requestTrace) segments(now time.Time) []Segment {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	inclusive := func(i int) time.Duration {
+		end := tr.leave[i]
+		if end.IsZero() {
+			end = now
+		}
+		return end.Sub(tr.enter[i])
+	}
+
+	segments := make([]Segment, 0, tr.layers)
+	for i := 0; i < tr.layers; i++ {
+		duration := inclusive(i)
+		if i+1 < tr.layers {
+			duration -= inclusive(i + 1)
+		}
+		segments = append(segments, Segment{Name: tr.names[i], Duration: duration})
+	}
+	return segments
+}
+
+// middlewareName derives a readable name for a middleware from its function name,
+// e.g. "github.com/JedizLaPulga/kese/middleware.CORSWithConfig.func1" -> "CORSWithConfig".
+func middlewareName(mw MiddlewareFunc) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer())
+	if fn == nil {
+		return "middleware"
+	}
+
+	name := fn.Name()
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	parts := strings.Split(name, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		if !strings.HasPrefix(parts[i], "func") && !strings.HasPrefix(parts[i], "gowrap") {
+			return parts[i]
+		}
+	}
+	return parts[0]
+}
+
+// serverTimingWriter wraps the response writer of c so the Server-Timing
+// header is added just before the response headers are sent, reporting the
+// metrics and segments measured up to that point.
+func serverTimingWriter(c *context.Context) http.ResponseWriter {
+	w := &rw.Wrapper{ResponseWriter: c.Writer}
+	w.OnWriteHeader = func(int) {
+		if value := serverTimingValue(c); value != "" {
+			w.Header().Set("Server-Timing", value)
+		}
+	}
+	return w
+}
+
+// serverTimingValue formats the recorded metrics and request segments as a
+// Server-Timing header value.
+func serverTimingValue(c *context.Context) string {
+	var metrics []string
+	for _, timing := range c.Timings() {
+		metric := formatTiming(timing.Name, timing.Duration)
+		if timing.Description != "" {
+			metric += ";desc=" + strconv.Quote(timing.Description)
+		}
+		metrics = append(metrics, metric)
+	}
+	for _, segment := range Segments(c) {
+		metrics = append(metrics, formatTiming(segment.Name, segment.Duration))
+	}
+	return strings.Join(metrics, ", ")
+}
+
+// formatTiming formats a Server-Timing metric with its duration in milliseconds.
+func formatTiming(name string, d time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.3f", name, float64(d.Microseconds())/1000)
+}
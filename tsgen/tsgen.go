@@ -0,0 +1,331 @@
+// Package tsgen generates a typed TypeScript fetch client from an app's API
+// schema (see kese.App.Schema), so frontend code stays in sync with the routes
+// and the request, query and response types documented on them.
+//
+// Every named type becomes an exported interface and every route a method of
+// the client class, named after its method and path (GET /todos/:id becomes
+// getTodosById). Path parameters are positional arguments, the request body
+// and query follow, and the method resolves to the type of the route's
+// lowest documented 2xx response. Non-2xx responses reject with an APIError
+// carrying the decoded body, such as problem details.
+//
+// Example:
+//
+//	f, err := os.Create("web/src/api.ts")
+//	...
+//	err = tsgen.Generate(f, app.Schema(), tsgen.Config{})
+//
+// The kese-tsgen command does the same from a schema file or a running app
+// serving App.SchemaHandler, and can be used with go:generate:
+//
+//	//go:generate go run github.com/JedizLaPulga/kese/cmd/kese-tsgen -schema api.json -out web/src/api.ts
+package tsgen
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JedizLaPulga/kese"
+)
+
+// Config holds configuration for the generated client.
+type Config struct {
+	// ClientName is the name of the generated client class (default: "Client")
+	ClientName string
+}
+
+// identifierPattern matches names that need no quoting as TypeScript properties.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// Generate writes a TypeScript client for schema to w.
+func Generate(w io.Writer, schema *kese.APISchema, config Config) error {
+	if config.ClientName == "" {
+		config.ClientName = "Client"
+	}
+	if !identifierPattern.MatchString(config.ClientName) {
+		return fmt.Errorf("tsgen: client name %q is not a valid identifier", config.ClientName)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by kese-tsgen. DO NOT EDIT.\n")
+
+	names := make([]string, 0, len(schema.Types))
+	for name := range schema.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\nexport interface %s %s\n", name, objectType(schema.Types[name], ""))
+	}
+
+	b.WriteString(runtime)
+	fmt.Fprintf(&b, "\nexport class %s {\n", config.ClientName)
+	b.WriteString("  constructor(private readonly baseURL: string, private readonly init: RequestInit = {}) {}\n")
+
+	used := make(map[string]int)
+	for _, route := range schema.Routes {
+		writeMethod(&b, route, used)
+	}
+	b.WriteString(request)
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMethod writes the client method calling route.
+func writeMethod(b *strings.Builder, route kese.RouteSchema, used map[string]int) {
+	name := methodName(route)
+	if used[name]++; used[name] > 1 {
+		name += strconv.Itoa(used[name])
+	}
+
+	var args []string
+	for _, param := range route.Params {
+		args = append(args, paramName(param)+": string | number")
+	}
+	body := "undefined"
+	if route.Request != nil {
+		args = append(args, "body: "+tsType(route.Request, "  "))
+		body = "body"
+	}
+	query := "undefined"
+	if route.Query != nil {
+		args = append(args, "query?: "+tsType(route.Query, "  "))
+		query = "query"
+	}
+
+	var doc []string
+	if route.Description != "" {
+		doc = append(doc, route.Description)
+	}
+	if route.Deprecated {
+		doc = append(doc, "@deprecated")
+	}
+	if len(doc) > 0 {
+		fmt.Fprintf(b, "\n  /** %s */", strings.Join(doc, " "))
+	}
+
+	fmt.Fprintf(b, "\n  %s(%s): Promise<%s> {\n", name, strings.Join(args, ", "), responseType(route))
+	fmt.Fprintf(b, "    return this.request(%q, %s, %s, %s);\n  }\n", route.Method, pathExpr(route.Path), query, body)
+}
+
+// methodName derives a camelCase method name from a route's method and path:
+// static segments are appended in PascalCase and parameters as "ByName".
+func methodName(route kese.RouteSchema) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, segment := range strings.Split(route.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			b.WriteString("By")
+			segment = segment[1:]
+		}
+		b.WriteString(pascalCase(segment))
+	}
+	return b.String()
+}
+
+// pascalCase joins the words of s, split at non-alphanumeric characters,
+// with each word capitalized.
+func pascalCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	var b strings.Builder
+	for _, word := range words {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// paramName returns a TypeScript identifier for a path parameter.
+func paramName(param string) string {
+	name := pascalCase(param)
+	if name == "" {
+		return "param"
+	}
+	name = strings.ToLower(name[:1]) + name[1:]
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// pathExpr returns a TypeScript expression building path with its
+// parameters substituted and escaped.
+func pathExpr(path string) string {
+	var parts []string
+	static := ""
+	for _, segment := range strings.Split(path, "/")[1:] {
+		static += "/"
+		if strings.HasPrefix(segment, ":") {
+			parts = append(parts, strconv.Quote(static), "encodeURIComponent(String("+paramName(segment[1:])+"))")
+			static = ""
+			continue
+		}
+		static += segment
+	}
+	if static != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(static))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// responseType returns the type of the lowest documented 2xx response:
+// void if it has no body and unknown if no success response is documented.
+func responseType(route kese.RouteSchema) string {
+	best := 0
+	for status := range route.Responses {
+		code, err := strconv.Atoi(status)
+		if err == nil && code >= 200 && code < 300 && (best == 0 || code < best) {
+			best = code
+		}
+	}
+	if best == 0 {
+		return "unknown"
+	}
+	s := route.Responses[strconv.Itoa(best)]
+	if s == nil {
+		return "void"
+	}
+	return tsType(s, "  ")
+}
+
+// tsType returns the TypeScript type for s. indent is the indentation of the
+// line the type appears on, used for inline object types.
+func tsType(s *kese.TypeSchema, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+
+	var t string
+	switch {
+	case s.Ref != "":
+		t = strings.TrimPrefix(s.Ref, "#/types/")
+	case len(s.Enum) > 0:
+		values := make([]string, len(s.Enum))
+		for i, value := range s.Enum {
+			if s.Type == "string" {
+				value = strconv.Quote(value)
+			}
+			values[i] = value
+		}
+		t = strings.Join(values, " | ")
+	case s.Type == "string":
+		t = "string"
+	case s.Type == "integer", s.Type == "number":
+		t = "number"
+	case s.Type == "boolean":
+		t = "boolean"
+	case s.Type == "array":
+		t = "Array<" + tsType(s.Items, indent) + ">"
+	case s.Type == "object" && s.Properties != nil:
+		t = objectType(s, indent)
+	case s.Type == "object":
+		t = "Record<string, " + tsType(s.AdditionalProperties, indent) + ">"
+	default:
+		t = "unknown"
+	}
+
+	if s.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+// objectType returns the TypeScript object type for an object schema.
+// Properties not marked as required are optional.
+func objectType(s *kese.TypeSchema, indent string) string {
+	if len(s.Properties) == 0 {
+		return "{}"
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range names {
+		key := name
+		if !identifierPattern.MatchString(key) {
+			key = strconv.Quote(key)
+		}
+		if !required[name] {
+			key += "?"
+		}
+		fmt.Fprintf(&b, "%s  %s: %s;\n", indent, key, tsType(s.Properties[name], indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// runtime is the helper code shared by all generated clients.
+const runtime = `
+export class APIError extends Error {
+  constructor(readonly status: number, readonly body: unknown) {
+    super("request failed with status " + status);
+  }
+}
+
+function appendQuery(params: URLSearchParams, key: string, value: unknown): void {
+  if (value === undefined || value === null) return;
+  if (Array.isArray(value)) {
+    for (const item of value) appendQuery(params, key, item);
+  } else if (value instanceof Date) {
+    params.append(key, value.toISOString());
+  } else if (typeof value === "object") {
+    for (const [name, item] of Object.entries(value)) {
+      appendQuery(params, key ? key + "[" + name + "]" : name, item);
+    }
+  } else {
+    params.append(key, String(value));
+  }
+}
+`
+
+// request is the method of the client class that sends requests.
+const request = `
+  protected async request<T>(method: string, path: string, query?: object, body?: unknown): Promise<T> {
+    let url = this.baseURL.replace(/\/+$/, "") + path;
+    if (query !== undefined) {
+      const params = new URLSearchParams();
+      appendQuery(params, "", query);
+      const search = params.toString();
+      if (search) url += "?" + search;
+    }
+
+    const headers = new Headers(this.init.headers);
+    headers.set("Accept", "application/json");
+    if (body !== undefined) headers.set("Content-Type", "application/json");
+
+    const res = await fetch(url, {
+      ...this.init,
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    const text = await res.text();
+    let data: unknown = undefined;
+    if (text) {
+      try {
+        data = JSON.parse(text);
+      } catch {
+        data = text;
+      }
+    }
+    if (!res.ok) throw new APIError(res.status, data);
+    return data as T;
+  }
+`
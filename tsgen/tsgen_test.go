@@ -0,0 +1,75 @@
+package tsgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JedizLaPulga/kese"
+	"github.com/JedizLaPulga/kese/context"
+)
+
+type owner struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type todo struct {
+	ID     int64             `json:"id"`
+	Title  string            `json:"title" validate:"required"`
+	Status string            `json:"status" validate:"oneof=open done"`
+	Owner  *owner            `json:"owner"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels"`
+	Extra  string            `json:"x-extra"`
+}
+
+type filter struct {
+	Page int `query:"page"`
+}
+
+func TestGenerate(t *testing.T) {
+	noop := func(c *context.Context) error { return nil }
+	app := kese.New()
+	app.GET("/todos", noop).Query(filter{}).Response(200, []todo{}).Doc("Lists todos")
+	app.POST("/todos", noop).Request(todo{}).Response(201, todo{}).Response(400, nil)
+	app.DELETE("/todos/:id", noop).Response(204, nil)
+	app.GET("/users/:user_id/todos/:id", noop).Response(200, todo{})
+	app.GET("/health", noop)
+
+	var b strings.Builder
+	if err := Generate(&b, app.Schema(), Config{ClientName: "TodoClient"}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"// Code generated by kese-tsgen. DO NOT EDIT.",
+		"export interface owner {\n  name: string;\n}",
+		"  title: string;\n",
+		"  id?: number;\n",
+		`  status?: "open" | "done";`,
+		"  owner?: owner | null;\n",
+		"  tags?: Array<string>;\n",
+		"  labels?: Record<string, string>;\n",
+		`  "x-extra"?: string;`,
+		"export interface filterQuery {\n  page?: number;\n}",
+		"export class TodoClient {",
+		"/** Lists todos */",
+		"getTodos(query?: filterQuery): Promise<Array<todo>> {",
+		`return this.request("GET", "/todos", query, undefined);`,
+		"postTodos(body: todo): Promise<todo> {",
+		`return this.request("POST", "/todos", undefined, body);`,
+		"deleteTodosById(id: string | number): Promise<void> {",
+		`return this.request("DELETE", "/todos/" + encodeURIComponent(String(id)), undefined, undefined);`,
+		"getUsersByUserIdTodosById(userId: string | number, id: string | number): Promise<todo> {",
+		`"/users/" + encodeURIComponent(String(userId)) + "/todos/" + encodeURIComponent(String(id))`,
+		"getHealth(): Promise<unknown> {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q\n%s", want, out)
+		}
+	}
+
+	if err := Generate(&b, app.Schema(), Config{ClientName: "my-client"}); err == nil {
+		t.Error("Expected error for invalid client name")
+	}
+}
@@ -0,0 +1,42 @@
+package kese
+
+import (
+	"fmt"
+	"os"
+)
+
+// SetUploadTempDir sets the directory where multipart uploads larger than the
+// in-memory limit are spilled to disk, for example a dedicated volume instead
+// of a small /tmp. Temporary files are removed when the request finishes.
+//
+// Go's mime/multipart always spills to os.TempDir, so this sets TMPDIR for the
+// whole process. It returns a *ConfigError if dir is not a writable directory.
+//
+// Example:
+//
+//	if err := app.SetUploadTempDir("/var/lib/myapp/uploads-tmp"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (a *App) SetUploadTempDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return &ConfigError{
+			Component: "uploads",
+			Problem:   fmt.Sprintf("upload temp dir %q is not a directory", dir),
+			Fix:       "create the directory before starting the app",
+		}
+	}
+
+	probe, err := os.CreateTemp(dir, "kese-probe-")
+	if err != nil {
+		return &ConfigError{
+			Component: "uploads",
+			Problem:   fmt.Sprintf("upload temp dir %q is not writable: %v", dir, err),
+			Fix:       "grant the server process write access to the directory",
+		}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return os.Setenv("TMPDIR", dir)
+}
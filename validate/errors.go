@@ -0,0 +1,31 @@
+package validate
+
+import "fmt"
+
+// ValidationError represents validation errors for struct fields.
+// It is re-exported as kese.ValidationError, which the default error handler
+// turns into a 400 response listing every failing field.
+type ValidationError struct {
+	Errors map[string]string
+}
+
+func (v *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed: %d errors", len(v.Errors))
+}
+
+// NewValidationError creates a new validation error.
+func NewValidationError() *ValidationError {
+	return &ValidationError{
+		Errors: make(map[string]string),
+	}
+}
+
+// Add adds a field error to the validation error.
+func (v *ValidationError) Add(field, message string) {
+	v.Errors[field] = message
+}
+
+// HasErrors returns true if there are any validation errors.
+func (v *ValidationError) HasErrors() bool {
+	return len(v.Errors) > 0
+}
@@ -0,0 +1,338 @@
+// Package validate implements declarative struct validation driven by
+// `validate:"..."` struct tags.
+//
+// Example:
+//
+//	type SignupInput struct {
+//	    Name  string `json:"name" validate:"required,min=3,max=50"`
+//	    Email string `json:"email" validate:"required,email"`
+//	    Age   int    `json:"age" validate:"omitempty,gte=13"`
+//	    Role  string `json:"role" validate:"oneof=admin member"`
+//	}
+//
+//	if err := validate.Struct(&in); err != nil {
+//	    return err // *validate.ValidationError -> 400 with field errors
+//	}
+//
+// Built-in rules: required, omitempty, email, url, uuid, alpha, alphanum,
+// numeric, min, max, len, gt, gte, lt, lte, oneof. For strings, slices and maps
+// min/max/len compare the length; for numbers they compare the value.
+//
+// Domain rules can be added with Register, a `message:"..."` tag replaces the
+// error message of a field, and types implementing StructValidator get a
+// struct-level hook for cross-field checks.
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/JedizLaPulga/kese/sanitize"
+)
+
+// Func checks a single field value against a rule parameter (e.g. "3" for min=3).
+// It returns an empty string if the value is valid, or an error message otherwise.
+type Func func(value reflect.Value, param string) string
+
+var (
+	alphaPattern    = regexp.MustCompile(`^[a-zA-Z]+$`)
+	alphaNumPattern = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	numericPattern  = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// StructValidator is implemented by types that need struct-level validation,
+// such as rules spanning several fields. ValidateStruct runs after the field
+// tags are checked and records failures with errs.Add; field names are
+// prefixed automatically for nested structs.
+//
+// Example:
+//
+//	func (r DateRange) ValidateStruct(errs *validate.ValidationError) {
+//	    if r.End.Before(r.Start) {
+//	        errs.Add("end", "must be after start")
+//	    }
+//	}
+type StructValidator interface {
+	ValidateStruct(errs *ValidationError)
+}
+
+// rulesMu guards rules against concurrent Register calls.
+var rulesMu sync.RWMutex
+
+// rules holds the registered validators keyed by tag name.
+var rules = map[string]Func{
+	"required": func(v reflect.Value, _ string) string {
+		if isZero(v) {
+			return "is required"
+		}
+		return ""
+	},
+	"email": stringRule(sanitize.IsEmail, "must be a valid email address"),
+	"url":   stringRule(sanitize.IsURL, "must be a valid URL"),
+	"uuid":  stringRule(uuidPattern.MatchString, "must be a valid UUID"),
+	"alpha": stringRule(alphaPattern.MatchString, "must contain only letters"),
+	"alphanum": stringRule(alphaNumPattern.MatchString,
+		"must contain only letters and numbers"),
+	"numeric": stringRule(numericPattern.MatchString, "must be numeric"),
+	"min":     compareRule(func(a, b float64) bool { return a >= b }, "must be at least %s", "must be at least %s characters", "must contain at least %s items"),
+	"max":     compareRule(func(a, b float64) bool { return a <= b }, "must be at most %s", "must be at most %s characters", "must contain at most %s items"),
+	"len":     compareRule(func(a, b float64) bool { return a == b }, "must be exactly %s", "must be exactly %s characters", "must contain exactly %s items"),
+	"gt":      compareRule(func(a, b float64) bool { return a > b }, "must be greater than %s", "must be longer than %s characters", "must contain more than %s items"),
+	"gte":     compareRule(func(a, b float64) bool { return a >= b }, "must be greater than or equal to %s", "must be at least %s characters", "must contain at least %s items"),
+	"lt":      compareRule(func(a, b float64) bool { return a < b }, "must be less than %s", "must be shorter than %s characters", "must contain fewer than %s items"),
+	"lte":     compareRule(func(a, b float64) bool { return a <= b }, "must be less than or equal to %s", "must be at most %s characters", "must contain at most %s items"),
+	"oneof": func(v reflect.Value, param string) string {
+		options := strings.Fields(param)
+		actual := fmt.Sprintf("%v", v.Interface())
+		for _, option := range options {
+			if actual == option {
+				return ""
+			}
+		}
+		return "must be one of: " + strings.Join(options, ", ")
+	},
+}
+
+// Register adds a validation rule usable in `validate` tags, replacing any
+// existing rule with the same name. It is typically called from init.
+// Panics if name is empty or contains tag syntax, or if fn is nil.
+//
+// Example:
+//
+//	validate.Register("iban", func(v reflect.Value, _ string) string {
+//	    if v.Kind() == reflect.String && !isIBAN(v.String()) {
+//	        return "must be a valid IBAN"
+//	    }
+//	    return ""
+//	})
+func Register(name string, fn Func) {
+	if name == "" || strings.ContainsAny(name, ",= ") {
+		panic(fmt.Sprintf("validate: invalid rule name %q", name))
+	}
+	if fn == nil {
+		panic("validate: nil rule function for " + name)
+	}
+
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules[name] = fn
+}
+
+// Struct validates the struct (or pointer to struct) v using its `validate` tags.
+// It returns a *ValidationError listing every failing field, or nil if v is valid.
+// Field names in errors use the json tag name when present, and nested structs
+// are reported with dotted paths such as "address.city".
+func Struct(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return fmt.Errorf("validate: nil %T", v)
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: expected a struct, got %T", v)
+	}
+
+	verr := NewValidationError()
+	validateStruct(value, "", verr)
+
+	if verr.HasErrors() {
+		return verr
+	}
+	return nil
+}
+
+// validateStruct validates every field of a struct value, recording errors under prefix.
+func validateStruct(value reflect.Value, prefix string, verr *ValidationError) {
+	structType := value.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := value.Field(i)
+
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		// Embedded structs contribute their fields at the same level
+		if field.Anonymous && indirect(fieldValue).Kind() == reflect.Struct {
+			validateStruct(indirect(fieldValue), prefix, verr)
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+
+		if tag != "" && !validateField(fieldValue, tag, name, field.Tag.Get("message"), verr) {
+			continue
+		}
+
+		// Recurse into nested structs and slices of structs
+		nested := indirect(fieldValue)
+		switch {
+		case nested.Kind() == reflect.Struct && nested.Type().PkgPath() != "time":
+			validateStruct(nested, name+".", verr)
+		case nested.Kind() == reflect.Slice || nested.Kind() == reflect.Array:
+			for j := 0; j < nested.Len(); j++ {
+				if item := indirect(nested.Index(j)); item.Kind() == reflect.Struct {
+					validateStruct(item, fmt.Sprintf("%s[%d].", name, j), verr)
+				}
+			}
+		}
+	}
+
+	// Run the struct-level hook, if any, with field names relative to this struct
+	if hook, ok := structValidator(value); ok {
+		local := NewValidationError()
+		hook.ValidateStruct(local)
+		for field, message := range local.Errors {
+			verr.Add(prefix+field, message)
+		}
+	}
+}
+
+// structValidator returns the StructValidator implemented by value or its address.
+func structValidator(value reflect.Value) (StructValidator, bool) {
+	if value.CanAddr() {
+		if hook, ok := value.Addr().Interface().(StructValidator); ok {
+			return hook, true
+		}
+	}
+	if value.CanInterface() {
+		hook, ok := value.Interface().(StructValidator)
+		return hook, ok
+	}
+	return nil, false
+}
+
+// validateField applies the comma-separated rules in tag to a field value.
+// A non-empty message replaces the rule's error message.
+// It returns false if the field failed a rule, so nested validation is skipped.
+func validateField(value reflect.Value, tag, name, message string, verr *ValidationError) bool {
+	ruleList := strings.Split(tag, ",")
+
+	for _, rule := range ruleList {
+		if rule == "omitempty" && isZero(value) {
+			return true
+		}
+	}
+
+	// Pointers are validated by the value they point to; nil fails only "required"
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	for _, rule := range ruleList {
+		rule = strings.TrimSpace(rule)
+		if rule == "" || rule == "omitempty" {
+			continue
+		}
+
+		ruleName, param, _ := strings.Cut(rule, "=")
+		rulesMu.RLock()
+		check, exists := rules[ruleName]
+		rulesMu.RUnlock()
+		if !exists {
+			panic(fmt.Sprintf("validate: unknown rule %q on field %s", ruleName, name))
+		}
+
+		if value.Kind() == reflect.Ptr && ruleName != "required" {
+			continue
+		}
+
+		if failure := check(value, param); failure != "" {
+			if message != "" {
+				failure = message
+			}
+			verr.Add(name, failure)
+			return false
+		}
+	}
+	return true
+}
+
+// fieldName returns the json tag name of a field, falling back to the Go name.
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("json"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// indirect dereferences pointers until a non-pointer or nil pointer is reached.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// isZero reports whether v is the zero value (nil, "", 0, false, empty collection).
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// stringRule builds a rule that applies a predicate to string values.
+func stringRule(valid func(string) bool, message string) Func {
+	return func(v reflect.Value, _ string) string {
+		if v.Kind() != reflect.String {
+			return ""
+		}
+		if !valid(v.String()) {
+			return message
+		}
+		return ""
+	}
+}
+
+// compareRule builds a rule comparing a number, or the length of a string or
+// collection, against the rule parameter.
+func compareRule(ok func(actual, limit float64) bool, numberMsg, stringMsg, collectionMsg string) Func {
+	return func(v reflect.Value, param string) string {
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid rule parameter %q", param))
+		}
+
+		var actual float64
+		message := numberMsg
+		switch v.Kind() {
+		case reflect.String:
+			actual = float64(len([]rune(v.String())))
+			message = stringMsg
+		case reflect.Slice, reflect.Map, reflect.Array:
+			actual = float64(v.Len())
+			message = collectionMsg
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			actual = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			actual = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			actual = v.Float()
+		default:
+			return ""
+		}
+
+		if ok(actual, limit) {
+			return ""
+		}
+		return fmt.Sprintf(message, param)
+	}
+}
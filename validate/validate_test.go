@@ -0,0 +1,182 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"numeric,len=5"`
+}
+
+type signup struct {
+	Name     string    `json:"name" validate:"required,min=3,max=10"`
+	Email    string    `json:"email" validate:"required,email"`
+	Age      int       `json:"age" validate:"omitempty,gte=13,lte=130"`
+	Role     string    `json:"role" validate:"oneof=admin member"`
+	Website  string    `json:"website" validate:"omitempty,url"`
+	Nickname *string   `json:"nickname" validate:"omitempty,alpha"`
+	Tags     []string  `json:"tags" validate:"max=2"`
+	Address  address   `json:"address"`
+	Contacts []address `json:"contacts"`
+	Internal string    `validate:"-"`
+}
+
+func validSignup() signup {
+	return signup{
+		Name:    "alice",
+		Email:   "alice@example.com",
+		Role:    "member",
+		Address: address{City: "Lagos", Zip: "12345"},
+	}
+}
+
+func TestStructValid(t *testing.T) {
+	in := validSignup()
+	if err := Struct(&in); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestStructRules(t *testing.T) {
+	nick := "b0b"
+
+	tests := []struct {
+		name   string
+		modify func(*signup)
+		field  string
+	}{
+		{"required", func(s *signup) { s.Name = "" }, "name"},
+		{"min", func(s *signup) { s.Name = "al" }, "name"},
+		{"max", func(s *signup) { s.Name = "alexandrina" }, "name"},
+		{"email", func(s *signup) { s.Email = "not-an-email" }, "email"},
+		{"gte", func(s *signup) { s.Age = 10 }, "age"},
+		{"lte", func(s *signup) { s.Age = 200 }, "age"},
+		{"oneof", func(s *signup) { s.Role = "root" }, "role"},
+		{"url", func(s *signup) { s.Website = "nope" }, "website"},
+		{"pointer", func(s *signup) { s.Nickname = &nick }, "nickname"},
+		{"collection max", func(s *signup) { s.Tags = []string{"a", "b", "c"} }, "tags"},
+		{"nested", func(s *signup) { s.Address.City = "" }, "address.city"},
+		{"nested len", func(s *signup) { s.Address.Zip = "123" }, "address.zip"},
+		{"slice element", func(s *signup) { s.Contacts = []address{{City: "Abuja", Zip: "12345"}, {Zip: "12345"}} }, "contacts[1].city"},
+	}
+
+	for _, test := range tests {
+		in := validSignup()
+		test.modify(&in)
+
+		err := Struct(&in)
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("%s: expected *ValidationError, got %v", test.name, err)
+			continue
+		}
+		if _, exists := verr.Errors[test.field]; !exists || len(verr.Errors) != 1 {
+			t.Errorf("%s: expected a single error on %q, got %v", test.name, test.field, verr.Errors)
+		}
+	}
+}
+
+func TestStructOmitEmpty(t *testing.T) {
+	in := validSignup()
+	in.Age = 0
+	in.Website = ""
+	if err := Struct(&in); err != nil {
+		t.Errorf("Empty omitempty fields should be valid, got %v", err)
+	}
+}
+
+func TestStructInvalidTarget(t *testing.T) {
+	if err := Struct("not a struct"); err == nil {
+		t.Error("Expected error for non-struct")
+	}
+	var nilPtr *signup
+	if err := Struct(nilPtr); err == nil {
+		t.Error("Expected error for nil pointer")
+	}
+}
+
+func TestStructUnknownRulePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for unknown rule")
+		}
+	}()
+
+	var in struct {
+		Name string `validate:"bogus"`
+	}
+	Struct(&in)
+}
+
+type dateRange struct {
+	Start int `json:"start" validate:"required"`
+	End   int `json:"end" validate:"required"`
+}
+
+func (r dateRange) ValidateStruct(errs *ValidationError) {
+	if r.End < r.Start {
+		errs.Add("end", "must be after start")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("even", func(v reflect.Value, _ string) string {
+		if v.Kind() == reflect.Int && v.Int()%2 != 0 {
+			return "must be even"
+		}
+		return ""
+	})
+
+	var in struct {
+		Count int `json:"count" validate:"even"`
+	}
+	in.Count = 3
+	err := Struct(&in)
+	verr, ok := err.(*ValidationError)
+	if !ok || verr.Errors["count"] != "must be even" {
+		t.Fatalf("Expected custom rule error, got %v", err)
+	}
+
+	in.Count = 4
+	if err := Struct(&in); err != nil {
+		t.Errorf("Expected valid, got %v", err)
+	}
+}
+
+func TestRegisterInvalidPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for invalid rule name")
+		}
+	}()
+	Register("bad,name", func(reflect.Value, string) string { return "" })
+}
+
+func TestCustomMessage(t *testing.T) {
+	var in struct {
+		Name string `json:"name" validate:"required,min=3" message:"please enter your name"`
+	}
+	verr, ok := Struct(&in).(*ValidationError)
+	if !ok || verr.Errors["name"] != "please enter your name" {
+		t.Fatalf("Expected custom message, got %v", verr)
+	}
+}
+
+func TestStructValidator(t *testing.T) {
+	var in struct {
+		Period dateRange `json:"period"`
+	}
+	in.Period = dateRange{Start: 5, End: 2}
+
+	verr, ok := Struct(&in).(*ValidationError)
+	if !ok || verr.Errors["period.end"] != "must be after start" {
+		t.Fatalf("Expected struct-level error on period.end, got %v", verr)
+	}
+
+	in.Period.End = 9
+	if err := Struct(&in); err != nil {
+		t.Errorf("Expected valid, got %v", err)
+	}
+}
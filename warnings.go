@@ -0,0 +1,128 @@
+package kese
+
+import (
+	"fmt"
+	"os"
+)
+
+// Warning describes framework behavior that is legal but likely to surprise,
+// such as a limitation of an API or a deprecated usage. Warnings are logged
+// once through app.Logger when the app starts serving, or immediately if
+// recorded later, instead of leaving the behavior silent.
+type Warning struct {
+	// Code identifies the kind of warning, e.g. "static-nested-paths".
+	// Each code is reported once per app.
+	Code string
+
+	// Component is the part of the framework the warning is about
+	Component string
+
+	// Message describes the behavior
+	Message string
+
+	// Fix describes how to avoid it
+	Fix string
+}
+
+// Warnings returns the warnings recorded so far.
+func (a *App) Warnings() []Warning {
+	a.warningsMu.Lock()
+	defer a.warningsMu.Unlock()
+
+	warnings := make([]Warning, len(a.warnings))
+	copy(warnings, a.warnings)
+	return warnings
+}
+
+// SuppressWarnings stops warnings with the given codes from being recorded
+// and logged, for apps that rely on the behavior knowingly.
+//
+// Example:
+//
+//	app.SuppressWarnings("static-nested-paths")
+func (a *App) SuppressWarnings(codes ...string) {
+	a.warningsMu.Lock()
+	defer a.warningsMu.Unlock()
+
+	if a.suppressedWarnings == nil {
+		a.suppressedWarnings = make(map[string]bool)
+	}
+	for _, code := range codes {
+		a.suppressedWarnings[code] = true
+	}
+}
+
+// warn records a warning, unless one with the same code was recorded or
+// suppressed. Once the app has started, it is logged right away.
+func (a *App) warn(w Warning) {
+	a.warningsMu.Lock()
+	if a.suppressedWarnings[w.Code] {
+		a.warningsMu.Unlock()
+		return
+	}
+	for _, existing := range a.warnings {
+		if existing.Code == w.Code {
+			a.warningsMu.Unlock()
+			return
+		}
+	}
+	a.warnings = append(a.warnings, w)
+	started := a.warningsLogged.Load()
+	a.warningsMu.Unlock()
+
+	if started {
+		a.logWarning(w)
+	}
+}
+
+// logWarnings logs the recorded warnings the first time it is called.
+// It runs when the app starts serving, from Run and friends or the first request.
+func (a *App) logWarnings() {
+	if a.warningsLogged.Load() {
+		return
+	}
+
+	a.warningsMu.Lock()
+	if a.warningsLogged.Load() {
+		a.warningsMu.Unlock()
+		return
+	}
+	a.warningsLogged.Store(true)
+	warnings := make([]Warning, len(a.warnings))
+	copy(warnings, a.warnings)
+	a.warningsMu.Unlock()
+
+	for _, w := range warnings {
+		a.logWarning(w)
+	}
+}
+
+// logWarning logs a single warning.
+func (a *App) logWarning(w Warning) {
+	fields := []interface{}{"code", w.Code, "component", w.Component}
+	if w.Fix != "" {
+		fields = append(fields, "fix", w.Fix)
+	}
+	a.Logger.Warn("kese: "+w.Message, fields...)
+}
+
+// warnNestedStatic warns if a directory served with Static has
+// subdirectories, whose files Static cannot serve.
+func (a *App) warnNestedStatic(urlPrefix, fsPath string) {
+	entries, err := os.ReadDir(fsPath)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			a.warn(Warning{
+				Code:      "static-nested-paths",
+				Component: "static",
+				Message: fmt.Sprintf("Static(%q, %q) serves a single path level; files in subdirectories such as %s/ are not reachable",
+					urlPrefix, fsPath, entry.Name()),
+				Fix: "flatten the directory, register a Static call per subdirectory, or suppress the warning",
+			})
+			return
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Package xlsx writes Excel workbooks (Office Open XML spreadsheets) without
+// external dependencies. It covers report exports: sheets of rows holding
+// text, numbers, booleans and dates, with no formulas or formatting.
+//
+// Example:
+//
+//	err := xlsx.Write(w, xlsx.Sheet{
+//	    Name: "Orders",
+//	    Rows: [][]interface{}{
+//	        {"ID", "Customer", "Total", "Placed"},
+//	        {1042, "Ada Lovelace", 99.5, order.PlacedAt},
+//	    },
+//	})
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MIMEType is the content type of workbooks.
+const MIMEType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Sheet is a worksheet of a workbook.
+type Sheet struct {
+	// Name is shown on the sheet's tab; at most 31 characters without
+	// []:*?/\. Default: "Sheet1", "Sheet2", ...
+	Name string
+
+	// Rows holds the cells, row by row. Cells may be strings, integers,
+	// floats, bools, time.Time (shown as dates), fmt.Stringer or nil for an
+	// empty cell; other values are written with fmt.Sprint.
+	Rows [][]interface{}
+}
+
+// Write writes a workbook holding sheets to w. It returns an error for
+// invalid or duplicate sheet names.
+func Write(w io.Writer, sheets ...Sheet) error {
+	if len(sheets) == 0 {
+		sheets = []Sheet{{}}
+	}
+	names := make([]string, len(sheets))
+	seen := make(map[string]bool, len(sheets))
+	for i, sheet := range sheets {
+		name := sheet.Name
+		if name == "" {
+			name = "Sheet" + strconv.Itoa(i+1)
+		}
+		if len([]rune(name)) > 31 || strings.ContainsAny(name, `[]:*?/\`) {
+			return fmt.Errorf("xlsx: invalid sheet name %q", name)
+		}
+		if seen[strings.ToLower(name)] {
+			return fmt.Errorf("xlsx: duplicate sheet name %q", name)
+		}
+		seen[strings.ToLower(name)] = true
+		names[i] = name
+	}
+
+	z := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		content func(io.Writer) error
+	}{
+		{"[Content_Types].xml", func(w io.Writer) error { return writeContentTypes(w, len(sheets)) }},
+		{"_rels/.rels", writeString(rootRels)},
+		{"xl/workbook.xml", func(w io.Writer) error { return writeWorkbook(w, names) }},
+		{"xl/_rels/workbook.xml.rels", func(w io.Writer) error { return writeWorkbookRels(w, len(sheets)) }},
+		{"xl/styles.xml", writeString(styles)},
+	}
+	for i, sheet := range sheets {
+		rows := sheet.Rows
+		files = append(files, struct {
+			name    string
+			content func(io.Writer) error
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), func(w io.Writer) error { return writeSheet(w, rows) }})
+	}
+
+	for _, file := range files {
+		fw, err := z.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if err := file.content(fw); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// styles defines style 1 as a date and time, used for time.Time cells.
+const styles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+	`<borders count="1"><border/></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+	`</styleSheet>`
+
+func writeString(s string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+func writeContentTypes(w io.Writer, sheets int) error {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeWorkbook(w io.Writer, names []string) error {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range names {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeWorkbookRels(w io.Writer, sheets int) error {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSheet writes the worksheet XML for rows, streaming row by row.
+func writeSheet(w io.Writer, rows [][]interface{}) error {
+	if _, err := io.WriteString(w, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for r, row := range rows {
+		b.Reset()
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for col, value := range row {
+			writeCell(&b, cellRef(col, r), value)
+		}
+		b.WriteString(`</row>`)
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, `</sheetData></worksheet>`)
+	return err
+}
+
+// writeCell writes the <c> element of value at ref; nil writes nothing.
+func writeCell(b *strings.Builder, ref string, value interface{}) {
+	number := func(s string) {
+		fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, s)
+	}
+	switch v := value.(type) {
+	case nil:
+	case string:
+		inlineString(b, ref, v)
+	case bool:
+		n := "0"
+		if v {
+			n = "1"
+		}
+		fmt.Fprintf(b, `<c r="%s" t="b"><v>%s</v></c>`, ref, n)
+	case float32:
+		number(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case float64:
+		number(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		fmt.Fprintf(b, `<c r="%s" s="1"><v>%s</v></c>`, ref, strconv.FormatFloat(serialDate(v), 'f', -1, 64))
+	case fmt.Stringer:
+		inlineString(b, ref, v.String())
+	default:
+		switch rv := reflect.ValueOf(v); rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			number(strconv.FormatInt(rv.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			number(strconv.FormatUint(rv.Uint(), 10))
+		default:
+			inlineString(b, ref, fmt.Sprint(v))
+		}
+	}
+}
+
+func inlineString(b *strings.Builder, ref, s string) {
+	fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(s))
+}
+
+// excelEpoch is day 0 of Excel's date serial numbers.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// serialDate converts t to an Excel serial date in t's own time zone, as
+// Excel dates carry no zone.
+func serialDate(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+// cellRef returns the A1 reference of the zero-based col and row.
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+// escape escapes s for XML text and attributes, replacing characters XML
+// cannot hold.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readPart returns the content of the named part of the workbook in data.
+func readPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Workbook is not a zip: %v", err)
+	}
+	f, err := z.Open(name)
+	if err != nil {
+		t.Fatalf("Missing part %s: %v", name, err)
+	}
+	defer f.Close()
+	content, _ := io.ReadAll(f)
+	return string(content)
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf,
+		Sheet{Name: "Orders", Rows: [][]interface{}{
+			{"ID", "Customer", "Paid", "Placed"},
+			{int64(1042), "Ada & Co <ltd>", true, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+			{uint8(7), nil, 9.5},
+		}},
+		Sheet{},
+	)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	workbook := readPart(t, buf.Bytes(), "xl/workbook.xml")
+	if !strings.Contains(workbook, `name="Orders"`) || !strings.Contains(workbook, `name="Sheet2"`) {
+		t.Errorf("Unexpected workbook %s", workbook)
+	}
+
+	sheet := readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	for _, want := range []string{
+		`<c r="A2"><v>1042</v></c>`,
+		`<t xml:space="preserve">Ada &amp; Co &lt;ltd&gt;</t>`,
+		`<c r="C2" t="b"><v>1</v></c>`,
+		`<c r="D2" s="1"><v>45292.5</v></c>`,
+		`<c r="A3"><v>7</v></c>`,
+		`<c r="C3"><v>9.5</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("Expected %s in sheet:\n%s", want, sheet)
+		}
+	}
+	if strings.Contains(sheet, `r="B3"`) {
+		t.Error("Expected nil cell to be omitted")
+	}
+	readPart(t, buf.Bytes(), "xl/worksheets/sheet2.xml")
+}
+
+func TestWriteInvalidSheetName(t *testing.T) {
+	if err := Write(io.Discard, Sheet{Name: "Q1/Q2"}); err == nil {
+		t.Error("Expected error for sheet name with /")
+	}
+	if err := Write(io.Discard, Sheet{Name: "Data"}, Sheet{Name: "data"}); err == nil {
+		t.Error("Expected error for duplicate sheet names")
+	}
+}
+
+func TestCellRef(t *testing.T) {
+	tests := map[[2]int]string{
+		{0, 0}:   "A1",
+		{25, 9}:  "Z10",
+		{26, 0}:  "AA1",
+		{701, 0}: "ZZ1",
+		{702, 0}: "AAA1",
+	}
+	for in, want := range tests {
+		if got := cellRef(in[0], in[1]); got != want {
+			t.Errorf("cellRef(%d, %d) = %s, want %s", in[0], in[1], got, want)
+		}
+	}
+}